@@ -0,0 +1,190 @@
+package agentscript
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CrashError is returned by Run when a crash step fires.
+type CrashError struct {
+	Code int
+}
+
+func (e *CrashError) Error() string {
+	return fmt.Sprintf("scripted crash (exit %d)", e.Code)
+}
+
+// Runner replays a Script against a terminal.
+type Runner struct {
+	Script *Script
+
+	// Dir is the working directory for commands and commits.
+	Dir string
+
+	// Stdin and Stdout are the agent's terminal.
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	// BinDir, if set, is put first on PATH for run steps, so "gt" resolves
+	// to the binary hosting the runner.
+	BinDir string
+
+	// Attempt is the launch number used to filter steps (see Step.Attempt).
+	Attempt int
+
+	input *bufio.Scanner
+}
+
+// Run executes the script. It returns a *CrashError for crash steps and an
+// error if a command or commit fails.
+func (r *Runner) Run() error {
+	r.input = bufio.NewScanner(r.Stdin)
+
+	for i, step := range r.Script.Steps {
+		if step.Attempt != 0 && step.Attempt != r.Attempt {
+			continue
+		}
+		if err := r.runStep(step); err != nil {
+			if _, ok := err.(*CrashError); ok {
+				return err
+			}
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	if r.Script.Finish == FinishExit {
+		return nil
+	}
+	// Idle at the prompt like an interactive agent with nothing to do.
+	for {
+		r.printf("\n%s", r.Script.prompt())
+		if !r.input.Scan() {
+			return nil
+		}
+	}
+}
+
+func (r *Runner) runStep(step Step) error {
+	switch {
+	case step.Say != "":
+		r.printf("%s\n", os.ExpandEnv(step.Say))
+
+	case step.Wait != nil:
+		re := regexp.MustCompile(*step.Wait) // validated in Script.Validate
+		for {
+			r.printf("\n%s", r.Script.prompt())
+			if !r.input.Scan() {
+				return fmt.Errorf("input closed while waiting for %q", *step.Wait)
+			}
+			if re.MatchString(cleanInput(r.input.Text())) {
+				return nil
+			}
+		}
+
+	case step.Run != "":
+		r.printf("● Bash(%s)\n", step.Run)
+		if err := r.command("sh", "-c", step.Run); err != nil && !step.IgnoreError {
+			return fmt.Errorf("%s: %w", step.Run, err)
+		}
+
+	case step.Commit != nil:
+		return r.commit(step.Commit)
+
+	case step.Sleep != "":
+		d, _ := time.ParseDuration(step.Sleep) // validated in Script.Validate
+		time.Sleep(d)
+
+	case step.RateLimit != "":
+		r.printf("You've hit your limit · resets %s\n", step.RateLimit)
+
+	case step.Crash != 0:
+		r.printf("Error: scripted crash\n")
+		return &CrashError{Code: step.Crash}
+	}
+	return nil
+}
+
+// commit writes the step's files and commits them.
+func (r *Runner) commit(c *Commit) error {
+	args := []string{"commit", "--allow-empty", "-m", os.ExpandEnv(c.Message)}
+	if len(c.Files) > 0 {
+		paths := make([]string, 0, len(c.Files))
+		for name, content := range c.Files {
+			path := filepath.Join(r.Dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(os.ExpandEnv(content)), 0644); err != nil {
+				return err
+			}
+			paths = append(paths, name)
+		}
+		if err := r.command("git", append([]string{"add", "--"}, paths...)...); err != nil {
+			return fmt.Errorf("git add: %w", err)
+		}
+	}
+	if err := r.command("git", args...); err != nil {
+		return fmt.Errorf("git commit: %w", err)
+	}
+	return nil
+}
+
+// command runs a process in Dir with its output on the agent's terminal.
+func (r *Runner) command(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = r.Dir
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stdout
+	cmd.Env = os.Environ()
+	if r.BinDir != "" {
+		cmd.Env = append(cmd.Env, "PATH="+r.BinDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+	return cmd.Run()
+}
+
+func (r *Runner) printf(format string, args ...any) {
+	fmt.Fprintf(r.Stdout, format, args...)
+}
+
+// cleanInput strips the escape and control characters a nudge carries
+// along with its text.
+func cleanInput(s string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s))
+}
+
+// NextAttempt increments and returns the launch counter for a script run in
+// a working directory. The counter lives under stateDir so that it survives
+// agent restarts without dirtying the worktree.
+func NextAttempt(stateDir, scriptPath, workDir string) (int, error) {
+	sum := sha256.Sum256([]byte(scriptPath + "\x00" + workDir))
+	path := filepath.Join(stateDir, hex.EncodeToString(sum[:8]))
+
+	attempt := 1
+	if data, err := os.ReadFile(path); err == nil {
+		if n, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			attempt = n + 1
+		}
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(attempt)), 0644); err != nil {
+		return 0, err
+	}
+	return attempt, nil
+}
@@ -0,0 +1,136 @@
+package agentscript
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func runScript(t *testing.T, dir, script, input string, attempt int) (string, error) {
+	t.Helper()
+	s, err := Parse([]byte(script))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var out bytes.Buffer
+	r := &Runner{
+		Script:  s,
+		Dir:     dir,
+		Stdin:   strings.NewReader(input),
+		Stdout:  &out,
+		Attempt: attempt,
+	}
+	err = r.Run()
+	return out.String(), err
+}
+
+func TestRunnerPropulsion(t *testing.T) {
+	dir := initRepo(t)
+	t.Setenv("GT_POLECAT", "Toast")
+
+	script := `
+steps:
+  - say: "$GT_POLECAT starting"
+  - wait: "^gt prime"
+  - run: echo hooked > hook.out
+  - commit:
+      message: "Fix widget"
+      files:
+        sub/widget.txt: "fixed by $GT_POLECAT\n"
+  - rate_limit: 7pm
+`
+	// The nudge arrives with a trailing escape, as NudgeSession sends it.
+	out, err := runScript(t, dir, script, "hello\ngt prime && gt mail check --inject\x1b\n", 1)
+	if err != nil {
+		t.Fatalf("Run: %v\n%s", err, out)
+	}
+
+	for _, want := range []string{"Toast starting", DefaultPrompt, "You've hit your limit · resets 7pm"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "hook.out")); string(data) != "hooked\n" {
+		t.Errorf("run step output file = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "widget.txt")); string(data) != "fixed by Toast\n" {
+		t.Errorf("committed file = %q", data)
+	}
+	logCmd := exec.Command("git", "log", "--format=%s", "-1", "--", "sub/widget.txt")
+	logCmd.Dir = dir
+	if log, err := logCmd.Output(); err != nil || strings.TrimSpace(string(log)) != "Fix widget" {
+		t.Errorf("git log = (%q, %v)", log, err)
+	}
+}
+
+func TestRunnerCrashOnFirstAttempt(t *testing.T) {
+	script := `
+steps:
+  - crash: 3
+    attempt: 1
+  - say: recovered
+finish: exit
+`
+	_, err := runScript(t, t.TempDir(), script, "", 1)
+	var crash *CrashError
+	if !errors.As(err, &crash) || crash.Code != 3 {
+		t.Fatalf("attempt 1 error = %v, want crash with code 3", err)
+	}
+
+	out, err := runScript(t, t.TempDir(), script, "", 2)
+	if err != nil || !strings.Contains(out, "recovered") {
+		t.Errorf("attempt 2 = (%q, %v), want recovery", out, err)
+	}
+}
+
+func TestRunnerFailures(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := runScript(t, dir, "steps: [{run: exit 4}]", "", 1); err == nil || !strings.Contains(err.Error(), "step 1") {
+		t.Errorf("failed run error = %v, want step error", err)
+	}
+	if _, err := runScript(t, dir, "steps: [{run: exit 4, ignore_error: true}]\nfinish: exit", "", 1); err != nil {
+		t.Errorf("ignored failure error = %v", err)
+	}
+	if _, err := runScript(t, dir, "steps: [{wait: never}]", "nope\n", 1); err == nil {
+		t.Error("wait on closed input succeeded")
+	}
+	// Idle finish returns once input closes.
+	if out, err := runScript(t, dir, "steps: []", "ignored\n", 1); err != nil || strings.Count(out, DefaultPrompt) != 2 {
+		t.Errorf("idle = (%q, %v)", out, err)
+	}
+}
+
+func TestNextAttempt(t *testing.T) {
+	stateDir := t.TempDir()
+	for want := 1; want <= 3; want++ {
+		got, err := NextAttempt(stateDir, "/s.yaml", "/work/a")
+		if err != nil || got != want {
+			t.Fatalf("NextAttempt = (%d, %v), want %d", got, err, want)
+		}
+	}
+	if got, _ := NextAttempt(stateDir, "/s.yaml", "/work/b"); got != 1 {
+		t.Errorf("NextAttempt for another workdir = %d, want 1", got)
+	}
+}
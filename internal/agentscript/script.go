@@ -0,0 +1,179 @@
+// Package agentscript implements the "scripted" agent runtime: a fake agent
+// that replays a YAML or JSON script instead of calling an LLM.
+//
+// A scripted agent behaves like a real one from Gas Town's point of view. It
+// prints the ready prompt, reacts to nudges, runs gt commands (hook, done,
+// mail), makes commits, and can crash or show a rate-limit banner on cue.
+// This lets whole-town scenarios (sling → polecat → done → refinery merge →
+// convoy close) run deterministically in tests.
+//
+// Example script:
+//
+//	steps:
+//	  - wait: "gt prime"        # block until the startup nudge arrives
+//	  - run: gt hook
+//	  - commit:
+//	      message: "Fix the widget"
+//	      files:
+//	        widget.txt: "fixed by $GT_POLECAT\n"
+//	  - crash: 1
+//	    attempt: 1              # only crash on the first launch
+//	  - run: gt done
+//	finish: exit
+package agentscript
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPrompt is the ready prompt printed whenever the agent waits for
+// input. It matches the scripted preset's ReadyPromptPrefix.
+const DefaultPrompt = "❯ "
+
+// Finish behaviors once all steps have run.
+const (
+	// FinishIdle keeps the agent at its prompt, reading input until EOF,
+	// like an interactive agent that has nothing left to do.
+	FinishIdle = "idle"
+	// FinishExit exits with status 0.
+	FinishExit = "exit"
+)
+
+// Script is a scripted agent's behavior.
+type Script struct {
+	// Prompt overrides DefaultPrompt.
+	Prompt string `yaml:"prompt,omitempty"`
+
+	// Steps run in order. Each step performs exactly one action.
+	Steps []Step `yaml:"steps"`
+
+	// Finish is FinishIdle (default) or FinishExit.
+	Finish string `yaml:"finish,omitempty"`
+}
+
+// Step is one scripted action.
+type Step struct {
+	// Say prints text, as if the agent were thinking out loud.
+	Say string `yaml:"say,omitempty"`
+
+	// Wait prints the prompt and reads input lines until one matches this
+	// regular expression. Use ".*" (or "") to accept any line, e.g. a nudge.
+	Wait *string `yaml:"wait,omitempty"`
+
+	// Run executes a shell command in the agent's working directory.
+	// The running gt binary is first on PATH, so "gt done" reaches it.
+	Run string `yaml:"run,omitempty"`
+
+	// IgnoreError continues the script when Run fails. By default a failed
+	// command makes the agent exit with status 1.
+	IgnoreError bool `yaml:"ignore_error,omitempty"`
+
+	// Commit writes files and commits them.
+	Commit *Commit `yaml:"commit,omitempty"`
+
+	// Sleep pauses for a duration such as "500ms" or "2s".
+	Sleep string `yaml:"sleep,omitempty"`
+
+	// RateLimit prints a rate-limit banner with this reset time (e.g. "7pm"),
+	// matching constants.DefaultRateLimitPatterns.
+	RateLimit string `yaml:"rate_limit,omitempty"`
+
+	// Crash exits immediately with this (non-zero) status.
+	Crash int `yaml:"crash,omitempty"`
+
+	// Attempt restricts the step to the Nth launch of the agent in the same
+	// working directory (1 = first launch). Zero runs it on every launch.
+	// Use it to crash once and then recover after a respawn.
+	Attempt int `yaml:"attempt,omitempty"`
+}
+
+// Commit describes a scripted git commit.
+type Commit struct {
+	Message string `yaml:"message"`
+	// Files maps paths (relative to the working directory) to contents.
+	// With no files, an empty commit is made.
+	Files map[string]string `yaml:"files,omitempty"`
+}
+
+// Load reads and validates a script file. JSON is accepted as YAML.
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading agent script: %w", err)
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes and validates a script.
+func Parse(data []byte) (*Script, error) {
+	var s Script
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing agent script: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that every step has exactly one well-formed action.
+func (s *Script) Validate() error {
+	switch s.Finish {
+	case "", FinishIdle, FinishExit:
+	default:
+		return fmt.Errorf("finish must be %q or %q, got %q", FinishIdle, FinishExit, s.Finish)
+	}
+	for i, step := range s.Steps {
+		n := i + 1
+		if actions := step.actions(); actions != 1 {
+			return fmt.Errorf("step %d: want exactly one action, got %d", n, actions)
+		}
+		if step.Wait != nil {
+			if _, err := regexp.Compile(*step.Wait); err != nil {
+				return fmt.Errorf("step %d: invalid wait pattern: %w", n, err)
+			}
+		}
+		if step.Sleep != "" {
+			if _, err := time.ParseDuration(step.Sleep); err != nil {
+				return fmt.Errorf("step %d: invalid sleep: %w", n, err)
+			}
+		}
+		if step.Commit != nil && step.Commit.Message == "" {
+			return fmt.Errorf("step %d: commit needs a message", n)
+		}
+		if step.Crash < 0 || step.Attempt < 0 {
+			return fmt.Errorf("step %d: crash and attempt must not be negative", n)
+		}
+	}
+	return nil
+}
+
+// prompt returns the script's ready prompt.
+func (s *Script) prompt() string {
+	if s.Prompt != "" {
+		return s.Prompt
+	}
+	return DefaultPrompt
+}
+
+func (st Step) actions() int {
+	n := 0
+	for _, set := range []bool{
+		st.Say != "", st.Wait != nil, st.Run != "", st.Commit != nil,
+		st.Sleep != "", st.RateLimit != "", st.Crash != 0,
+	} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,81 @@
+package agentscript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`
+steps:
+  - wait: "gt prime"
+  - run: gt hook
+  - commit:
+      message: fix
+      files: {a.txt: "x"}
+  - crash: 2
+    attempt: 1
+finish: exit
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(s.Steps) != 4 || s.Finish != FinishExit {
+		t.Fatalf("Parse = %+v", s)
+	}
+	if s.Steps[0].Wait == nil || *s.Steps[0].Wait != "gt prime" {
+		t.Errorf("wait step = %+v", s.Steps[0])
+	}
+	if s.Steps[2].Commit.Files["a.txt"] != "x" {
+		t.Errorf("commit step = %+v", s.Steps[2].Commit)
+	}
+	if s.Steps[3].Crash != 2 || s.Steps[3].Attempt != 1 {
+		t.Errorf("crash step = %+v", s.Steps[3])
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	s, err := Parse([]byte(`{"steps": [{"say": "hi"}, {"rate_limit": "7pm"}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(s.Steps) != 2 || s.Steps[1].RateLimit != "7pm" {
+		t.Errorf("Parse = %+v", s)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"no action", "steps: [{attempt: 1}]", "exactly one action"},
+		{"two actions", "steps: [{say: a, run: b}]", "exactly one action"},
+		{"bad wait", `steps: [{wait: "("}]`, "invalid wait pattern"},
+		{"bad sleep", "steps: [{sleep: soon}]", "invalid sleep"},
+		{"commit without message", "steps: [{commit: {files: {a: b}}}]", "needs a message"},
+		{"bad finish", "finish: later", "finish must be"},
+		{"unknown field type", "steps: [{crash: boom}]", "parsing agent script"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.script))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultPromptMatchesPreset(t *testing.T) {
+	preset := config.GetAgentPreset(config.AgentScripted)
+	if preset == nil {
+		t.Fatal("scripted preset not registered")
+	}
+	if preset.ReadyPromptPrefix != DefaultPrompt {
+		t.Errorf("preset ReadyPromptPrefix = %q, want %q", preset.ReadyPromptPrefix, DefaultPrompt)
+	}
+}
@@ -824,14 +824,24 @@ func (b *Beads) ReleaseWithReason(id, reason string) error {
 }
 
 // AddDependency adds a dependency: issue depends on dependsOn.
+// dependsOn may be a hop:// or beads:// URI (see DependencyRef).
 func (b *Beads) AddDependency(issue, dependsOn string) error {
-	_, err := b.run("dep", "add", issue, dependsOn)
+	ref, err := DependencyRef(b.getTownRoot(), dependsOn)
+	if err != nil {
+		return err
+	}
+	_, err = b.run("dep", "add", issue, ref)
 	return err
 }
 
 // RemoveDependency removes a dependency.
+// dependsOn may be a hop:// or beads:// URI (see DependencyRef).
 func (b *Beads) RemoveDependency(issue, dependsOn string) error {
-	_, err := b.run("dep", "remove", issue, dependsOn)
+	ref, err := DependencyRef(b.getTownRoot(), dependsOn)
+	if err != nil {
+		return err
+	}
+	_, err = b.run("dep", "remove", issue, ref)
 	return err
 }
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -590,13 +591,18 @@ author: someone`,
 // TestMRFieldsRoundTrip tests that parse/format round-trips correctly.
 func TestMRFieldsRoundTrip(t *testing.T) {
 	original := &MRFields{
-		Branch:      "polecat/Nux/gt-xyz",
-		Target:      "main",
-		SourceIssue: "gt-xyz",
-		Worker:      "Nux",
-		Rig:         "gastown",
-		MergeCommit: "abc123def789",
-		CloseReason: "merged",
+		Branch:       "polecat/Nux/gt-xyz",
+		Target:       "main",
+		SourceIssue:  "gt-xyz",
+		Worker:       "Nux",
+		Rig:          "gastown",
+		MergeCommit:  "abc123def789",
+		CloseReason:  "merged",
+		Phase:        "awaiting-approval",
+		ApprovedBy:   []string{"alice", "bob"},
+		ApprovedHead: "0123abcd",
+		PRNumber:     42,
+		PRURL:        "https://github.com/o/r/pull/42",
 	}
 
 	// Format to string
@@ -610,7 +616,7 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		t.Fatal("round-trip parse returned nil")
 	}
 
-	if *parsed != *original {
+	if !reflect.DeepEqual(parsed, original) {
 		t.Errorf("round-trip mismatch:\ngot  %+v\nwant %+v", parsed, original)
 	}
 }
@@ -237,6 +237,15 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Human approval (for MRs touching paths that require sign-off)
+	Phase        string   // MR phase when not plain ready (e.g., "awaiting-approval")
+	ApprovedBy   []string // Humans who approved the MR
+	ApprovedHead string   // Branch head the approvals were given for
+
+	// Forge pull request (merge_mode pull_request)
+	PRNumber int    // Pull request number on the forge
+	PRURL    string // Pull request web URL
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -311,6 +320,27 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "phase":
+			fields.Phase = value
+			hasFields = true
+		case "approved_by", "approved-by", "approvedby":
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					fields.ApprovedBy = append(fields.ApprovedBy, name)
+				}
+			}
+			hasFields = true
+		case "approved_head", "approved-head", "approvedhead":
+			fields.ApprovedHead = value
+			hasFields = true
+		case "pr_number", "pr-number", "prnumber":
+			if n, err := parseIntField(value); err == nil {
+				fields.PRNumber = n
+				hasFields = true
+			}
+		case "pr_url", "pr-url", "prurl":
+			fields.PRURL = value
+			hasFields = true
 		}
 	}
 
@@ -375,6 +405,21 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.Phase != "" {
+		lines = append(lines, "phase: "+fields.Phase)
+	}
+	if len(fields.ApprovedBy) > 0 {
+		lines = append(lines, "approved_by: "+strings.Join(fields.ApprovedBy, ", "))
+	}
+	if fields.ApprovedHead != "" {
+		lines = append(lines, "approved_head: "+fields.ApprovedHead)
+	}
+	if fields.PRNumber > 0 {
+		lines = append(lines, fmt.Sprintf("pr_number: %d", fields.PRNumber))
+	}
+	if fields.PRURL != "" {
+		lines = append(lines, "pr_url: "+fields.PRURL)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -421,6 +466,19 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"phase":              true,
+		"approved_by":        true,
+		"approved-by":        true,
+		"approvedby":         true,
+		"approved_head":      true,
+		"approved-head":      true,
+		"approvedhead":       true,
+		"pr_number":          true,
+		"pr-number":          true,
+		"prnumber":           true,
+		"pr_url":             true,
+		"pr-url":             true,
+		"prurl":              true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -0,0 +1,429 @@
+package beads
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Work unit URI schemes (see docs/design/federation.md).
+//
+//	hop://entity/chain/rig/issue-id     e.g. hop://steve@example.com/main-town/greenplace/gp-xyz
+//	beads://platform/org/repo/issue-id  e.g. beads://github/acme/backend/ac-123
+const (
+	URISchemeHop   = "hop"
+	URISchemeBeads = "beads"
+)
+
+// ErrDoltRemoteURI is returned when a work unit URI resolves to a Dolt remote.
+// Those issues are only readable after pulling the remote database.
+var ErrDoltRemoteURI = errors.New("issue lives in a Dolt remote; pull the database to read it")
+
+// WorkURI is a parsed hop:// or beads:// work unit reference.
+// The path segments keep their hop:// names; for beads:// URIs Entity is the
+// platform, Chain the org and Rig the repo.
+type WorkURI struct {
+	Scheme  string
+	Entity  string
+	Chain   string
+	Rig     string
+	IssueID string
+}
+
+// IsWorkURI reports whether s looks like a hop:// or beads:// reference.
+func IsWorkURI(s string) bool {
+	return strings.HasPrefix(s, URISchemeHop+"://") || strings.HasPrefix(s, URISchemeBeads+"://")
+}
+
+// ParseWorkURI parses a hop:// or beads:// work unit reference.
+func ParseWorkURI(s string) (*WorkURI, error) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || (scheme != URISchemeHop && scheme != URISchemeBeads) {
+		return nil, fmt.Errorf("invalid work unit URI %q: expected hop:// or beads://", s)
+	}
+	parts := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid work unit URI %q: expected %s://%s/<issue-id>", s, scheme, uriSegmentNames(scheme))
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("invalid work unit URI %q: empty path segment", s)
+		}
+	}
+	return &WorkURI{
+		Scheme:  scheme,
+		Entity:  parts[0],
+		Chain:   parts[1],
+		Rig:     parts[2],
+		IssueID: parts[3],
+	}, nil
+}
+
+func uriSegmentNames(scheme string) string {
+	if scheme == URISchemeBeads {
+		return "<platform>/<org>/<repo>"
+	}
+	return "<entity>/<chain>/<rig>"
+}
+
+// String returns the URI in canonical form.
+func (u *WorkURI) String() string {
+	return fmt.Sprintf("%s://%s/%s/%s/%s", u.Scheme, u.Entity, u.Chain, u.Rig, u.IssueID)
+}
+
+// ExternalRef returns the dependency target for a work unit owned by another
+// town, in the external:<project>:<id> form bd uses for cross-database
+// edges. ExtractIssueID unwraps it back to the URI.
+func (u *WorkURI) ExternalRef() string {
+	return "external:" + u.Chain + ":" + u.String()
+}
+
+// URITargetKind says where a work unit URI resolved to.
+type URITargetKind string
+
+const (
+	// URITargetLocal is an issue in this town, reachable through routes.jsonl.
+	URITargetLocal URITargetKind = "local"
+	// URITargetMachine is an issue in the town on a registered machine.
+	URITargetMachine URITargetKind = "machine"
+	// URITargetDoltRemote is an issue in a Dolt remote of one of our databases.
+	URITargetDoltRemote URITargetKind = "dolt-remote"
+)
+
+// URITarget is the resolved location of a work unit URI.
+type URITarget struct {
+	URI  *WorkURI
+	Kind URITargetKind
+
+	// IssueID is the bead ID within the owning database.
+	IssueID string
+
+	// TownRoot and BeadsDir locate local issues.
+	TownRoot string
+	BeadsDir string
+
+	// Machine and TownPath locate issues in a town on a registered machine.
+	Machine  string
+	TownPath string
+	conn     connection.Connection
+
+	// Database, Remote and RemoteURL locate issues in a Dolt remote.
+	Database  string
+	Remote    string
+	RemoteURL string
+}
+
+// IsLocal reports whether the issue lives in this town.
+func (t *URITarget) IsLocal() bool {
+	return t.Kind == URITargetLocal
+}
+
+// Describe returns a short human-readable description of the location.
+func (t *URITarget) Describe() string {
+	switch t.Kind {
+	case URITargetMachine:
+		return fmt.Sprintf("town on machine %s (%s)", t.Machine, t.TownPath)
+	case URITargetDoltRemote:
+		return fmt.Sprintf("Dolt remote %s of database %s (%s)", t.Remote, t.Database, t.RemoteURL)
+	default:
+		return fmt.Sprintf("local beads (%s)", t.BeadsDir)
+	}
+}
+
+// ResolveWorkURI maps a hop:// or beads:// reference to the town that owns
+// the issue. Resolution order:
+//
+//  1. Local: hop:// chain/entity match this town's identity (mayor/town.json),
+//     or beads:// org/repo match a rig's git URL. The issue must route
+//     through routes.jsonl.
+//  2. Machine: the hop:// chain names a machine in mayor/machines.json that
+//     has a town_path. Towns register each other under their town names.
+//  3. Dolt remote: a database in .dolt-data has a remote named after the
+//     chain (hop://) or whose URL ends in org/repo (beads://).
+func ResolveWorkURI(townRoot, ref string) (*URITarget, error) {
+	uri, err := ParseWorkURI(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := isLocalWorkURI(townRoot, uri)
+	if err != nil {
+		return nil, err
+	}
+	if local {
+		return resolveLocalWorkURI(townRoot, uri)
+	}
+
+	if uri.Scheme == URISchemeHop {
+		if target, err := resolveMachineWorkURI(townRoot, uri); err != nil || target != nil {
+			return target, err
+		}
+	}
+
+	if target := resolveDoltRemoteWorkURI(townRoot, uri); target != nil {
+		return target, nil
+	}
+
+	return nil, fmt.Errorf("cannot resolve %s: no local rig, registered machine or Dolt remote matches", ref)
+}
+
+// isLocalWorkURI reports whether the URI names this town.
+func isLocalWorkURI(townRoot string, uri *WorkURI) (bool, error) {
+	if uri.Scheme == URISchemeBeads {
+		return findRigByRepo(townRoot, uri) != "", nil
+	}
+
+	townCfg, err := config.LoadTownConfig(constants.MayorTownPath(townRoot))
+	if err != nil {
+		return false, fmt.Errorf("loading town identity: %w", err)
+	}
+	if uri.Chain != townCfg.Name && (townCfg.PublicName == "" || uri.Chain != townCfg.PublicName) {
+		return false, nil
+	}
+	// An owner, when configured, must match too: two entities may both
+	// name their town "main-town".
+	return townCfg.Owner == "" || uri.Entity == townCfg.Owner, nil
+}
+
+func resolveLocalWorkURI(townRoot string, uri *WorkURI) (*URITarget, error) {
+	prefix := ExtractPrefix(uri.IssueID)
+	if prefix == "" {
+		return nil, fmt.Errorf("cannot resolve %s: issue ID %q has no prefix", uri, uri.IssueID)
+	}
+	rigPath := GetRigPathForPrefix(townRoot, prefix)
+	if rigPath == "" {
+		return nil, fmt.Errorf("cannot resolve %s: no route for prefix %q", uri, prefix)
+	}
+	// Town-level beads (hq-*) have no rig; otherwise the URI's rig must own
+	// the prefix.
+	if uri.Scheme == URISchemeHop && rigPath != townRoot {
+		if rig := GetRigNameForPrefix(townRoot, prefix); rig != uri.Rig {
+			return nil, fmt.Errorf("cannot resolve %s: prefix %q belongs to rig %s, not %s", uri, prefix, rig, uri.Rig)
+		}
+	}
+	townBeads := filepath.Join(townRoot, ".beads")
+	return &URITarget{
+		URI:      uri,
+		Kind:     URITargetLocal,
+		IssueID:  uri.IssueID,
+		TownRoot: townRoot,
+		BeadsDir: ResolveRoutingTarget(townRoot, uri.IssueID, townBeads),
+	}, nil
+}
+
+// resolveMachineWorkURI returns nil (and no error) when no machine matches.
+func resolveMachineWorkURI(townRoot string, uri *WorkURI) (*URITarget, error) {
+	registryPath := constants.MayorMachinesPath(townRoot)
+	if _, err := os.Stat(registryPath); err != nil {
+		return nil, nil
+	}
+	reg, err := connection.NewMachineRegistry(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	m, err := reg.Get(uri.Chain)
+	if err != nil || m.Type == "local" || m.TownPath == "" {
+		return nil, nil
+	}
+	conn, err := reg.Connection(uri.Chain)
+	if err != nil {
+		return nil, err
+	}
+	return &URITarget{
+		URI:      uri,
+		Kind:     URITargetMachine,
+		IssueID:  uri.IssueID,
+		TownRoot: townRoot,
+		Machine:  uri.Chain,
+		TownPath: m.TownPath,
+		conn:     conn,
+	}, nil
+}
+
+// resolveDoltRemoteWorkURI returns nil when no Dolt remote matches.
+func resolveDoltRemoteWorkURI(townRoot string, uri *WorkURI) *URITarget {
+	dataDir := filepath.Join(townRoot, ".dolt-data")
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil
+	}
+
+	// Prefer the database named after the rig, then the rest in name order.
+	var dbs []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != uri.Rig {
+			dbs = append(dbs, e.Name())
+		}
+	}
+	sort.Strings(dbs)
+	dbs = append([]string{uri.Rig}, dbs...)
+
+	for _, db := range dbs {
+		remotes := readDoltRemotes(filepath.Join(dataDir, db))
+		names := make([]string, 0, len(remotes))
+		for name := range remotes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			url := remotes[name]
+			var match bool
+			if uri.Scheme == URISchemeHop {
+				match = name == uri.Chain
+			} else {
+				match = strings.HasSuffix(strings.TrimSuffix(url, "/"), "/"+uri.Chain+"/"+uri.Rig)
+			}
+			if match {
+				return &URITarget{
+					URI:       uri,
+					Kind:      URITargetDoltRemote,
+					IssueID:   uri.IssueID,
+					TownRoot:  townRoot,
+					Database:  db,
+					Remote:    name,
+					RemoteURL: url,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readDoltRemotes reads the configured remotes (name -> URL) of a Dolt
+// database from its repo_state.json, without invoking dolt.
+func readDoltRemotes(dbDir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dbDir, ".dolt", "repo_state.json"))
+	if err != nil {
+		return nil
+	}
+	var state struct {
+		Remotes map[string]struct {
+			URL string `json:"url"`
+		} `json:"remotes"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	remotes := make(map[string]string, len(state.Remotes))
+	for name, r := range state.Remotes {
+		remotes[name] = r.URL
+	}
+	return remotes
+}
+
+// findRigByRepo returns the local rig whose git URL points at the beads://
+// org/repo on the named platform, or "" if none does.
+func findRigByRepo(townRoot string, uri *WorkURI) string {
+	rigsCfg, err := config.LoadRigsConfig(constants.MayorRigsPath(townRoot))
+	if err != nil {
+		return ""
+	}
+	want := strings.ToLower(uri.Chain + "/" + uri.Rig)
+	for name, entry := range rigsCfg.Rigs {
+		host, repoPath := splitGitURL(entry.GitURL)
+		if !strings.Contains(host, strings.ToLower(uri.Entity)) {
+			continue
+		}
+		if strings.TrimSuffix(repoPath, ".git") == want {
+			return name
+		}
+	}
+	return ""
+}
+
+// splitGitURL splits https://host/org/repo(.git) and git@host:org/repo(.git)
+// URLs into a lowercased host and path.
+func splitGitURL(url string) (host, repoPath string) {
+	url = strings.ToLower(strings.TrimSuffix(url, "/"))
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		host, repoPath, _ = strings.Cut(rest, "/")
+		if _, h, ok := strings.Cut(host, "@"); ok {
+			host = h
+		}
+		return host, repoPath
+	}
+	if at := strings.Index(url, "@"); at >= 0 {
+		url = url[at+1:]
+	}
+	host, repoPath, _ = strings.Cut(url, ":")
+	return host, repoPath
+}
+
+// Bd runs a bd command in the town that owns the issue. Local targets run
+// from the town root so prefix routing applies.
+func (t *URITarget) Bd(args ...string) ([]byte, error) {
+	return t.run("bd", args...)
+}
+
+// Gt runs a gt command in the town that owns the issue.
+func (t *URITarget) Gt(args ...string) ([]byte, error) {
+	return t.run("gt", args...)
+}
+
+func (t *URITarget) run(name string, args ...string) ([]byte, error) {
+	switch t.Kind {
+	case URITargetLocal:
+		cmd := exec.Command(name, args...) //nolint:gosec // G204: bd/gt are trusted internal tools
+		cmd.Dir = t.TownRoot
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return out, fmt.Errorf("%s %s: %w: %s", name, args[0], err, strings.TrimSpace(string(out)))
+		}
+		return out, nil
+	case URITargetMachine:
+		out, err := t.conn.ExecDir(t.TownPath, name, args...)
+		if err != nil {
+			return out, fmt.Errorf("%s %s on %s: %w: %s", name, args[0], t.Machine, err, strings.TrimSpace(string(out)))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%s: %w (%s)", t.URI, ErrDoltRemoteURI, t.Describe())
+	}
+}
+
+// Show fetches the issue from the town that owns it.
+func (t *URITarget) Show() (*Issue, error) {
+	out, err := t.Bd("show", t.IssueID, "--json")
+	if err != nil {
+		return nil, err
+	}
+	// Remote commands run with stdout and stderr combined; skip any
+	// warnings printed before the JSON document.
+	if i := strings.Index(string(out), "["); i > 0 {
+		out = out[i:]
+	}
+	var issues []Issue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("parsing bd show output for %s: %w", t.URI, err)
+	}
+	if len(issues) == 0 {
+		return nil, ErrNotFound
+	}
+	return &issues[0], nil
+}
+
+// DependencyRef returns the bd dependency target for ref. Plain bead IDs are
+// returned unchanged; work unit URIs owned by this town resolve to their
+// local bead ID, and URIs owned by other towns become external references.
+func DependencyRef(townRoot, ref string) (string, error) {
+	if !IsWorkURI(ref) {
+		return ref, nil
+	}
+	target, err := ResolveWorkURI(townRoot, ref)
+	if err != nil {
+		return "", err
+	}
+	if target.IsLocal() {
+		return target.IssueID, nil
+	}
+	return target.URI.ExternalRef(), nil
+}
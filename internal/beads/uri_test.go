@@ -0,0 +1,237 @@
+package beads
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupURITown creates a town with an identity, routes, a rig registry,
+// a machine registry and a Dolt database with a remote.
+func setupURITown(t *testing.T) string {
+	t.Helper()
+	townRoot := t.TempDir()
+
+	files := map[string]string{
+		"mayor/town.json": `{"type":"town","version":1,"name":"main-town","owner":"steve@example.com"}`,
+		"mayor/rigs.json": `{"version":1,"rigs":{"gastown":{"git_url":"git@github.com:steveyegge/gastown.git"}}}`,
+		"mayor/machines.json": `{"version":1,"machines":{
+			"platform-town":{"type":"ssh","host":"gt@platform","town_path":"/srv/gt"},
+			"nopath":{"type":"ssh","host":"gt@nopath"}}}`,
+		".beads/routes.jsonl": `{"prefix":"gt-","path":"gastown/mayor/rig"}
+{"prefix":"hq-","path":"."}
+`,
+		".dolt-data/gastown/.dolt/repo_state.json": `{"head":"refs/heads/main","remotes":{
+			"origin":{"name":"origin","url":"https://doltremoteapi.dolthub.com/acme/backend"},
+			"partner-town":{"name":"partner-town","url":"http://partner.example.com:8000/gastown"}}}`,
+	}
+	for rel, content := range files {
+		p := filepath.Join(townRoot, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return townRoot
+}
+
+func TestParseWorkURI(t *testing.T) {
+	uri, err := ParseWorkURI("hop://steve@example.com/main-town/greenplace/gp-xyz")
+	if err != nil {
+		t.Fatalf("ParseWorkURI: %v", err)
+	}
+	want := WorkURI{Scheme: "hop", Entity: "steve@example.com", Chain: "main-town", Rig: "greenplace", IssueID: "gp-xyz"}
+	if *uri != want {
+		t.Errorf("ParseWorkURI = %+v, want %+v", *uri, want)
+	}
+	if uri.String() != "hop://steve@example.com/main-town/greenplace/gp-xyz" {
+		t.Errorf("String() = %q", uri.String())
+	}
+
+	uri, err = ParseWorkURI("beads://github/acme/backend/ac-123/")
+	if err != nil {
+		t.Fatalf("ParseWorkURI beads: %v", err)
+	}
+	if uri.Entity != "github" || uri.Chain != "acme" || uri.Rig != "backend" || uri.IssueID != "ac-123" {
+		t.Errorf("ParseWorkURI beads = %+v", *uri)
+	}
+
+	for _, bad := range []string{
+		"gp-xyz",
+		"http://a/b/c/d",
+		"hop://steve@example.com/main-town/gp-xyz",
+		"hop://a/b/c/d/e",
+		"hop://a//c/d",
+	} {
+		if _, err := ParseWorkURI(bad); err == nil {
+			t.Errorf("ParseWorkURI(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestWorkURIExternalRefRoundTrip(t *testing.T) {
+	raw := "hop://acme.com/platform-town/api/ap-123"
+	uri, err := ParseWorkURI(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := uri.ExternalRef()
+	if !strings.HasPrefix(ref, "external:platform-town:") {
+		t.Errorf("ExternalRef = %q", ref)
+	}
+	if got := ExtractIssueID(ref); got != raw {
+		t.Errorf("ExtractIssueID(%q) = %q, want %q", ref, got, raw)
+	}
+}
+
+func TestResolveWorkURI(t *testing.T) {
+	townRoot := setupURITown(t)
+
+	tests := []struct {
+		name     string
+		ref      string
+		wantKind URITargetKind
+		check    func(t *testing.T, target *URITarget)
+		wantErr  string
+	}{
+		{
+			name:     "local rig issue",
+			ref:      "hop://steve@example.com/main-town/gastown/gt-abc",
+			wantKind: URITargetLocal,
+			check: func(t *testing.T, target *URITarget) {
+				if target.IssueID != "gt-abc" {
+					t.Errorf("IssueID = %q", target.IssueID)
+				}
+			},
+		},
+		{
+			name:     "local town-level issue",
+			ref:      "hop://steve@example.com/main-town/hq/hq-cv-123",
+			wantKind: URITargetLocal,
+		},
+		{
+			name:    "local issue with wrong rig",
+			ref:     "hop://steve@example.com/main-town/beads/gt-abc",
+			wantErr: "belongs to rig gastown",
+		},
+		{
+			name:    "same chain name, different owner",
+			ref:     "hop://mallory@example.com/main-town/gastown/gt-abc",
+			wantErr: "cannot resolve",
+		},
+		{
+			name:     "registered machine",
+			ref:      "hop://acme.com/platform-town/api/ap-123",
+			wantKind: URITargetMachine,
+			check: func(t *testing.T, target *URITarget) {
+				if target.Machine != "platform-town" || target.TownPath != "/srv/gt" {
+					t.Errorf("target = %+v", target)
+				}
+			},
+		},
+		{
+			name:     "Dolt remote named after chain",
+			ref:      "hop://partner.com/partner-town/gastown/gt-77",
+			wantKind: URITargetDoltRemote,
+			check: func(t *testing.T, target *URITarget) {
+				if target.Database != "gastown" || target.Remote != "partner-town" {
+					t.Errorf("target = %+v", target)
+				}
+			},
+		},
+		{
+			name:     "beads URI for local rig repo",
+			ref:      "beads://github/steveyegge/gastown/gt-abc",
+			wantKind: URITargetLocal,
+		},
+		{
+			name:     "beads URI for Dolt remote repo",
+			ref:      "beads://dolthub/acme/backend/ac-123",
+			wantKind: URITargetDoltRemote,
+			check: func(t *testing.T, target *URITarget) {
+				if target.Remote != "origin" {
+					t.Errorf("Remote = %q", target.Remote)
+				}
+			},
+		},
+		{
+			name:    "unknown town",
+			ref:     "hop://nobody.com/elsewhere/rig/xx-1",
+			wantErr: "cannot resolve",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := ResolveWorkURI(townRoot, tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveWorkURI error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveWorkURI: %v", err)
+			}
+			if target.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", target.Kind, tt.wantKind)
+			}
+			if tt.check != nil {
+				tt.check(t, target)
+			}
+		})
+	}
+}
+
+func TestDoltRemoteTargetNotRunnable(t *testing.T) {
+	townRoot := setupURITown(t)
+	target, err := ResolveWorkURI(townRoot, "beads://dolthub/acme/backend/ac-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := target.Show(); err == nil || !strings.Contains(err.Error(), ErrDoltRemoteURI.Error()) {
+		t.Errorf("Show() error = %v, want ErrDoltRemoteURI", err)
+	}
+}
+
+func TestDependencyRef(t *testing.T) {
+	townRoot := setupURITown(t)
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"gt-abc", "gt-abc"},
+		{"hop://steve@example.com/main-town/gastown/gt-abc", "gt-abc"},
+		{"hop://acme.com/platform-town/api/ap-123", "external:platform-town:hop://acme.com/platform-town/api/ap-123"},
+	}
+	for _, tt := range tests {
+		got, err := DependencyRef(townRoot, tt.ref)
+		if err != nil {
+			t.Errorf("DependencyRef(%q): %v", tt.ref, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("DependencyRef(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestSplitGitURL(t *testing.T) {
+	tests := []struct {
+		url, host, path string
+	}{
+		{"git@github.com:Acme/Backend.git", "github.com", "acme/backend.git"},
+		{"https://github.com/acme/backend", "github.com", "acme/backend"},
+		{"ssh://git@gitlab.example.com/acme/backend.git", "gitlab.example.com", "acme/backend.git"},
+	}
+	for _, tt := range tests {
+		host, path := splitGitURL(tt.url)
+		if host != tt.host || path != tt.path {
+			t.Errorf("splitGitURL(%q) = (%q, %q), want (%q, %q)", tt.url, host, path, tt.host, tt.path)
+		}
+	}
+}
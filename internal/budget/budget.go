@@ -0,0 +1,158 @@
+// Package budget enforces the cost budgets configured in town and rig
+// settings against the costs ledger.
+//
+// Budgets cap daily and monthly spending per rig, per role, and per convoy.
+// gt sling checks them before spawning a polecat and the daemon checks them
+// before respawning a crashed one; the first time a budget is found
+// exhausted in a period, an escalation is raised through the town's
+// escalation routes.
+//
+// The package also owns reading the ledger, so the same totals back the
+// per-convoy and per-bead cost reports.
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Scope kinds.
+const (
+	KindRig    = "rig"
+	KindRole   = "role"
+	KindConvoy = "convoy"
+)
+
+// Budget periods.
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+// Scope identifies the work a spawn would be charged to.
+type Scope struct {
+	Rig    string
+	Role   string
+	Convoy string
+
+	// Bead is the work bead being spawned for. When Convoy is empty and
+	// convoy budgets are configured, the bead's tracking convoy is looked up.
+	Bead string
+}
+
+// Breach describes an exhausted budget.
+type Breach struct {
+	Kind     string  // KindRig, KindRole or KindConvoy
+	Name     string  // rig name, role, or convoy ID
+	Period   string  // PeriodDaily or PeriodMonthly
+	LimitUSD float64 // configured cap
+	SpentUSD float64 // spending so far in the period
+}
+
+func (b *Breach) Error() string {
+	return fmt.Sprintf("%s %s %s budget exhausted: $%.2f spent of $%.2f",
+		b.Kind, b.Name, b.Period, b.SpentUSD, b.LimitUSD)
+}
+
+// Check returns the first budget that spend exhausts for scope, or nil.
+// Rig budgets are checked before role and convoy budgets, daily caps before
+// monthly ones.
+func Check(budgets *config.BudgetConfig, rig *config.RigSettings, spend *Spend, scope Scope) *Breach {
+	type candidate struct {
+		kind, name string
+		limit      *config.BudgetLimit
+		daily      map[string]float64
+		monthly    map[string]float64
+	}
+	candidates := []candidate{
+		{KindRig, scope.Rig, budgets.RigBudget(scope.Rig, rig), spend.Daily.ByRig, spend.Monthly.ByRig},
+		{KindRole, scope.Role, budgets.RoleBudget(scope.Role), spend.Daily.ByRole, spend.Monthly.ByRole},
+		{KindConvoy, scope.Convoy, budgets.ConvoyBudget(scope.Convoy), spend.Daily.ByConvoy, spend.Monthly.ByConvoy},
+	}
+	for _, c := range candidates {
+		if c.name == "" || c.limit.IsZero() {
+			continue
+		}
+		if c.limit.DailyUSD > 0 && c.daily[c.name] >= c.limit.DailyUSD {
+			return &Breach{Kind: c.kind, Name: c.name, Period: PeriodDaily, LimitUSD: c.limit.DailyUSD, SpentUSD: c.daily[c.name]}
+		}
+		if c.limit.MonthlyUSD > 0 && c.monthly[c.name] >= c.limit.MonthlyUSD {
+			return &Breach{Kind: c.kind, Name: c.name, Period: PeriodMonthly, LimitUSD: c.limit.MonthlyUSD, SpentUSD: c.monthly[c.name]}
+		}
+	}
+	return nil
+}
+
+// now is the clock used for budget periods. Replaced in tests.
+var now = time.Now
+
+// Enforce checks scope against the town's budgets, escalating the first time
+// each budget is found exhausted in its period. It returns the breach, or
+// nil when every applicable budget has headroom. With no budgets configured
+// it returns immediately without reading the ledger.
+func Enforce(townRoot string, scope Scope) (*Breach, error) {
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	var rig *config.RigSettings
+	if scope.Rig != "" {
+		// Missing rig settings just means no rig-level override.
+		rig, _ = config.LoadRigSettings(config.RigSettingsPath(filepath.Join(townRoot, scope.Rig)))
+	}
+	budgets := settings.Budgets
+	if budgets.RigBudget(scope.Rig, rig).IsZero() && budgets.RoleBudget(scope.Role).IsZero() && !budgets.HasConvoyBudgets() {
+		return nil, nil
+	}
+
+	if scope.Convoy == "" && scope.Bead != "" && budgets.HasConvoyBudgets() {
+		scope.Convoy = trackingConvoy(townRoot, scope.Bead)
+	}
+
+	spend, err := LoadSpend(townRoot, CostsLogPath(), now())
+	if err != nil {
+		return nil, err
+	}
+	breach := Check(budgets, rig, spend, scope)
+	if breach != nil {
+		if err := escalateOnce(townRoot, budgets, breach); err != nil {
+			return breach, fmt.Errorf("escalating %s: %w", breach, err)
+		}
+	}
+	return breach, nil
+}
+
+// trackingConvoy returns the open convoy tracking beadID, or "".
+// Replaced in tests.
+var trackingConvoy = func(townRoot, beadID string) string {
+	cmd := exec.Command("bd", "dep", "list", beadID, "--direction=up", "--type=tracks", "--json") //nolint:gosec // G204: beadID is a bead ID
+	cmd.Dir = townRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return parseTrackingConvoy(out)
+}
+
+// parseTrackingConvoy picks the open convoy out of bd dep list --json output.
+func parseTrackingConvoy(out []byte) string {
+	var trackers []struct {
+		ID        string `json:"id"`
+		IssueType string `json:"issue_type"`
+		Status    string `json:"status"`
+	}
+	if err := json.Unmarshal(out, &trackers); err != nil {
+		return ""
+	}
+	for _, t := range trackers {
+		if t.IssueType == "convoy" && t.Status == "open" {
+			return t.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,285 @@
+package budget
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeLog(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func stubDigests(t *testing.T, digests ...Digest) {
+	t.Helper()
+	orig := loadDigests
+	loadDigests = func(string) ([]Digest, error) { return digests, nil }
+	t.Cleanup(func() { loadDigests = orig })
+}
+
+func TestLoadSpend(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	endedAt := func(daysAgo int) string {
+		return now.Add(-3*time.Hour).AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+	}
+	logPath := filepath.Join(t.TempDir(), "costs.jsonl")
+	writeLog(t, logPath,
+		`{"role":"polecat","rig":"gastown","convoy":"hq-cv-1","cost_usd":2,"ended_at":"`+endedAt(0)+`"}`,
+		`{"role":"polecat","rig":"gastown","cost_usd":3,"ended_at":"`+endedAt(1)+`"}`,
+		`{"role":"witness","rig":"beads","cost_usd":100,"ended_at":"`+endedAt(15)+`"}`,
+		`not json`,
+	)
+	stubDigests(t,
+		Digest{Date: "2026-03-10", ByRole: map[string]float64{"polecat": 10}, ByRig: map[string]float64{"gastown": 10}, ByConvoy: map[string]float64{"hq-cv-1": 4}},
+		Digest{Date: "2026-03-15", ByRole: map[string]float64{"mayor": 1}},
+		Digest{Date: "2026-02-01", ByRole: map[string]float64{"polecat": 50}},
+	)
+
+	spend, err := LoadSpend("/town", logPath, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"daily rig", spend.Daily.ByRig["gastown"], 2},
+		{"daily polecat", spend.Daily.ByRole["polecat"], 2},
+		{"daily mayor (today's digest)", spend.Daily.ByRole["mayor"], 1},
+		{"daily convoy", spend.Daily.ByConvoy["hq-cv-1"], 2},
+		{"monthly rig", spend.Monthly.ByRig["gastown"], 15},
+		{"monthly polecat", spend.Monthly.ByRole["polecat"], 15},
+		{"monthly convoy", spend.Monthly.ByConvoy["hq-cv-1"], 6},
+		{"last month excluded", spend.Monthly.ByRig["beads"], 0},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// A missing ledger is empty, not an error.
+	if _, err := LoadSpend("/town", filepath.Join(t.TempDir(), "none.jsonl"), now); err != nil {
+		t.Errorf("missing log: %v", err)
+	}
+}
+
+func TestLoadTotals(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "costs.jsonl")
+	writeLog(t, logPath,
+		`{"role":"polecat","rig":"gastown","work_item":"gt-a","convoy":"hq-cv-1","cost_usd":2,"ended_at":"2026-03-15T09:00:00Z"}`,
+		`{"role":"polecat","rig":"gastown","work_item":"gt-b","cost_usd":1,"ended_at":"2025-01-01T09:00:00Z"}`,
+	)
+	stubDigests(t,
+		Digest{Date: "2025-12-01", ByConvoy: map[string]float64{"hq-cv-1": 4}, ByBead: map[string]float64{"gt-a": 4}},
+	)
+
+	totals, err := LoadTotals("/town", logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := totals.ByConvoy["hq-cv-1"]; got != 6 {
+		t.Errorf("convoy total = %v, want 6", got)
+	}
+	if got := totals.ByBead["gt-a"]; got != 6 {
+		t.Errorf("gt-a total = %v, want 6", got)
+	}
+	if got := totals.ByBead["gt-b"]; got != 1 {
+		t.Errorf("gt-b total = %v, want 1", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	budgets := &config.BudgetConfig{
+		Rigs:    map[string]*config.BudgetLimit{"gastown": {DailyUSD: 50}, "*": {MonthlyUSD: 500}},
+		Roles:   map[string]*config.BudgetLimit{"polecat": {DailyUSD: 100, MonthlyUSD: 1000}},
+		Convoys: map[string]*config.BudgetLimit{"*": {DailyUSD: 20}},
+	}
+	spend := &Spend{
+		Daily: Totals{
+			ByRig:    map[string]float64{"gastown": 10, "beads": 10},
+			ByRole:   map[string]float64{"polecat": 20},
+			ByConvoy: map[string]float64{"hq-cv-hot": 25},
+		},
+		Monthly: Totals{
+			ByRig:    map[string]float64{"gastown": 900, "beads": 600},
+			ByRole:   map[string]float64{"polecat": 1500},
+			ByConvoy: map[string]float64{"hq-cv-hot": 25},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		rig        *config.RigSettings
+		scope      Scope
+		wantKind   string
+		wantPeriod string
+	}{
+		{"rig under daily cap, no monthly cap", nil, Scope{Rig: "gastown"}, "", ""},
+		{"wildcard rig monthly cap", nil, Scope{Rig: "beads"}, KindRig, PeriodMonthly},
+		{"rig settings override town", &config.RigSettings{Budget: &config.BudgetLimit{DailyUSD: 5}}, Scope{Rig: "gastown"}, KindRig, PeriodDaily},
+		{"role monthly cap", nil, Scope{Rig: "gastown", Role: "polecat"}, KindRole, PeriodMonthly},
+		{"wildcard convoy cap", nil, Scope{Rig: "gastown", Convoy: "hq-cv-hot"}, KindConvoy, PeriodDaily},
+		{"cold convoy", nil, Scope{Rig: "gastown", Convoy: "hq-cv-cold"}, "", ""},
+		{"unbudgeted role", nil, Scope{Role: "mayor"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Check(budgets, tt.rig, spend, tt.scope)
+			if tt.wantKind == "" {
+				if b != nil {
+					t.Fatalf("unexpected breach: %v", b)
+				}
+				return
+			}
+			if b == nil {
+				t.Fatal("expected breach")
+			}
+			if b.Kind != tt.wantKind || b.Period != tt.wantPeriod {
+				t.Errorf("breach = %s/%s, want %s/%s", b.Kind, b.Period, tt.wantKind, tt.wantPeriod)
+			}
+		})
+	}
+
+	if b := Check(nil, nil, spend, Scope{Rig: "gastown", Role: "polecat"}); b != nil {
+		t.Errorf("nil budgets breach: %v", b)
+	}
+}
+
+func TestEnforce(t *testing.T) {
+	townRoot := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stubDigests(t)
+
+	fixed := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	origNow, origEscalate, origConvoy := now, escalate, trackingConvoy
+	t.Cleanup(func() { now, escalate, trackingConvoy = origNow, origEscalate, origConvoy })
+	now = func() time.Time { return fixed }
+	var escalated []string
+	escalate = func(_, severity string, b *Breach) error {
+		escalated = append(escalated, severity+" "+b.Error())
+		return nil
+	}
+	trackingConvoy = func(_, beadID string) string {
+		if beadID == "gt-hot" {
+			return "hq-cv-hot"
+		}
+		return ""
+	}
+
+	// No budgets configured: nothing to enforce.
+	if b, err := Enforce(townRoot, Scope{Rig: "gastown", Role: "polecat"}); b != nil || err != nil {
+		t.Fatalf("Enforce without budgets = %v, %v", b, err)
+	}
+
+	settings := config.NewTownSettings()
+	settings.Budgets = &config.BudgetConfig{
+		Convoys:            map[string]*config.BudgetLimit{"*": {DailyUSD: 20}},
+		EscalationSeverity: "critical",
+	}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	writeLog(t, CostsLogPath(),
+		`{"role":"polecat","rig":"gastown","convoy":"hq-cv-hot","cost_usd":25,"ended_at":"`+fixed.Add(-time.Hour).Format(time.RFC3339)+`"}`)
+
+	if b, err := Enforce(townRoot, Scope{Rig: "gastown", Role: "polecat", Bead: "gt-cold"}); b != nil || err != nil {
+		t.Fatalf("Enforce cold bead = %v, %v", b, err)
+	}
+	for i := 0; i < 2; i++ {
+		b, err := Enforce(townRoot, Scope{Rig: "gastown", Role: "polecat", Bead: "gt-hot"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b == nil || b.Kind != KindConvoy || b.Name != "hq-cv-hot" {
+			t.Fatalf("Enforce hot bead = %v", b)
+		}
+	}
+	if len(escalated) != 1 || !strings.HasPrefix(escalated[0], "critical convoy hq-cv-hot daily") {
+		t.Errorf("escalations = %q, want one critical convoy escalation", escalated)
+	}
+
+	// The next day the same budget escalates again.
+	fixed = fixed.Add(24 * time.Hour)
+	writeLog(t, CostsLogPath(),
+		`{"role":"polecat","rig":"gastown","convoy":"hq-cv-hot","cost_usd":25,"ended_at":"`+fixed.Add(-time.Hour).Format(time.RFC3339)+`"}`)
+	if _, err := Enforce(townRoot, Scope{Rig: "gastown", Role: "polecat", Bead: "gt-hot"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(escalated) != 2 {
+		t.Errorf("escalations after period rollover = %d, want 2", len(escalated))
+	}
+
+	data, err := os.ReadFile(escalationStatePath(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state map[string]time.Time
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state) != 1 {
+		t.Errorf("escalation state keeps stale periods: %v", state)
+	}
+}
+
+func TestParseTrackingConvoy(t *testing.T) {
+	out := []byte(`[{"id":"gt-epic","issue_type":"epic","status":"open"},` +
+		`{"id":"hq-cv-old","issue_type":"convoy","status":"closed"},` +
+		`{"id":"hq-cv-1","issue_type":"convoy","status":"open"}]`)
+	if got := parseTrackingConvoy(out); got != "hq-cv-1" {
+		t.Errorf("parseTrackingConvoy = %q, want hq-cv-1", got)
+	}
+	if got := parseTrackingConvoy([]byte("garbage")); got != "" {
+		t.Errorf("parseTrackingConvoy(garbage) = %q", got)
+	}
+}
+
+func TestQueryDigests_ListsOnlyLabeledDigests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake bd is a shell script")
+	}
+	binDir := t.TempDir()
+	calls := filepath.Join(binDir, "calls.log")
+	script := `#!/bin/sh
+echo "$*" >> "` + calls + `"
+case "$1" in
+  list) echo '[{"id":"hq-d1"}]' ;;
+  show) echo '[{"id":"hq-d1","event_kind":"costs.digest","payload":"{\"date\":\"2026-03-14\",\"by_role\":{\"polecat\":7}}"}]' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(binDir, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	digests, err := queryDigests(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(digests) != 1 || digests[0].Date != "2026-03-14" || digests[0].ByRole["polecat"] != 7 {
+		t.Errorf("digests = %+v", digests)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(call, "list") && !strings.Contains(call, "--label="+DigestLabel) {
+			t.Errorf("listed beads without the digest label: %q", call)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// escalationStatePath records which budget breaches have been escalated,
+// so each exhausted budget escalates once per period rather than on every
+// refused spawn.
+func escalationStatePath(townRoot string) string {
+	return filepath.Join(townRoot, ".runtime", "budget-escalations.json")
+}
+
+// periodKey names the budget period containing t.
+func periodKey(period string, t time.Time) string {
+	if period == PeriodMonthly {
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// escalate raises a budget escalation. Replaced in tests.
+var escalate = func(townRoot, severity string, b *Breach) error {
+	reason := fmt.Sprintf("New polecat spawns and daemon respawns for %s %s are blocked until the %s period ends or the budget is raised in settings/config.json.",
+		b.Kind, b.Name, b.Period)
+	args := []string{"escalate", "--severity", severity, "--source", "budget:" + b.Kind, "--reason", reason}
+	if b.Kind == KindConvoy {
+		args = append(args, "--related", b.Name)
+	}
+	args = append(args, "Budget exhausted: "+b.Error())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	// Run this gt, not whichever gt comes first on PATH.
+	gtPath, err := os.Executable()
+	if err != nil {
+		gtPath = "gt"
+	}
+	cmd := exec.CommandContext(ctx, gtPath, args...) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = townRoot
+	cmd.Env = os.Environ()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// escalateOnce escalates b unless it was already escalated this period.
+func escalateOnce(townRoot string, budgets *config.BudgetConfig, b *Breach) error {
+	key := strings.Join([]string{b.Kind, b.Name, b.Period, periodKey(b.Period, now())}, ":")
+
+	path := escalationStatePath(townRoot)
+	state := make(map[string]time.Time)
+	if data, err := os.ReadFile(path); err == nil { //nolint:gosec // G304: path is constructed internally
+		_ = json.Unmarshal(data, &state)
+	}
+	if _, done := state[key]; done {
+		return nil
+	}
+
+	severity := config.SeverityHigh
+	if budgets != nil && config.IsValidSeverity(budgets.EscalationSeverity) {
+		severity = budgets.EscalationSeverity
+	}
+	if err := escalate(townRoot, severity, b); err != nil {
+		return err
+	}
+
+	// Drop keys from past periods so the file stays small.
+	for k := range state {
+		if !strings.HasSuffix(k, ":"+periodKey(PeriodDaily, now())) && !strings.HasSuffix(k, ":"+periodKey(PeriodMonthly, now())) {
+			delete(state, k)
+		}
+	}
+	state[key] = now()
+	return util.EnsureDirAndWriteJSON(path, state)
+}
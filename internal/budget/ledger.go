@@ -0,0 +1,282 @@
+package budget
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DigestEventKind is the event kind of the daily cost digest beads written
+// by gt costs digest.
+const DigestEventKind = "costs.digest"
+
+// DigestLabel marks digest beads, so the ledger can list them without
+// reading every event bead.
+const DigestLabel = "gt:costs-digest"
+
+// CostsLogPath returns the path to the local costs ledger (~/.gt/costs.jsonl).
+func CostsLogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "/tmp/gt-costs.jsonl" // Fallback
+	}
+	return filepath.Join(home, ".gt", "costs.jsonl")
+}
+
+// logEntry holds the fields of a costs.jsonl line used for attribution.
+type logEntry struct {
+	Role     string    `json:"role"`
+	Rig      string    `json:"rig,omitempty"`
+	WorkItem string    `json:"work_item,omitempty"`
+	Convoy   string    `json:"convoy,omitempty"`
+	CostUSD  float64   `json:"cost_usd"`
+	EndedAt  time.Time `json:"ended_at"`
+}
+
+// Digest holds the aggregates of a daily cost digest bead payload.
+type Digest struct {
+	Date     string             `json:"date"`
+	ByRole   map[string]float64 `json:"by_role"`
+	ByRig    map[string]float64 `json:"by_rig,omitempty"`
+	ByConvoy map[string]float64 `json:"by_convoy,omitempty"`
+	ByBead   map[string]float64 `json:"by_bead,omitempty"`
+}
+
+// Totals is spending over one period, broken down by attribution scope.
+type Totals struct {
+	ByRig    map[string]float64
+	ByRole   map[string]float64
+	ByConvoy map[string]float64
+	ByBead   map[string]float64
+}
+
+func newTotals() Totals {
+	return Totals{
+		ByRig:    make(map[string]float64),
+		ByRole:   make(map[string]float64),
+		ByConvoy: make(map[string]float64),
+		ByBead:   make(map[string]float64),
+	}
+}
+
+func (t Totals) add(e logEntry) {
+	if e.Role != "" {
+		t.ByRole[e.Role] += e.CostUSD
+	}
+	if e.Rig != "" {
+		t.ByRig[e.Rig] += e.CostUSD
+	}
+	if e.Convoy != "" {
+		t.ByConvoy[e.Convoy] += e.CostUSD
+	}
+	if e.WorkItem != "" {
+		t.ByBead[e.WorkItem] += e.CostUSD
+	}
+}
+
+func (t Totals) merge(d Digest) {
+	for _, m := range []struct{ dst, src map[string]float64 }{
+		{t.ByRole, d.ByRole}, {t.ByRig, d.ByRig}, {t.ByConvoy, d.ByConvoy}, {t.ByBead, d.ByBead},
+	} {
+		for k, v := range m.src {
+			m.dst[k] += v
+		}
+	}
+}
+
+// Spend is spending for the current day and the current month.
+type Spend struct {
+	Daily   Totals
+	Monthly Totals
+}
+
+// digestQueryTimeout bounds the bd queries for digest beads.
+const digestQueryTimeout = 30 * time.Second
+
+// loadDigests returns the cost digests recorded in the town's beads.
+// Replaced in tests.
+var loadDigests = queryDigests
+
+// LoadSpend totals the costs ledger for the day and month containing now:
+// entries still in logPath plus digest beads (gt costs digest moves a day's
+// entries from the log into a digest, so the two never overlap).
+func LoadSpend(townRoot, logPath string, now time.Time) (*Spend, error) {
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	entries, digests, err := readLedger(townRoot, logPath)
+	if err != nil {
+		return nil, err
+	}
+	spend := &Spend{Daily: newTotals(), Monthly: newTotals()}
+	for _, e := range entries {
+		if e.EndedAt.Before(monthStart) {
+			continue
+		}
+		spend.Monthly.add(e)
+		if !e.EndedAt.Before(dayStart) {
+			spend.Daily.add(e)
+		}
+	}
+	today := now.Format("2006-01-02")
+	for _, d := range digests {
+		date, err := time.ParseInLocation("2006-01-02", d.Date, now.Location())
+		if err != nil || date.Before(monthStart) || date.After(now) {
+			continue
+		}
+		spend.Monthly.merge(d)
+		if d.Date == today {
+			spend.Daily.merge(d)
+		}
+	}
+	return spend, nil
+}
+
+// LoadTotals totals the whole costs ledger (log plus digest beads), e.g. the
+// lifetime cost of each convoy.
+func LoadTotals(townRoot, logPath string) (Totals, error) {
+	entries, digests, err := readLedger(townRoot, logPath)
+	if err != nil {
+		return Totals{}, err
+	}
+	totals := newTotals()
+	for _, e := range entries {
+		totals.add(e)
+	}
+	for _, d := range digests {
+		totals.merge(d)
+	}
+	return totals, nil
+}
+
+// readLedger reads the costs log and the town's digest beads.
+func readLedger(townRoot, logPath string) ([]logEntry, []Digest, error) {
+	data, err := os.ReadFile(logPath) //nolint:gosec // G304: path is the costs ledger
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("reading costs log: %w", err)
+	}
+	var entries []logEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e logEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+
+	digests, err := loadDigests(townRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, digests, nil
+}
+
+// queryDigests loads the labeled digest beads from the town's beads.
+func queryDigests(townRoot string) ([]Digest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), digestQueryTimeout)
+	defer cancel()
+
+	events, err := showEvents(ctx, townRoot, "--label="+DigestLabel)
+	if err != nil {
+		return nil, err
+	}
+	var digests []Digest
+	for _, ev := range events {
+		if ev.EventKind != DigestEventKind || ev.Payload == "" {
+			continue
+		}
+		var d Digest
+		if err := json.Unmarshal([]byte(ev.Payload), &d); err != nil {
+			continue
+		}
+		digests = append(digests, d)
+	}
+	return digests, nil
+}
+
+// LabelDigests labels digest beads created before digests were labeled, so
+// the ledger sees them. It scans the event history only while the town has
+// no labeled digest, so it is cheap once any digest carries the label. It
+// returns the number of beads labeled.
+func LabelDigests(townRoot string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), digestQueryTimeout)
+	defer cancel()
+
+	labeled, err := listEventIDs(ctx, townRoot, "--label="+DigestLabel)
+	if err != nil || len(labeled) > 0 {
+		return 0, err
+	}
+	events, err := showEvents(ctx, townRoot, "--type=event")
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, ev := range events {
+		if ev.EventKind != DigestEventKind {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, "bd", "label", "add", ev.ID, DigestLabel) //nolint:gosec // G204: args are a bead ID and a constant
+		cmd.Dir = townRoot
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return n, fmt.Errorf("labeling digest %s: %s", ev.ID, strings.TrimSpace(string(out)))
+		}
+		n++
+	}
+	return n, nil
+}
+
+// digestEvent holds the fields of a bd show event used for digests.
+type digestEvent struct {
+	ID        string `json:"id"`
+	EventKind string `json:"event_kind"`
+	Payload   string `json:"payload"`
+}
+
+// listEventIDs lists the IDs of all beads, open or closed, matching filter.
+// A town without a beads database (or without bd) has none.
+func listEventIDs(ctx context.Context, townRoot, filter string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "bd", "list", filter, "--all", "--limit=0", "--json") //nolint:gosec // G204: filter is a constant flag
+	cmd.Dir = townRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nil
+	}
+	var items []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(out, &items); err != nil {
+		return nil, fmt.Errorf("parsing event list: %w", err)
+	}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids, nil
+}
+
+// showEvents returns the details of the beads matching filter.
+func showEvents(ctx context.Context, townRoot, filter string) ([]digestEvent, error) {
+	ids, err := listEventIDs(ctx, townRoot, filter)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, "bd", append([]string{"show", "--json"}, ids...)...) //nolint:gosec // G204: args are bead IDs
+	cmd.Dir = townRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("showing events: %w", err)
+	}
+	var events []digestEvent
+	if err := json.Unmarshal(out, &events); err != nil {
+		return nil, fmt.Errorf("parsing event details: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/agentscript"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+var agentScriptPath string
+
+var agentScriptCmd = &cobra.Command{
+	Use:    "agent-script [prompt]",
+	Short:  "Run the scripted fake agent (internal use)",
+	Hidden: true, // Launched as the "scripted" agent preset
+	Long: `Replay a YAML or JSON agent script in place of an LLM agent.
+
+This is the runtime behind the built-in "scripted" agent preset, used for
+deterministic end-to-end tests of the propulsion loop. The optional prompt
+argument is the startup beacon Gas Town passes to every agent; it is echoed
+but otherwise ignored.
+
+The script is found in this order:
+  1. --script
+  2. GT_AGENT_SCRIPT
+  3. $GT_ROOT/settings/agent-scripts/<role>.yaml (or .json), where <role>
+     is derived from GT_ROLE (polecat, refinery, witness, crew, ...)
+
+Steps whose "attempt" field is set only run on that launch of the agent in
+the current directory, so a script can crash once and recover on respawn.
+See the agentscript package documentation for the script format.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAgentScript,
+}
+
+func init() {
+	rootCmd.AddCommand(agentScriptCmd)
+	agentScriptCmd.Flags().StringVar(&agentScriptPath, "script", "", "Path to the agent script")
+}
+
+func runAgentScript(cmd *cobra.Command, args []string) error {
+	path, err := findAgentScript()
+	if err != nil {
+		return err
+	}
+	script, err := agentscript.Load(path)
+	if err != nil {
+		return err
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	stateDir := filepath.Join(os.TempDir(), "gt-agent-script")
+	attempt, err := agentscript.NextAttempt(stateDir, path, workDir)
+	if err != nil {
+		return fmt.Errorf("recording attempt: %w", err)
+	}
+
+	var binDir string
+	if exe, err := os.Executable(); err == nil {
+		binDir = filepath.Dir(exe)
+	}
+
+	if len(args) > 0 {
+		fmt.Printf("> %s\n", args[0])
+	}
+	r := &agentscript.Runner{
+		Script:  script,
+		Dir:     workDir,
+		Stdin:   os.Stdin,
+		Stdout:  os.Stdout,
+		BinDir:  binDir,
+		Attempt: attempt,
+	}
+	err = r.Run()
+	var crash *agentscript.CrashError
+	if errors.As(err, &crash) {
+		return NewSilentExit(crash.Code)
+	}
+	return err
+}
+
+// findAgentScript resolves the script for this agent (see agentScriptCmd).
+func findAgentScript() (string, error) {
+	if agentScriptPath != "" {
+		return agentScriptPath, nil
+	}
+	if path := os.Getenv("GT_AGENT_SCRIPT"); path != "" {
+		return path, nil
+	}
+
+	townRoot := os.Getenv("GT_ROOT")
+	id, err := session.ParseAddress(os.Getenv("GT_ROLE"))
+	if townRoot == "" || err != nil {
+		return "", fmt.Errorf("no agent script: use --script or set GT_AGENT_SCRIPT")
+	}
+	dir := filepath.Join(townRoot, "settings", "agent-scripts")
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		path := filepath.Join(dir, string(id.Role)+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no agent script for role %s in %s", id.Role, dir)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindAgentScript(t *testing.T) {
+	townRoot := t.TempDir()
+	scriptDir := filepath.Join(townRoot, "settings", "agent-scripts")
+	if err := os.MkdirAll(scriptDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	polecatScript := filepath.Join(scriptDir, "polecat.yaml")
+	if err := os.WriteFile(polecatScript, []byte("steps: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agentScriptPath = ""
+	t.Setenv("GT_AGENT_SCRIPT", "")
+	t.Setenv("GT_ROOT", townRoot)
+	t.Setenv("GT_ROLE", "gastown/polecats/Toast")
+
+	if got, err := findAgentScript(); err != nil || got != polecatScript {
+		t.Errorf("role lookup = (%q, %v), want %q", got, err, polecatScript)
+	}
+
+	t.Setenv("GT_ROLE", "gastown/refinery")
+	if _, err := findAgentScript(); err == nil {
+		t.Error("expected error for role without a script")
+	}
+
+	t.Setenv("GT_AGENT_SCRIPT", "/explicit.yaml")
+	if got, _ := findAgentScript(); got != "/explicit.yaml" {
+		t.Errorf("GT_AGENT_SCRIPT lookup = %q", got)
+	}
+}
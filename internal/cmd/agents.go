@@ -158,10 +158,11 @@ func categorizeSession(name string) *AgentSession {
 	return sess
 }
 
-// getAgentSessions returns all categorized Gas Town sessions.
+// getAgentSessions returns all categorized Gas Town sessions, as listed by
+// the town's session backend.
 func getAgentSessions(includePolecats bool) ([]*AgentSession, error) {
-	t := tmux.NewTmux()
-	sessions, err := t.ListSessions()
+	townRoot, _ := workspace.FindFromCwd()
+	sessions, err := session.NewBackend(townRoot).ListSessions()
 	if err != nil {
 		return nil, err
 	}
@@ -6,8 +6,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
-	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -102,8 +102,8 @@ func runBroadcast(cmd *cobra.Command, args []string) error {
 	}
 
 	// Send nudges
-	t := tmux.NewTmux()
 	townRoot, _ := workspace.FindFromCwd()
+	t := session.NewBackend(townRoot)
 	var succeeded, failed, skipped int
 	var failures []string
 
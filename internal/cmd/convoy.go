@@ -34,6 +34,10 @@ func generateShortID() string {
 // looksLikeIssueID checks if a string looks like a beads issue ID.
 // Issue IDs have the format: prefix-id (e.g., gt-abc, bd-xyz, hq-123).
 func looksLikeIssueID(s string) bool {
+	// hop:// and beads:// work unit URIs reference issues in any town
+	if beads.IsWorkURI(s) {
+		return true
+	}
 	// Check registry prefixes and legacy fallbacks via centralized helper
 	if session.HasKnownPrefix(s) {
 		return true
@@ -479,8 +483,15 @@ func runConvoyCreate(cmd *cobra.Command, args []string) error {
 	// Add 'tracks' relations for each tracked issue
 	trackedCount := 0
 	for _, issueID := range trackedIssues {
+		// hop:// and beads:// URIs resolve to a local ID or an external ref
+		depTarget, err := beads.DependencyRef(filepath.Dir(townBeads), issueID)
+		if err != nil {
+			style.PrintWarning("couldn't track %s: %v", issueID, err)
+			continue
+		}
+
 		// Use --type=tracks for non-blocking tracking relation
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		depArgs := []string{"dep", "add", convoyID, depTarget, "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = townBeads
 		var depStderr bytes.Buffer
@@ -591,7 +602,14 @@ func runConvoyAdd(cmd *cobra.Command, args []string) error {
 	// Add 'tracks' relations for each issue
 	addedCount := 0
 	for _, issueID := range issuesToAdd {
-		depArgs := []string{"dep", "add", convoyID, issueID, "--type=tracks"}
+		// hop:// and beads:// URIs resolve to a local ID or an external ref
+		depTarget, err := beads.DependencyRef(filepath.Dir(townBeads), issueID)
+		if err != nil {
+			style.PrintWarning("couldn't add %s: %v", issueID, err)
+			continue
+		}
+
+		depArgs := []string{"dep", "add", convoyID, depTarget, "--type=tracks"}
 		depCmd := exec.Command("bd", depArgs...)
 		depCmd.Dir = townBeads
 		var depStderr bytes.Buffer
@@ -1562,6 +1580,12 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Attribute recorded costs (best-effort: the ledger is optional)
+	costs, _ := loadCostTotalsFn(filepath.Dir(townBeads))
+	for i := range tracked {
+		tracked[i].CostUSD = costs.ByBead[tracked[i].ID]
+	}
+
 	if convoyStatusJSON {
 		lifecycle := "system-managed"
 		if isOwned {
@@ -1577,6 +1601,7 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			Tracked       []trackedIssueInfo `json:"tracked"`
 			Completed     int                `json:"completed"`
 			Total         int                `json:"total"`
+			CostUSD       float64            `json:"cost_usd"`
 		}
 		out := jsonStatus{
 			ID:            convoy.ID,
@@ -1588,6 +1613,7 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 			Tracked:       tracked,
 			Completed:     completed,
 			Total:         len(tracked),
+			CostUSD:       costs.ByConvoy[convoy.ID],
 		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -1608,6 +1634,7 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Merge:     %s\n", merge)
 	}
 	fmt.Printf("  Progress:  %d/%d completed\n", completed, len(tracked))
+	fmt.Printf("  Cost:      $%.2f\n", costs.ByConvoy[convoy.ID])
 	fmt.Printf("  Created:   %s\n", convoy.CreatedAt)
 	if convoy.ClosedAt != "" {
 		fmt.Printf("  Closed:    %s\n", convoy.ClosedAt)
@@ -1642,6 +1669,9 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 				}
 				line += fmt.Sprintf("  %s", style.Dim.Render(workerDisplay))
 			}
+			if t.CostUSD > 0 {
+				line += fmt.Sprintf("  %s", style.Dim.Render(fmt.Sprintf("$%.2f", t.CostUSD)))
+			}
 			fmt.Println(line)
 		}
 	}
@@ -1667,10 +1697,11 @@ func showAllConvoyStatus(townBeads string) error {
 	}
 
 	var convoys []struct {
-		ID     string   `json:"id"`
-		Title  string   `json:"title"`
-		Status string   `json:"status"`
-		Labels []string `json:"labels"`
+		ID      string   `json:"id"`
+		Title   string   `json:"title"`
+		Status  string   `json:"status"`
+		Labels  []string `json:"labels"`
+		CostUSD float64  `json:"cost_usd"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &convoys); err != nil {
 		return fmt.Errorf("parsing convoy list: %w", err)
@@ -1682,6 +1713,11 @@ func showAllConvoyStatus(townBeads string) error {
 		return nil
 	}
 
+	costs, _ := loadCostTotalsFn(filepath.Dir(townBeads))
+	for i := range convoys {
+		convoys[i].CostUSD = costs.ByConvoy[convoys[i].ID]
+	}
+
 	if convoyStatusJSON {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -1694,7 +1730,7 @@ func showAllConvoyStatus(townBeads string) error {
 		if hasLabel(c.Labels, "gt:owned") {
 			ownedTag = " " + style.Warning.Render("[owned]")
 		}
-		fmt.Printf("  🚚 %s: %s%s\n", c.ID, c.Title, ownedTag)
+		fmt.Printf("  🚚 %s: %s%s  %s\n", c.ID, c.Title, ownedTag, style.Dim.Render(fmt.Sprintf("$%.2f", c.CostUSD)))
 	}
 	fmt.Printf("\nUse 'gt convoy status <id>' for detailed status.\n")
 
@@ -1912,15 +1948,16 @@ func formatConvoyStatus(status string) string {
 
 // trackedIssueInfo holds info about an issue being tracked by a convoy.
 type trackedIssueInfo struct {
-	ID        string `json:"id"`
-	Title     string `json:"title"`
-	Status    string `json:"status"`
-	Type      string `json:"dependency_type"`
-	IssueType string `json:"issue_type"`
-	Blocked   bool   `json:"blocked,omitempty"`    // True if issue currently has blockers
-	Assignee  string `json:"assignee,omitempty"`   // Assigned agent (e.g., gastown/polecats/goose)
-	Worker    string `json:"worker,omitempty"`     // Worker currently assigned (e.g., gastown/nux)
-	WorkerAge string `json:"worker_age,omitempty"` // How long worker has been on this issue
+	ID        string  `json:"id"`
+	Title     string  `json:"title"`
+	Status    string  `json:"status"`
+	Type      string  `json:"dependency_type"`
+	IssueType string  `json:"issue_type"`
+	Blocked   bool    `json:"blocked,omitempty"`    // True if issue currently has blockers
+	Assignee  string  `json:"assignee,omitempty"`   // Assigned agent (e.g., gastown/polecats/goose)
+	Worker    string  `json:"worker,omitempty"`     // Worker currently assigned (e.g., gastown/nux)
+	WorkerAge string  `json:"worker_age,omitempty"` // How long worker has been on this issue
+	CostUSD   float64 `json:"cost_usd,omitempty"`   // Recorded agent cost attributed to this issue
 }
 
 // trackedDependency is dep-list data enriched with fresh issue details.
@@ -2122,6 +2159,19 @@ func (d issueDetails) IsBlocked() bool {
 // Returns a map from issue ID to details. Missing/invalid issues are omitted from the map.
 func getIssueDetailsBatch(issueIDs []string) map[string]*issueDetails {
 	result := make(map[string]*issueDetails)
+
+	// hop:// and beads:// URIs are looked up in the town that owns them
+	localIDs := make([]string, 0, len(issueIDs))
+	for _, id := range issueIDs {
+		if beads.IsWorkURI(id) {
+			if details := getWorkURIDetails(id); details != nil {
+				result[id] = details
+			}
+			continue
+		}
+		localIDs = append(localIDs, id)
+	}
+	issueIDs = localIDs
 	if len(issueIDs) == 0 {
 		return result
 	}
@@ -2160,6 +2210,10 @@ func getIssueDetailsBatch(issueIDs []string) map[string]*issueDetails {
 // getIssueDetails fetches issue details by trying to show it via bd.
 // Prefer getIssueDetailsBatch for multiple issues to avoid N+1 subprocess calls.
 func getIssueDetails(issueID string) *issueDetails {
+	if beads.IsWorkURI(issueID) {
+		return getWorkURIDetails(issueID)
+	}
+
 	// Use bd show with routing - it should find the issue in the right rig
 	showCmd := exec.Command("bd", "show", issueID, "--json")
 	var stdout bytes.Buffer
@@ -2181,6 +2235,32 @@ func getIssueDetails(issueID string) *issueDetails {
 	return issues[0].toIssueDetails()
 }
 
+// getWorkURIDetails fetches issue details for a hop:// or beads:// URI from
+// the town that owns the issue. The result is keyed by the URI, so status
+// reflects the owning town. Returns nil if the URI can't be resolved or the
+// owning town is unreachable.
+func getWorkURIDetails(uri string) *issueDetails {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil
+	}
+	target, err := beads.ResolveWorkURI(townRoot, uri)
+	if err != nil {
+		return nil
+	}
+	issue, err := target.Show()
+	if err != nil {
+		return nil
+	}
+	return &issueDetails{
+		ID:        uri,
+		Title:     issue.Title,
+		Status:    issue.Status,
+		IssueType: issue.Type,
+		Assignee:  issue.Assignee,
+	}
+}
+
 // workerInfo holds info about a worker assigned to an issue.
 type workerInfo struct {
 	Worker string // Agent identity (e.g., gastown/nux)
@@ -2,10 +2,8 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,6 +13,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/session"
@@ -24,16 +24,20 @@ import (
 )
 
 var (
-	costsJSON    bool
-	costsToday   bool
-	costsWeek    bool
-	costsByRole  bool
-	costsByRig   bool
-	costsVerbose bool
+	costsJSON       bool
+	costsToday      bool
+	costsWeek       bool
+	costsByRole     bool
+	costsByRig      bool
+	costsByProvider bool
+	costsByConvoy   bool
+	costsByBead     bool
+	costsVerbose    bool
 
 	// Record subcommand flags
 	recordSession  string
 	recordWorkItem string
+	recordAgent    string
 
 	// Digest subcommand flags
 	digestYesterday bool
@@ -47,11 +51,13 @@ var (
 var costsCmd = &cobra.Command{
 	Use:     "costs",
 	GroupID: GroupDiag,
-	Short:   "Show costs for running Claude sessions",
-	Long: `Display costs for Claude Code sessions in Gas Town.
+	Short:   "Show costs for running agent sessions",
+	Long: `Display costs for agent sessions in Gas Town.
 
-Costs are calculated from Claude Code transcript files at ~/.claude/projects/
-by summing token usage from assistant messages and applying model-specific pricing.
+Costs are calculated from each agent's own session records (Claude Code
+transcripts, Gemini CLI chats, Codex rollouts, OpenCode sessions) by summing
+token usage and applying model-specific pricing. Prices can be overridden
+per model (or model prefix) with "pricing" in settings/config.json.
 
 Examples:
   gt costs              # Live costs from running sessions
@@ -59,6 +65,9 @@ Examples:
   gt costs --week       # This week's costs from digest beads + today's log
   gt costs --by-role    # Breakdown by role (polecat, witness, etc.)
   gt costs --by-rig     # Breakdown by rig
+  gt costs --by-provider  # Breakdown by agent provider (claude, gemini, ...)
+  gt costs --by-convoy  # Breakdown by convoy (what each feature cost)
+  gt costs --by-bead    # Breakdown by work bead
   gt costs --json       # Output as JSON
   gt costs -v           # Show debug output for failures
 
@@ -73,12 +82,16 @@ var costsRecordCmd = &cobra.Command{
 	Short: "Record session cost to local log file (called by Stop hook)",
 	Long: `Record the final cost of a session to a local log file.
 
-This command is intended to be called from a Claude Code Stop hook.
-It reads token usage from the Claude Code transcript file (~/.claude/projects/...)
-and calculates the cost based on model pricing, then appends it to
-~/.gt/costs.jsonl. This is a simple append operation that never fails
+This command is intended to be called from an agent's Stop/session-end hook.
+It reads token usage from the agent's session records (chosen by --agent or
+GT_AGENT, defaulting to the town's default agent), calculates the cost based
+on model pricing, then appends it to ~/.gt/costs.jsonl. This is a simple append operation that never fails
 due to database availability.
 
+Each entry is attributed to a work bead and its convoy. The bead is
+--work-item if given, otherwise the bead on the agent's hook (read from
+the agent bead) at record time.
+
 Session costs are aggregated daily by 'gt costs digest' into a single
 permanent "Cost Report YYYY-MM-DD" bead for audit purposes.
 
@@ -135,12 +148,16 @@ func init() {
 	costsCmd.Flags().BoolVar(&costsWeek, "week", false, "Show this week's total from session events")
 	costsCmd.Flags().BoolVar(&costsByRole, "by-role", false, "Show breakdown by role")
 	costsCmd.Flags().BoolVar(&costsByRig, "by-rig", false, "Show breakdown by rig")
+	costsCmd.Flags().BoolVar(&costsByProvider, "by-provider", false, "Show breakdown by agent provider")
+	costsCmd.Flags().BoolVar(&costsByConvoy, "by-convoy", false, "Show breakdown by convoy")
+	costsCmd.Flags().BoolVar(&costsByBead, "by-bead", false, "Show breakdown by work bead")
 	costsCmd.Flags().BoolVarP(&costsVerbose, "verbose", "v", false, "Show debug output for failures")
 
 	// Add record subcommand
 	costsCmd.AddCommand(costsRecordCmd)
 	costsRecordCmd.Flags().StringVar(&recordSession, "session", "", "Tmux session name to record")
-	costsRecordCmd.Flags().StringVar(&recordWorkItem, "work-item", "", "Work item ID (bead) for attribution")
+	costsRecordCmd.Flags().StringVar(&recordWorkItem, "work-item", "", "Work item ID (bead) for attribution (default: the agent's hooked bead)")
+	costsRecordCmd.Flags().StringVar(&recordAgent, "agent", "", "Agent name whose usage to read (default: GT_AGENT)")
 
 	// Add digest subcommand
 	costsCmd.AddCommand(costsDigestCmd)
@@ -155,12 +172,14 @@ func init() {
 
 // SessionCost represents cost info for a single session.
 type SessionCost struct {
-	Session string  `json:"session"`
-	Role    string  `json:"role"`
-	Rig     string  `json:"rig,omitempty"`
-	Worker  string  `json:"worker,omitempty"`
-	Cost    float64 `json:"cost_usd"`
-	Running bool    `json:"running"`
+	Session  string  `json:"session"`
+	Role     string  `json:"role"`
+	Rig      string  `json:"rig,omitempty"`
+	Worker   string  `json:"worker,omitempty"`
+	Provider string  `json:"provider,omitempty"`
+	Model    string  `json:"model,omitempty"`
+	Cost     float64 `json:"cost_usd"`
+	Running  bool    `json:"running"`
 }
 
 // CostEntry is a ledger entry for historical cost tracking.
@@ -169,77 +188,32 @@ type CostEntry struct {
 	Role      string    `json:"role"`
 	Rig       string    `json:"rig,omitempty"`
 	Worker    string    `json:"worker,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
 	CostUSD   float64   `json:"cost_usd"`
 	StartedAt time.Time `json:"started_at"`
 	EndedAt   time.Time `json:"ended_at"`
 	WorkItem  string    `json:"work_item,omitempty"`
+	Convoy    string    `json:"convoy,omitempty"`
 }
 
 // CostsOutput is the JSON output structure.
 type CostsOutput struct {
-	Sessions []SessionCost      `json:"sessions,omitempty"`
-	Total    float64            `json:"total_usd"`
-	ByRole   map[string]float64 `json:"by_role,omitempty"`
-	ByRig    map[string]float64 `json:"by_rig,omitempty"`
-	Period   string             `json:"period,omitempty"`
+	Sessions   []SessionCost      `json:"sessions,omitempty"`
+	Total      float64            `json:"total_usd"`
+	ByRole     map[string]float64 `json:"by_role,omitempty"`
+	ByRig      map[string]float64 `json:"by_rig,omitempty"`
+	ByProvider map[string]float64 `json:"by_provider,omitempty"`
+	ByConvoy   map[string]float64 `json:"by_convoy,omitempty"`
+	ByBead     map[string]float64 `json:"by_bead,omitempty"`
+	Period     string             `json:"period,omitempty"`
 }
 
 // costRegex matches cost patterns like "$1.23" or "$12.34"
 var costRegex = regexp.MustCompile(`\$(\d+\.\d{2})`)
 
-// TranscriptMessage represents a message from a Claude Code transcript file.
-type TranscriptMessage struct {
-	Type      string                 `json:"type"`
-	SessionID string                 `json:"sessionId"`
-	CWD       string                 `json:"cwd"`
-	Message   *TranscriptMessageBody `json:"message,omitempty"`
-}
-
-// TranscriptMessageBody contains the message content and usage info.
-type TranscriptMessageBody struct {
-	Model string          `json:"model"`
-	Role  string          `json:"role"`
-	Usage *TranscriptUsage `json:"usage,omitempty"`
-}
-
-// TranscriptUsage contains token usage information.
-type TranscriptUsage struct {
-	InputTokens              int `json:"input_tokens"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
-}
-
-// TokenUsage aggregates token usage across a session.
-type TokenUsage struct {
-	Model                    string
-	InputTokens              int
-	CacheCreationInputTokens int
-	CacheReadInputTokens     int
-	OutputTokens             int
-}
-
-// Model pricing per million tokens (as of Jan 2025).
-// See: https://www.anthropic.com/pricing
-var modelPricing = map[string]struct {
-	InputPerMillion       float64
-	OutputPerMillion      float64
-	CacheReadPerMillion   float64 // 90% discount on input price
-	CacheCreatePerMillion float64 // 25% premium on input price
-}{
-	// Claude Opus 4.5
-	"claude-opus-4-5-20251101": {15.0, 75.0, 1.5, 18.75},
-	// Claude Sonnet 4
-	"claude-sonnet-4-20250514": {3.0, 15.0, 0.3, 3.75},
-	// Claude Haiku 3.5
-	"claude-3-5-haiku-20241022": {1.0, 5.0, 0.1, 1.25},
-	// Fallback for unknown models (use Sonnet pricing)
-	"default": {3.0, 15.0, 0.3, 3.75},
-}
-
 func runCosts(cmd *cobra.Command, args []string) error {
 	// If querying ledger, use ledger functions
-	if costsToday || costsWeek || costsByRole || costsByRig {
+	if costsToday || costsWeek || costsByRole || costsByRig || costsByProvider || costsByConvoy || costsByBead {
 		return runCostsFromLedger()
 	}
 
@@ -256,6 +230,7 @@ func runLiveCosts() error {
 		return fmt.Errorf("listing sessions: %w", err)
 	}
 
+	settings := loadCostSettings()
 	var costs []SessionCost
 	var total float64
 
@@ -277,8 +252,9 @@ func runLiveCosts() error {
 			continue
 		}
 
-		// Extract cost from Claude transcript
-		cost, err := extractCostFromWorkDir(workDir)
+		// Extract cost from the agent's session records
+		agent, _ := t.GetEnvironment(sess, "GT_AGENT")
+		cost, provider, model, err := sessionCostFromWorkDir(settings, agent, workDir)
 		if err != nil {
 			if costsVerbose {
 				fmt.Fprintf(os.Stderr, "[costs] could not extract cost for %s: %v\n", sess, err)
@@ -291,12 +267,14 @@ func runLiveCosts() error {
 		running := t.IsAgentRunning(sess)
 
 		costs = append(costs, SessionCost{
-			Session: sess,
-			Role:    role,
-			Rig:     rig,
-			Worker:  worker,
-			Cost:    cost,
-			Running: running,
+			Session:  sess,
+			Role:     role,
+			Rig:      rig,
+			Worker:   worker,
+			Provider: provider,
+			Model:    model,
+			Cost:     cost,
+			Running:  running,
 		})
 		total += cost
 	}
@@ -319,6 +297,7 @@ func runLiveCosts() error {
 func runCostsFromLedger() error {
 	now := time.Now()
 	var entries []CostEntry
+	var digests []CostDigest
 	var err error
 
 	if costsToday {
@@ -331,7 +310,7 @@ func runCostsFromLedger() error {
 	} else if costsWeek {
 		// For week: query digest beads (costs.digest events)
 		// These are the aggregated daily reports
-		entries, err = queryDigestBeads(7)
+		entries, digests, err = queryDigestBeads(7)
 		if err != nil {
 			return fmt.Errorf("querying digest beads: %w", err)
 		}
@@ -339,8 +318,8 @@ func runCostsFromLedger() error {
 		// Also include today's wisps (not yet digested)
 		todayEntries, _ := querySessionCostEntries(now)
 		entries = append(entries, todayEntries...)
-	} else if costsByRole || costsByRig {
-		// When using --by-role or --by-rig without time filter, default to today
+	} else if costsByRole || costsByRig || costsByProvider || costsByConvoy || costsByBead {
+		// When using a breakdown flag without time filter, default to today
 		// (querying all historical events would be expensive and likely empty)
 		entries, err = querySessionCostEntries(now)
 		if err != nil {
@@ -361,6 +340,9 @@ func runCostsFromLedger() error {
 	var total float64
 	byRole := make(map[string]float64)
 	byRig := make(map[string]float64)
+	byProvider := make(map[string]float64)
+	byConvoy := make(map[string]float64)
+	byBead := make(map[string]float64)
 
 	for _, entry := range entries {
 		total += entry.CostUSD
@@ -368,6 +350,24 @@ func runCostsFromLedger() error {
 		if entry.Rig != "" {
 			byRig[entry.Rig] += entry.CostUSD
 		}
+		if entry.Provider != "" {
+			byProvider[entry.Provider] += entry.CostUSD
+		}
+		if entry.Convoy != "" {
+			byConvoy[entry.Convoy] += entry.CostUSD
+		}
+		if entry.WorkItem != "" {
+			byBead[entry.WorkItem] += entry.CostUSD
+		}
+	}
+	// Aggregate-only digests carry their convoy/bead breakdowns separately
+	for _, d := range digests {
+		for convoyID, cost := range d.ByConvoy {
+			byConvoy[convoyID] += cost
+		}
+		for beadID, cost := range d.ByBead {
+			byBead[beadID] += cost
+		}
 	}
 
 	// Build output
@@ -381,6 +381,15 @@ func runCostsFromLedger() error {
 	if costsByRig {
 		output.ByRig = byRig
 	}
+	if costsByProvider {
+		output.ByProvider = byProvider
+	}
+	if costsByConvoy {
+		output.ByConvoy = byConvoy
+	}
+	if costsByBead {
+		output.ByBead = byBead
+	}
 
 	// Set period label
 	if costsToday {
@@ -563,7 +572,9 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 }
 
 // queryDigestBeads queries costs.digest events from the past N days and extracts session entries.
-func queryDigestBeads(days int) ([]CostEntry, error) {
+// Digests that only carry aggregates are also returned, since their convoy and
+// bead breakdowns cannot be expressed as per-role entries.
+func queryDigestBeads(days int) ([]CostEntry, []CostDigest, error) {
 	// Get list of event IDs
 	listArgs := []string{
 		"list",
@@ -576,16 +587,16 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 	listCmd := exec.Command("bd", listArgs...)
 	listOutput, err := listCmd.Output()
 	if err != nil {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	var listItems []EventListItem
 	if err := json.Unmarshal(listOutput, &listItems); err != nil {
-		return nil, fmt.Errorf("parsing event list: %w", err)
+		return nil, nil, fmt.Errorf("parsing event list: %w", err)
 	}
 
 	if len(listItems) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Get full details for all events
@@ -597,12 +608,12 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 	showCmd := exec.Command("bd", showArgs...)
 	showOutput, err := showCmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("showing events: %w", err)
+		return nil, nil, fmt.Errorf("showing events: %w", err)
 	}
 
 	var events []SessionEvent
 	if err := json.Unmarshal(showOutput, &events); err != nil {
-		return nil, fmt.Errorf("parsing event details: %w", err)
+		return nil, nil, fmt.Errorf("parsing event details: %w", err)
 	}
 
 	// Calculate date range
@@ -610,6 +621,7 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 	cutoff := now.AddDate(0, 0, -days)
 
 	var entries []CostEntry
+	var aggregates []CostDigest
 	for _, event := range events {
 		// Filter for costs.digest events only
 		if event.EventKind != "costs.digest" {
@@ -638,6 +650,7 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		if len(digest.Sessions) > 0 {
 			entries = append(entries, digest.Sessions...)
 		} else {
+			aggregates = append(aggregates, digest)
 			for role, cost := range digest.ByRole {
 				entries = append(entries, CostEntry{
 					SessionID: fmt.Sprintf("digest-%s-%s", digest.Date, role),
@@ -649,7 +662,7 @@ func queryDigestBeads(days int) ([]CostEntry, error) {
 		}
 	}
 
-	return entries, nil
+	return entries, aggregates, nil
 }
 
 // parseSessionName extracts role, rig, and worker from a session name.
@@ -699,144 +712,83 @@ func extractCost(content string) float64 {
 	return cost
 }
 
-// getClaudeProjectDir returns the Claude Code project directory for a working directory.
-// Claude Code stores transcripts in ~/.claude/projects/<path-with-dashes-instead-of-slashes>/
-func getClaudeProjectDir(workDir string) (string, error) {
-	home, err := os.UserHomeDir()
+// sessionCostFromWorkDir prices the agent session that ran in workDir, using
+// the usage extractor registered for the agent's preset and the town's
+// pricing table. agent is the GT_AGENT name (empty means the town default).
+// The usage provider is returned even on error, for attribution.
+func sessionCostFromWorkDir(settings *config.TownSettings, agent, workDir string) (cost float64, provider, model string, err error) {
+	provider = resolveUsageProvider(settings, agent)
+	extract := config.GetUsageExtractor(provider)
+	if extract == nil {
+		return 0, provider, "", fmt.Errorf("no usage extractor for %q", provider)
+	}
+	usage, err := extract(workDir)
 	if err != nil {
-		return "", err
+		return 0, provider, "", err
 	}
-
-	// Convert path to Claude's directory naming: replace / with -
-	// Keep leading slash - it becomes a leading dash in Claude's encoding
-	projectName := strings.ReplaceAll(workDir, "/", "-")
-	return filepath.Join(home, ".claude", "projects", projectName), nil
+	var pricing map[string]*config.ModelPricing
+	if settings != nil {
+		pricing = settings.Pricing
+	}
+	return usage.Cost(pricing), provider, usage.Model, nil
 }
 
-// findLatestTranscript finds the most recently modified .jsonl file in a directory.
-func findLatestTranscript(projectDir string) (string, error) {
-	var latestPath string
-	var latestTime time.Time
-
-	err := filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+// resolveUsageProvider maps an agent name to its usage provider. Custom agents
+// defined in town settings resolve through the command they run.
+func resolveUsageProvider(settings *config.TownSettings, agent string) string {
+	var command string
+	if settings != nil {
+		if agent == "" {
+			agent = settings.DefaultAgent
 		}
-		if d.IsDir() && path != projectDir {
-			return fs.SkipDir // Don't recurse into subdirectories
+		if rc := settings.Agents[agent]; rc != nil {
+			command = rc.Command
 		}
-		if !d.IsDir() && strings.HasSuffix(path, ".jsonl") {
-			info, err := d.Info()
-			if err != nil {
-				return nil // Skip files we can't stat
-			}
-			if info.ModTime().After(latestTime) {
-				latestTime = info.ModTime()
-				latestPath = path
-			}
-		}
-		return nil
-	})
-
-	if err != nil {
-		return "", err
 	}
-	if latestPath == "" {
-		return "", fmt.Errorf("no transcript files found in %s", projectDir)
-	}
-	return latestPath, nil
+	return config.ResolveUsageProvider(agent, command)
 }
 
-// parseTranscriptUsage reads a transcript file and sums token usage from assistant messages.
-func parseTranscriptUsage(transcriptPath string) (*TokenUsage, error) {
-	file, err := os.Open(transcriptPath)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	usage := &TokenUsage{}
-	scanner := bufio.NewScanner(file)
-	// Increase buffer for potentially large JSON lines
-	buf := make([]byte, 0, 256*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
+// agentRecordsOwnCost reports whether agent's hooks run gt costs record when
+// its session stops, as the Claude and Gemini Stop hooks and the OpenCode and
+// Pi plugins do. agent is the GT_AGENT name (empty means the town default); a
+// custom agent is matched to a preset by its command.
+func agentRecordsOwnCost(settings *config.TownSettings, agent string) bool {
+	var command string
+	if settings != nil {
+		if agent == "" {
+			agent = settings.DefaultAgent
 		}
-
-		var msg TranscriptMessage
-		if err := json.Unmarshal(line, &msg); err != nil {
-			continue // Skip malformed lines
+		if rc := settings.Agents[agent]; rc != nil {
+			command = rc.Command
 		}
-
-		// Only process assistant messages with usage info
-		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
-			continue
-		}
-
-		// Capture the model (use first one found, they should all be the same)
-		if usage.Model == "" && msg.Message.Model != "" {
-			usage.Model = msg.Message.Model
-		}
-
-		// Sum token usage
-		u := msg.Message.Usage
-		usage.InputTokens += u.InputTokens
-		usage.CacheCreationInputTokens += u.CacheCreationInputTokens
-		usage.CacheReadInputTokens += u.CacheReadInputTokens
-		usage.OutputTokens += u.OutputTokens
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	if agent == "" {
+		agent = string(config.AgentClaude)
 	}
-
-	return usage, nil
-}
-
-// calculateCost converts token usage to USD cost based on model pricing.
-func calculateCost(usage *TokenUsage) float64 {
-	if usage == nil {
-		return 0.0
-	}
-
-	// Look up pricing for the model
-	pricing, ok := modelPricing[usage.Model]
-	if !ok {
-		pricing = modelPricing["default"]
+	preset := config.GetAgentPresetByName(agent)
+	if preset == nil && command != "" {
+		for _, name := range config.ListAgentPresets() {
+			if p := config.GetAgentPresetByName(name); p != nil && filepath.Base(p.Command) == filepath.Base(command) {
+				preset = p
+				break
+			}
+		}
 	}
-
-	// Calculate cost (prices are per million tokens)
-	inputCost := float64(usage.InputTokens) / 1_000_000 * pricing.InputPerMillion
-	cacheReadCost := float64(usage.CacheReadInputTokens) / 1_000_000 * pricing.CacheReadPerMillion
-	cacheCreateCost := float64(usage.CacheCreationInputTokens) / 1_000_000 * pricing.CacheCreatePerMillion
-	outputCost := float64(usage.OutputTokens) / 1_000_000 * pricing.OutputPerMillion
-
-	return inputCost + cacheReadCost + cacheCreateCost + outputCost
+	return preset != nil && preset.SupportsHooks
 }
 
-// extractCostFromWorkDir extracts cost from Claude Code transcript for a working directory.
-// This reads the most recent transcript file and sums all token usage.
-func extractCostFromWorkDir(workDir string) (float64, error) {
-	projectDir, err := getClaudeProjectDir(workDir)
-	if err != nil {
-		return 0, fmt.Errorf("getting project dir: %w", err)
-	}
-
-	transcriptPath, err := findLatestTranscript(projectDir)
-	if err != nil {
-		return 0, fmt.Errorf("finding transcript: %w", err)
+// loadCostSettings loads town settings (agents and pricing) for the town
+// containing the cwd. Returns nil outside a town.
+func loadCostSettings() *config.TownSettings {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil
 	}
-
-	usage, err := parseTranscriptUsage(transcriptPath)
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
 	if err != nil {
-		return 0, fmt.Errorf("parsing transcript: %w", err)
+		return nil
 	}
-
-	return calculateCost(usage), nil
+	return settings
 }
 
 // getTmuxSessionWorkDir gets the current working directory of a tmux session.
@@ -864,9 +816,9 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 	fmt.Printf("\n%s Live Session Costs\n\n", style.Bold.Render("💰"))
 
 	// Print table header
-	fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
-		"Session", "Role", "Rig/Worker", "Cost", "Status")
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Printf("%-25s %-10s %-15s %-10s %10s %8s\n",
+		"Session", "Role", "Rig/Worker", "Provider", "Cost", "Status")
+	fmt.Println(strings.Repeat("─", 86))
 
 	// Print each session
 	for _, c := range costs {
@@ -884,16 +836,17 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 			}
 		}
 
-		fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
+		fmt.Printf("%-25s %-10s %-15s %-10s %10s %8s\n",
 			c.Session,
 			c.Role,
 			rigWorker,
+			c.Provider,
 			fmt.Sprintf("$%.2f", c.Cost),
 			statusIcon)
 	}
 
 	// Print total
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Println(strings.Repeat("─", 86))
 	fmt.Printf("%s %s\n", style.Bold.Render("Total:"), fmt.Sprintf("$%.2f", total))
 
 	return nil
@@ -927,6 +880,30 @@ func outputLedgerHuman(output CostsOutput, entries []CostEntry) error {
 		}
 	}
 
+	// By provider breakdown
+	if len(output.ByProvider) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Provider:"))
+		for provider, cost := range output.ByProvider {
+			fmt.Printf("  %-15s $%.2f\n", provider, cost)
+		}
+	}
+
+	// By convoy breakdown
+	if len(output.ByConvoy) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Convoy:"))
+		for convoyID, cost := range output.ByConvoy {
+			fmt.Printf("  %-15s $%.2f\n", convoyID, cost)
+		}
+	}
+
+	// By bead breakdown
+	if len(output.ByBead) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("By Bead:"))
+		for beadID, cost := range output.ByBead {
+			fmt.Printf("  %-15s $%.2f\n", beadID, cost)
+		}
+	}
+
 	// Session count
 	fmt.Printf("\n%s %d sessions\n", style.Dim.Render("Entries:"), len(entries))
 
@@ -939,26 +916,34 @@ type CostLogEntry struct {
 	Role      string    `json:"role"`
 	Rig       string    `json:"rig,omitempty"`
 	Worker    string    `json:"worker,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
 	CostUSD   float64   `json:"cost_usd"`
 	EndedAt   time.Time `json:"ended_at"`
 	WorkItem  string    `json:"work_item,omitempty"`
+	Convoy    string    `json:"convoy,omitempty"`
 }
 
 // getCostsLogPath returns the path to the costs log file (~/.gt/costs.jsonl).
 func getCostsLogPath() string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "/tmp/gt-costs.jsonl" // Fallback
-	}
-	return filepath.Join(home, ".gt", "costs.jsonl")
+	return budget.CostsLogPath()
 }
 
 // runCostsRecord captures the final cost from a session and appends it to a local log file.
-// This is called by the Claude Code Stop hook. It's designed to never fail due to
-// database availability - it's a simple file append operation.
+// This is called by the agents' Stop hooks, and by gt done for agents without
+// hooks. It's designed to never fail due to database availability - it's a
+// simple file append operation.
 func runCostsRecord(cmd *cobra.Command, args []string) error {
-	// Get session from flag or try to detect from environment
-	session := recordSession
+	return recordSessionCost(recordSession)
+}
+
+// recordSessionCostFn records a session's cost as gt costs record does. A
+// seam for tests.
+var recordSessionCostFn = recordSessionCost
+
+// recordSessionCost appends a session's cost to the costs log. An empty
+// session is detected from the environment.
+func recordSessionCost(session string) error {
 	if session == "" {
 		session = os.Getenv("GT_SESSION")
 	}
@@ -987,14 +972,21 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Extract cost from Claude transcript
+	// Extract cost from the agent's session records
+	agent := recordAgent
+	if agent == "" {
+		agent = os.Getenv("GT_AGENT")
+	}
+	settings := loadCostSettings()
+	provider := resolveUsageProvider(settings, agent)
 	var cost float64
+	var model string
 	if workDir != "" {
 		var err error
-		cost, err = extractCostFromWorkDir(workDir)
+		cost, provider, model, err = sessionCostFromWorkDir(settings, agent, workDir)
 		if err != nil {
 			if costsVerbose {
-				fmt.Fprintf(os.Stderr, "[costs] could not extract cost from transcript: %v\n", err)
+				fmt.Fprintf(os.Stderr, "[costs] could not extract cost from %s usage: %v\n", provider, err)
 			}
 			cost = 0.0
 		}
@@ -1003,15 +995,28 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
 
+	// Attribute the session to its work bead and that bead's convoy
+	workItem := recordWorkItem
+	if workItem == "" {
+		workItem = resolveHookedBeadFn(session, workDir)
+	}
+	var convoyID string
+	if workItem != "" {
+		convoyID = trackingConvoyFn(workItem)
+	}
+
 	// Build log entry
 	entry := CostLogEntry{
 		SessionID: session,
 		Role:      role,
 		Rig:       rig,
 		Worker:    worker,
+		Provider:  provider,
+		Model:     model,
 		CostUSD:   cost,
 		EndedAt:   time.Now(),
-		WorkItem:  recordWorkItem,
+		WorkItem:  workItem,
+		Convoy:    convoyID,
 	}
 
 	// Marshal to JSON
@@ -1044,10 +1049,10 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Output confirmation (silent if cost is zero and no work item)
-	if cost > 0 || recordWorkItem != "" {
+	if cost > 0 || workItem != "" {
 		fmt.Printf("%s Recorded $%.2f for %s", style.Success.Render("✓"), cost, session)
-		if recordWorkItem != "" {
-			fmt.Printf(" (work: %s)", recordWorkItem)
+		if workItem != "" {
+			fmt.Printf(" (work: %s)", workItem)
 		}
 		fmt.Println()
 	}
@@ -1055,6 +1060,37 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// loadCostTotalsFn is a seam for tests. Production totals the whole costs
+// ledger (log file plus digest beads).
+var loadCostTotalsFn = func(townRoot string) (budget.Totals, error) {
+	return budget.LoadTotals(townRoot, getCostsLogPath())
+}
+
+// resolveHookedBeadFn and trackingConvoyFn are seams for tests.
+var (
+	resolveHookedBeadFn = resolveHookedBead
+	trackingConvoyFn    = isTrackedByConvoy
+)
+
+// resolveHookedBead returns the bead on the hook of the agent running in
+// session, read from the agent bead's hook slot. Returns "" if the agent has
+// no agent bead or nothing hooked.
+func resolveHookedBead(sessionName, workDir string) string {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return ""
+	}
+	agentBeadID := agentIDToBeadID(sessionToAgentID(sessionName), townRoot)
+	if agentBeadID == "" {
+		return ""
+	}
+	agentBead, err := beads.New(beads.ResolveHookDir(townRoot, agentBeadID, workDir)).Show(agentBeadID)
+	if err != nil {
+		return ""
+	}
+	return agentBead.HookBead
+}
+
 // deriveSessionName derives the tmux session name from GT_* environment variables.
 // Uses session.* helpers for canonical naming. Parses GT_ROLE via parseRoleString
 // so compound forms (e.g. "gastown/witness") resolve to their canonical session names.
@@ -1133,6 +1169,9 @@ type CostDigest struct {
 	Sessions     []CostEntry        `json:"sessions,omitempty"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByProvider   map[string]float64 `json:"by_provider,omitempty"`
+	ByConvoy     map[string]float64 `json:"by_convoy,omitempty"`
+	ByBead       map[string]float64 `json:"by_bead,omitempty"`
 }
 
 // CostDigestPayload is the compact payload stored in the bead.
@@ -1143,6 +1182,9 @@ type CostDigestPayload struct {
 	SessionCount int                `json:"session_count"`
 	ByRole       map[string]float64 `json:"by_role"`
 	ByRig        map[string]float64 `json:"by_rig,omitempty"`
+	ByProvider   map[string]float64 `json:"by_provider,omitempty"`
+	ByConvoy     map[string]float64 `json:"by_convoy,omitempty"`
+	ByBead       map[string]float64 `json:"by_bead,omitempty"`
 }
 
 // runCostsDigest aggregates session cost entries into a daily digest bead.
@@ -1177,10 +1219,13 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 
 	// Build digest
 	digest := CostDigest{
-		Date:     dateStr,
-		Sessions: costEntries,
-		ByRole:   make(map[string]float64),
-		ByRig:    make(map[string]float64),
+		Date:       dateStr,
+		Sessions:   costEntries,
+		ByRole:     make(map[string]float64),
+		ByRig:      make(map[string]float64),
+		ByProvider: make(map[string]float64),
+		ByConvoy:   make(map[string]float64),
+		ByBead:     make(map[string]float64),
 	}
 
 	for _, e := range costEntries {
@@ -1190,6 +1235,15 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 		if e.Rig != "" {
 			digest.ByRig[e.Rig] += e.CostUSD
 		}
+		if e.Provider != "" {
+			digest.ByProvider[e.Provider] += e.CostUSD
+		}
+		if e.Convoy != "" {
+			digest.ByConvoy[e.Convoy] += e.CostUSD
+		}
+		if e.WorkItem != "" {
+			digest.ByBead[e.WorkItem] += e.CostUSD
+		}
 	}
 
 	if digestDryRun {
@@ -1206,9 +1260,31 @@ func runCostsDigest(cmd *cobra.Command, args []string) error {
 				fmt.Printf("    %s: $%.2f\n", rig, cost)
 			}
 		}
+		if len(digest.ByProvider) > 0 {
+			fmt.Printf("  By Provider:\n")
+			for provider, cost := range digest.ByProvider {
+				fmt.Printf("    %s: $%.2f\n", provider, cost)
+			}
+		}
+		if len(digest.ByConvoy) > 0 {
+			fmt.Printf("  By Convoy:\n")
+			for convoyID, cost := range digest.ByConvoy {
+				fmt.Printf("    %s: $%.2f\n", convoyID, cost)
+			}
+		}
 		return nil
 	}
 
+	// Budgets and convoy costs only read labeled digests. Label the ones
+	// written before digests were labeled (a no-op once any is labeled).
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		if n, err := budget.LabelDigests(townRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to label existing digests: %v\n", err)
+		} else if n > 0 {
+			fmt.Printf("  Labeled %d existing digest(s)\n", n)
+		}
+	}
+
 	// Create permanent digest bead
 	digestID, err := createCostDigestBead(digest)
 	if err != nil {
@@ -1272,9 +1348,11 @@ func querySessionCostEntries(targetDate time.Time) ([]CostEntry, error) {
 			Role:      logEntry.Role,
 			Rig:       logEntry.Rig,
 			Worker:    logEntry.Worker,
+			Provider:  logEntry.Provider,
 			CostUSD:   logEntry.CostUSD,
 			EndedAt:   logEntry.EndedAt,
 			WorkItem:  logEntry.WorkItem,
+			Convoy:    logEntry.Convoy,
 		})
 	}
 
@@ -1315,6 +1393,32 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		desc.WriteString("\n")
 	}
 
+	if len(digest.ByProvider) > 0 {
+		desc.WriteString("## By Provider\n")
+		providers := make([]string, 0, len(digest.ByProvider))
+		for provider := range digest.ByProvider {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		for _, provider := range providers {
+			desc.WriteString(fmt.Sprintf("- %s: $%.2f\n", provider, digest.ByProvider[provider]))
+		}
+		desc.WriteString("\n")
+	}
+
+	if len(digest.ByConvoy) > 0 {
+		desc.WriteString("## By Convoy\n")
+		convoyIDs := make([]string, 0, len(digest.ByConvoy))
+		for convoyID := range digest.ByConvoy {
+			convoyIDs = append(convoyIDs, convoyID)
+		}
+		sort.Strings(convoyIDs)
+		for _, convoyID := range convoyIDs {
+			desc.WriteString(fmt.Sprintf("- %s: $%.2f\n", convoyID, digest.ByConvoy[convoyID]))
+		}
+		desc.WriteString("\n")
+	}
+
 	// Build compact payload (aggregate only, no per-session details).
 	// Per-session details can be thousands of records and exceed Dolt column limits.
 	compactPayload := CostDigestPayload{
@@ -1323,6 +1427,9 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		SessionCount: digest.SessionCount,
 		ByRole:       digest.ByRole,
 		ByRig:        digest.ByRig,
+		ByProvider:   digest.ByProvider,
+		ByConvoy:     digest.ByConvoy,
+		ByBead:       digest.ByBead,
 	}
 	payloadJSON, err := json.Marshal(compactPayload)
 	if err != nil {
@@ -1336,6 +1443,7 @@ func createCostDigestBead(digest CostDigest) (string, error) {
 		"--type=event",
 		"--title=" + title,
 		"--event-category=costs.digest",
+		"--labels=" + budget.DigestLabel,
 		"--event-payload=" + string(payloadJSON),
 		"--description=" + desc.String(),
 		"--silent",
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/session"
 )
 
@@ -264,3 +265,118 @@ func TestCostDigestPayload_ExcludesSessions(t *testing.T) {
 		t.Errorf("by_role should have 3 entries, got %d", len(asDigest.ByRole))
 	}
 }
+
+func TestResolveUsageProvider_TownSettings(t *testing.T) {
+	settings := config.NewTownSettings()
+	settings.DefaultAgent = "gemini"
+	settings.Agents["fast-codex"] = &config.RuntimeConfig{Command: "codex"}
+
+	tests := []struct {
+		agent string
+		want  string
+	}{
+		{"", "gemini"},              // town default agent
+		{"fast-codex", "codex"},     // custom agent resolved via its command
+		{"opencode", "opencode"},    // built-in preset
+		{"mystery-agent", "claude"}, // unknown: historical default
+	}
+	for _, tt := range tests {
+		if got := resolveUsageProvider(settings, tt.agent); got != tt.want {
+			t.Errorf("resolveUsageProvider(%q) = %q, want %q", tt.agent, got, tt.want)
+		}
+	}
+	if got := resolveUsageProvider(nil, ""); got != "claude" {
+		t.Errorf("resolveUsageProvider outside a town = %q, want claude", got)
+	}
+}
+
+func TestRunCostsRecord_AttributesHookedBead(t *testing.T) {
+	setupCostsTestRegistry(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GT_CWD", "")
+	t.Setenv("GT_AGENT", "")
+
+	origHook, origConvoy := resolveHookedBeadFn, trackingConvoyFn
+	t.Cleanup(func() { resolveHookedBeadFn, trackingConvoyFn = origHook, origConvoy })
+	var hookSession string
+	resolveHookedBeadFn = func(sessionName, _ string) string {
+		hookSession = sessionName
+		return "gt-abc"
+	}
+	trackingConvoyFn = func(beadID string) string {
+		if beadID == "gt-abc" {
+			return "hq-cv-1"
+		}
+		return ""
+	}
+
+	origSession, origWorkItem := recordSession, recordWorkItem
+	t.Cleanup(func() { recordSession, recordWorkItem = origSession, origWorkItem })
+	recordSession = "gt-toast"
+	recordWorkItem = ""
+
+	if err := runCostsRecord(costsRecordCmd, nil); err != nil {
+		t.Fatalf("runCostsRecord: %v", err)
+	}
+	if hookSession != "gt-toast" {
+		t.Errorf("hook resolved for session %q, want gt-toast", hookSession)
+	}
+
+	data, err := os.ReadFile(getCostsLogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry CostLogEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("parsing log entry %q: %v", data, err)
+	}
+	if entry.WorkItem != "gt-abc" || entry.Convoy != "hq-cv-1" {
+		t.Errorf("entry attributed to %q/%q, want gt-abc/hq-cv-1", entry.WorkItem, entry.Convoy)
+	}
+	if entry.Rig != "gastown" || entry.Role != "polecat" {
+		t.Errorf("entry rig/role = %q/%q, want gastown/polecat", entry.Rig, entry.Role)
+	}
+
+	// An explicit --work-item wins over the hook.
+	recordWorkItem = "gt-explicit"
+	if err := runCostsRecord(costsRecordCmd, nil); err != nil {
+		t.Fatalf("runCostsRecord: %v", err)
+	}
+	entries, err := querySessionCostEntries(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].WorkItem != "gt-explicit" || entries[1].Convoy != "" {
+		t.Errorf("entries = %+v, want second entry for gt-explicit without convoy", entries)
+	}
+}
+
+func TestAgentRecordsOwnCost(t *testing.T) {
+	settings := &config.TownSettings{
+		DefaultAgent: "codex",
+		Agents: map[string]*config.RuntimeConfig{
+			"fast-claude": {Command: "/usr/local/bin/claude"},
+			"house-codex": {Command: "codex"},
+		},
+	}
+	tests := []struct {
+		settings *config.TownSettings
+		agent    string
+		want     bool
+	}{
+		{nil, "", true}, // Claude by default
+		{nil, "gemini", true},
+		{nil, "opencode", true},
+		{nil, "codex", false},
+		{nil, "copilot", false},
+		{settings, "", false}, // the town default is codex
+		{settings, "fast-claude", true},
+		{settings, "house-codex", false},
+	}
+	for _, tt := range tests {
+		if got := agentRecordsOwnCost(tt.settings, tt.agent); got != tt.want {
+			t.Errorf("agentRecordsOwnCost(%q) = %v, want %v", tt.agent, got, tt.want)
+		}
+	}
+}
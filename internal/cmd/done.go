@@ -1400,6 +1400,14 @@ func selfKillSession(townRoot string, roleInfo RoleInfo) error {
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 	agentID := fmt.Sprintf("%s/polecats/%s", rigName, polecatName)
 
+	// Agents without executable hooks have no Stop hook to record the
+	// session's cost, so record it here while the session still exists.
+	if !agentRecordsOwnCost(loadCostSettings(), os.Getenv("GT_AGENT")) {
+		if err := recordSessionCostFn(sessionName); err != nil {
+			style.PrintWarning("could not record session cost: %v", err)
+		}
+	}
+
 	// Log to townlog (human-readable audit log)
 	if townRoot != "" {
 		logger := townlog.NewLogger(townRoot)
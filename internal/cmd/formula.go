@@ -9,10 +9,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
+	"github.com/BurntSushi/toml"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
@@ -25,12 +27,14 @@ import (
 
 // Formula command flags
 var (
-	formulaListJSON   bool
-	formulaShowJSON   bool
-	formulaRunPR      int
-	formulaRunRig     string
-	formulaRunDryRun  bool
-	formulaCreateType string
+	formulaListJSON     bool
+	formulaShowJSON     bool
+	formulaShowResolved bool
+	formulaRunPR        int
+	formulaRunRig       string
+	formulaRunDryRun    bool
+	formulaRunVars      []string
+	formulaCreateType   string
 )
 
 var formulaCmd = &cobra.Command{
@@ -46,15 +50,23 @@ and composition rules. They can be "poured" to create molecules or "wisped"
 for ephemeral patrol cycles.
 
 Commands:
-  list    List available formulas from all search paths
-  show    Display formula details (steps, variables, composition)
-  run     Execute a formula (pour and dispatch)
-  create  Create a new formula template
-
-Search paths (in order):
-  1. .beads/formulas/ (project)
-  2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator)
+  list      List available formulas from all search paths
+  show      Display formula details (steps, variables, composition)
+  run       Execute a formula (pour and dispatch)
+  simulate  Estimate a formula's schedule, peak parallelism and duration
+  create    Create a new formula template
+
+Registry commands (Mol Mall):
+  install   Install formulas from a registry into the town
+  upgrade   Upgrade installed formulas
+  outdated  List installed formulas with newer releases
+  remove    Remove installed formulas
+
+Search paths (in order, most specific wins):
+  1. .beads/formulas/ nearest the current directory (project)
+  2. $GT_ROOT/.beads/formulas/ (town; gt formula install puts formulas here)
+  3. ~/.beads/formulas/ (user)
+  4. Formulas built into gt (system)
 
 Examples:
   gt formula list                    # List all formulas
@@ -69,9 +81,9 @@ var formulaListCmd = &cobra.Command{
 	Long: `List available formulas from all search paths.
 
 Searches for formula files (.formula.toml, .formula.json) in:
-  1. .beads/formulas/ (project)
-  2. ~/.beads/formulas/ (user)
-  3. $GT_ROOT/.beads/formulas/ (orchestrator)
+  1. .beads/formulas/ nearest the current directory (project)
+  2. $GT_ROOT/.beads/formulas/ (town)
+  3. ~/.beads/formulas/ (user)
 
 Examples:
   gt formula list            # List all formulas
@@ -90,9 +102,14 @@ Shows:
   - Steps with dependencies
   - Composition rules (extends, aspects)
 
+With --resolved, resolves extends and include and prints the fully expanded
+formula as TOML (or JSON with --json). Formulas are looked up in the search
+paths, then among the formulas built into gt.
+
 Examples:
   gt formula show shiny
-  gt formula show rule-of-five --json`,
+  gt formula show rule-of-five --json
+  gt formula show shiny-secure --resolved`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFormulaShow,
 }
@@ -109,18 +126,24 @@ This command:
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
+Formula inputs are set with --var and checked against their declared types
+before anything is dispatched. Missing required inputs are prompted for
+when run from a terminal.
+
 If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
   --pr=N      Run formula on GitHub PR #N
   --rig=NAME  Target specific rig (default: current or gastown)
+  --var=K=V   Set formula input K (repeatable)
   --dry-run   Show what would happen without executing
 
 Examples:
   gt formula run shiny                    # Run formula in current rig
   gt formula run                          # Run default formula from rig config
   gt formula run shiny --pr=123           # Run on PR #123
+  gt formula run design --var problem="Rate limiting"
   gt formula run security-audit --rig=beads  # Run in specific rig
   gt formula run release --dry-run        # Preview execution`,
 	Args: cobra.MaximumNArgs(1),
@@ -154,11 +177,13 @@ func init() {
 
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
+	formulaShowCmd.Flags().BoolVar(&formulaShowResolved, "resolved", false, "Show the formula with extends and include resolved (compose is listed, not applied)")
 
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Formula input (key=value), can be repeated")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -185,9 +210,13 @@ func runFormulaList(cmd *cobra.Command, args []string) error {
 	return bdCmd.Run()
 }
 
-// runFormulaShow delegates to bd formula show
+// runFormulaShow delegates to bd formula show, or shows the resolved
+// formula itself with --resolved.
 func runFormulaShow(cmd *cobra.Command, args []string) error {
 	formulaName := args[0]
+	if formulaShowResolved {
+		return showResolvedFormula(formulaName)
+	}
 	bdArgs := []string{"formula", "show", formulaName}
 	if formulaShowJSON {
 		bdArgs = append(bdArgs, "--json")
@@ -261,9 +290,25 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("parsing formula: %w", err)
 	}
 
+	// Check inputs before anything is dispatched
+	if err := checkPourable(f); err != nil {
+		return err
+	}
+	given, err := parseVarFlags(formulaRunVars)
+	if err != nil {
+		return err
+	}
+	if _, ok := f.Param("pr"); ok && formulaRunPR > 0 && given["pr"] == "" {
+		given["pr"] = strconv.Itoa(formulaRunPR)
+	}
+	vars, err := resolveFormulaParams(f, given, nil)
+	if err != nil {
+		return err
+	}
+
 	// Handle dry-run mode
 	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
+		return dryRunFormula(f, formulaName, targetRig, vars)
 	}
 
 	// Currently only convoy formulas are supported for execution
@@ -271,6 +316,12 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
 			style.Dim.Render("Note:"), f.Type)
 		fmt.Printf("Currently only 'convoy' formulas can be run.\n")
+		if needsResolvedPour(f) {
+			// bd cook would miss its includes and pour when/foreach steps as written
+			fmt.Printf("\nTo run '%s', sling it (gt resolves include, when and foreach first):\n", formulaName)
+			fmt.Printf("  gt sling %s %s\n", formulaName, targetRig)
+			return nil
+		}
 		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
 		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
 		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
@@ -280,11 +331,11 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
+	return executeConvoyFormula(f, formulaName, targetRig, vars)
 }
 
 // dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formula.Formula, formulaName, targetRig string) error {
+func dryRunFormula(f *formula.Formula, formulaName, targetRig string, vars map[string]string) error {
 	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
 	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
 	fmt.Printf("  Type:    %s\n", f.Type)
@@ -292,6 +343,17 @@ func dryRunFormula(f *formula.Formula, formulaName, targetRig string) error {
 	if formulaRunPR > 0 {
 		fmt.Printf("  PR:      #%d\n", formulaRunPR)
 	}
+	if len(vars) > 0 {
+		names := make([]string, 0, len(vars))
+		for name := range vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("  Vars:\n")
+		for _, name := range names {
+			fmt.Printf("    %s = %s\n", name, vars[name])
+		}
+	}
 
 	if f.Type == formula.TypeConvoy && len(f.Legs) > 0 {
 		// Generate review ID for dry-run display
@@ -369,7 +431,7 @@ func dryRunFormula(f *formula.Formula, formulaName, targetRig string) error {
 }
 
 // executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) error {
+func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string, vars map[string]string) error {
 	fmt.Printf("%s Executing convoy formula: %s\n\n",
 		style.Bold.Render("🚚"), formulaName)
 
@@ -481,6 +543,7 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 					"changed_files": changedFiles,
 					"files":         []string{}, // TODO: support --files flag
 				}
+				addVarsToContext(legCtx, vars)
 
 				// Compute output path for this leg
 				if f.Output != nil {
@@ -634,18 +697,86 @@ func executeConvoyFormula(f *formula.Formula, formulaName, targetRig string) err
 	return nil
 }
 
-// findFormulaFile searches for a formula file by name
-func findFormulaFile(name string) (string, error) {
-	// Search paths in order
+// showResolvedFormula prints a formula with extends and include resolved.
+// Its [compose] table is printed as is, with a warning that bd cook applies
+// it.
+func showResolvedFormula(name string) error {
+	f, err := loadFormula(name)
+	if err != nil {
+		return err
+	}
+	warnUnappliedCompose(f)
+
+	// The resolved formula stands alone.
+	f.Extends, f.Include = nil, nil
+	if formulaShowJSON {
+		return outputJSON(f)
+	}
+	return toml.NewEncoder(os.Stdout).Encode(f)
+}
+
+// warnUnappliedCompose warns on stderr that f's compose aspects and
+// expansions are missing from gt's view of its steps.
+func warnUnappliedCompose(f *formula.Formula) {
+	if desc := unappliedCompose(f); desc != "" {
+		fmt.Fprintf(os.Stderr, "%s %s: %s not applied; bd cook applies them when the formula is poured\n",
+			style.Warning.Render("⚠"), f.Name, desc)
+	}
+}
+
+// unappliedCompose describes f's compose aspects and expansions, or returns
+// "" if it has none.
+func unappliedCompose(f *formula.Formula) string {
+	if f.Compose.Empty() {
+		return ""
+	}
+	var parts []string
+	if len(f.Compose.Aspects) > 0 {
+		parts = append(parts, "aspects "+strings.Join(f.Compose.Aspects, ", "))
+	}
+	for _, e := range f.Compose.Expand {
+		parts = append(parts, fmt.Sprintf("expansion %s on %s", e.With, e.Target))
+	}
+	return "compose " + strings.Join(parts, "; ")
+}
+
+// loadFormula finds the named formula in the search paths, then among the
+// embedded formulas, and parses it with extends and include resolved.
+func loadFormula(name string) (*formula.Formula, error) {
+	var (
+		f   *formula.Formula
+		err error
+	)
+	if path, findErr := findFormulaFile(name); findErr == nil {
+		f, err = parseFormulaFile(path)
+	} else {
+		data, loadErr := formula.EmbeddedLoader(name)
+		if loadErr != nil {
+			return nil, fmt.Errorf("%w: %s", formula.ErrFormulaNotFound, name)
+		}
+		f, err = formula.ParseWithLoader(data, formula.DirLoader(formulaSearchPaths()...))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolving formula %s: %w", name, err)
+	}
+	return f, nil
+}
+
+// formulaSearchPaths returns the directories searched for formulas, in order.
+// The order follows docs/formula-resolution.md: project, then town (where
+// gt formula install puts formulas), with the embedded formulas as the
+// final fallback.
+func formulaSearchPaths() []string {
 	searchPaths := []string{}
+	townRoot, _ := workspace.FindFromCwd()
 
-	// 1. Project .beads/formulas/
+	// 1. Project: the nearest .beads/formulas/ from cwd up to the town root
 	if cwd, err := os.Getwd(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(cwd, ".beads", "formulas"))
+		searchPaths = append(searchPaths, projectFormulasDir(cwd, townRoot))
 	}
 
 	// 2. Town .beads/formulas/
-	if townRoot, err := workspace.FindFromCwd(); err == nil {
+	if townRoot != "" {
 		searchPaths = append(searchPaths, filepath.Join(townRoot, ".beads", "formulas"))
 	}
 
@@ -653,10 +784,35 @@ func findFormulaFile(name string) (string, error) {
 	if home, err := os.UserHomeDir(); err == nil {
 		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
 	}
+	return searchPaths
+}
+
+// projectFormulasDir returns the nearest .beads/formulas/ directory at or
+// above cwd and below townRoot, or cwd's if there is none.
+func projectFormulasDir(cwd, townRoot string) string {
+	fallback := filepath.Join(cwd, ".beads", "formulas")
+	if townRoot == "" {
+		return fallback
+	}
+	for dir := cwd; dir != townRoot; {
+		candidate := filepath.Join(dir, ".beads", "formulas")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return fallback
+}
 
+// findFormulaFile searches for a formula file by name
+func findFormulaFile(name string) (string, error) {
 	// Try each path with common extensions
 	extensions := []string{".formula.toml", ".formula.json"}
-	for _, basePath := range searchPaths {
+	for _, basePath := range formulaSearchPaths() {
 		for _, ext := range extensions {
 			path := filepath.Join(basePath, name+ext)
 			if _, err := os.Stat(path); err == nil {
@@ -668,9 +824,16 @@ func findFormulaFile(name string) (string, error) {
 	return "", fmt.Errorf("formula '%s' not found in search paths", name)
 }
 
-// parseFormulaFile parses a formula file using the formula package's TOML parser.
+// parseFormulaFile parses a formula file using the formula package's TOML
+// parser. Formulas it extends or includes are looked up next to it, then in
+// the search paths.
 func parseFormulaFile(path string) (*formula.Formula, error) {
-	return formula.ParseFile(path)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from the formula search paths
+	if err != nil {
+		return nil, fmt.Errorf("reading formula file: %w", err)
+	}
+	dirs := append([]string{filepath.Dir(path)}, formulaSearchPaths()...)
+	return formula.ParseWithLoader(data, formula.DirLoader(dirs...))
 }
 
 // renderTemplate renders a Go text/template with the given context map
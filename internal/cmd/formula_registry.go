@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/molmall"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Formula registry command flags
+var (
+	formulaInstallRegistry string
+	formulaInstallForce    bool
+	formulaUpgradeForce    bool
+	formulaOutdatedJSON    bool
+	formulaRemoveForce     bool
+)
+
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install <name[@version]>...",
+	Short: "Install formulas from a registry",
+	Long: `Install formulas from a Mol Mall registry into the town's
+.beads/formulas/ directory.
+
+Registries are listed in settings/config.json under formula_registries and
+searched in order (or pick one with --registry). A registry URL may be
+http(s), file:// or a local directory. Downloads are verified against the
+registry's sha256 checksums and recorded in .beads/formulas/formulas.lock.
+
+Giving a version pins the formula: gt formula upgrade leaves it alone.
+A major ("@4") or major.minor ("@4.1") version picks the newest match.
+"@latest" installs the latest release and unpins.
+
+Examples:
+  gt formula install mol-polecat-code-review
+  gt formula install mol-polecat-work@4.0.0     # Pin to 4.0.0
+  gt formula install mol-deploy@2 --registry=acme`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFormulaInstall,
+}
+
+var formulaUpgradeCmd = &cobra.Command{
+	Use:   "upgrade [name[@version]]...",
+	Short: "Upgrade installed formulas",
+	Long: `Upgrade formulas installed from a registry to their latest release.
+
+With no names, upgrades every installed formula that is not pinned. Naming a
+version moves the formula to it and pins it there. Formulas edited since
+install are not overwritten without --force.
+
+Examples:
+  gt formula upgrade                          # Upgrade all unpinned formulas
+  gt formula upgrade mol-polecat-code-review
+  gt formula upgrade mol-polecat-work@4.1.0   # Move the pin`,
+	RunE: runFormulaUpgrade,
+}
+
+var formulaOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List installed formulas with newer releases",
+	Long: `List formulas installed from a registry whose registry has a newer
+latest release. Pinned formulas are included and marked.
+
+Examples:
+  gt formula outdated
+  gt formula outdated --json`,
+	Args: cobra.NoArgs,
+	RunE: runFormulaOutdated,
+}
+
+var formulaRemoveCmd = &cobra.Command{
+	Use:     "remove <name>...",
+	Aliases: []string{"uninstall"},
+	Short:   "Remove installed formulas",
+	Long: `Remove formulas installed from a registry: their files and their
+formulas.lock entries. If a removed formula replaced a built-in one, the
+built-in version is used again (run gt formula update to restore its copy).
+
+Examples:
+  gt formula remove mol-polecat-code-review`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFormulaRemove,
+}
+
+func init() {
+	formulaInstallCmd.Flags().StringVar(&formulaInstallRegistry, "registry", "", "Install from this registry only")
+	formulaInstallCmd.Flags().BoolVar(&formulaInstallForce, "force", false, "Replace local formulas and local edits")
+	formulaUpgradeCmd.Flags().BoolVar(&formulaUpgradeForce, "force", false, "Discard local edits to installed formulas")
+	formulaOutdatedCmd.Flags().BoolVar(&formulaOutdatedJSON, "json", false, "Output as JSON")
+	formulaRemoveCmd.Flags().BoolVar(&formulaRemoveForce, "force", false, "Remove even if edited since install")
+
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpgradeCmd)
+	formulaCmd.AddCommand(formulaOutdatedCmd)
+	formulaCmd.AddCommand(formulaRemoveCmd)
+}
+
+// newFormulaInstaller returns an installer for the town's formulas
+// directory using the registries in town settings.
+func newFormulaInstaller(force bool) (*molmall.Installer, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, err
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return nil, fmt.Errorf("loading town settings: %w", err)
+	}
+	in := &molmall.Installer{
+		Dir:   filepath.Join(townRoot, ".beads", "formulas"),
+		Force: force,
+	}
+	for _, rc := range settings.FormulaRegistries {
+		reg, err := molmall.New(molmall.Config{Name: rc.Name, URL: rc.URL, TokenEnv: rc.TokenEnv})
+		if err != nil {
+			return nil, err
+		}
+		in.Registries = append(in.Registries, reg)
+	}
+	return in, nil
+}
+
+func runFormulaInstall(cmd *cobra.Command, args []string) error {
+	in, err := newFormulaInstaller(formulaInstallForce)
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		spec, err := molmall.ParseSpec(arg)
+		if err != nil {
+			return err
+		}
+		res, err := in.Install(context.Background(), spec, formulaInstallRegistry)
+		if err != nil {
+			return fmt.Errorf("installing %s: %w", spec, err)
+		}
+		printFormulaResult(res)
+	}
+	return nil
+}
+
+func runFormulaUpgrade(cmd *cobra.Command, args []string) error {
+	in, err := newFormulaInstaller(formulaUpgradeForce)
+	if err != nil {
+		return err
+	}
+	var specs []molmall.Spec
+	for _, arg := range args {
+		spec, err := molmall.ParseSpec(arg)
+		if err != nil {
+			return err
+		}
+		specs = append(specs, spec)
+	}
+	if len(specs) == 0 {
+		installed, err := in.Installed()
+		if err != nil {
+			return err
+		}
+		if len(installed) == 0 {
+			fmt.Println("No formulas installed from a registry.")
+			return nil
+		}
+		for _, name := range installed {
+			specs = append(specs, molmall.Spec{Name: name})
+		}
+	}
+
+	var failed []string
+	for _, spec := range specs {
+		res, err := in.Upgrade(context.Background(), spec)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", style.Error.Render("✗"), spec.Name, err)
+			failed = append(failed, spec.Name)
+			continue
+		}
+		printFormulaResult(res)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to upgrade: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func runFormulaOutdated(cmd *cobra.Command, args []string) error {
+	in, err := newFormulaInstaller(false)
+	if err != nil {
+		return err
+	}
+	outdated, err := in.Outdated(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if formulaOutdatedJSON {
+		type row struct {
+			Name     string `json:"name"`
+			Current  string `json:"current"`
+			Latest   string `json:"latest,omitempty"`
+			Pinned   bool   `json:"pinned"`
+			Registry string `json:"registry"`
+			Error    string `json:"error,omitempty"`
+		}
+		rows := []row{}
+		for _, st := range outdated {
+			r := row{Name: st.Name, Current: st.Current, Latest: st.Latest, Pinned: st.Pinned, Registry: st.Registry}
+			if st.Err != nil {
+				r.Error = st.Err.Error()
+			}
+			rows = append(rows, r)
+		}
+		return outputJSON(rows)
+	}
+
+	if len(outdated) == 0 {
+		fmt.Println("All installed formulas are up to date.")
+		return nil
+	}
+	for _, st := range outdated {
+		if st.Err != nil {
+			fmt.Printf("  %-32s %-10s %s\n", st.Name, st.Current, style.Warning.Render("check failed: "+st.Err.Error()))
+			continue
+		}
+		pinned := ""
+		if st.Pinned {
+			pinned = style.Dim.Render(" [pinned]")
+		}
+		fmt.Printf("  %-32s %-10s → %-10s %s%s\n", st.Name, st.Current, st.Latest, style.Dim.Render(st.Registry), pinned)
+	}
+	return nil
+}
+
+func runFormulaRemove(cmd *cobra.Command, args []string) error {
+	in, err := newFormulaInstaller(formulaRemoveForce)
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		res, err := in.Remove(name)
+		if err != nil {
+			return fmt.Errorf("removing %s: %w", name, err)
+		}
+		fmt.Printf("%s Removed %s@%s\n", style.Bold.Render("✓"), res.Name, res.Previous)
+	}
+	return nil
+}
+
+// printFormulaResult reports an install or upgrade.
+func printFormulaResult(res *molmall.Result) {
+	switch {
+	case res.Skipped != "":
+		fmt.Printf("%s %s@%s: %s\n", style.Dim.Render("○"), res.Name, res.Version, res.Skipped)
+		return
+	case res.Previous != "" && res.Previous != res.Version:
+		fmt.Printf("%s %s %s → %s (%s)\n", style.Bold.Render("✓"), res.Name, res.Previous, res.Version, res.Registry)
+	default:
+		fmt.Printf("%s Installed %s@%s (%s)\n", style.Bold.Render("✓"), res.Name, res.Version, res.Registry)
+	}
+	if res.Pinned {
+		fmt.Printf("  %s\n", style.Dim.Render("pinned; gt formula upgrade will skip it"))
+	}
+	if res.Changelog != "" {
+		for _, line := range strings.Split(strings.TrimRight(res.Changelog, "\n"), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
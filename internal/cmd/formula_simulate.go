@@ -0,0 +1,550 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Formula simulate command flags
+var (
+	formulaSimVars      []string
+	formulaSimDurations []string
+	formulaSimDefault   time.Duration
+	formulaSimOutputs   []string
+	formulaSimFailures  []string
+	formulaSimNoHistory bool
+	formulaSimJSON      bool
+	formulaSimMermaid   bool
+)
+
+var formulaSimulateCmd = &cobra.Command{
+	Use:   "simulate <name>",
+	Short: "Estimate a formula's schedule without running it",
+	Long: `Simulate a formula run in memory and report its schedule.
+
+No beads are written. Ready steps are dispatched in waves, as gt and bd
+dispatch them: parallel steps that are ready together start together, and
+each wave waits for its longest step. The report shows the waves, the peak
+parallelism (how many polecats the formula needs at once), the critical path
+and the estimated wall-clock time.
+
+Step durations come from, in order of precedence:
+  1. --duration step=30m flags (a foreach step's duration covers its items)
+  2. Averages over closed step beads of past molecules of this formula
+     (skip with --no-history)
+  3. --default-duration (10m unless set)
+
+For workflow formulas, when and foreach are evaluated here; gt sling and
+gt formula run refuse formulas that use them, since bd cannot pour them.
+Give step outputs with --output and failing steps with --fail to explore
+other branches.
+
+Examples:
+  gt formula simulate code-review
+  gt formula simulate mol-polecat-work --var issue=gt-123
+  gt formula simulate release --output detect="api web" --duration test=20m
+  gt formula simulate release --fail test.0 --json
+  gt formula simulate code-review --mermaid > schedule.mmd`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaSimulate,
+}
+
+func init() {
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimVars, "var", nil, "Formula var (key=value, repeatable)")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimDurations, "duration", nil, "Step duration (step=30m, repeatable)")
+	formulaSimulateCmd.Flags().DurationVar(&formulaSimDefault, "default-duration", formula.DefaultSimDuration, "Duration of steps with no estimate")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimOutputs, "output", nil, "Step output (step=value, repeatable)")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimFailures, "fail", nil, "Step that fails (repeatable)")
+	formulaSimulateCmd.Flags().BoolVar(&formulaSimNoHistory, "no-history", false, "Ignore durations of past molecules")
+	formulaSimulateCmd.Flags().BoolVar(&formulaSimJSON, "json", false, "Output as JSON")
+	formulaSimulateCmd.Flags().BoolVar(&formulaSimMermaid, "mermaid", false, "Output a Mermaid gantt chart")
+
+	formulaCmd.AddCommand(formulaSimulateCmd)
+}
+
+// Sources of simulated step durations.
+const (
+	simSourceFlag    = "flag"
+	simSourceHistory = "history"
+	simSourceDefault = "default"
+)
+
+func runFormulaSimulate(cmd *cobra.Command, args []string) error {
+	if formulaSimJSON && formulaSimMermaid {
+		return fmt.Errorf("--json and --mermaid are mutually exclusive")
+	}
+
+	name := args[0]
+	f, err := loadFormula(name)
+	if errors.Is(err, formula.ErrFormulaNotFound) && !strings.HasPrefix(name, "mol-") {
+		f, err = loadFormula("mol-" + name)
+	}
+	if err != nil {
+		return err
+	}
+	warnUnappliedCompose(f)
+
+	given, err := parseVarFlags(formulaSimVars)
+	if err != nil {
+		return err
+	}
+	placeholders := fillSimPlaceholders(f, given)
+	vars, err := f.ResolveParams(given, formula.ParamOptions{})
+	if err != nil {
+		return err
+	}
+	flagDurations, err := parseStepDurations(formulaSimDurations)
+	if err != nil {
+		return err
+	}
+	outputs := make(map[string]string)
+	for _, flag := range formulaSimOutputs {
+		id, value, ok := strings.Cut(flag, "=")
+		if !ok || id == "" {
+			return fmt.Errorf("invalid --output %q (want step=value)", flag)
+		}
+		outputs[id] = value
+	}
+	failures := make(map[string]bool)
+	for _, id := range formulaSimFailures {
+		failures[id] = true
+	}
+
+	durations := make(map[string]time.Duration)
+	sources := make(map[string]string)
+	var history *stepHistory
+	if !formulaSimNoHistory {
+		history = loadStepHistory(f)
+		for id, d := range history.averages() {
+			durations[id] = d
+			sources[id] = simSourceHistory
+		}
+	}
+	for id, d := range flagDurations {
+		durations[id] = d
+		sources[id] = simSourceFlag
+	}
+
+	sim, err := f.Simulate(formula.SimOptions{
+		Vars:            vars,
+		Durations:       durations,
+		DefaultDuration: formulaSimDefault,
+		Outputs:         outputs,
+		Failures:        failures,
+	})
+	if err != nil {
+		return err
+	}
+	source := func(id string) string {
+		if s, ok := sources[id]; ok {
+			return s
+		}
+		if origin, ok := formula.ForeachOrigin(id); ok {
+			if s, ok := sources[origin]; ok {
+				return s
+			}
+		}
+		return simSourceDefault
+	}
+
+	switch {
+	case formulaSimJSON:
+		return outputJSON(simulationJSON(sim, source, placeholders))
+	case formulaSimMermaid:
+		fmt.Print(simulationMermaid(sim))
+		return nil
+	}
+	printSimulation(sim, source, history, placeholders)
+	return nil
+}
+
+// fillSimPlaceholders sets placeholder values for required vars missing
+// from given, so a formula can be simulated without real inputs, and
+// returns their names. A var whose placeholder fails its type is left for
+// ResolveParams to report.
+func fillSimPlaceholders(f *formula.Formula, given map[string]string) []string {
+	var filled []string
+	tried := make(map[string]bool)
+	for {
+		var next *formula.Param
+		for _, p := range f.MissingParams(given, nil) {
+			if !tried[p.Name] {
+				next = &p
+				break
+			}
+		}
+		if next == nil {
+			return filled
+		}
+		tried[next.Name] = true
+
+		value := "<" + next.Name + ">"
+		switch next.Type {
+		case formula.ParamEnum:
+			if len(next.Values) > 0 {
+				value = next.Values[0]
+			}
+		case formula.ParamInt:
+			value = "0"
+		case formula.ParamBool:
+			value = "false"
+		case formula.ParamBeadID:
+			value = "gt-sim"
+		case formula.ParamRigName:
+			value = "rig"
+		case formula.ParamPath:
+			value = "."
+		}
+		if next.Check(value) == nil {
+			given[next.Name] = value
+			filled = append(filled, next.Name)
+		}
+	}
+}
+
+// parseStepDurations parses step=duration flags.
+func parseStepDurations(flags []string) (map[string]time.Duration, error) {
+	durations := make(map[string]time.Duration)
+	for _, flag := range flags {
+		id, value, ok := strings.Cut(flag, "=")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid --duration %q (want step=duration)", flag)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid --duration %q: want a duration like 30m or 1h30m", flag)
+		}
+		durations[id] = d
+	}
+	return durations, nil
+}
+
+// stepHistory collects how long each step of a formula took in past
+// molecules.
+type stepHistory struct {
+	molecules int
+	samples   map[string][]time.Duration
+}
+
+// averages returns the mean duration of each step with samples.
+func (h *stepHistory) averages() map[string]time.Duration {
+	avg := make(map[string]time.Duration)
+	for id, samples := range h.samples {
+		var total time.Duration
+		for _, d := range samples {
+			total += d
+		}
+		avg[id] = total / time.Duration(len(samples))
+	}
+	return avg
+}
+
+// loadStepHistory reads past molecules of f from the local beads database.
+// History is best-effort: with no beads database it is empty.
+func loadStepHistory(f *formula.Formula) *stepHistory {
+	workDir, err := findLocalBeadsDir()
+	if err != nil {
+		return &stepHistory{samples: map[string][]time.Duration{}}
+	}
+	issues, err := beads.New(workDir).List(beads.ListOptions{Status: "all", Priority: -1})
+	if err != nil {
+		return &stepHistory{samples: map[string][]time.Duration{}}
+	}
+	return collectStepHistory(f, issues)
+}
+
+// collectStepHistory measures the closed steps of every molecule poured
+// from f. A molecule root is titled with the formula name, and its step
+// beads with the step titles. A step is taken to start when it was created
+// or, if later, when the last of the steps it needs closed.
+func collectStepHistory(f *formula.Formula, issues []*beads.Issue) *stepHistory {
+	h := &stepHistory{samples: make(map[string][]time.Duration)}
+	matchers := simStepMatchers(f)
+
+	children := make(map[string][]*beads.Issue)
+	for _, issue := range issues {
+		if issue.Parent != "" {
+			children[issue.Parent] = append(children[issue.Parent], issue)
+		}
+	}
+
+	for _, root := range issues {
+		if root.Title != f.Name || len(children[root.ID]) == 0 {
+			continue
+		}
+		type closedStep struct {
+			id             string
+			created, ended time.Time
+		}
+		var steps []closedStep
+		closed := make(map[string]time.Time) // step ID -> last close
+		for _, child := range children[root.ID] {
+			if child.Status != "closed" {
+				continue
+			}
+			id := matchSimStep(matchers, child.Title)
+			created, err1 := time.Parse(time.RFC3339, child.CreatedAt)
+			ended, err2 := time.Parse(time.RFC3339, child.ClosedAt)
+			if id == "" || err1 != nil || err2 != nil {
+				continue
+			}
+			steps = append(steps, closedStep{id: id, created: created, ended: ended})
+			if ended.After(closed[id]) {
+				closed[id] = ended
+			}
+		}
+		if len(steps) == 0 {
+			continue
+		}
+		h.molecules++
+		for _, step := range steps {
+			start := step.created
+			for _, need := range simStepNeeds(f, step.id) {
+				if t := closed[need]; t.After(start) {
+					start = t
+				}
+			}
+			if d := step.ended.Sub(start); d > 0 {
+				h.samples[step.id] = append(h.samples[step.id], d)
+			}
+		}
+	}
+	return h
+}
+
+// simStepMatcher matches a step bead title to a formula step. Titles with
+// {{placeholders}} match on their literal prefix and suffix.
+type simStepMatcher struct {
+	id             string
+	prefix, suffix string
+	exact          bool
+}
+
+// simStepMatchers returns matchers for each step of f, exact titles first.
+func simStepMatchers(f *formula.Formula) []simStepMatcher {
+	var matchers []simStepMatcher
+	add := func(id, title string) {
+		if title == "" {
+			title = id
+		}
+		open := strings.Index(title, "{{")
+		if open < 0 {
+			matchers = append(matchers, simStepMatcher{id: id, prefix: title, exact: true})
+			return
+		}
+		suffix := title[strings.LastIndex(title, "}}")+2:]
+		matchers = append(matchers, simStepMatcher{id: id, prefix: title[:open], suffix: suffix})
+	}
+	for _, id := range f.GetAllIDs() {
+		add(id, simStepTitle(f, id))
+	}
+	if f.Type == formula.TypeConvoy && f.Synthesis != nil {
+		add("synthesis", f.Synthesis.Title)
+	}
+	sort.SliceStable(matchers, func(i, j int) bool {
+		return matchers[i].exact && !matchers[j].exact
+	})
+	return matchers
+}
+
+// matchSimStep returns the step a bead title belongs to, or "".
+func matchSimStep(matchers []simStepMatcher, title string) string {
+	for _, m := range matchers {
+		if m.exact && title == m.prefix {
+			return m.id
+		}
+		if !m.exact && m.prefix+m.suffix != "" && len(title) > len(m.prefix)+len(m.suffix) &&
+			strings.HasPrefix(title, m.prefix) && strings.HasSuffix(title, m.suffix) {
+			return m.id
+		}
+	}
+	return ""
+}
+
+// simStepTitle returns the title of a step, leg, template or aspect.
+func simStepTitle(f *formula.Formula, id string) string {
+	switch f.Type {
+	case formula.TypeWorkflow:
+		if step := f.GetStep(id); step != nil {
+			return step.Title
+		}
+	case formula.TypeConvoy:
+		if leg := f.GetLeg(id); leg != nil {
+			return leg.Title
+		}
+	case formula.TypeExpansion:
+		if tmpl := f.GetTemplate(id); tmpl != nil {
+			return tmpl.Title
+		}
+	case formula.TypeAspect:
+		if aspect := f.GetAspect(id); aspect != nil {
+			return aspect.Title
+		}
+	}
+	return ""
+}
+
+// simStepNeeds returns the steps id waits for.
+func simStepNeeds(f *formula.Formula, id string) []string {
+	if f.Type == formula.TypeConvoy && id == "synthesis" && f.Synthesis != nil {
+		if len(f.Synthesis.DependsOn) == 0 {
+			return f.GetAllIDs()
+		}
+		return f.Synthesis.DependsOn
+	}
+	return f.GetDependencies(id)
+}
+
+// simulationJSON is the --json form of a simulation, with times in seconds.
+func simulationJSON(sim *formula.Simulation, source func(string) string, placeholders []string) interface{} {
+	type step struct {
+		ID       string   `json:"id"`
+		Title    string   `json:"title,omitempty"`
+		Wave     int      `json:"wave,omitempty"`
+		Start    float64  `json:"start_seconds"`
+		Duration float64  `json:"duration_seconds"`
+		Source   string   `json:"duration_source,omitempty"`
+		Needs    []string `json:"needs,omitempty"`
+		Skipped  bool     `json:"skipped,omitempty"`
+		Critical bool     `json:"critical,omitempty"`
+	}
+	out := struct {
+		Formula          string     `json:"formula"`
+		Type             string     `json:"type"`
+		MaxParallel      int        `json:"max_parallel"`
+		WallClock        float64    `json:"wall_clock_seconds"`
+		CriticalPath     []string   `json:"critical_path"`
+		CriticalDuration float64    `json:"critical_path_seconds"`
+		Waves            [][]string `json:"waves"`
+		Steps            []step     `json:"steps"`
+		PlaceholderVars  []string   `json:"placeholder_vars,omitempty"`
+	}{
+		Formula:          sim.Formula,
+		Type:             string(sim.Type),
+		MaxParallel:      sim.MaxParallel,
+		WallClock:        sim.WallClock.Seconds(),
+		CriticalPath:     sim.CriticalPath,
+		CriticalDuration: sim.CriticalDuration.Seconds(),
+		Waves:            sim.Waves,
+		Steps:            []step{},
+		PlaceholderVars:  placeholders,
+	}
+	for _, s := range sim.Steps {
+		js := step{
+			ID:       s.ID,
+			Title:    s.Title,
+			Wave:     s.Wave,
+			Start:    s.Start.Seconds(),
+			Duration: s.Duration.Seconds(),
+			Needs:    s.Needs,
+			Skipped:  s.Skipped,
+			Critical: s.Critical,
+		}
+		if !s.Skipped {
+			js.Source = source(s.ID)
+		}
+		out.Steps = append(out.Steps, js)
+	}
+	return out
+}
+
+// simulationMermaid renders a simulation as a Mermaid gantt chart, one
+// section per wave, with the critical path marked crit.
+func simulationMermaid(sim *formula.Simulation) string {
+	var sb strings.Builder
+	sb.WriteString("gantt\n")
+	fmt.Fprintf(&sb, "    title %s (simulated)\n", mermaidText(sim.Formula))
+	sb.WriteString("    dateFormat X\n")
+	sb.WriteString("    axisFormat %H:%M\n")
+	for i, wave := range sim.Waves {
+		fmt.Fprintf(&sb, "    section Wave %d\n", i+1)
+		for _, id := range wave {
+			step := sim.Step(id)
+			tags := ""
+			if step.Critical {
+				tags = "crit, "
+			}
+			fmt.Fprintf(&sb, "    %s :%s%s, %d, %d\n", mermaidText(id), tags, mermaidID(id),
+				int64(step.Start.Seconds()), int64(step.End().Seconds()))
+		}
+	}
+	return sb.String()
+}
+
+// mermaidText strips characters that end a gantt task label.
+func mermaidText(s string) string {
+	return strings.NewReplacer(":", " ", "#", " ", ";", " ", "\n", " ").Replace(s)
+}
+
+// mermaidID makes a step ID safe as a gantt task ID.
+func mermaidID(id string) string {
+	var sb strings.Builder
+	sb.WriteString("s_")
+	for _, c := range id {
+		if c == '_' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			sb.WriteRune(c)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// printSimulation prints the text report.
+func printSimulation(sim *formula.Simulation, source func(string) string, history *stepHistory, placeholders []string) {
+	fmt.Printf("\n%s %s (%s)\n", style.Bold.Render("⏱ Simulation:"), sim.Formula, sim.Type)
+	if history != nil && history.molecules > 0 {
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("Durations averaged over %d past molecule(s)", history.molecules)))
+	}
+	if len(placeholders) > 0 {
+		fmt.Printf("%s\n", style.Dim.Render("Placeholder values for vars: "+strings.Join(placeholders, ", ")+" (set with --var)"))
+	}
+	fmt.Println()
+
+	peakWave := 0
+	for i, wave := range sim.Waves {
+		if len(wave) > len(sim.Waves[peakWave]) {
+			peakWave = i
+		}
+		step := sim.Step(wave[0])
+		fmt.Printf("%s %s\n", style.Bold.Render(fmt.Sprintf("Wave %d", i+1)), style.Dim.Render("at +"+formatDuration(step.Start)))
+		for _, id := range wave {
+			step := sim.Step(id)
+			marker := " "
+			if step.Critical {
+				marker = style.Warning.Render("◆")
+			}
+			fmt.Printf("  %s %-28s %-10s %s\n", marker, id, formatDuration(step.Duration), style.Dim.Render(source(id)))
+		}
+	}
+
+	var skipped []string
+	for _, step := range sim.Steps {
+		if step.Skipped {
+			skipped = append(skipped, step.ID)
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("%s %s\n", style.Dim.Render("Skipped:"), strings.Join(skipped, ", "))
+	}
+
+	fmt.Println()
+	if len(sim.Waves) == 0 {
+		fmt.Println("No steps to run.")
+		return
+	}
+	fmt.Printf("Max parallelism:      %d (wave %d)\n", sim.MaxParallel, peakWave+1)
+	fmt.Printf("Critical path:        %s (%s)\n", strings.Join(sim.CriticalPath, " → "), formatDuration(sim.CriticalDuration))
+	fmt.Printf("Estimated wall-clock: %s\n", formatDuration(sim.WallClock))
+	if sim.WallClock > sim.CriticalDuration {
+		fmt.Printf("%s\n", style.Dim.Render(fmt.Sprintf("  %s spent waiting for the slowest step of each wave", formatDuration(sim.WallClock-sim.CriticalDuration))))
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+const simulateTestFormula = `
+formula = "mol-ship"
+type = "workflow"
+
+[[steps]]
+id = "design"
+title = "Design the change"
+
+[[steps]]
+id = "test"
+title = "Test {{item}}"
+needs = ["design"]
+foreach = "steps.design.output"
+parallel = true
+
+[[steps]]
+id = "merge"
+needs = ["test"]
+`
+
+func TestCollectStepHistory(t *testing.T) {
+	f, err := formula.Parse([]byte(simulateTestFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	issue := func(id, parent, title, status, created, closed string) *beads.Issue {
+		return &beads.Issue{ID: id, Parent: parent, Title: title, Status: status, CreatedAt: created, ClosedAt: closed}
+	}
+	issues := []*beads.Issue{
+		issue("gt-1", "", "mol-ship", "closed", "2026-01-01T10:00:00Z", ""),
+		issue("gt-1.1", "gt-1", "Design the change", "closed", "2026-01-01T10:00:00Z", "2026-01-01T10:20:00Z"),
+		// Created with the molecule, but could only start once design closed.
+		issue("gt-1.2", "gt-1", "Test api", "closed", "2026-01-01T10:00:00Z", "2026-01-01T10:50:00Z"),
+		issue("gt-1.3", "gt-1", "Test web", "closed", "2026-01-01T10:00:00Z", "2026-01-01T10:30:00Z"),
+		issue("gt-1.4", "gt-1", "merge", "open", "2026-01-01T10:00:00Z", ""),
+		issue("gt-2", "", "mol-ship", "open", "2026-01-02T10:00:00Z", ""),
+		issue("gt-2.1", "gt-2", "Design the change", "closed", "2026-01-02T10:00:00Z", "2026-01-02T10:40:00Z"),
+		issue("gt-3", "", "mol-other", "closed", "2026-01-02T10:00:00Z", ""),
+		issue("gt-3.1", "gt-3", "Design the change", "closed", "2026-01-02T10:00:00Z", "2026-01-02T18:00:00Z"),
+	}
+
+	h := collectStepHistory(f, issues)
+	if h.molecules != 2 {
+		t.Errorf("molecules = %d, want 2", h.molecules)
+	}
+	avg := h.averages()
+	if avg["design"] != 30*time.Minute {
+		t.Errorf("design = %v, want 30m", avg["design"])
+	}
+	if avg["test"] != 20*time.Minute {
+		t.Errorf("test = %v, want 20m (items average, from design closing)", avg["test"])
+	}
+	if _, ok := avg["merge"]; ok {
+		t.Error("open step merge should have no history")
+	}
+}
+
+func TestSimulationMermaid(t *testing.T) {
+	f, err := formula.Parse([]byte(simulateTestFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	sim, err := f.Simulate(formula.SimOptions{
+		Durations: map[string]time.Duration{"design": time.Hour, "test.1": 2 * time.Hour},
+		Outputs:   map[string]string{"design": "api,web"},
+	})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+
+	got := simulationMermaid(sim)
+	for _, want := range []string{
+		"gantt\n",
+		"dateFormat X",
+		"section Wave 2",
+		"design :crit, s_design, 0, 3600",
+		"test.0 :s_test_0, 3600, 4200",
+		"test.1 :crit, s_test_1, 3600, 10800",
+		"merge :crit, s_merge, 10800, 11400",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("mermaid output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseStepDurations(t *testing.T) {
+	got, err := parseStepDurations([]string{"design=1h30m", "test=45s"})
+	if err != nil {
+		t.Fatalf("parseStepDurations failed: %v", err)
+	}
+	if got["design"] != 90*time.Minute || got["test"] != 45*time.Second {
+		t.Errorf("parseStepDurations = %v", got)
+	}
+	for _, bad := range []string{"design", "=1h", "design=soon", "design=-1m"} {
+		if _, err := parseStepDurations([]string{bad}); err == nil {
+			t.Errorf("parseStepDurations(%q) should fail", bad)
+		}
+	}
+}
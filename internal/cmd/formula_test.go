@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectFormulasDir(t *testing.T) {
+	town := t.TempDir()
+	rig := filepath.Join(town, "gastown")
+	deep := filepath.Join(rig, "crew", "max", "internal")
+	for _, dir := range []string{deep, filepath.Join(rig, ".beads", "formulas"), filepath.Join(town, ".beads", "formulas")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := projectFormulasDir(deep, town), filepath.Join(rig, ".beads", "formulas"); got != want {
+		t.Errorf("projectFormulasDir = %q, want the rig's %q", got, want)
+	}
+	// The town's own directory is the town tier, never the project tier.
+	other := filepath.Join(town, "other")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := projectFormulasDir(other, town), filepath.Join(other, ".beads", "formulas"); got != want {
+		t.Errorf("projectFormulasDir = %q, want cwd fallback %q", got, want)
+	}
+}
+
+func TestUnappliedCompose(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+
+	tests := map[string]string{
+		"shiny":            "",
+		"shiny-secure":     "compose aspects security-audit",
+		"shiny-enterprise": "compose expansion rule-of-five on implement",
+	}
+	for name, want := range tests {
+		f, err := loadFormula(name)
+		if err != nil {
+			t.Fatalf("loadFormula(%s): %v", name, err)
+		}
+		if got := unappliedCompose(f); got != want {
+			t.Errorf("unappliedCompose(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
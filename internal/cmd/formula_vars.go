@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// parseVarFlags parses --var key=value flags into a map.
+func parseVarFlags(vars []string) (map[string]string, error) {
+	out := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, val, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q (want key=value)", v)
+		}
+		out[key] = val
+	}
+	return out, nil
+}
+
+// validateFormulaVars checks --var flags against the typed vars and inputs
+// of the named formula, prompting on a terminal for required ones that are
+// missing. injected names vars the caller sets itself. It returns vars with
+// any prompted values appended.
+//
+// Formulas gt cannot find or read locally are left for bd to check. Formulas
+// whose when or foreach read other steps' results are rejected, since only a
+// run knows those; resolvedPourFormula expands the rest before bd pours.
+func validateFormulaVars(formulaName string, vars, injected []string) ([]string, error) {
+	given, err := parseVarFlags(vars)
+	if err != nil {
+		return nil, err
+	}
+	f, err := loadSlingFormula(formulaName)
+	if errors.Is(err, formula.ErrFormulaNotFound) {
+		return vars, nil
+	}
+	if err != nil {
+		if path, findErr := findFormulaFile(formulaName); findErr == nil && strings.HasSuffix(path, ".json") {
+			return vars, nil
+		}
+		return nil, err
+	}
+	if err := checkPourable(f); err != nil {
+		return nil, err
+	}
+
+	before := make(map[string]bool, len(given))
+	for name := range given {
+		before[name] = true
+	}
+	if _, err := resolveFormulaParams(f, given, injected); err != nil {
+		return nil, err
+	}
+	for name, val := range given {
+		if !before[name] {
+			vars = append(vars, name+"="+val)
+		}
+	}
+	return vars, nil
+}
+
+// loadSlingFormula loads the named formula as sling finds it, trying the
+// mol- prefixed name when the bare one is not found.
+func loadSlingFormula(name string) (*formula.Formula, error) {
+	f, err := loadFormula(name)
+	if errors.Is(err, formula.ErrFormulaNotFound) && !strings.HasPrefix(name, "mol-") {
+		f, err = loadFormula("mol-" + name)
+	}
+	return f, err
+}
+
+// checkPourable fails for a formula with when or foreach steps that read
+// other steps' results, which only a run (or gt formula simulate) evaluates.
+func checkPourable(f *formula.Formula) error {
+	ids := f.RunOnlySteps()
+	if len(ids) == 0 {
+		return nil
+	}
+	return fmt.Errorf("formula %s has steps whose when or foreach read other steps' results (steps: %s), which bd cannot pour; only gt formula simulate evaluates them",
+		f.Name, strings.Join(ids, ", "))
+}
+
+// resolvedPourFormula returns the name bd should cook and wisp for the named
+// formula. bd reads formula files as they are, without gt's include, when
+// and foreach, so for a formula using any of them gt writes a resolved copy
+// into the town formulas directory: includes merged, foreach steps expanded
+// and false when steps dropped for vars. remove deletes the copy once the
+// wisp exists. Formulas gt cannot read, or that need none of this, are
+// returned unchanged for bd to resolve.
+func resolvedPourFormula(formulaName string, vars []string, townRoot string) (name string, remove func(), err error) {
+	f, err := loadSlingFormula(formulaName)
+	if err != nil || !needsResolvedPour(f) {
+		return formulaName, func() {}, nil
+	}
+	given, err := parseVarFlags(vars)
+	if err != nil {
+		return "", nil, err
+	}
+	poured, err := f.Pour(given)
+	if err != nil {
+		return "", nil, err
+	}
+	poured.Extends, poured.Include = nil, nil
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(poured); err != nil {
+		return "", nil, fmt.Errorf("encoding resolved formula %s: %w", f.Name, err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	name = f.Name + "-pour-" + hex.EncodeToString(sum[:4])
+	poured.Name = name
+	buf.Reset()
+	if err := toml.NewEncoder(&buf).Encode(poured); err != nil {
+		return "", nil, fmt.Errorf("encoding resolved formula %s: %w", f.Name, err)
+	}
+
+	dir := filepath.Join(townRoot, ".beads", "formulas")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("creating formulas directory: %w", err)
+	}
+	path := filepath.Join(dir, name+".formula.toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "", nil, fmt.Errorf("writing resolved formula %s: %w", f.Name, err)
+	}
+	return name, func() { _ = os.Remove(path) }, nil
+}
+
+// needsResolvedPour reports whether f uses include, when or foreach, which
+// bd would otherwise pour missing, unconditionally or unexpanded.
+func needsResolvedPour(f *formula.Formula) bool {
+	if len(f.Include) > 0 {
+		return true
+	}
+	for _, step := range f.Steps {
+		if step.When != "" || step.Foreach != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFormulaParams prompts on a terminal for the formula's missing
+// required params, adding the answers to given, then checks given and
+// returns it merged over the param defaults.
+func resolveFormulaParams(f *formula.Formula, given map[string]string, injected []string) (map[string]string, error) {
+	if isStdinTerminal() {
+		if err := promptMissingParams(f, given, injected, bufio.NewReader(os.Stdin)); err != nil {
+			return nil, err
+		}
+	}
+	return f.ResolveParams(given, formula.ParamOptions{
+		Injected:   injected,
+		BeadExists: verifyBeadExists,
+		RigExists: func(name string) error {
+			if _, ok := IsRigName(name); !ok {
+				return fmt.Errorf("rig '%s' not found", name)
+			}
+			return nil
+		},
+	})
+}
+
+// promptMissingParams asks for each missing required param in turn, adding
+// the answers to given. An empty answer skips a param that another one
+// could satisfy (required_unless).
+func promptMissingParams(f *formula.Formula, given map[string]string, injected []string, in *bufio.Reader) error {
+	asked := make(map[string]bool)
+	for {
+		var p *formula.Param
+		for _, m := range f.MissingParams(given, injected) {
+			if !asked[m.Name] {
+				p = &m
+				break
+			}
+		}
+		if p == nil {
+			return nil
+		}
+		asked[p.Name] = true
+
+		fmt.Printf("%s %s needs %s\n", style.Bold.Render("?"), f.Name, describeParam(*p))
+		for {
+			fmt.Printf("  %s: ", p.Name)
+			answer, err := in.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				if err == io.EOF || !p.Required {
+					break
+				}
+				if err != nil {
+					return fmt.Errorf("reading %s: %w", p.Name, err)
+				}
+				continue
+			}
+			if checkErr := p.Check(answer); checkErr != nil {
+				fmt.Printf("  %s %v\n", style.Warning.Render("⚠"), checkErr)
+				if err != nil {
+					break
+				}
+				continue
+			}
+			given[p.Name] = answer
+			break
+		}
+	}
+}
+
+// describeParam returns a one-line description of a param for prompts.
+func describeParam(p formula.Param) string {
+	s := p.Name + " (" + p.Type
+	switch {
+	case p.Type == formula.ParamEnum:
+		s += ": " + strings.Join(p.Values, "|")
+	case p.Pattern != "":
+		s += " matching " + p.Pattern
+	}
+	s += ")"
+	if p.Description != "" {
+		s += " - " + p.Description
+	}
+	if len(p.RequiredUnless) > 0 && !p.Required {
+		s += " [or set " + strings.Join(p.RequiredUnless, ", ") + "]"
+	}
+	return s
+}
+
+// addVarsToContext adds formula vars to a prompt template context, without
+// overriding the values gt computes itself.
+func addVarsToContext(ctx map[string]interface{}, vars map[string]string) {
+	for name, val := range vars {
+		if _, ok := ctx[name]; !ok {
+			ctx[name] = val
+		}
+	}
+}
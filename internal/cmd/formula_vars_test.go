@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func TestParseVarFlags(t *testing.T) {
+	got, err := parseVarFlags([]string{"a=1", "b=x=y", "c="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "1" || got["b"] != "x=y" || got["c"] != "" {
+		t.Errorf("parseVarFlags = %v", got)
+	}
+	if _, err := parseVarFlags([]string{"novalue"}); err == nil {
+		t.Error("expected error for a var without =")
+	}
+}
+
+func TestValidateFormulaVars_RejectsRunOnlySteps(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", dir)
+	formulas := filepath.Join(dir, ".beads", "formulas")
+	if err := os.MkdirAll(formulas, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(formulas, name+".formula.toml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("fan-out", `
+formula = "fan-out"
+type = "workflow"
+[vars.targets]
+default = "linux,darwin"
+[[steps]]
+id = "build"
+title = "Build {{item}}"
+foreach = "targets"
+`)
+	write("per-file", `
+formula = "per-file"
+type = "workflow"
+[[steps]]
+id = "list"
+[[steps]]
+id = "fix"
+needs = ["list"]
+foreach = "steps.list.output"
+`)
+	write("plain", `
+formula = "plain"
+type = "workflow"
+[[steps]]
+id = "build"
+`)
+
+	if _, err := validateFormulaVars("fan-out", nil, nil); err != nil {
+		t.Errorf("validateFormulaVars(fan-out) = %v, want nil for a foreach over a var", err)
+	}
+	_, err := validateFormulaVars("per-file", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "fix") || !strings.Contains(err.Error(), "simulate") {
+		t.Errorf("validateFormulaVars(per-file) = %v, want a refusal naming step fix", err)
+	}
+	if _, err := validateFormulaVars("plain", nil, nil); err != nil {
+		t.Errorf("validateFormulaVars(plain) = %v, want nil", err)
+	}
+}
+
+func TestResolvedPourFormula(t *testing.T) {
+	town := t.TempDir()
+	t.Chdir(town)
+	t.Setenv("HOME", town)
+	formulas := filepath.Join(town, ".beads", "formulas")
+	for _, dir := range []string{formulas, filepath.Join(town, "mayor")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(formulas, name+".formula.toml"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("checks", `
+formula = "checks"
+type = "workflow"
+[[steps]]
+id = "lint"
+title = "Lint"
+`)
+	write("release", `
+formula = "release"
+type = "workflow"
+[vars]
+targets = "linux,darwin"
+notes = "false"
+[[include]]
+formula = "checks"
+before = "build"
+[[steps]]
+id = "build"
+title = "Build {{item}} for {{version}}"
+foreach = "targets"
+[[steps]]
+id = "notes"
+title = "Write notes"
+needs = ["build"]
+when = "notes"
+`)
+	write("plain", `
+formula = "plain"
+type = "workflow"
+[[steps]]
+id = "build"
+`)
+
+	name, remove, err := resolvedPourFormula("release", []string{"version=1.2.0"}, town)
+	if err != nil {
+		t.Fatalf("resolvedPourFormula(release) failed: %v", err)
+	}
+	if name == "release" || !strings.HasPrefix(name, "release-pour-") {
+		t.Fatalf("pour name = %q, want a resolved copy", name)
+	}
+	path := filepath.Join(formulas, name+".formula.toml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("resolved copy not written: %v", err)
+	}
+	poured, err := formula.Parse(data)
+	if err != nil {
+		t.Fatalf("resolved copy does not parse: %v", err)
+	}
+	if poured.Name != name || len(poured.Include) != 0 {
+		t.Errorf("resolved copy name %q, include %v", poured.Name, poured.Include)
+	}
+	var ids []string
+	for _, step := range poured.Steps {
+		ids = append(ids, step.ID)
+	}
+	if got, want := strings.Join(ids, ","), "lint,build.0,build.1"; got != want {
+		t.Errorf("resolved steps = %s, want %s", got, want)
+	}
+	if got := poured.GetStep("build.1").Title; got != "Build darwin for {{version}}" {
+		t.Errorf("build.1 title = %q", got)
+	}
+
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("remove left the resolved copy: %v", err)
+	}
+
+	name, remove, err = resolvedPourFormula("plain", nil, town)
+	if err != nil || name != "plain" {
+		t.Errorf("resolvedPourFormula(plain) = %q, %v; want the formula unchanged", name, err)
+	}
+	remove()
+	if name, _, err := resolvedPourFormula("missing", nil, town); err != nil || name != "missing" {
+		t.Errorf("resolvedPourFormula(missing) = %q, %v; want it left for bd", name, err)
+	}
+}
+
+func TestPromptMissingParams(t *testing.T) {
+	f, err := formula.Parse([]byte(`
+formula = "deploy"
+
+[vars.env]
+type = "enum"
+values = ["staging", "production"]
+required = true
+
+[vars.replicas]
+type = "int"
+required = true
+
+[vars.note]
+description = "Optional"
+
+[[steps]]
+id = "deploy"
+title = "Deploy"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// env: invalid then valid; replicas: empty (re-asked) then valid.
+	in := bufio.NewReader(strings.NewReader("prod\nproduction\n\n3\n"))
+	given := map[string]string{}
+	if err := promptMissingParams(f, given, nil, in); err != nil {
+		t.Fatalf("promptMissingParams: %v", err)
+	}
+	if given["env"] != "production" || given["replicas"] != "3" {
+		t.Errorf("given = %v", given)
+	}
+	if _, ok := given["note"]; ok {
+		t.Error("optional var should not be prompted for")
+	}
+
+	// Input ending early leaves the rest missing for ResolveParams to report.
+	given = map[string]string{}
+	if err := promptMissingParams(f, given, nil, bufio.NewReader(strings.NewReader("staging\n"))); err != nil {
+		t.Fatalf("promptMissingParams: %v", err)
+	}
+	if _, err := f.ResolveParams(given, formula.ParamOptions{}); err == nil || !strings.Contains(err.Error(), "missing required var replicas") {
+		t.Errorf("ResolveParams error = %v, want missing replicas", err)
+	}
+}
+
+func TestAddVarsToContext(t *testing.T) {
+	ctx := map[string]interface{}{"pr_number": 5}
+	addVarsToContext(ctx, map[string]string{"pr_number": "9", "problem": "slow builds"})
+	if ctx["pr_number"] != 5 || ctx["problem"] != "slow builds" {
+		t.Errorf("ctx = %v", ctx)
+	}
+}
@@ -103,11 +103,17 @@ Addresses:
   <rig>/<polecat>  - Send to a specific polecat
   <rig>/           - Broadcast to a rig
   list:<name>      - Send to a mailing list (fans out to all members)
+  <machine>:<addr> - Send to an agent in the town on another machine
 
 Mailing lists are defined in ~/gt/config/messaging.json and allow
 sending to multiple recipients at once. Each recipient gets their
 own copy of the message.
 
+Machine-qualified addresses are forwarded to the town at the machine's
+town_path in mayor/machines.json. The sender is qualified with this town's
+name so the recipient can reply; the other town must register this town
+under that name. Delivery acks are copied back by the daemon.
+
 Message types:
   task          - Required processing
   scavenge      - Optional first-come work
@@ -133,6 +139,7 @@ Examples:
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
   gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send platform:mayor/ -s "Outage" -m "Our CI is down"
 
   # Read body from stdin (avoids shell quoting issues):
   gt mail send mayor/ -s "Update" --stdin <<'BODY'
@@ -25,7 +25,8 @@ var (
 	mqSubmitNoCleanup bool
 
 	// Retry flags
-	mqRetryNow bool
+	mqRetryNow     bool
+	mqRetryNoCache bool
 
 	// Reject flags
 	mqRejectReason string
@@ -39,6 +40,7 @@ var (
 	mqListEpic    string
 	mqListJSON    bool
 	mqListVerify  bool
+	mqListPredict bool
 
 	// Status command flags
 	mqStatusJSON bool
@@ -118,9 +120,13 @@ var mqRetryCmd = &cobra.Command{
 Resets a failed MR so it can be processed again by the refinery.
 The MR must be in a failed state (open with an error).
 
+Gates that already passed on the same tree are normally skipped on retry
+(merge_queue.gate_cache_ttl). Use --no-cache to rerun every gate.
+
 Examples:
   gt mq retry greenplace gp-mr-abc123
-  gt mq retry greenplace gp-mr-abc123 --now`,
+  gt mq retry greenplace gp-mr-abc123 --now
+  gt mq retry greenplace gp-mr-abc123 --no-cache`,
 	Args: cobra.ExactArgs(2),
 	RunE: runMQRetry,
 }
@@ -139,11 +145,19 @@ Output format:
   gt-mr-003   blocked      P1        polecat/Capable/gt-def    Capable 8m
               (waiting on gt-mr-001)
 
+MRs are listed in processing order: by score, except that an MR predicted to
+conflict with the one before it is moved back behind a non-conflicting MR.
+The CONFLICTS column names the MRs each one is predicted to conflict with.
+Predictions come from the refinery's last test-merge of ready MRs whose
+changed files overlap; --predict recomputes them in the refinery worktree
+when more than one MR is ready.
+
 Examples:
   gt mq list greenplace
   gt mq list greenplace --ready
   gt mq list greenplace --status=open
-  gt mq list greenplace --worker=Nux`,
+  gt mq list greenplace --worker=Nux
+  gt mq list greenplace --predict`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMQList,
 }
@@ -169,7 +183,9 @@ var mqStatusCmd = &cobra.Command{
 	Long: `Display detailed information about a merge request.
 
 Shows all MR fields, current status with timestamps, dependencies,
-blockers, and processing history.
+blockers, and processing history. If the refinery has run quality gates
+for the MR, lists each gate's saved output and shows the last lines of
+the gates that failed.
 
 Example:
   gt mq status gp-mr-abc123`,
@@ -288,6 +304,7 @@ func init() {
 
 	// Retry flags
 	mqRetryCmd.Flags().BoolVar(&mqRetryNow, "now", false, "Immediately process instead of waiting for refinery loop")
+	mqRetryCmd.Flags().BoolVar(&mqRetryNoCache, "no-cache", false, "Rerun every quality gate instead of reusing cached passes")
 
 	// List flags
 	mqListCmd.Flags().BoolVar(&mqListReady, "ready", false, "Show only ready-to-merge (no blockers)")
@@ -296,6 +313,7 @@ func init() {
 	mqListCmd.Flags().StringVar(&mqListEpic, "epic", "", "Show MRs targeting integration/<epic>")
 	mqListCmd.Flags().BoolVar(&mqListJSON, "json", false, "Output as JSON")
 	mqListCmd.Flags().BoolVar(&mqListVerify, "verify", false, "Verify branches exist in git (shows MISSING for deleted branches)")
+	mqListCmd.Flags().BoolVar(&mqListPredict, "predict", false, "Recompute predicted conflicts by test-merging overlapping ready MRs")
 
 	// Reject flags
 	mqRejectCmd.Flags().StringVarP(&mqRejectReason, "reason", "r", "", "Reason for rejection (required unless --stdin)")
@@ -384,7 +402,7 @@ func runMQRetry(cmd *cobra.Command, args []string) error {
 	rigName := args[0]
 	mrID := args[1]
 
-	mgr, _, _, err := getRefineryManager(rigName)
+	mgr, r, _, err := getRefineryManager(rigName)
 	if err != nil {
 		return err
 	}
@@ -406,6 +424,13 @@ func runMQRetry(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Previous error: %s\n", style.Dim.Render(mr.Error))
 	}
 
+	if mqRetryNoCache {
+		if err := refinery.RequestGateCacheBypass(r.Path, mr.ID); err != nil {
+			return fmt.Errorf("bypassing gate cache: %w", err)
+		}
+		fmt.Printf("  %s\n", style.Dim.Render("Gate cache bypassed: every gate will rerun"))
+	}
+
 	// Perform the retry
 	if err := mgr.Retry(mrID, mqRetryNow); err != nil {
 		if err == refinery.ErrMRNotFailed {
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqApproveAs string
+
+var mqApproveCmd = &cobra.Command{
+	Use:   "approve <rig> <mr-id>",
+	Short: "Approve a merge request held for human sign-off",
+	Long: `Approve a merge request that touches paths requiring human approval.
+
+merge_queue.approvals maps path patterns to the people who may approve
+changes to them. The refinery holds an MR that changes a matching file in
+the awaiting-approval phase and mails the overseer. Each matched pattern
+needs an approval from one of its approvers. Approvals are recorded on the
+MR bead (approved_by). Once every pattern is approved the MR returns to the
+ready queue.
+
+The approver defaults to the town's overseer (username, else name).
+Approval is human sign-off: it is refused from agent sessions (GT_ROLE set),
+whatever --as says. Approvals apply to the branch head they were given for;
+new commits or a force-push on the MR branch clear them.
+
+Examples:
+  gt mq approve gastown gt-mr-abc
+  gt mq approve gastown gt-mr-abc --as alice`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQApprove,
+}
+
+func init() {
+	mqApproveCmd.Flags().StringVar(&mqApproveAs, "as", "", "Approver name (default: the overseer)")
+	mqCmd.AddCommand(mqApproveCmd)
+}
+
+func runMQApprove(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	// Agents run as the same OS user as the overseer, so their session
+	// environment is what tells them apart. --as can't override this.
+	if role := os.Getenv("GT_ROLE"); role != "" {
+		return fmt.Errorf("approvals need a human: refusing to approve from agent session %s (GT_ROLE)", role)
+	}
+
+	townRoot, r, err := getRig(rigName)
+	if err != nil {
+		return err
+	}
+
+	approver := mqApproveAs
+	if approver == "" {
+		overseer, err := config.LoadOrDetectOverseer(townRoot)
+		if err != nil {
+			return fmt.Errorf("determining approver (use --as): %w", err)
+		}
+		approver = overseer.Username
+		if approver == "" {
+			approver = overseer.Name
+		}
+	}
+
+	b := beads.New(r.BeadsPath())
+	issue, err := b.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("looking up %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if !beads.HasLabel(issue, "gt:merge-request") || fields == nil {
+		return fmt.Errorf("%s is not a merge request", mrID)
+	}
+	if issue.Status == "closed" {
+		return fmt.Errorf("%s is closed", mrID)
+	}
+
+	eng, err := newMQEngineer(r)
+	if err != nil {
+		return err
+	}
+	head, reset, err := eng.ApprovalHead(fields)
+	if err != nil {
+		return err
+	}
+	if reset {
+		fmt.Printf("%s %s changed since its earlier approvals; they no longer count\n", style.Warning.Render("⚠"), mrID)
+	}
+	reqs, err := eng.ApprovalStatus(fields)
+	if err != nil {
+		return err
+	}
+	if len(reqs) == 0 {
+		return fmt.Errorf("%s changes no paths that require approval", mrID)
+	}
+	if !canApprove(reqs, approver) {
+		return fmt.Errorf("%s is not an approver for %s (approvers: %s)", approver, mrID, approverList(reqs))
+	}
+
+	if !slices.Contains(fields.ApprovedBy, approver) {
+		fields.ApprovedBy = append(fields.ApprovedBy, approver)
+	}
+	fields.ApprovedHead = head
+	for i := range reqs {
+		if !reqs[i].Satisfied() && slices.Contains(reqs[i].Approvers, approver) {
+			reqs[i].ApprovedBy = approver
+		}
+	}
+	pending := refinery.PendingApprovals(reqs)
+	if len(pending) == 0 && fields.Phase == string(refinery.MRPhaseAwaitingApproval) {
+		fields.Phase = ""
+	}
+	desc := beads.SetMRFields(issue, fields)
+	if err := b.Update(mrID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return fmt.Errorf("recording approval: %w", err)
+	}
+
+	fmt.Printf("%s %s approved by %s\n", style.Bold.Render("✓"), mrID, approver)
+	if len(pending) == 0 {
+		fmt.Printf("  All required approvals given; %s is back in the ready queue\n", mrID)
+		return nil
+	}
+	fmt.Println("  Still awaiting:")
+	for _, p := range pending {
+		fmt.Printf("    %s %s\n", p.Pattern, style.Dim.Render("(any of: "+strings.Join(p.Approvers, ", ")+")"))
+	}
+	return nil
+}
+
+// canApprove reports whether approver may approve any pattern in reqs.
+func canApprove(reqs []refinery.ApprovalRequirement, approver string) bool {
+	for _, r := range reqs {
+		if slices.Contains(r.Approvers, approver) {
+			return true
+		}
+	}
+	return false
+}
+
+// approverList lists the distinct approvers of reqs.
+func approverList(reqs []refinery.ApprovalRequirement) string {
+	var names []string
+	for _, r := range reqs {
+		for _, a := range r.Approvers {
+			if !slices.Contains(names, a) {
+				names = append(names, a)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
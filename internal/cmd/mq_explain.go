@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqExplainJSON bool
+	mqExplainVs   string
+)
+
+var mqExplainCmd = &cobra.Command{
+	Use:   "explain <rig> <mr-id>",
+	Short: "Show how an MR's queue score is made up",
+	Long: `Show an MR's priority score factor by factor.
+
+The refinery processes ready MRs highest score first. The score starts at
+merge_queue.scoring.base_score and adds or subtracts a term per factor:
+priority, convoy age, MR age, retries, label boosts, author role, diff size
+and the number of open issues blocked on the MR's source issue. Factors that
+don't apply or aren't weighted in the rig's scoring policy are omitted.
+
+The MR's position among the ready MRs is shown along with its neighbours.
+Use --vs to compare against another MR factor by factor, e.g. to see why it
+jumped ahead.
+
+Examples:
+  gt mq explain gastown gt-mr-002
+  gt mq explain gastown gt-mr-002 --vs gt-mr-001
+  gt mq explain gastown gt-mr-002 --json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runMQExplain,
+}
+
+func init() {
+	mqExplainCmd.Flags().BoolVar(&mqExplainJSON, "json", false, "Output as JSON")
+	mqExplainCmd.Flags().StringVar(&mqExplainVs, "vs", "", "Compare the score against another MR")
+	mqCmd.AddCommand(mqExplainCmd)
+}
+
+// mqExplanation is an MR's score breakdown and its place in the ready queue.
+type mqExplanation struct {
+	ID        string                   `json:"id"`
+	Branch    string                   `json:"branch,omitempty"`
+	Score     refinery.ScoreBreakdown  `json:"score"`
+	Position  int                      `json:"position,omitempty"` // 1-based among ready MRs; 0 if not ready
+	QueueSize int                      `json:"queue_size"`
+	Ahead     *mqQueueNeighbour        `json:"ahead,omitempty"`
+	Behind    *mqQueueNeighbour        `json:"behind,omitempty"`
+	Vs        *refinery.ScoreBreakdown `json:"vs,omitempty"`
+	VsID      string                   `json:"vs_id,omitempty"`
+}
+
+type mqQueueNeighbour struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+func runMQExplain(cmd *cobra.Command, args []string) error {
+	rigName, mrID := args[0], args[1]
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	b := beads.New(r.BeadsPath())
+	eng, err := newMQEngineer(r)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	explainOne := func(id string) (*beads.Issue, refinery.ScoreBreakdown, error) {
+		issue, err := b.Show(id)
+		if err != nil {
+			return nil, refinery.ScoreBreakdown{}, fmt.Errorf("looking up %s: %w", id, err)
+		}
+		if !beads.HasLabel(issue, "gt:merge-request") {
+			return nil, refinery.ScoreBreakdown{}, fmt.Errorf("%s is not a merge request", id)
+		}
+		return issue, eng.ScoreIssue(issue, beads.ParseMRFields(issue), now), nil
+	}
+
+	issue, breakdown, err := explainOne(mrID)
+	if err != nil {
+		return err
+	}
+	ex := mqExplanation{ID: issue.ID, Score: breakdown}
+	if fields := beads.ParseMRFields(issue); fields != nil {
+		ex.Branch = fields.Branch
+	}
+	if mqExplainVs != "" {
+		_, vs, err := explainOne(mqExplainVs)
+		if err != nil {
+			return err
+		}
+		ex.Vs, ex.VsID = &vs, mqExplainVs
+	}
+
+	// Place the MR among the ready MRs, scored the same way.
+	open, err := b.List(beads.ListOptions{Label: "gt:merge-request", Status: "open", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("querying merge queue: %w", err)
+	}
+	var queue []mqQueueNeighbour
+	for _, other := range open {
+		if other.Status != "open" || len(other.BlockedBy) > 0 || other.BlockedByCount > 0 || mrAwaitingApproval(beads.ParseMRFields(other)) {
+			continue
+		}
+		score := breakdown.Total
+		if other.ID != issue.ID {
+			score = eng.ScoreIssue(other, beads.ParseMRFields(other), now).Total
+		}
+		queue = append(queue, mqQueueNeighbour{ID: other.ID, Score: score})
+	}
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].Score > queue[j].Score })
+	ex.QueueSize = len(queue)
+	for i, q := range queue {
+		if q.ID != issue.ID {
+			continue
+		}
+		ex.Position = i + 1
+		if i > 0 {
+			ex.Ahead = &queue[i-1]
+		}
+		if i+1 < len(queue) {
+			ex.Behind = &queue[i+1]
+		}
+	}
+
+	if mqExplainJSON {
+		return outputJSON(ex)
+	}
+	printMQExplanation(ex)
+	return nil
+}
+
+func printMQExplanation(ex mqExplanation) {
+	title := ex.ID
+	if ex.Branch != "" {
+		title += " (" + ex.Branch + ")"
+	}
+	fmt.Printf("%s Score for %s: %s\n\n", style.Bold.Render("📊"), title, style.Bold.Render(fmt.Sprintf("%.1f", ex.Score.Total)))
+
+	if ex.Vs == nil {
+		fmt.Printf("  %-12s %9s  %s\n", "FACTOR", "POINTS", "DETAIL")
+		for _, f := range ex.Score.Factors {
+			fmt.Printf("  %-12s %+9.1f  %s\n", f.Name, f.Points, style.Dim.Render(f.Detail))
+		}
+		fmt.Printf("  %-12s %9.1f\n", "total", ex.Score.Total)
+	} else {
+		fmt.Printf("  %-18s %10s %10s %9s\n", "FACTOR", truncate(ex.ID, 10), truncate(ex.VsID, 10), "DIFF")
+		for _, row := range compareScoreFactors(ex.Score, *ex.Vs) {
+			fmt.Printf("  %-18s %+10.1f %+10.1f %+9.1f\n", row.key, row.a, row.b, row.a-row.b)
+		}
+		fmt.Printf("  %-18s %10.1f %10.1f %+9.1f\n", "total", ex.Score.Total, ex.Vs.Total, ex.Score.Total-ex.Vs.Total)
+	}
+	fmt.Println()
+
+	if ex.Position == 0 {
+		fmt.Printf("  %s\n", style.Dim.Render("Not in the ready queue (blocked, awaiting approval, claimed or closed)"))
+		return
+	}
+	fmt.Printf("  Queue position %d of %d ready MR(s)\n", ex.Position, ex.QueueSize)
+	if ex.Ahead != nil {
+		fmt.Printf("  Ahead:  %s (%.1f, %+.1f)\n", ex.Ahead.ID, ex.Ahead.Score, ex.Ahead.Score-ex.Score.Total)
+	}
+	if ex.Behind != nil {
+		fmt.Printf("  Behind: %s (%.1f, %+.1f)\n", ex.Behind.ID, ex.Behind.Score, ex.Behind.Score-ex.Score.Total)
+	}
+}
+
+// scoreFactorRow pairs the points two breakdowns give one factor.
+type scoreFactorRow struct {
+	key  string
+	a, b float64
+}
+
+// compareScoreFactors lines up the factors of a and b, in a's order followed
+// by factors only b has. Labels and roles are keyed by value so that
+// different labels show as separate rows.
+func compareScoreFactors(a, b refinery.ScoreBreakdown) []scoreFactorRow {
+	key := func(f refinery.ScoreFactor) string {
+		switch f.Name {
+		case "label", "author role":
+			return f.Name + " " + f.Detail
+		}
+		return f.Name
+	}
+	var rows []scoreFactorRow
+	index := make(map[string]int)
+	for _, f := range a.Factors {
+		index[key(f)] = len(rows)
+		rows = append(rows, scoreFactorRow{key: key(f), a: f.Points})
+	}
+	for _, f := range b.Factors {
+		if i, ok := index[key(f)]; ok {
+			rows[i].b = f.Points
+			continue
+		}
+		index[key(f)] = len(rows)
+		rows = append(rows, scoreFactorRow{key: key(f), b: f.Points})
+	}
+	return rows
+}
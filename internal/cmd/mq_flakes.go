@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqFlakesJSON  bool
+	mqFlakesReset string
+)
+
+var mqFlakesCmd = &cobra.Command{
+	Use:   "flakes [rig]",
+	Short: "Show flaky tests recorded by the refinery",
+	Long: `Show the rig's flaky test history.
+
+For quality gates that declare a test report format, the refinery retries a
+failing gate under merge_queue.retry_flaky_tests. A test that fails and then
+passes on retry counts as a flake. Once a test reaches
+merge_queue.flake_quarantine_threshold flakes it is quarantined: its failures
+alone no longer block merges, and a bead is filed for the witness.
+
+Use --reset to clear a test's history and lift its quarantine once fixed.
+
+Examples:
+  gt mq flakes gastown
+  gt mq flakes gastown --json
+  gt mq flakes gastown --reset "test/pkg.TestFlaky"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMqFlakes,
+}
+
+func init() {
+	mqFlakesCmd.Flags().BoolVar(&mqFlakesJSON, "json", false, "Output as JSON")
+	mqFlakesCmd.Flags().StringVar(&mqFlakesReset, "reset", "", "Clear the history of a test (<gate>/<test>), lifting its quarantine")
+	mqCmd.AddCommand(mqFlakesCmd)
+}
+
+func runMqFlakes(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	history, err := refinery.LoadFlakeHistory(r.Path)
+	if err != nil {
+		return fmt.Errorf("loading flake history: %w", err)
+	}
+
+	if mqFlakesReset != "" {
+		if !history.Reset(mqFlakesReset) {
+			return fmt.Errorf("no flake history for %q", mqFlakesReset)
+		}
+		if err := history.Save(r.Path); err != nil {
+			return fmt.Errorf("saving flake history: %w", err)
+		}
+		fmt.Printf("%s Cleared flake history for %s\n", style.Bold.Render("✓"), mqFlakesReset)
+		return nil
+	}
+
+	records := history.Sorted()
+	if mqFlakesJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("%s No flaky tests recorded for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+	fmt.Printf("%s Flaky tests in '%s':\n\n", style.Bold.Render("🎲"), rigName)
+	for _, rec := range records {
+		status := ""
+		if rec.Quarantined {
+			status = style.Warning.Render(" [quarantined]")
+			if rec.Bead != "" {
+				status += style.Dim.Render(" " + rec.Bead)
+			}
+		}
+		fmt.Printf("  %3d  %s/%s%s %s\n", rec.Flakes, rec.Gate, rec.Test, status,
+			style.Dim.Render("last "+rec.LastFlake.Format("2006-01-02 15:04")))
+	}
+	return nil
+}
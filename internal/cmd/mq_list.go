@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -27,6 +29,26 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	// Create beads wrapper for the rig - use BeadsPath() to get the git-synced location
 	b := beads.New(r.BeadsPath())
 
+	// Predicted conflicts: recompute in the refinery worktree with --predict,
+	// otherwise use the refinery's last prediction. Recomputing test-merges
+	// MR pairs, so it is skipped unless more than one MR is ready.
+	eng, err := newMQEngineer(r)
+	if err != nil {
+		return err
+	}
+	var prediction *refinery.ConflictPrediction
+	var ready []*refinery.MRInfo
+	if mqListPredict {
+		if ready, err = eng.ListReadyMRs(); err != nil {
+			return fmt.Errorf("listing ready MRs: %w", err)
+		}
+	}
+	if len(ready) > 1 {
+		prediction = eng.PredictConflicts(ready)
+	} else if prediction, err = refinery.LoadConflictPrediction(r.Path); err != nil {
+		style.PrintWarning("could not load conflict prediction: %v", err)
+	}
+
 	// Create git client for branch verification when --verify is set
 	var gitClient *git.Git
 	if mqListVerify {
@@ -104,6 +126,9 @@ func runMQList(cmd *cobra.Command, args []string) error {
 
 		// Parse MR fields
 		fields := beads.ParseMRFields(issue)
+		if mqListReady && mrAwaitingApproval(fields) {
+			continue
+		}
 
 		// Filter by worker
 		if mqListWorker != "" {
@@ -131,15 +156,25 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		// Check branch existence if --verify is set (local + remote-tracking refs)
 		branchMissing, branchVerifyErr := verifyBranch(mqListVerify, gitClient, fields)
 
-		// Calculate priority score
-		score := calculateMRScore(issue, fields, now)
+		// Calculate priority score under the rig's scoring policy
+		score := eng.ScoreIssue(issue, fields, now).Total
 		scored = append(scored, scoredIssue{issue: issue, fields: fields, score: score, branchMissing: branchMissing, branchVerifyErr: branchVerifyErr})
 	}
 
-	// Sort by score descending (highest priority first)
+	// Sort by score descending (highest priority first), then space out
+	// MRs predicted to conflict with each other.
 	sort.Slice(scored, func(i, j int) bool {
 		return scored[i].score > scored[j].score
 	})
+	ids := make([]string, len(scored))
+	for i, s := range scored {
+		ids[i] = s.issue.ID
+	}
+	spaced := make([]scoredIssue, 0, len(scored))
+	for _, idx := range prediction.SpaceConflicts(ids) {
+		spaced = append(spaced, scored[idx])
+	}
+	scored = spaced
 
 	// Extract filtered issues for JSON output compatibility
 	var filtered []*beads.Issue
@@ -190,6 +225,7 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		{Name: "CONVOY", Width: 12},
 		{Name: "BRANCH", Width: 24},
 		{Name: "STATUS", Width: 10},
+		{Name: "CONFLICTS", Width: 14},
 	}
 	if mqListVerify {
 		columns = append(columns, style.Column{Name: "GIT", Width: 8})
@@ -208,6 +244,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		if issue.Status == "open" {
 			if len(issue.BlockedBy) > 0 || issue.BlockedByCount > 0 {
 				displayStatus = "blocked"
+			} else if mrAwaitingApproval(fields) {
+				displayStatus = "approval"
 			} else {
 				displayStatus = "ready"
 			}
@@ -222,6 +260,8 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			styledStatus = style.Warning.Render("active")
 		case "blocked":
 			styledStatus = style.Dim.Render("blocked")
+		case "approval":
+			styledStatus = style.Warning.Render("approval")
 		case "closed":
 			styledStatus = style.Dim.Render("closed")
 		}
@@ -267,6 +307,14 @@ func runMQList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		// Format predicted conflicts
+		conflictsDisplay := style.Dim.Render("-")
+		if prediction != nil {
+			if others := prediction.Conflicts[issue.ID]; len(others) > 0 {
+				conflictsDisplay = style.Warning.Render(formatConflictIDs(others))
+			}
+		}
+
 		// Calculate age
 		age := formatMRAge(issue.CreatedAt)
 
@@ -278,9 +326,9 @@ func runMQList(cmd *cobra.Command, args []string) error {
 
 		// Build row with conditional GIT column
 		if mqListVerify {
-			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, gitStatus, style.Dim.Render(age))
+			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, conflictsDisplay, gitStatus, style.Dim.Render(age))
 		} else {
-			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, style.Dim.Render(age))
+			table.AddRow(displayID, scoreStr, priority, convoyDisplay, branch, styledStatus, conflictsDisplay, style.Dim.Render(age))
 		}
 	}
 
@@ -321,6 +369,19 @@ func runMQList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatConflictIDs formats predicted-conflict MR IDs for the CONFLICTS
+// column: the first ID, plus a count of the rest.
+func formatConflictIDs(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	first := ids[0]
+	if len(ids) == 1 {
+		return first
+	}
+	return fmt.Sprintf("%s +%d", first, len(ids)-1)
+}
+
 // formatMRAge formats the age of an MR from its created_at timestamp.
 func formatMRAge(createdAt string) string {
 	t, err := time.Parse(time.RFC3339, createdAt)
@@ -353,38 +414,20 @@ func outputJSON(data interface{}) error {
 	return enc.Encode(data)
 }
 
-// calculateMRScore computes the priority score for an MR using the refinery scoring function.
-// Higher scores mean higher priority (process first).
-func calculateMRScore(issue *beads.Issue, fields *beads.MRFields, now time.Time) float64 {
-	// Parse MR creation time
-	mrCreatedAt, err := time.Parse(time.RFC3339, issue.CreatedAt)
-	if err != nil {
-		mrCreatedAt, err = time.Parse("2006-01-02T15:04:05Z", issue.CreatedAt)
-		if err != nil {
-			mrCreatedAt = now // Fallback to now if parsing fails
-		}
-	}
-
-	// Build score input
-	input := refinery.ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
-	}
-
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
-
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime, err := time.Parse(time.RFC3339, fields.ConvoyCreatedAt); err == nil {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
+// newMQEngineer returns a quiet refinery Engineer for r with the rig's merge
+// queue config loaded, for scoring and conflict prediction from the CLI.
+func newMQEngineer(r *rig.Rig) (*refinery.Engineer, error) {
+	eng := refinery.NewEngineer(r)
+	eng.SetOutput(io.Discard)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
 	}
+	return eng, nil
+}
 
-	return refinery.ScoreMRWithDefaults(input)
+// mrAwaitingApproval reports whether an MR is held for human approval.
+func mrAwaitingApproval(fields *beads.MRFields) bool {
+	return fields != nil && fields.Phase == string(refinery.MRPhaseAwaitingApproval)
 }
 
 // branchVerifier abstracts git branch existence checks for testability.
@@ -70,14 +70,14 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("querying merge queue: %w", err)
 	}
 
-	// Filter to only ready MRs (no blockers)
+	// Filter to only ready MRs (no blockers, not awaiting approval)
 	var ready []*beads.Issue
 	for _, issue := range issues {
 		// Skip closed MRs (workaround for bd list not respecting --status filter)
 		if issue.Status != "open" {
 			continue
 		}
-		if len(issue.BlockedBy) == 0 && issue.BlockedByCount == 0 {
+		if len(issue.BlockedBy) == 0 && issue.BlockedByCount == 0 && !mrAwaitingApproval(beads.ParseMRFields(issue)) {
 			ready = append(ready, issue)
 		}
 	}
@@ -90,6 +90,10 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	eng, err := newMQEngineer(r)
+	if err != nil {
+		return err
+	}
 	now := time.Now()
 
 	// Sort based on strategy
@@ -109,7 +113,7 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 		scored := make([]scoredIssue, len(ready))
 		for i, issue := range ready {
 			fields := beads.ParseMRFields(issue)
-			score := calculateMRScore(issue, fields, now)
+			score := eng.ScoreIssue(issue, fields, now).Total
 			scored[i] = scoredIssue{issue: issue, score: score}
 		}
 
@@ -140,7 +144,7 @@ func runMQNext(cmd *cobra.Command, args []string) error {
 	// Human-readable output
 	fmt.Printf("%s Next MR to process:\n\n", style.Bold.Render("🎯"))
 
-	score := calculateMRScore(next, fields, now)
+	score := eng.ScoreIssue(next, fields, now).Total
 
 	fmt.Printf("  ID:       %s\n", next.ID)
 	fmt.Printf("  Score:    %.1f\n", score)
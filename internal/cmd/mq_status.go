@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // MRStatusOutput is the JSON output structure for gt mq status.
@@ -37,6 +40,9 @@ type MRStatusOutput struct {
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
 	Blocks    []DependencyInfo `json:"blocks,omitempty"`
+
+	// Quality gate output from the MR's last refinery run
+	GateOutput []refinery.GateArtifact `json:"gate_output,omitempty"`
 }
 
 // DependencyInfo represents a dependency or blocker.
@@ -96,6 +102,7 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
 	}
+	output.GateOutput = mqGateArtifacts(issue.ID, mrFields)
 
 	// Add dependency info from the issue's Dependencies field
 	for _, dep := range issue.Dependencies {
@@ -127,7 +134,61 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Human-readable output
-	return printMqStatus(issue, mrFields)
+	if err := printMqStatus(issue, mrFields); err != nil {
+		return err
+	}
+	printMqGateOutput(output.GateOutput)
+	return nil
+}
+
+// mqGateArtifacts returns the stored gate output for an MR, found through
+// the rig recorded in its fields. Missing output is not an error.
+func mqGateArtifacts(mrID string, mrFields *beads.MRFields) []refinery.GateArtifact {
+	if mrFields == nil || mrFields.Rig == "" {
+		return nil
+	}
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil
+	}
+	artifacts, err := refinery.ListGateArtifacts(filepath.Join(townRoot, mrFields.Rig), mrID)
+	if err != nil {
+		style.PrintWarning("could not read gate output: %v", err)
+	}
+	return artifacts
+}
+
+// printMqGateOutput lists an MR's gate artifacts and shows the output tail
+// of the gates that failed.
+func printMqGateOutput(artifacts []refinery.GateArtifact) {
+	if len(artifacts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("Gate Output"))
+	for _, a := range artifacts {
+		icon := style.Success.Render("✓")
+		if a.Failed {
+			icon = style.Error.Render("✗")
+		}
+		fmt.Printf("   %s %s %s\n", icon, a.Gate, style.Dim.Render(fmt.Sprintf("(%v) %s", a.Elapsed.Truncate(time.Millisecond), a.Path)))
+	}
+	for _, a := range artifacts {
+		if !a.Failed {
+			continue
+		}
+		out, err := refinery.ReadGateOutput(a)
+		if err != nil {
+			continue
+		}
+		tail := refinery.OutputTail(out, refinery.GateOutputTailLines)
+		if tail == "" {
+			continue
+		}
+		fmt.Printf("\n%s\n", style.Bold.Render(fmt.Sprintf("Gate %q (last %d lines)", a.Gate, refinery.GateOutputTailLines)))
+		for _, line := range strings.Split(tail, "\n") {
+			fmt.Printf("   %s\n", line)
+		}
+	}
 }
 
 // printMqStatus prints detailed MR status in human-readable format.
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 )
 
 func TestParseBranchName(t *testing.T) {
@@ -110,6 +112,63 @@ func TestFormatMRAge(t *testing.T) {
 	}
 }
 
+func TestFormatConflictIDs(t *testing.T) {
+	tests := []struct {
+		ids  []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"gt-mr-1"}, "gt-mr-1"},
+		{[]string{"gt-mr-1", "gt-mr-2", "gt-mr-3"}, "gt-mr-1 +2"},
+		{[]string{"gastown-mr-abcdef", "gastown-mr-123456"}, "gastown-mr-abcdef +1"},
+	}
+	for _, tt := range tests {
+		if got := formatConflictIDs(tt.ids); got != tt.want {
+			t.Errorf("formatConflictIDs(%v) = %q, want %q", tt.ids, got, tt.want)
+		}
+	}
+}
+
+func TestMQApproveRefusesAgents(t *testing.T) {
+	origAs := mqApproveAs
+	defer func() { mqApproveAs = origAs }()
+
+	t.Setenv("GT_ROLE", "gastown/polecats/Toast")
+	mqApproveAs = "alice"
+	err := runMQApprove(mqApproveCmd, []string{"gastown", "gt-mr-abc"})
+	if err == nil || !strings.Contains(err.Error(), "GT_ROLE") {
+		t.Errorf("runMQApprove from an agent session = %v, want a GT_ROLE refusal", err)
+	}
+}
+
+func TestCompareScoreFactors(t *testing.T) {
+	a := refinery.ScoreBreakdown{Factors: []refinery.ScoreFactor{
+		{Name: "base", Points: 1000},
+		{Name: "priority", Points: 300},
+		{Name: "label", Detail: "hotfix", Points: 500},
+	}}
+	b := refinery.ScoreBreakdown{Factors: []refinery.ScoreFactor{
+		{Name: "base", Points: 1000},
+		{Name: "priority", Points: 400},
+		{Name: "retries", Points: -50},
+	}}
+	rows := compareScoreFactors(a, b)
+	want := []scoreFactorRow{
+		{key: "base", a: 1000, b: 1000},
+		{key: "priority", a: 300, b: 400},
+		{key: "label hotfix", a: 500},
+		{key: "retries", b: -50},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("compareScoreFactors = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
+
 func TestGetDescriptionWithoutMRFields(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/nudge"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
@@ -26,6 +27,7 @@ var (
 	nudgeIfFreshFlag  bool
 	nudgeModeFlag     string
 	nudgePriorityFlag string
+	nudgeFromFlag     string
 )
 
 // Nudge delivery modes.
@@ -49,6 +51,8 @@ func init() {
 	nudgeCmd.Flags().BoolVar(&nudgeIfFreshFlag, "if-fresh", false, "Only send if caller's tmux session is <60s old (suppresses compaction nudges)")
 	nudgeCmd.Flags().StringVar(&nudgeModeFlag, "mode", NudgeModeImmediate, "Delivery mode: immediate (default), queue, or wait-idle")
 	nudgeCmd.Flags().StringVar(&nudgePriorityFlag, "priority", nudge.PriorityNormal, "Queue priority: normal (default) or urgent")
+	nudgeCmd.Flags().StringVar(&nudgeFromFlag, "from", "", "Override sender attribution (used when forwarding from another town)")
+	_ = nudgeCmd.Flags().MarkHidden("from")
 }
 
 var nudgeCmd = &cobra.Command{
@@ -84,6 +88,12 @@ Role shortcuts (expand to session names):
   witness   Maps to gt-<rig>-witness (uses current rig)
   refinery  Maps to gt-<rig>-refinery (uses current rig)
 
+Cross-town targets:
+  <machine>:<target>  Forwards the nudge to the town on a machine from
+                      mayor/machines.json (e.g. platform:mayor,
+                      platform:gastown/alpha). That town's gt nudge handles
+                      session lookup, DND and delivery mode.
+
 Channel syntax:
   channel:<name>  Nudges all members of a named channel defined in
                   ~/gt/config/messaging.json under "nudge_channels".
@@ -100,6 +110,7 @@ Examples:
   gt nudge witness "Check polecat health"
   gt nudge deacon session-started
   gt nudge channel:workers "New priority work available"
+  gt nudge platform:mayor "Deploy window opens in 10 minutes"
 
   # Use --stdin for messages with special characters or formatting:
   gt nudge gastown/alpha --stdin <<'EOF'
@@ -119,11 +130,18 @@ const ifFreshMaxAge = 60 * time.Second
 // This is a var (not const) so tests can override it to avoid 15s waits.
 var waitIdleTimeout = 15 * time.Second
 
+// idleWaiter is implemented by session backends that can tell when an
+// agent is sitting at its prompt. Backends without it (headless PTY
+// sessions) treat wait-idle as busy and queue the nudge.
+type idleWaiter interface {
+	WaitForIdle(session string, timeout time.Duration) error
+}
+
 // deliverNudge routes a nudge based on the --mode flag.
-// For "immediate" mode: sends directly via tmux (current behavior).
+// For "immediate" mode: sends directly through the session backend.
 // For "queue" mode: writes to the nudge queue for cooperative delivery.
 // For "wait-idle" mode: waits for idle, then delivers or falls back to queue.
-func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
+func deliverNudge(t session.SessionBackend, sessionName, message, sender string) error {
 	townRoot, _ := workspace.FindFromCwd()
 
 	// For direct tmux delivery, prefix with sender attribution.
@@ -149,7 +167,10 @@ func deliverNudge(t *tmux.Tmux, sessionName, message, sender string) error {
 			return fmt.Errorf("--mode=wait-idle requires a Gas Town workspace")
 		}
 		// Try to wait for idle
-		err := t.WaitForIdle(sessionName, waitIdleTimeout)
+		err := fmt.Errorf("%s: idle detection not supported by session backend", sessionName)
+		if w, ok := t.(idleWaiter); ok {
+			err = w.WaitForIdle(sessionName, waitIdleTimeout)
+		}
 		if err == nil {
 			// Agent is idle — safe to deliver directly
 			return t.NudgeSession(sessionName, prefixedMessage)
@@ -261,12 +282,24 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if nudgeFromFlag != "" {
+		sender = nudgeFromFlag
+	}
+
 	// Handle channel syntax: channel:<name>
 	if strings.HasPrefix(target, "channel:") {
 		channelName := strings.TrimPrefix(target, "channel:")
 		return runNudgeChannel(channelName, message, sender)
 	}
 
+	// Handle machine-qualified targets: forward to the town on that machine
+	if machine, remoteTarget, ok := mail.ParseRemoteAddress(target); ok {
+		if machine != "local" {
+			return runNudgeRemote(machine, remoteTarget, message, sender)
+		}
+		target = remoteTarget
+	}
+
 	// Check DND status for target (unless force flag or channel target)
 	townRoot, _ := workspace.FindFromCwd()
 	if townRoot != "" && !nudgeForceFlag {
@@ -278,7 +311,7 @@ func runNudge(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 
 	// Expand role shortcuts to session names
 	// These shortcuts let users type "mayor" instead of "gt-mayor"
@@ -412,6 +445,45 @@ func runNudge(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runNudgeRemote forwards a nudge to the town on another machine. The remote
+// gt nudge does session lookup, DND and delivery there; the sender is
+// qualified with this town's name so the recipient knows where it came from.
+func runNudgeRemote(machine, target, message, sender string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	rt, err := mail.OpenRemoteTown(townRoot, machine)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"nudge", target,
+		"-m", message,
+		"--from", mail.QualifyAddress(townRoot, sender),
+		"--mode", nudgeModeFlag,
+		"--priority", nudgePriorityFlag,
+	}
+	if nudgeForceFlag {
+		args = append(args, "--force")
+	}
+	out, err := rt.Gt(args...)
+	if err != nil {
+		return fmt.Errorf("nudging %s:%s: %w", machine, target, err)
+	}
+	// The remote gt reports skips (DND, deacon not running) on stdout.
+	if text := strings.TrimSpace(string(out)); text != "" && !strings.HasPrefix(text, "✓") {
+		fmt.Println(text)
+		return nil
+	}
+
+	remoteAddr := machine + ":" + target
+	fmt.Printf("%s Nudged %s (%s)\n", style.Bold.Render("✓"), remoteAddr, nudgeModeFlag)
+	_ = LogNudge(townRoot, remoteAddr, message)
+	_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload("", remoteAddr, message))
+	return nil
+}
+
 // runNudgeChannel nudges all members of a named channel.
 // Routes each target through deliverNudge so --mode is respected.
 func runNudgeChannel(channelName, message, sender string) error {
@@ -464,7 +536,7 @@ func runNudgeChannel(channelName, message, sender string) error {
 	}
 
 	// Send nudges via deliverNudge (respects --mode flag)
-	t := tmux.NewTmux()
+	t := session.NewBackend(townRoot)
 	var succeeded, failed, skipped int
 	var failures []string
 
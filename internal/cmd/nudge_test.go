@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -324,6 +326,33 @@ func TestNudgeValidModesAccepted(t *testing.T) {
 	}
 }
 
+func TestDeliverNudgeWaitIdleQueuesOnHeadlessBackend(t *testing.T) {
+	origMode := nudgeModeFlag
+	origPriority := nudgePriorityFlag
+	defer func() {
+		nudgeModeFlag = origMode
+		nudgePriorityFlag = origPriority
+	}()
+
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+	t.Setenv("GT_SESSION_BACKEND", "pty")
+
+	// PTY sessions have no idle detection, so wait-idle must queue
+	// rather than type into a possibly busy agent.
+	nudgeModeFlag = NudgeModeWaitIdle
+	nudgePriorityFlag = nudge.PriorityNormal
+	if err := deliverNudge(session.NewBackend(townRoot), "gt-alpha", "hello", "mayor"); err != nil {
+		t.Fatalf("deliverNudge: %v", err)
+	}
+	if n, err := nudge.Pending(townRoot, "gt-alpha"); err != nil || n != 1 {
+		t.Errorf("Pending = %d, %v; want 1 queued nudge", n, err)
+	}
+}
+
 func TestIfFreshMaxAge(t *testing.T) {
 	// Verify the constant is 60 seconds as specified in the design.
 	if ifFreshMaxAge != 60*time.Second {
@@ -216,6 +216,32 @@ func TestBuildRefineryPatrolVars_AllCommandsSet(t *testing.T) {
 	}
 }
 
+func TestBuildRefineryPatrolVars_MergeTrain(t *testing.T) {
+	tmpDir := t.TempDir()
+	settingsDir := filepath.Join(tmpDir, "testrig", "settings")
+	if err := os.MkdirAll(settingsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	mq := config.DefaultMergeQueueConfig()
+	mq.MergeTrain = true
+	data, _ := json.Marshal(config.RigSettings{Type: "rig-settings", Version: 1, MergeQueue: mq})
+	if err := os.WriteFile(filepath.Join(settingsDir, "config.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := buildRefineryPatrolVars(RoleContext{TownRoot: tmpDir, Rig: "testrig"})
+	found := false
+	for _, v := range vars {
+		if v == "merge_train=true" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("merge_train=true missing from %v", vars)
+	}
+}
+
 func TestBuildRefineryPatrolVars_EmptyTestCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	rigDir := filepath.Join(tmpDir, "testrig")
@@ -6,7 +6,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
@@ -16,6 +15,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -264,28 +264,55 @@ func (s *SpawnedPolecatInfo) StartSession() (string, error) {
 	t := tmux.NewTmux()
 	polecatSessMgr := polecat.NewSessionManager(t, r)
 
+	// An explicit --agent pins the agent; otherwise walk the polecat role's
+	// fallback chain, skipping agents whose binary is missing, whose account
+	// is rate-limited, or that never reach their prompt.
+	spawnTownRoot := filepath.Dir(r.Path)
+	candidates := []session.AgentCandidate{{
+		Agent:         s.agent,
+		Override:      s.agent,
+		RuntimeConfig: config.ResolveRoleAgentConfig("polecat", spawnTownRoot, r.Path),
+	}}
+	if s.agent == "" {
+		candidates = session.AgentCandidates("polecat", spawnTownRoot, r.Path)
+	}
+
 	fmt.Printf("Starting session for %s/%s...\n", s.RigName, s.PolecatName)
-	startOpts := polecat.SessionStartOptions{
-		RuntimeConfigDir: claudeConfigDir,
-		DoltBranch:       s.DoltBranch,
-		Agent:            s.agent,
-	}
-	if s.agent != "" {
-		cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(s.RigName, s.PolecatName, r.Path, "", s.agent)
-		if err != nil {
-			return "", err
+	for i, cand := range candidates {
+		last := i == len(candidates)-1
+		if !last {
+			if reason := cand.Unavailable(spawnTownRoot, claudeConfigDir); reason != "" {
+				session.LogAgentFallback(s.SessionName, "polecat", cand.Agent, candidates[i+1].Agent, reason)
+				continue
+			}
 		}
-		startOpts.Command = cmd
-	}
-	if err := polecatSessMgr.Start(s.PolecatName, startOpts); err != nil {
-		return "", fmt.Errorf("starting session: %w", err)
-	}
 
-	// Wait for runtime to be fully ready before returning.
-	spawnTownRoot := filepath.Dir(r.Path)
-	runtimeConfig := config.ResolveRoleAgentConfig("polecat", spawnTownRoot, r.Path)
-	if err := t.WaitForRuntimeReady(s.SessionName, runtimeConfig, 30*time.Second); err != nil {
-		style.PrintWarning("runtime may not be fully ready: %v", err)
+		startOpts := polecat.SessionStartOptions{
+			RuntimeConfigDir: claudeConfigDir,
+			DoltBranch:       s.DoltBranch,
+			Agent:            cand.Override,
+		}
+		if cand.Override != "" {
+			cmd, err := config.BuildPolecatStartupCommandWithAgentOverride(s.RigName, s.PolecatName, r.Path, "", cand.Override)
+			if err != nil {
+				return "", err
+			}
+			startOpts.Command = cmd
+		}
+		if err := polecatSessMgr.Start(s.PolecatName, startOpts); err != nil {
+			return "", fmt.Errorf("starting session: %w", err)
+		}
+
+		// Wait for runtime to be fully ready before returning.
+		if err := t.WaitForRuntimeReady(s.SessionName, cand.RuntimeConfig, session.DefaultReadyTimeout); err != nil {
+			if !last {
+				_ = t.KillSessionWithProcesses(s.SessionName)
+				session.LogAgentFallback(s.SessionName, "polecat", cand.Agent, candidates[i+1].Agent, session.FallbackReadyTimeout)
+				continue
+			}
+			style.PrintWarning("runtime may not be fully ready: %v", err)
+		}
+		break
 	}
 
 	// Update agent state with retry logic (gt-94llt7: fail-safe Dolt writes).
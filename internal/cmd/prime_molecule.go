@@ -361,5 +361,8 @@ func buildRefineryPatrolVars(ctx RoleContext) []string {
 		vars = append(vars, fmt.Sprintf("build_command=%s", mq.BuildCommand))
 	}
 	vars = append(vars, fmt.Sprintf("delete_merged_branches=%t", mq.IsDeleteMergedBranchesEnabled()))
+	if mq.MergeTrain {
+		vars = append(vars, "merge_train=true")
+	}
 	return vars
 }
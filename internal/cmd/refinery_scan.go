@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	refineryScanTarget string
+	refineryScanJSON   bool
+)
+
+var refineryScanCmd = &cobra.Command{
+	Use:   "scan <branch> [rig]",
+	Short: "Scan a branch for secrets and merge policy violations",
+	Long: `Run the built-in policy scan gate on a branch.
+
+Checks the changes the branch makes against the target branch, using the
+rules in merge_queue.scan:
+  - secrets in added lines: private keys, cloud and API tokens, and
+    high-entropy strings (suppress a false positive with gt:allow-secret
+    on the line, or list the file in allow_paths)
+  - files matching forbidden_paths (e.g. .env)
+  - binary files larger than max_binary_bytes
+  - more than max_files files or max_diff_lines lines changed
+
+The refinery runs this scan before the quality gates. A violation fails the
+MR with failure type policy_violation, which goes back to the polecat.
+
+Exits 1 when violations are found. Does nothing when merge_queue.scan is
+not configured.
+
+Examples:
+  gt refinery scan polecat/nux
+  gt refinery scan polecat/nux gastown --target develop --json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRefineryScan,
+}
+
+func init() {
+	refineryScanCmd.Flags().StringVar(&refineryScanTarget, "target", "", "Branch to compare against (default: the rig's default branch)")
+	refineryScanCmd.Flags().BoolVar(&refineryScanJSON, "json", false, "Output violations as JSON")
+	refineryCmd.AddCommand(refineryScanCmd)
+}
+
+func runRefineryScan(cmd *cobra.Command, args []string) error {
+	branch := args[0]
+	rigName := ""
+	if len(args) > 1 {
+		rigName = args[1]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	scan := eng.Config().Scan
+	if scan == nil {
+		if refineryScanJSON {
+			return outputJSON([]refinery.ScanViolation{})
+		}
+		fmt.Printf("%s No policy scan configured for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	target := refineryScanTarget
+	if target == "" {
+		target = r.DefaultBranch()
+	}
+	violations, err := eng.ScanChanges(target, branch, *scan)
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", branch, err)
+	}
+
+	if refineryScanJSON {
+		if violations == nil {
+			violations = []refinery.ScanViolation{}
+		}
+		if err := outputJSON(violations); err != nil {
+			return err
+		}
+	} else if len(violations) == 0 {
+		fmt.Printf("%s %s passed the policy scan against %s\n", style.Bold.Render("✓"), branch, target)
+	} else {
+		fmt.Printf("%s %s has %d policy violation(s) against %s\n", style.Error.Render("✗"), branch, len(violations), target)
+		for _, v := range violations {
+			fmt.Printf("  %s\n", v)
+		}
+	}
+	if len(violations) > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var refineryTrainDryRun bool
+
+var refineryTrainCmd = &cobra.Command{
+	Use:   "train [rig]",
+	Short: "Merge the top-scored ready MRs as one speculative batch",
+	Long: `Run one merge train.
+
+Picks the highest-scored ready MRs for one target branch (up to
+merge_queue.max_concurrent when merge_queue.merge_train is enabled, else one),
+stacks them onto a temporary integration ref, runs the quality gates once, and
+lands the whole batch on success. MRs predicted to conflict with one already in
+the train (see gt mq list --predict) are held back for a later train.
+
+If the gates fail, the batch is bisected to find the first MR that breaks
+them: that MR fails as usual (witness notified), the MRs ahead of it land,
+and the MRs behind it are released back to the queue.
+
+When merge_queue.merge_mode is "pull_request", each MR instead lands through
+a forge pull request, opened by the refinery and merged once the forge's
+checks pass. MRs whose checks are still running are released back to the
+queue.
+
+When merge_queue.post_merge_gates is set, the target head is verified after
+the train lands (see gt refinery verify).
+
+Examples:
+  gt refinery train
+  gt refinery train gastown --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryTrain,
+}
+
+func init() {
+	refineryTrainCmd.Flags().BoolVar(&refineryTrainDryRun, "dry-run", false, "Show the MRs the next train would carry without merging")
+	refineryCmd.AddCommand(refineryTrainCmd)
+}
+
+func runRefineryTrain(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+	if refineryTrainDryRun {
+		// Don't test-merge in the refinery worktree for a dry run; use the
+		// last saved prediction.
+		if saved, err := refinery.LoadConflictPrediction(r.Path); err == nil {
+			eng.SetConflictPrediction(saved)
+		}
+	} else {
+		eng.PredictConflicts(ready)
+	}
+	now := time.Now()
+	train := eng.SelectTrain(ready, now)
+	if len(train) == 0 {
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	if refineryTrainDryRun {
+		fmt.Printf("%s Next merge train for '%s' (%d MR(s) into %s):\n\n", style.Bold.Render("🚂"), rigName, len(train), train[0].Target)
+		for i, mr := range train {
+			fmt.Printf("  %d. %s  %s  (score %.0f)\n", i+1, mr.ID, mr.Branch, mr.ScoreWith(eng.Config().Scoring, now))
+		}
+		return nil
+	}
+
+	workerID := getWorkerID()
+	var claimed []*refinery.MRInfo
+	for _, mr := range train {
+		if err := eng.ClaimMR(mr.ID, workerID); err != nil {
+			style.PrintWarning("could not claim %s: %v (skipping)", mr.ID, err)
+			continue
+		}
+		claimed = append(claimed, mr)
+	}
+
+	outcomes := eng.ProcessTrain(context.Background(), claimed)
+	eng.HandleTrainOutcomes(outcomes)
+
+	var landed, failed, requeued int
+	for _, o := range outcomes {
+		switch {
+		case o.Result.Success:
+			landed++
+		case o.Requeued:
+			requeued++
+		default:
+			failed++
+		}
+	}
+	fmt.Printf("\n%s Merge train: %d landed, %d failed, %d requeued\n", style.Bold.Render("✓"), landed, failed, requeued)
+	if landed > 0 && len(eng.Config().PostMergeGates) > 0 {
+		if err := reportPostMerge(eng.VerifyPostMerge(context.Background(), claimed[0].Target)); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return NewSilentExit(1)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var refineryVerifyTarget string
+
+var refineryVerifyCmd = &cobra.Command{
+	Use:   "verify [rig]",
+	Short: "Run the post-merge gates on the target branch head",
+	Long: `Verify the target branch after merges have landed.
+
+Runs merge_queue.post_merge_gates on the current head of the target branch.
+A passing head is recorded as the last green head. If the head fails, the
+merges since the last green head are bisected to find the one that broke
+it. That merge is reverted on a revert/<sha> branch, queued as a P0 merge
+request, and its polecat is sent MERGE_FAILED (failure type post-merge)
+through the witness.
+
+If no head has passed yet, a failing head is not blamed on any merge: it is
+recorded as the red baseline and the overseer is mailed instead.
+
+Does nothing when no post-merge gates are configured or the head was
+already verified. gt refinery train runs this after a train lands.
+
+Examples:
+  gt refinery verify
+  gt refinery verify gastown --target develop`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryVerify,
+}
+
+func init() {
+	refineryVerifyCmd.Flags().StringVar(&refineryVerifyTarget, "target", "", "Branch to verify (default: the rig's default branch)")
+	refineryCmd.AddCommand(refineryVerifyCmd)
+}
+
+func runRefineryVerify(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if len(eng.Config().PostMergeGates) == 0 {
+		fmt.Printf("%s No post-merge gates configured for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	target := refineryVerifyTarget
+	if target == "" {
+		target = r.DefaultBranch()
+	}
+	return reportPostMerge(eng.VerifyPostMerge(context.Background(), target))
+}
+
+// reportPostMerge prints a post-merge verification result, returning a
+// silent exit error when the head failed.
+func reportPostMerge(result refinery.PostMergeResult) error {
+	switch {
+	case result.NoBaseline:
+		fmt.Printf("%s Post-merge gates failed on %s, which has no green head yet; nothing reverted\n", style.Warning.Render("⚠"), result.Target)
+		fmt.Printf("  %s\n", style.Dim.Render(result.Error))
+		return NewSilentExit(1)
+	case result.Error != "" && result.Culprit == "":
+		return fmt.Errorf("post-merge verification: %s", result.Error)
+	case result.Success && result.Skipped:
+		fmt.Printf("%s %s head already verified green\n", style.Dim.Render("○"), result.Target)
+		return nil
+	case result.Success:
+		fmt.Printf("%s Post-merge gates passed on %s\n", style.Bold.Render("✓"), result.Target)
+		return nil
+	case result.Skipped:
+		fmt.Printf("%s %s head already verified; it failed the post-merge gates\n", style.Warning.Render("⚠"), result.Target)
+		return NewSilentExit(1)
+	}
+
+	fmt.Printf("%s Post-merge gates failed on %s\n", style.Error.Render("✗"), result.Target)
+	fmt.Printf("  Culprit: %s\n", result.Culprit)
+	if result.CulpritMR != nil {
+		fmt.Printf("  MR: %s (%s)\n", result.CulpritMR.ID, result.CulpritMR.Branch)
+	}
+	if result.RevertMR != "" {
+		fmt.Printf("  Revert MR: %s (%s)\n", style.Bold.Render(result.RevertMR), result.RevertBranch)
+	} else if result.RevertBranch != "" {
+		fmt.Printf("  Revert branch: %s\n", result.RevertBranch)
+	}
+	fmt.Printf("  %s\n", style.Dim.Render(result.Error))
+	return NewSilentExit(1)
+}
@@ -67,6 +67,10 @@ The command also:
   - Creates ~/gt/plugins/ (town-level) if it doesn't exist
   - Creates <rig>/plugins/ (rig-level)
 
+Use --machine to run the rig's polecats on a remote machine registered in
+mayor/machines.json. The rig container stays local; polecat worktrees and
+sessions are created under the machine's town_path.
+
 Use --adopt to register an existing directory instead of creating new:
   - Reads existing config.json if present
   - Auto-detects git URL from origin remote (git-url argument not required)
@@ -75,7 +79,8 @@ Use --adopt to register an existing directory instead of creating new:
 Example:
   gt rig add gastown https://github.com/steveyegge/gastown
   gt rig add my-project git@github.com:user/repo.git --prefix mp
-  gt rig add existing-rig --adopt`,
+  gt rig add existing-rig --adopt
+  gt rig add bigrepo git@github.com:org/bigrepo.git --machine buildbox`,
 	Args: cobra.RangeArgs(1, 2),
 	RunE: runRigAdd,
 }
@@ -299,6 +304,7 @@ var (
 	rigAddAdopt        bool
 	rigAddAdoptURL     string
 	rigAddAdoptForce   bool
+	rigAddMachine      string
 	rigResetHandoff    bool
 	rigResetMail       bool
 	rigResetStale      bool
@@ -358,6 +364,7 @@ func init() {
 	rigAddCmd.Flags().BoolVar(&rigAddAdopt, "adopt", false, "Adopt an existing directory instead of creating new")
 	rigAddCmd.Flags().StringVar(&rigAddAdoptURL, "url", "", "Git remote URL for --adopt (default: auto-detected from origin)")
 	rigAddCmd.Flags().BoolVar(&rigAddAdoptForce, "force", false, "With --adopt, register even if git remote cannot be detected")
+	rigAddCmd.Flags().StringVar(&rigAddMachine, "machine", "", "Run this rig's polecats on a machine from mayor/machines.json")
 
 	rigResetCmd.Flags().BoolVar(&rigResetHandoff, "handoff", false, "Clear handoff content")
 	rigResetCmd.Flags().BoolVar(&rigResetMail, "mail", false, "Clear stale mail messages")
@@ -470,6 +477,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 
 	// Handle --adopt mode: register existing directory
 	if rigAddAdopt {
+		if rigAddMachine != "" {
+			return fmt.Errorf("--machine cannot be combined with --adopt")
+		}
 		return runRigAdopt(cmd, args)
 	}
 
@@ -514,6 +524,9 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 	if rigAddLocalRepo != "" {
 		fmt.Printf("  Local repo: %s\n", rigAddLocalRepo)
 	}
+	if rigAddMachine != "" {
+		fmt.Printf("  Machine: %s\n", rigAddMachine)
+	}
 
 	// Validate push URL if provided
 	rigAddPushURL = strings.TrimSpace(rigAddPushURL)
@@ -531,6 +544,7 @@ func runRigAdd(cmd *cobra.Command, args []string) error {
 		BeadsPrefix:   rigAddPrefix,
 		LocalRepo:     rigAddLocalRepo,
 		DefaultBranch: rigAddBranch,
+		Machine:       rigAddMachine,
 	})
 	if err != nil {
 		return fmt.Errorf("adding rig: %w", err)
@@ -65,6 +65,7 @@ var beadsExemptCommands = map[string]bool{
 	"krc":           true, // KRC doesn't require beads
 	"run-migration":       true, // Migration orchestrator handles its own beads checks
 	"migrate-bead-labels": true, // Label migration handles its own beads access
+	"agent-script":        true, // Scripted test agent; its gt commands do their own checks
 }
 
 // Commands exempt from the town root branch warning.
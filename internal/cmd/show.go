@@ -7,6 +7,8 @@ import (
 	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 func init() {
@@ -23,12 +25,17 @@ Delegates to 'bd show' - all bd show flags are supported.
 Works with any bead prefix (gt-, bd-, hq-, etc.) and routes
 to the correct beads database automatically.
 
+Also accepts hop:// and beads:// work unit URIs. URIs owned by this
+town show the local bead; URIs owned by a town on a registered machine
+(mayor/machines.json) run bd show in that town.
+
 Examples:
   gt show gt-abc123          # Show a gastown issue
   gt show hq-xyz789          # Show a town-level bead (convoy, mail, etc.)
   gt show bd-def456          # Show a beads issue
   gt show gt-abc123 --json   # Output as JSON
-  gt show gt-abc123 -v       # Verbose output`,
+  gt show gt-abc123 -v       # Verbose output
+  gt show hop://acme.com/platform-town/api/ap-123   # Issue in another town`,
 	DisableFlagParsing: true, // Pass all flags through to bd show
 	RunE:               runShow,
 }
@@ -43,9 +50,35 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("bead ID required\n\nUsage: gt show <bead-id> [flags]")
 	}
 
+	if beads.IsWorkURI(args[0]) {
+		return runShowURI(args)
+	}
+
 	return execBdShow(args)
 }
 
+// runShowURI shows a bead referenced by a hop:// or beads:// URI.
+func runShowURI(args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return err
+	}
+	target, err := beads.ResolveWorkURI(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+	if target.IsLocal() {
+		return execBdShow(append([]string{target.IssueID}, args[1:]...))
+	}
+
+	out, err := target.Bd(append([]string{"show", target.IssueID}, args[1:]...)...)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
+
 // execBdShow replaces the current process with 'bd show'.
 func execBdShow(args []string) error {
 	bdPath, err := exec.LookPath("bd")
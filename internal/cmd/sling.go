@@ -78,7 +78,7 @@ Stdin Mode (for shell-quoting-safe multi-line content):
 
 Formula Slinging:
   gt sling mol-release mayor/           # Cook + wisp + attach + nudge
-  gt sling towers-of-hanoi --var disks=3
+  gt sling towers-of-hanoi --var target_peg=B
 
 Formula-on-Bead (--on flag):
   gt sling mol-review --on gt-abc       # Apply formula to existing work
@@ -91,6 +91,14 @@ Compare:
 
 The propulsion principle: if it's on your hook, YOU RUN IT.
 
+Cross-Town Beads (hop:// and beads:// URIs):
+  gt sling hop://me@example.com/main-town/gastown/gt-abc gastown
+  gt sling hop://acme.com/platform-town/api/ap-123 api
+
+  A URI owned by this town slings its local bead. A URI owned by a town on
+  a registered machine is forwarded to that town's gt sling (the target is
+  resolved there).
+
 Batch Slinging:
   gt sling gt-abc gt-def gt-ghi gastown   # Sling multiple beads to a rig
   gt sling gt-abc gt-def gastown --max-concurrent 3  # Limit concurrent spawns
@@ -226,6 +234,12 @@ func runSling(cmd *cobra.Command, args []string) error {
 		args[i] = strings.TrimRight(args[i], "/")
 	}
 
+	// Resolve hop:// and beads:// bead references. Beads owned by another
+	// town are slung by that town.
+	if handled, err := resolveSlingURIs(cmd, townRoot, args); handled || err != nil {
+		return err
+	}
+
 	// Validate target format early, before any dispatch path (bead, formula, batch)
 	// can trigger resolveTarget side-effects like polecat spawning.
 	if len(args) > 1 {
@@ -260,6 +274,11 @@ func runSling(cmd *cobra.Command, args []string) error {
 		if err := verifyFormulaExists(formulaName); err != nil {
 			return err
 		}
+		vars, err := validateFormulaVars(formulaName, slingVars, []string{"feature", "issue"})
+		if err != nil {
+			return err
+		}
+		slingVars = vars
 	} else {
 		// Could be bead mode or standalone formula mode
 		firstArg := args[0]
@@ -132,6 +132,13 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			}
 		}
 
+		// Budget guard: an exhausted rig/role/convoy budget blocks new spawns
+		if err := checkSpawnBudget(townRoot, rigName, beadID); err != nil {
+			results = append(results, slingResult{beadID: beadID, success: false, errMsg: err.Error()})
+			fmt.Printf("  %s %v\n", style.Dim.Render("✗"), err)
+			continue
+		}
+
 		// Spawn a fresh polecat
 		spawnOpts := SlingSpawnOptions{
 			Force:      slingForce,
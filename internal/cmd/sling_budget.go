@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// enforceBudgetFn is a seam for tests. Production uses budget.Enforce.
+var enforceBudgetFn = budget.Enforce
+
+// checkSpawnBudget refuses a new polecat spawn in rigName when a rig, polecat
+// role, or convoy budget covering beadID is exhausted. Budget lookups that
+// fail (e.g. an unreadable ledger) warn and allow the spawn: budgets guard
+// against runaway spend, they must not take down dispatch.
+func checkSpawnBudget(townRoot, rigName, beadID string) error {
+	if townRoot == "" {
+		var err error
+		if townRoot, err = workspace.FindFromCwd(); err != nil || townRoot == "" {
+			return nil
+		}
+	}
+	breach, err := enforceBudgetFn(townRoot, budget.Scope{Rig: rigName, Role: "polecat", Bead: beadID})
+	if err != nil {
+		fmt.Printf("%s Budget check: %v\n", style.Dim.Render("Warning:"), err)
+	}
+	if breach != nil {
+		return fmt.Errorf("refusing to spawn polecat in rig '%s': %s\nRaise the budget in settings/config.json (budgets) or wait for the %s period to reset",
+			rigName, breach, breach.Period)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/budget"
+)
+
+func TestCheckSpawnBudget(t *testing.T) {
+	orig := enforceBudgetFn
+	t.Cleanup(func() { enforceBudgetFn = orig })
+
+	var gotScope budget.Scope
+	enforceBudgetFn = func(_ string, scope budget.Scope) (*budget.Breach, error) {
+		gotScope = scope
+		return nil, nil
+	}
+	if err := checkSpawnBudget("/town", "gastown", "gt-abc"); err != nil {
+		t.Fatalf("under budget: %v", err)
+	}
+	if want := (budget.Scope{Rig: "gastown", Role: "polecat", Bead: "gt-abc"}); gotScope != want {
+		t.Errorf("scope = %+v, want %+v", gotScope, want)
+	}
+
+	enforceBudgetFn = func(string, budget.Scope) (*budget.Breach, error) {
+		return &budget.Breach{Kind: budget.KindConvoy, Name: "hq-cv-1", Period: budget.PeriodDaily, LimitUSD: 20, SpentUSD: 21}, nil
+	}
+	err := checkSpawnBudget("/town", "gastown", "gt-abc")
+	if err == nil || !strings.Contains(err.Error(), "convoy hq-cv-1 daily budget exhausted") {
+		t.Errorf("breach error = %v", err)
+	}
+
+	// A failing budget lookup must not block dispatch.
+	enforceBudgetFn = func(string, budget.Scope) (*budget.Breach, error) {
+		return nil, errors.New("ledger unreadable")
+	}
+	if err := checkSpawnBudget("/town", "gastown", "gt-abc"); err != nil {
+		t.Errorf("lookup failure blocked spawn: %v", err)
+	}
+}
@@ -82,6 +82,13 @@ func runSlingFormula(args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Check vars before resolving the target, which may spawn a polecat
+	vars, err := validateFormulaVars(formulaName, slingVars, nil)
+	if err != nil {
+		return err
+	}
+	slingVars = vars
+
 	// Resolve target using shared dispatch logic
 	var target string
 	if len(args) > 1 {
@@ -132,11 +139,20 @@ func runSlingFormula(args []string) error {
 		formulaWorkDir = townRoot
 	}
 
+	// bd does not resolve include, when or foreach; pour gt's resolved copy
+	pourName, removePour, err := resolvedPourFormula(formulaName, slingVars, townRoot)
+	if err != nil {
+		rollbackSpawned("")
+		return err
+	}
+	defer removePour()
+
 	// Step 1: Cook the formula (ensures proto exists)
 	fmt.Printf("  Cooking formula...\n")
-	cookArgs := []string{"cook", formulaName}
+	cookArgs := []string{"cook", pourName}
 	cookCmd := exec.Command("bd", cookArgs...)
 	cookCmd.Dir = formulaWorkDir
+	cookCmd.Env = append(os.Environ(), "GT_ROOT="+townRoot)
 	cookCmd.Stderr = os.Stderr
 	if err := cookCmd.Run(); err != nil {
 		rollbackSpawned("")
@@ -145,7 +161,7 @@ func runSlingFormula(args []string) error {
 
 	// Step 2: Create wisp instance (ephemeral)
 	fmt.Printf("  Creating wisp...\n")
-	wispArgs := []string{"mol", "wisp", formulaName}
+	wispArgs := []string{"mol", "wisp", pourName}
 	for _, v := range slingVars {
 		wispArgs = append(wispArgs, "--var", v)
 	}
@@ -655,9 +655,21 @@ func InstantiateFormulaOnBead(formulaName, beadID, title, hookWorkDir, townRoot
 	// Route bd mutations (wisp/bond) to the correct beads context for the target bead.
 	formulaWorkDir := beads.ResolveHookDir(townRoot, beadID, hookWorkDir)
 
+	featureVar := fmt.Sprintf("feature=%s", title)
+	issueVar := fmt.Sprintf("issue=%s", beadID)
+	vars := append([]string{featureVar, issueVar}, extraVars...)
+
+	// bd does not resolve include, when or foreach; pour gt's resolved copy,
+	// which depends on this bead's vars and so is always cooked
+	pourName, removePour, err := resolvedPourFormula(formulaName, vars, townRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer removePour()
+
 	// Step 1: Cook the formula (ensures proto exists)
-	if !skipCook {
-		cookCmd := exec.Command("bd", "cook", formulaName)
+	if !skipCook || pourName != formulaName {
+		cookCmd := exec.Command("bd", "cook", pourName)
 		cookCmd.Dir = formulaWorkDir
 		cookCmd.Env = append(os.Environ(), "GT_ROOT="+townRoot)
 		cookCmd.Stderr = os.Stderr
@@ -667,10 +679,8 @@ func InstantiateFormulaOnBead(formulaName, beadID, title, hookWorkDir, townRoot
 	}
 
 	// Step 2: Create wisp with feature and issue variables from bead
-	featureVar := fmt.Sprintf("feature=%s", title)
-	issueVar := fmt.Sprintf("issue=%s", beadID)
-	wispArgs := []string{"mol", "wisp", formulaName, "--var", featureVar, "--var", issueVar}
-	for _, variable := range extraVars {
+	wispArgs := []string{"mol", "wisp", pourName}
+	for _, variable := range vars {
 		wispArgs = append(wispArgs, "--var", variable)
 	}
 	wispArgs = append(wispArgs, "--json")
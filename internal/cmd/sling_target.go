@@ -188,6 +188,9 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 			result.Pane = "<new-pane>"
 			return result, nil
 		}
+		if err := checkSpawnBudget(opts.TownRoot, rigName, opts.BeadID); err != nil {
+			return nil, err
+		}
 		fmt.Printf("Target is rig '%s', spawning fresh polecat...\n", rigName)
 		spawnOpts := SlingSpawnOptions{
 			Force:      opts.Force,
@@ -225,6 +228,9 @@ func resolveTarget(target string, opts ResolveTargetOptions) (*ResolvedTarget, e
 						return nil, err
 					}
 				}
+				if err := checkSpawnBudget(opts.TownRoot, rigName, opts.BeadID); err != nil {
+					return nil, err
+				}
 				fmt.Printf("Target polecat has no active session, spawning fresh polecat in rig '%s'...\n", rigName)
 				spawnOpts := SlingSpawnOptions{
 					Force:      opts.Force,
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// resolveSlingURIs rewrites hop:// and beads:// bead arguments in place.
+// Beads owned by this town become their local IDs. A bead owned by another
+// town is handed to that town's gt sling, since only the owning town can
+// hook and dispatch it; handled reports that the sling was forwarded.
+func resolveSlingURIs(cmd *cobra.Command, townRoot string, args []string) (handled bool, err error) {
+	if beads.IsWorkURI(slingOnTarget) {
+		target, err := beads.ResolveWorkURI(townRoot, slingOnTarget)
+		if err != nil {
+			return true, err
+		}
+		if !target.IsLocal() {
+			return true, fmt.Errorf("--on %s: bead is owned by another town (%s)", slingOnTarget, target.Describe())
+		}
+		slingOnTarget = target.IssueID
+	}
+
+	for i, arg := range args {
+		if !beads.IsWorkURI(arg) {
+			continue
+		}
+		target, err := beads.ResolveWorkURI(townRoot, arg)
+		if err != nil {
+			return true, err
+		}
+		if target.IsLocal() {
+			args[i] = target.IssueID
+			continue
+		}
+		if i != 0 || len(args) > 2 {
+			return true, fmt.Errorf("%s is owned by another town (%s); sling it on its own", arg, target.Describe())
+		}
+		return true, forwardSling(cmd, target, args[1:])
+	}
+	return false, nil
+}
+
+// forwardSling runs gt sling for a remote bead in the town that owns it,
+// passing along the flags set on this invocation. Targets are interpreted
+// by the remote town.
+func forwardSling(cmd *cobra.Command, target *beads.URITarget, rest []string) error {
+	remoteArgs := append([]string{"sling", target.IssueID}, rest...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "stdin", "args", "message":
+			// Already read into slingArgs/slingMessage; forwarded below.
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				remoteArgs = append(remoteArgs, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		remoteArgs = append(remoteArgs, "--"+f.Name+"="+f.Value.String())
+	})
+	if slingArgs != "" {
+		remoteArgs = append(remoteArgs, "--args="+slingArgs)
+	}
+	if slingMessage != "" {
+		remoteArgs = append(remoteArgs, "--message="+slingMessage)
+	}
+
+	fmt.Printf("%s Forwarding %s to %s\n", style.Bold.Render("→"), target.URI, target.Describe())
+	out, err := target.Gt(remoteArgs...)
+	if len(out) > 0 && err == nil {
+		_, _ = os.Stdout.Write(out)
+	}
+	return err
+}
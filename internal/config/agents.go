@@ -33,6 +33,9 @@ const (
 	AgentCopilot AgentPreset = "copilot"
 	// AgentPi is Pi Coding Agent (extension-based lifecycle).
 	AgentPi AgentPreset = "pi"
+	// AgentScripted is a fake agent that replays a script (gt agent-script).
+	// Used for deterministic end-to-end tests; no LLM involved.
+	AgentScripted AgentPreset = "scripted"
 )
 
 // AgentPresetInfo contains the configuration details for an agent preset.
@@ -126,6 +129,10 @@ type AgentPresetInfo struct {
 	// Defaults to "AGENTS.md" if empty.
 	InstructionsFile string `json:"instructions_file,omitempty"`
 
+	// UsageProvider names the registered usage extractor that reads this agent's
+	// token usage for gt costs (e.g., "claude", "codex"). Empty means unknown.
+	UsageProvider string `json:"usage_provider,omitempty"`
+
 	// EmitsPermissionWarning indicates the agent shows a bypass-permissions warning on startup
 	// that needs to be acknowledged via tmux.
 	EmitsPermissionWarning bool `json:"emits_permission_warning,omitempty"`
@@ -182,6 +189,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		ReadyDelayMs:           10000,
 		InstructionsFile:       "CLAUDE.md",
 		EmitsPermissionWarning: true,
+		UsageProvider:          "claude",
 	},
 	AgentGemini: {
 		Name:                AgentGemini,
@@ -205,6 +213,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		HooksSettingsFile: "settings.json",
 		ReadyDelayMs:      5000,
 		InstructionsFile:  "AGENTS.md",
+		UsageProvider:     "gemini",
 	},
 	AgentCodex: {
 		Name:                AgentCodex,
@@ -224,6 +233,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		PromptMode:       "none",
 		ReadyDelayMs:     3000,
 		InstructionsFile: "AGENTS.md",
+		UsageProvider:    "codex",
 	},
 	AgentCursor: {
 		Name:                AgentCursor,
@@ -297,6 +307,7 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 		HooksSettingsFile: "gastown.js",
 		ReadyDelayMs:      8000,
 		InstructionsFile:  "AGENTS.md",
+		UsageProvider:     "opencode",
 	},
 	AgentCopilot: {
 		Name:                AgentCopilot,
@@ -340,6 +351,19 @@ var builtinPresets = map[AgentPreset]*AgentPresetInfo{
 			OutputFlag: "--no-session",
 		},
 	},
+	AgentScripted: {
+		Name:                AgentScripted,
+		Command:             "gt",
+		Args:                []string{"agent-script"}, // Script from GT_AGENT_SCRIPT or settings/agent-scripts/
+		ProcessNames:        []string{"gt"},
+		SupportsHooks:       false, // Receives gt prime via startup fallback nudge
+		SupportsForkSession: false,
+		// Runtime defaults
+		PromptMode:        "arg",
+		ReadyPromptPrefix: "❯ ", // Must match agentscript.DefaultPrompt
+		ReadyDelayMs:      1000,
+		InstructionsFile:  "AGENTS.md",
+	},
 }
 
 // Registry state with proper synchronization.
@@ -0,0 +1,92 @@
+package config
+
+// BudgetLimit caps spending over a calendar day and a calendar month (local
+// time). A zero cap means unlimited.
+type BudgetLimit struct {
+	DailyUSD   float64 `json:"daily_usd,omitempty"`
+	MonthlyUSD float64 `json:"monthly_usd,omitempty"`
+}
+
+// BudgetConfig configures enforced cost budgets (TownSettings.Budgets).
+// Spending is measured from the costs ledger (~/.gt/costs.jsonl plus cost
+// digest beads). When a budget is exhausted, gt sling refuses to spawn new
+// polecats in that scope, the daemon stops respawning crashed ones, and an
+// escalation is raised once per budget period.
+type BudgetConfig struct {
+	// Rigs caps spending per rig. Keys are rig names; "*" applies to every
+	// rig without its own entry. A rig's own settings (RigSettings.Budget)
+	// take precedence.
+	Rigs map[string]*BudgetLimit `json:"rigs,omitempty"`
+
+	// Roles caps spending per role across the town (e.g. "polecat").
+	Roles map[string]*BudgetLimit `json:"roles,omitempty"`
+
+	// Convoys caps spending per convoy. Keys are convoy IDs; "*" applies to
+	// every convoy without its own entry.
+	Convoys map[string]*BudgetLimit `json:"convoys,omitempty"`
+
+	// EscalationSeverity is the severity of the escalation raised when a
+	// budget is exhausted. Default: "high".
+	EscalationSeverity string `json:"escalation_severity,omitempty"`
+}
+
+// BudgetWildcard is the budget key matching any rig or convoy without an
+// explicit entry.
+const BudgetWildcard = "*"
+
+// IsZero reports whether the limit caps nothing.
+func (l *BudgetLimit) IsZero() bool {
+	return l == nil || (l.DailyUSD <= 0 && l.MonthlyUSD <= 0)
+}
+
+// RigBudget returns the budget for a rig: the rig's own setting if present,
+// else the town entry for the rig, else the town wildcard entry.
+func (c *BudgetConfig) RigBudget(rigName string, rig *RigSettings) *BudgetLimit {
+	if rig != nil && rig.Budget != nil {
+		return rig.Budget
+	}
+	if c == nil {
+		return nil
+	}
+	return lookupBudget(c.Rigs, rigName)
+}
+
+// RoleBudget returns the town-wide budget for a role.
+func (c *BudgetConfig) RoleBudget(role string) *BudgetLimit {
+	if c == nil || role == "" {
+		return nil
+	}
+	return c.Roles[role]
+}
+
+// ConvoyBudget returns the budget for a convoy, falling back to the
+// wildcard entry.
+func (c *BudgetConfig) ConvoyBudget(convoyID string) *BudgetLimit {
+	if c == nil {
+		return nil
+	}
+	return lookupBudget(c.Convoys, convoyID)
+}
+
+// HasConvoyBudgets reports whether any convoy budget is configured.
+func (c *BudgetConfig) HasConvoyBudgets() bool {
+	if c == nil {
+		return false
+	}
+	for _, l := range c.Convoys {
+		if !l.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupBudget(limits map[string]*BudgetLimit, name string) *BudgetLimit {
+	if name == "" {
+		return nil
+	}
+	if l, ok := limits[name]; ok {
+		return l
+	}
+	return limits[BudgetWildcard]
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -1381,6 +1382,33 @@ func ResolveRoleAgentName(role, townRoot, rigPath string) (agentName string, isR
 	return "claude", false
 }
 
+// ResolveRoleAgentChain returns the agents to try, in order, when starting a
+// session for a role: the agent ResolveRoleAgentName selects, then the role's
+// fallbacks (rig RoleAgentFallbacks replace the town's). Duplicates are dropped,
+// so a role without fallbacks yields a single entry.
+func ResolveRoleAgentChain(role, townRoot, rigPath string) []string {
+	primary, _ := ResolveRoleAgentName(role, townRoot, rigPath)
+	chain := []string{primary}
+
+	var fallbacks []string
+	if rigPath != "" {
+		if rigSettings, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			fallbacks = rigSettings.RoleAgentFallbacks[role]
+		}
+	}
+	if fallbacks == nil {
+		if townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot)); err == nil {
+			fallbacks = townSettings.RoleAgentFallbacks[role]
+		}
+	}
+	for _, name := range fallbacks {
+		if name != "" && !slices.Contains(chain, name) {
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
 // lookupAgentConfig looks up an agent by name.
 // Checks rig-level custom agents first, then town's custom agents, then built-in presets from agents.go.
 func lookupAgentConfig(name string, townSettings *TownSettings, rigSettings *RigSettings) *RuntimeConfig {
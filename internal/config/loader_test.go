@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -3280,6 +3281,42 @@ func TestResolveRoleAgentName(t *testing.T) {
 	})
 }
 
+func TestResolveRoleAgentChain(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	townSettings := NewTownSettings()
+	townSettings.RoleAgents = map[string]string{"polecat": "claude", "witness": "claude"}
+	townSettings.RoleAgentFallbacks = map[string][]string{
+		"polecat": {"gemini", "claude", "", "codex"},
+		"witness": {"gemini"},
+	}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.RoleAgentFallbacks = map[string][]string{"witness": {"amp"}}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	tests := []struct {
+		role string
+		want []string
+	}{
+		{"polecat", []string{"claude", "gemini", "codex"}},
+		{"witness", []string{"claude", "amp"}},
+		{"refinery", []string{"claude"}},
+	}
+	for _, tt := range tests {
+		got := ResolveRoleAgentChain(tt.role, townRoot, rigPath)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ResolveRoleAgentChain(%s) = %v, want %v", tt.role, got, tt.want)
+		}
+	}
+}
+
 func TestRoleAgentsRoundTrip(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
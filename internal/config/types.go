@@ -65,6 +65,12 @@ type TownSettings struct {
 	// Example: {"mayor": "claude-opus", "witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
 
+	// RoleAgentFallbacks lists, per role, the agents to try in order when the
+	// role's agent cannot start: its binary is missing, its account is marked
+	// rate-limited in mayor/quota.json, or its prompt never appears.
+	// Example: {"polecat": ["claude-sonnet", "gemini"]}
+	RoleAgentFallbacks map[string][]string `json:"role_agent_fallbacks,omitempty"`
+
 	// AgentEmailDomain is the domain used for agent git identity emails.
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
@@ -86,6 +92,40 @@ type TownSettings struct {
 	// Actual model assignments live in RoleAgents and Agents.
 	// Values: "standard", "economy", "budget", or empty for custom configs.
 	CostTier string `json:"cost_tier,omitempty"`
+
+	// SessionBackend selects how agent sessions are hosted.
+	// Values: "tmux" (default) or "pty" (headless sessions supervised by
+	// the daemon, for hosts without a tmux server).
+	// Can be overridden by GT_SESSION_BACKEND environment variable.
+	SessionBackend string `json:"session_backend,omitempty"`
+
+	// Pricing overrides per-model token prices used by gt costs.
+	// Keys are model names or model-name prefixes (longest prefix wins).
+	// Entries are layered over DefaultModelPricing.
+	// Example: {"gemini-2.5-pro": {"input_per_million": 1.25, "output_per_million": 10}}
+	Pricing map[string]*ModelPricing `json:"pricing,omitempty"`
+
+	// Budgets configures enforced daily/monthly cost caps per rig, role,
+	// and convoy. Unlike CostTier, budgets block new polecat spawns and
+	// daemon respawns once exhausted.
+	Budgets *BudgetConfig `json:"budgets,omitempty"`
+
+	// FormulaRegistries lists the Mol Mall registries gt formula install
+	// searches, in order. URLs may be http(s), file:// or a local directory.
+	// Example: [{"name": "acme", "url": "https://molmall.acme.corp", "token_env": "ACME_MOLMALL_TOKEN"}]
+	FormulaRegistries []FormulaRegistryConfig `json:"formula_registries,omitempty"`
+}
+
+// FormulaRegistryConfig is a formula registry (TownSettings.FormulaRegistries).
+type FormulaRegistryConfig struct {
+	// Name identifies the registry in formulas.lock and --registry.
+	Name string `json:"name"`
+
+	// URL is the registry root.
+	URL string `json:"url"`
+
+	// TokenEnv names the environment variable holding a bearer token.
+	TokenEnv string `json:"token_env,omitempty"`
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -352,6 +392,15 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// RoleAgentFallbacks lists, per role, the agents to try in order when the
+	// role's agent cannot start. A role listed here replaces the town's
+	// TownSettings.RoleAgentFallbacks entry for this rig.
+	RoleAgentFallbacks map[string][]string `json:"role_agent_fallbacks,omitempty"`
+
+	// Budget caps this rig's spending. Overrides the rig's entry in
+	// TownSettings.Budgets.Rigs.
+	Budget *BudgetLimit `json:"budget,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -879,11 +928,25 @@ type MergeQueueConfig struct {
 	PollInterval string `json:"poll_interval"`
 
 	// MaxConcurrent is the maximum number of concurrent merges.
+	// With MergeTrain it is the maximum number of MRs per train.
 	MaxConcurrent int `json:"max_concurrent"`
 
+	// MergeTrain makes the refinery stack the top MaxConcurrent MRs into one
+	// speculative batch, gate it once, and land the whole batch on success.
+	MergeTrain bool `json:"merge_train,omitempty"`
+
 	// StaleClaimTimeout is how long a claimed MR can go without updates before
 	// being considered abandoned and eligible for re-claim (e.g., "30m").
 	StaleClaimTimeout string `json:"stale_claim_timeout,omitempty"`
+
+	// FlakeQuarantineThreshold is how many flakes (fail, then pass on retry)
+	// quarantine a test in a gate that declares a report format. 0 disables
+	// quarantine; nil uses the refinery default.
+	FlakeQuarantineThreshold *int `json:"flake_quarantine_threshold,omitempty"`
+
+	// GateCacheTTL is how long a gate's pass is reused for an identical
+	// tree and gate command (e.g., "24h"). "0" disables the cache.
+	GateCacheTTL string `json:"gate_cache_ttl,omitempty"`
 }
 
 // OnConflict strategy constants.
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// TokenUsage is the token usage of one agent session, as extracted from the
+// agent's own transcript or session store.
+type TokenUsage struct {
+	// Model is the model that served the session (first one seen).
+	Model string
+
+	// InputTokens excludes cached input, which is counted separately.
+	InputTokens              int
+	CacheCreationInputTokens int
+	CacheReadInputTokens     int
+
+	// OutputTokens includes reasoning/thinking tokens.
+	OutputTokens int
+
+	// ReportedCostUSD is the session cost as computed by the agent itself,
+	// for providers that record it (e.g. OpenCode). Zero if unknown.
+	ReportedCostUSD float64
+}
+
+// ModelPricing is the price of a model in USD per million tokens.
+type ModelPricing struct {
+	InputPerMillion       float64 `json:"input_per_million"`
+	OutputPerMillion      float64 `json:"output_per_million"`
+	CacheReadPerMillion   float64 `json:"cache_read_per_million,omitempty"`
+	CacheCreatePerMillion float64 `json:"cache_create_per_million,omitempty"`
+}
+
+// DefaultModelPricing holds list prices for the models of the built-in
+// presets. Keys are exact model names or prefixes; "default" applies to
+// anything unmatched. Town settings can override any entry (see Pricing).
+var DefaultModelPricing = map[string]*ModelPricing{
+	// Anthropic
+	"claude-opus-4-5-20251101":  {15.0, 75.0, 1.5, 18.75},
+	"claude-opus-4":             {15.0, 75.0, 1.5, 18.75},
+	"claude-sonnet-4-20250514":  {3.0, 15.0, 0.3, 3.75},
+	"claude-sonnet-4":           {3.0, 15.0, 0.3, 3.75},
+	"claude-3-5-haiku-20241022": {1.0, 5.0, 0.1, 1.25},
+	"claude-haiku-4":            {1.0, 5.0, 0.1, 1.25},
+	// Google
+	"gemini-2.5-pro":   {1.25, 10.0, 0.31, 0},
+	"gemini-2.5-flash": {0.30, 2.50, 0.075, 0},
+	// OpenAI
+	"gpt-5":      {1.25, 10.0, 0.125, 0},
+	"gpt-5-mini": {0.25, 2.0, 0.025, 0},
+	"o4-mini":    {1.10, 4.40, 0.275, 0},
+	// Fallback for unknown models (Sonnet pricing)
+	"default": {3.0, 15.0, 0.3, 3.75},
+}
+
+// PricingFor returns the pricing for a model. overrides (typically
+// TownSettings.Pricing) are consulted before DefaultModelPricing; within each
+// table an exact model match wins over the longest matching prefix. Models
+// matching neither table use the "default" entry.
+func PricingFor(model string, overrides map[string]*ModelPricing) ModelPricing {
+	for _, table := range []map[string]*ModelPricing{overrides, DefaultModelPricing} {
+		if p := lookupPricing(table, model); p != nil {
+			return *p
+		}
+	}
+	if p := overrides["default"]; p != nil {
+		return *p
+	}
+	return *DefaultModelPricing["default"]
+}
+
+// lookupPricing finds model in table by exact name, then longest prefix.
+func lookupPricing(table map[string]*ModelPricing, model string) *ModelPricing {
+	if model == "" {
+		return nil
+	}
+	if p := table[model]; p != nil {
+		return p
+	}
+	var best *ModelPricing
+	bestLen := 0
+	for prefix, p := range table {
+		if p != nil && prefix != "default" && len(prefix) > bestLen && strings.HasPrefix(model, prefix) {
+			best, bestLen = p, len(prefix)
+		}
+	}
+	return best
+}
+
+// Cost converts token usage to USD. A cost reported by the agent itself is
+// used as-is; otherwise tokens are priced with PricingFor.
+func (u *TokenUsage) Cost(overrides map[string]*ModelPricing) float64 {
+	if u == nil {
+		return 0.0
+	}
+	if u.ReportedCostUSD > 0 {
+		return u.ReportedCostUSD
+	}
+
+	pricing := PricingFor(u.Model, overrides)
+	inputCost := float64(u.InputTokens) / 1_000_000 * pricing.InputPerMillion
+	cacheReadCost := float64(u.CacheReadInputTokens) / 1_000_000 * pricing.CacheReadPerMillion
+	cacheCreateCost := float64(u.CacheCreationInputTokens) / 1_000_000 * pricing.CacheCreatePerMillion
+	outputCost := float64(u.OutputTokens) / 1_000_000 * pricing.OutputPerMillion
+
+	return inputCost + cacheReadCost + cacheCreateCost + outputCost
+}
+
+// UsageExtractorFunc reads the token usage of the most recent agent session
+// that ran in workDir.
+type UsageExtractorFunc func(workDir string) (*TokenUsage, error)
+
+// usageExtractors maps usage providers to their extraction functions.
+// Registration happens via RegisterUsageExtractor, typically from runtime init().
+var usageExtractors = make(map[string]UsageExtractorFunc)
+
+// RegisterUsageExtractor registers a usage extraction function for a provider
+// (an AgentPresetInfo.UsageProvider value).
+func RegisterUsageExtractor(provider string, fn UsageExtractorFunc) {
+	usageExtractors[provider] = fn
+}
+
+// GetUsageExtractor returns the registered usage extractor for a provider.
+// Returns nil if no extractor is registered.
+func GetUsageExtractor(provider string) UsageExtractorFunc {
+	return usageExtractors[provider]
+}
+
+// ResetUsageExtractorsForTesting clears all usage extractor registrations.
+func ResetUsageExtractorsForTesting() {
+	usageExtractors = make(map[string]UsageExtractorFunc)
+}
+
+// ResolveUsageProvider determines which usage extractor applies to an agent,
+// following the same rules as ResolveProcessNames: the named preset if its
+// command matches, else any preset with the same command, else Claude (the
+// historical default, used when the agent is unknown).
+func ResolveUsageProvider(agentName, command string) string {
+	ensureRegistry()
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	cmdBase := command
+	if command != "" {
+		cmdBase = filepath.Base(command)
+	}
+
+	if info, ok := globalRegistry.Agents[agentName]; ok && info.UsageProvider != "" {
+		if cmdBase == "" || info.Command == command || filepath.Base(info.Command) == cmdBase {
+			return info.UsageProvider
+		}
+	}
+	if cmdBase != "" {
+		for _, info := range globalRegistry.Agents {
+			if info.UsageProvider != "" && filepath.Base(info.Command) == cmdBase {
+				return info.UsageProvider
+			}
+		}
+	}
+	return string(AgentClaude)
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricingFor(t *testing.T) {
+	t.Parallel()
+	overrides := map[string]*ModelPricing{
+		"gemini-2.5":  {InputPerMillion: 9},
+		"my-model-v2": {InputPerMillion: 7},
+	}
+	tests := []struct {
+		model     string
+		overrides map[string]*ModelPricing
+		wantInput float64
+	}{
+		{"claude-sonnet-4-20250514", nil, 3.0},   // exact
+		{"claude-sonnet-4-5-20250929", nil, 3.0}, // prefix
+		{"claude-opus-4-1", nil, 15.0},           // prefix
+		{"gpt-5-mini-2025", nil, 0.25},           // longest prefix beats "gpt-5"
+		{"gpt-5-codex", nil, 1.25},               // family prefix
+		{"unknown-model", nil, 3.0},              // default
+		{"", nil, 3.0},                           // default
+		{"gemini-2.5-pro", overrides, 9},         // override prefix beats default exact
+		{"gemini-2.5-flash-lite", overrides, 9},  // override prefix beats default prefix
+		{"my-model-v2", overrides, 7},            // override exact
+		{"claude-sonnet-4-5", overrides, 3.0},    // untouched family
+	}
+	for _, tt := range tests {
+		if got := PricingFor(tt.model, tt.overrides); got.InputPerMillion != tt.wantInput {
+			t.Errorf("PricingFor(%q).InputPerMillion = %v, want %v", tt.model, got.InputPerMillion, tt.wantInput)
+		}
+	}
+
+	defaultOverride := map[string]*ModelPricing{"default": {InputPerMillion: 42}}
+	if got := PricingFor("mystery", defaultOverride); got.InputPerMillion != 42 {
+		t.Errorf("default override InputPerMillion = %v, want 42", got.InputPerMillion)
+	}
+}
+
+func TestTokenUsageCost(t *testing.T) {
+	t.Parallel()
+	u := &TokenUsage{
+		Model:                    "claude-sonnet-4-20250514",
+		InputTokens:              1_000_000,
+		CacheCreationInputTokens: 1_000_000,
+		CacheReadInputTokens:     1_000_000,
+		OutputTokens:             1_000_000,
+	}
+	if got, want := u.Cost(nil), 3.0+3.75+0.3+15.0; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", got, want)
+	}
+
+	reported := &TokenUsage{Model: "gpt-5", InputTokens: 1_000_000, ReportedCostUSD: 0.42}
+	if got := reported.Cost(nil); got != 0.42 {
+		t.Errorf("Cost with reported cost = %v, want 0.42", got)
+	}
+
+	var nilUsage *TokenUsage
+	if got := nilUsage.Cost(nil); got != 0 {
+		t.Errorf("nil Cost = %v", got)
+	}
+}
+
+func TestResolveUsageProvider(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		agent, command string
+		want           string
+	}{
+		{"claude", "", "claude"},
+		{"gemini", "", "gemini"},
+		{"codex", "codex", "codex"},
+		{"opencode", "", "opencode"},
+		{"codex", "opencode", "opencode"},          // custom agent shadowing a preset name
+		{"my-gemini", "/opt/bin/gemini", "gemini"}, // custom agent using a known launcher
+		{"", "", "claude"},                         // unknown: historical default
+		{"cursor", "", "claude"},                   // preset without usage records
+	}
+	for _, tt := range tests {
+		if got := ResolveUsageProvider(tt.agent, tt.command); got != tt.want {
+			t.Errorf("ResolveUsageProvider(%q, %q) = %q, want %q", tt.agent, tt.command, got, tt.want)
+		}
+	}
+}
@@ -11,11 +11,13 @@ import (
 
 // Machine represents a managed machine in the federation.
 type Machine struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`      // "local", "ssh"
-	Host     string `json:"host"`      // for ssh: user@host
-	KeyPath  string `json:"key_path"`  // SSH private key path
-	TownPath string `json:"town_path"` // Path to town root on remote
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`              // "local", "ssh"
+	Host     string   `json:"host"`              // for ssh: user@host
+	Port     int      `json:"port,omitempty"`    // for ssh: port (0 = ssh default)
+	KeyPath  string   `json:"key_path"`          // SSH private key path
+	TownPath string   `json:"town_path"`         // Path to town root on remote
+	Options  []string `json:"options,omitempty"` // for ssh: extra -o options (e.g. "StrictHostKeyChecking=accept-new")
 }
 
 // registryData is the JSON file structure.
@@ -176,8 +178,7 @@ func (r *MachineRegistry) Connection(name string) (Connection, error) {
 	case "local":
 		return NewLocalConnection(), nil
 	case "ssh":
-		// SSH connection not yet implemented
-		return nil, fmt.Errorf("ssh connections not yet implemented")
+		return NewSSHConnection(m), nil
 	default:
 		return nil, fmt.Errorf("unknown machine type: %s", m.Type)
 	}
@@ -0,0 +1,587 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// sshExitConnectionFailed is the exit status ssh(1) uses for its own failures
+// (unreachable host, auth failure, broken control socket). Any other non-zero
+// status comes from the remote command.
+const sshExitConnectionFailed = 255
+
+// sshControlPersist is how long the multiplexed master connection stays open
+// after the last command finishes. Subsequent commands reuse it instead of
+// paying for a fresh handshake.
+const sshControlPersist = "10m"
+
+// sshConnectTimeout bounds how long ssh waits for the TCP connect and handshake.
+const sshConnectTimeout = 10 * time.Second
+
+// processKillGracePeriod mirrors the local tmux wrapper's SIGTERM → SIGKILL delay.
+const processKillGracePeriod = 2 * time.Second
+
+// SSHConnection implements Connection for a remote machine over SSH.
+//
+// It drives the system ssh(1) client rather than an in-process SSH stack so
+// that user config (~/.ssh/config, agents, ProxyJump) keeps working. All
+// commands share one OpenSSH ControlMaster socket per machine, so only the
+// first operation pays for the handshake.
+type SSHConnection struct {
+	name     string
+	host     string // user@host
+	port     int
+	keyPath  string
+	options  []string
+	townPath string
+
+	// controlDir holds the ControlMaster sockets. Socket paths are limited to
+	// ~104 bytes on most platforms, so this must stay short.
+	controlDir string
+}
+
+// NewSSHConnection creates a connection to the given ssh machine.
+// No network activity happens until the first operation.
+func NewSSHConnection(m *Machine) *SSHConnection {
+	return &SSHConnection{
+		name:       m.Name,
+		host:       m.Host,
+		port:       m.Port,
+		keyPath:    m.KeyPath,
+		options:    append([]string(nil), m.Options...),
+		townPath:   m.TownPath,
+		controlDir: filepath.Join(os.TempDir(), fmt.Sprintf("gt-ssh-%d", os.Getuid())),
+	}
+}
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// TownPath returns the path to the town root on the remote machine.
+func (c *SSHConnection) TownPath() string {
+	return c.townPath
+}
+
+// Close tears down the multiplexed master connection, if one is running.
+// It is safe to call on a connection that was never used.
+func (c *SSHConnection) Close() error {
+	args := append(c.baseArgs(), "-O", "exit", c.host)
+	out, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		msg := string(out)
+		// No master running is not an error - nothing to close.
+		if strings.Contains(msg, "No such file or directory") ||
+			strings.Contains(msg, "Control socket connect") {
+			return nil
+		}
+		return &ConnectionError{Op: "close", Machine: c.name, Err: fmt.Errorf("%s", strings.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// baseArgs returns the ssh options shared by every invocation.
+func (c *SSHConnection) baseArgs() []string {
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=" + strconv.Itoa(int(sshConnectTimeout.Seconds())),
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(c.controlDir, "%C"),
+		"-o", "ControlPersist=" + sshControlPersist,
+	}
+	if c.port > 0 {
+		args = append(args, "-p", strconv.Itoa(c.port))
+	}
+	if c.keyPath != "" {
+		args = append(args, "-i", c.keyPath, "-o", "IdentitiesOnly=yes")
+	}
+	for _, opt := range c.options {
+		args = append(args, "-o", opt)
+	}
+	return args
+}
+
+// run executes a shell script on the remote host, feeding stdin if non-nil.
+// Stdout and stderr are returned separately. A non-nil error is either a
+// *ConnectionError (ssh itself failed) or an *exec.ExitError from the remote
+// command.
+func (c *SSHConnection) run(stdin []byte, script string) ([]byte, []byte, error) {
+	if err := os.MkdirAll(c.controlDir, 0700); err != nil {
+		return nil, nil, &ConnectionError{Op: "connect", Machine: c.name, Err: err}
+	}
+
+	args := append(c.baseArgs(), "-T", c.host, "--", script)
+	cmd := exec.Command("ssh", args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() == sshExitConnectionFailed {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return stdout.Bytes(), stderr.Bytes(), &ConnectionError{Op: "exec", Machine: c.name, Err: errors.New(msg)}
+		}
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// runCombined executes a remote script and returns interleaved stdout/stderr,
+// matching exec.Cmd.CombinedOutput semantics for the Exec* methods.
+func (c *SSHConnection) runCombined(script string) ([]byte, error) {
+	if err := os.MkdirAll(c.controlDir, 0700); err != nil {
+		return nil, &ConnectionError{Op: "connect", Machine: c.name, Err: err}
+	}
+
+	args := append(c.baseArgs(), "-T", c.host, "--", script)
+	out, err := exec.Command("ssh", args...).CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() == sshExitConnectionFailed {
+			return out, &ConnectionError{Op: "exec", Machine: c.name, Err: err}
+		}
+	}
+	return out, err
+}
+
+// fileError maps a failed remote file operation to the package error types.
+func (c *SSHConnection) fileError(err error, stderr []byte, path, op string) error {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return err
+	}
+	msg := string(stderr)
+	switch {
+	case strings.Contains(msg, "No such file or directory"):
+		return &NotFoundError{Path: path}
+	case strings.Contains(msg, "Permission denied"):
+		return &PermissionError{Path: path, Op: op}
+	}
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return fmt.Errorf("%s %s on %s: %w", op, path, c.name, err)
+	}
+	return fmt.Errorf("%s %s on %s: %s", op, path, c.name, msg)
+}
+
+// ReadFile reads the named file on the remote host.
+func (c *SSHConnection) ReadFile(path string) ([]byte, error) {
+	stdout, stderr, err := c.run(nil, "cat -- "+shellQuote(path))
+	if err != nil {
+		return nil, c.fileError(err, stderr, path, "read")
+	}
+	return stdout, nil
+}
+
+// WriteFile writes data to the named file on the remote host.
+// Like os.WriteFile, perm only applies when the file is created.
+func (c *SSHConnection) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	p := shellQuote(path)
+	script := fmt.Sprintf("if [ -e %s ]; then cat > %s; else (umask 077 && cat > %s) && chmod %04o %s; fi",
+		p, p, p, perm.Perm(), p)
+	_, stderr, err := c.run(data, script)
+	if err != nil {
+		return c.fileError(err, stderr, path, "write")
+	}
+	return nil
+}
+
+// MkdirAll creates a directory and all parent directories on the remote host.
+func (c *SSHConnection) MkdirAll(path string, perm fs.FileMode) error {
+	script := fmt.Sprintf("mkdir -p -m %04o -- %s", perm.Perm(), shellQuote(path))
+	_, stderr, err := c.run(nil, script)
+	if err != nil {
+		return c.fileError(err, stderr, path, "mkdir")
+	}
+	return nil
+}
+
+// Remove removes the named file or empty directory on the remote host.
+func (c *SSHConnection) Remove(path string) error {
+	p := shellQuote(path)
+	script := fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir -- %s; else rm -f -- %s; fi", p, p, p, p)
+	_, stderr, err := c.run(nil, script)
+	if err != nil {
+		err = c.fileError(err, stderr, path, "remove")
+		var nf *NotFoundError
+		if errors.As(err, &nf) {
+			return nil // Already gone
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveAll removes the named file or directory and any children on the remote host.
+func (c *SSHConnection) RemoveAll(path string) error {
+	_, stderr, err := c.run(nil, "rm -rf -- "+shellQuote(path))
+	if err != nil {
+		return c.fileError(err, stderr, path, "remove")
+	}
+	return nil
+}
+
+// Stat returns file info for the named file on the remote host.
+// GNU and BSD stat(1) are both supported.
+func (c *SSHConnection) Stat(path string) (FileInfo, error) {
+	stdout, stderr, err := c.run(nil, statScript(path))
+	if err != nil {
+		return nil, c.fileError(err, stderr, path, "stat")
+	}
+	fi, err := parseStatOutput(filepath.Base(path), string(stdout))
+	if err != nil {
+		return nil, fmt.Errorf("stat %s on %s: %w", path, c.name, err)
+	}
+	return fi, nil
+}
+
+// statScript returns a remote command printing "size|mode|mtime" for path,
+// with GNU or BSD stat. The variant is picked up front, not by falling back
+// on failure, so the error on stderr is the real one: GNU stat would read
+// the BSD format as a missing file.
+func statScript(path string) string {
+	p := shellQuote(path)
+	return fmt.Sprintf("if stat --version >/dev/null 2>&1; then stat -L -c '%%s|%%f|%%Y' -- %s; else stat -L -f '%%z|%%Xp|%%m' -- %s; fi", p, p)
+}
+
+// Glob returns the names of all files on the remote host matching the pattern.
+// The pattern uses filepath.Match syntax and is expanded by the remote shell.
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	script := fmt.Sprintf(`for f in %s; do if [ -e "$f" ] || [ -L "$f" ]; then printf '%%s\0' "$f"; fi; done`,
+		globToShell(pattern))
+	stdout, stderr, err := c.run(nil, script)
+	if err != nil {
+		return nil, c.fileError(err, stderr, pattern, "glob")
+	}
+
+	var matches []string
+	for _, m := range strings.Split(string(stdout), "\x00") {
+		if m != "" {
+			matches = append(matches, m)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Exists returns true if the path exists on the remote host.
+func (c *SSHConnection) Exists(path string) (bool, error) {
+	_, _, err := c.run(nil, "test -e "+shellQuote(path))
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Exec runs a command on the remote host and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.runCombined(shellJoin(cmd, args))
+}
+
+// ExecDir runs a command in the specified directory on the remote host.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.runCombined("cd " + shellQuote(dir) + " && " + shellJoin(cmd, args))
+}
+
+// ExecEnv runs a command on the remote host with additional environment variables.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("env")
+	for _, k := range keys {
+		b.WriteString(" ")
+		b.WriteString(shellQuote(k + "=" + env[k]))
+	}
+	b.WriteString(" ")
+	b.WriteString(shellJoin(cmd, args))
+	return c.runCombined(b.String())
+}
+
+// tmux runs a tmux command on the remote host and maps its errors to the
+// same sentinel errors the local tmux wrapper uses.
+func (c *SSHConnection) tmux(script string) (string, error) {
+	stdout, stderr, err := c.run(nil, script)
+	if err != nil {
+		var connErr *ConnectionError
+		if errors.As(err, &connErr) {
+			return "", err
+		}
+		msg := strings.TrimSpace(string(stderr))
+		switch {
+		case strings.Contains(msg, "no server running"),
+			strings.Contains(msg, "error connecting to"),
+			strings.Contains(msg, "server exited unexpectedly"):
+			return "", tmux.ErrNoServer
+		case strings.Contains(msg, "duplicate session"):
+			return "", tmux.ErrSessionExists
+		case strings.Contains(msg, "session not found"),
+			strings.Contains(msg, "can't find session"):
+			return "", tmux.ErrSessionNotFound
+		}
+		if msg != "" {
+			return "", fmt.Errorf("tmux on %s: %s", c.name, msg)
+		}
+		return "", fmt.Errorf("tmux on %s: %w", c.name, err)
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// TmuxNewSession creates a new tmux session on the remote host.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	script := "tmux -u new-session -d -s " + shellQuote(name)
+	if dir != "" {
+		script += " -c " + shellQuote(dir)
+	}
+	_, err := c.tmux(script)
+	return err
+}
+
+// TmuxKillSession terminates a tmux session on the remote host.
+// Like the local KillSessionWithProcesses, it signals every descendant of the
+// pane processes (deepest first) before killing the session, so agents that
+// ignore SIGHUP don't outlive it.
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	n := shellQuote("=" + name)
+	script := fmt.Sprintf(`walk() { for c in $(pgrep -P "$1" 2>/dev/null); do walk "$c"; echo "$c"; done; }
+pids=""
+for p in $(tmux list-panes -s -t %s -F '#{pane_pid}' 2>/dev/null); do pids="$pids $(walk "$p") $p"; done
+if [ -n "$pids" ]; then
+  kill -TERM $pids 2>/dev/null
+  sleep %d
+  kill -KILL $pids 2>/dev/null
+fi
+tmux kill-session -t %s 2>/dev/null
+exit 0`, n, int(processKillGracePeriod.Seconds()), n)
+	_, err := c.tmux(script)
+	return err
+}
+
+// TmuxSendKeys sends keys to a tmux session on the remote host, followed by Enter.
+// The text is sent literally and Enter is sent separately after a short
+// debounce, matching the local SendKeys behavior.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	s := shellQuote(session)
+	script := fmt.Sprintf("tmux -u send-keys -t %s -l %s && sleep 0.1 && tmux -u send-keys -t %s Enter",
+		s, shellQuote(keys), s)
+	_, err := c.tmux(script)
+	return err
+}
+
+// TmuxCapturePane captures the last N lines from a tmux pane on the remote host.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	return c.tmux(fmt.Sprintf("tmux -u capture-pane -p -t %s -S -%d", shellQuote(session), lines))
+}
+
+// TmuxHasSession returns true if the session exists on the remote host.
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	_, err := c.tmux("tmux -u has-session -t " + shellQuote("="+name))
+	if err != nil {
+		if errors.Is(err, tmux.ErrSessionNotFound) || errors.Is(err, tmux.ErrNoServer) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// TmuxListSessions returns all tmux session names on the remote host.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	out, err := c.tmux("tmux -u list-sessions -F '#{session_name}'")
+	if err != nil {
+		if errors.Is(err, tmux.ErrNoServer) {
+			return nil, nil // No server = no sessions
+		}
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// shellQuote quotes s for safe use as a single word in a POSIX shell.
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := true
+	for _, r := range s {
+		if !isShellSafe(r) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellSafe(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		strings.ContainsRune("_-./=:@%+,", r)
+}
+
+// shellJoin quotes a command and its arguments into a single shell command line.
+func shellJoin(cmd string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(cmd))
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// globToShell converts a filepath.Match pattern into a shell word that the
+// remote shell will glob-expand with the same meaning. Literal runs are
+// single-quoted; *, ? and bracket expressions are left bare. filepath's
+// negated class [^...] becomes the POSIX [!...].
+func globToShell(pattern string) string {
+	var b, lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			b.WriteString(shellQuote(lit.String()))
+			lit.Reset()
+		}
+	}
+
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		switch {
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			if inClass {
+				b.WriteString(shellQuote(string(pattern[i])))
+			} else {
+				lit.WriteByte(pattern[i])
+			}
+		case inClass:
+			switch {
+			case ch == ']':
+				inClass = false
+				b.WriteByte(ch)
+			case ch == '-':
+				b.WriteByte(ch)
+			default:
+				b.WriteString(shellQuote(string(ch)))
+			}
+		case ch == '*' || ch == '?':
+			flush()
+			b.WriteByte(ch)
+		case ch == '[':
+			flush()
+			inClass = true
+			b.WriteByte(ch)
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				b.WriteByte('!')
+				i++
+			}
+		default:
+			lit.WriteByte(ch)
+		}
+	}
+	flush()
+	if b.Len() == 0 {
+		return "''"
+	}
+	return b.String()
+}
+
+// parseStatOutput parses "size|rawmode-hex|mtime-epoch" as printed by the
+// stat invocation in Stat.
+func parseStatOutput(name, out string) (BasicFileInfo, error) {
+	fields := strings.Split(strings.TrimSpace(out), "|")
+	if len(fields) != 3 {
+		return BasicFileInfo{}, fmt.Errorf("unexpected stat output %q", out)
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing size: %w", err)
+	}
+	raw, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing mode: %w", err)
+	}
+	mtime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return BasicFileInfo{}, fmt.Errorf("parsing mtime: %w", err)
+	}
+
+	mode := unixToFileMode(uint32(raw))
+	return BasicFileInfo{
+		FileName:    name,
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// unixToFileMode converts a raw st_mode value into an fs.FileMode.
+func unixToFileMode(raw uint32) fs.FileMode {
+	mode := fs.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if raw&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if raw&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if raw&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
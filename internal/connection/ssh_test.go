@@ -0,0 +1,423 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "''"},
+		{"plain", "plain"},
+		{"/tmp/a-b_c.txt", "/tmp/a-b_c.txt"},
+		{"has space", "'has space'"},
+		{"it's", `'it'\''s'`},
+		{"$HOME", "'$HOME'"},
+		{"a;rm -rf /", "'a;rm -rf /'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGlobToShell(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"/tmp/*.go", "/tmp/*.go"},
+		{"a b/*", "'a b/'*"},
+		{"file?.txt", "file?.txt"},
+		{"[abc]x", "[abc]x"},
+		{"$x*", "'$x'*"},
+		{"[^a-z]", "[!a-z]"},
+		{`lit\*`, "'lit*'"},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := globToShell(tt.in); got != tt.want {
+			t.Errorf("globToShell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStatScriptKeepsRealError(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := &SSHConnection{name: "testbox"}
+
+	out, err := exec.Command("sh", "-c", statScript(file)).Output()
+	if err != nil {
+		t.Fatalf("stat script: %v", err)
+	}
+	if _, err := parseStatOutput("file", string(out)); err != nil {
+		t.Errorf("parseStatOutput(%q): %v", out, err)
+	}
+
+	// A path through a regular file fails with "Not a directory", which is
+	// not a missing file.
+	cmd := exec.Command("sh", "-c", statScript(filepath.Join(file, "child")))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = c.fileError(cmd.Run(), stderr.Bytes(), file+"/child", "stat")
+	if err == nil || errors.As(err, new(*NotFoundError)) {
+		t.Errorf("stat through a file: err = %v, want a non-NotFound error", err)
+	}
+
+	cmd = exec.Command("sh", "-c", statScript(filepath.Join(dir, "missing")))
+	stderr.Reset()
+	cmd.Stderr = &stderr
+	if err := c.fileError(cmd.Run(), stderr.Bytes(), "missing", "stat"); !errors.As(err, new(*NotFoundError)) {
+		t.Errorf("stat of missing file: err = %v, want NotFoundError", err)
+	}
+}
+
+func TestParseStatOutput(t *testing.T) {
+	fi, err := parseStatOutput("dir", "4096|41ed|1700000000\n")
+	if err != nil {
+		t.Fatalf("parseStatOutput: %v", err)
+	}
+	if !fi.IsDir() {
+		t.Error("expected directory")
+	}
+	if fi.Mode().Perm() != 0755 {
+		t.Errorf("Perm = %o, want 755", fi.Mode().Perm())
+	}
+	if fi.Size() != 4096 {
+		t.Errorf("Size = %d, want 4096", fi.Size())
+	}
+	if !fi.ModTime().Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("ModTime = %v", fi.ModTime())
+	}
+
+	fi, err = parseStatOutput("f", "12|81a4|1700000000")
+	if err != nil {
+		t.Fatalf("parseStatOutput: %v", err)
+	}
+	if fi.IsDir() || fi.Mode() != 0644 {
+		t.Errorf("Mode = %v, want -rw-r--r--", fi.Mode())
+	}
+
+	if _, err := parseStatOutput("f", "garbage"); err == nil {
+		t.Error("expected error for malformed output")
+	}
+}
+
+func TestUnixToFileMode(t *testing.T) {
+	tests := []struct {
+		raw  uint32
+		want fs.FileMode
+	}{
+		{0100644, 0644},
+		{0040755, fs.ModeDir | 0755},
+		{0120777, fs.ModeSymlink | 0777},
+		{0104755, fs.ModeSetuid | 0755},
+		{0041777, fs.ModeDir | fs.ModeSticky | 0777},
+	}
+	for _, tt := range tests {
+		if got := unixToFileMode(tt.raw); got != tt.want {
+			t.Errorf("unixToFileMode(%o) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrySSHConnection(t *testing.T) {
+	r, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatalf("NewMachineRegistry: %v", err)
+	}
+	if err := r.Add(&Machine{Name: "box", Type: "ssh", Host: "gt@box", TownPath: "/srv/gt"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	conn, err := r.Connection("box")
+	if err != nil {
+		t.Fatalf("Connection: %v", err)
+	}
+	sc, ok := conn.(*SSHConnection)
+	if !ok {
+		t.Fatalf("Connection returned %T, want *SSHConnection", conn)
+	}
+	if sc.Name() != "box" || sc.IsLocal() || sc.TownPath() != "/srv/gt" {
+		t.Errorf("unexpected connection: name=%q local=%v town=%q", sc.Name(), sc.IsLocal(), sc.TownPath())
+	}
+}
+
+// startTestSSHD launches a throwaway sshd on a random localhost port that
+// accepts a freshly generated key for the current user. The test is skipped
+// when sshd or ssh-keygen is not installed.
+func startTestSSHD(t *testing.T) *SSHConnection {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("sshd not supported on Windows")
+	}
+	sshd, err := exec.LookPath("sshd")
+	if err != nil {
+		for _, p := range []string{"/usr/sbin/sshd", "/usr/local/sbin/sshd"} {
+			if _, statErr := os.Stat(p); statErr == nil {
+				sshd = p
+				break
+			}
+		}
+	}
+	if sshd == "" {
+		t.Skip("sshd not installed")
+	}
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not installed")
+	}
+
+	dir := t.TempDir()
+	hostKey := filepath.Join(dir, "host_key")
+	userKey := filepath.Join(dir, "user_key")
+	for _, k := range []string{hostKey, userKey} {
+		if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", k).CombinedOutput(); err != nil {
+			t.Fatalf("ssh-keygen: %v\n%s", err, out)
+		}
+	}
+	pub, err := os.ReadFile(userKey + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authKeys := filepath.Join(dir, "authorized_keys")
+	if err := os.WriteFile(authKeys, pub, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	config := filepath.Join(dir, "sshd_config")
+	cfg := fmt.Sprintf(`Port %d
+ListenAddress 127.0.0.1
+HostKey %s
+AuthorizedKeysFile %s
+PidFile %s
+PasswordAuthentication no
+KbdInteractiveAuthentication no
+StrictModes no
+UsePAM no
+`, port, hostKey, authKeys, filepath.Join(dir, "sshd.pid"))
+	if err := os.WriteFile(config, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(sshd, "-D", "-e", "-f", config)
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sshd: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	user := os.Getenv("USER")
+	if user == "" {
+		user = "root"
+	}
+	conn := NewSSHConnection(&Machine{
+		Name:    "testbox",
+		Type:    "ssh",
+		Host:    user + "@127.0.0.1",
+		Port:    port,
+		KeyPath: userKey,
+		Options: []string{"StrictHostKeyChecking=no", "UserKnownHostsFile=/dev/null", "LogLevel=ERROR"},
+	})
+	// Keep the control socket path short and private to this test.
+	conn.controlDir = filepath.Join(os.TempDir(), fmt.Sprintf("gt-ssh-test-%d", port))
+	t.Cleanup(func() {
+		_ = conn.Close()
+		_ = os.RemoveAll(conn.controlDir)
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := conn.Exec("true"); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Skipf("sshd did not accept connections: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return conn
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	conn := startTestSSHD(t)
+	dir := t.TempDir()
+
+	sub := filepath.Join(dir, "a b", "c")
+	if err := conn.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	path := filepath.Join(sub, "it's.txt")
+	if err := conn.WriteFile(path, []byte("hello\x00world"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := conn.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "hello\x00world" {
+		t.Errorf("ReadFile = %q", data)
+	}
+
+	fi, err := conn.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Size() != 11 || fi.IsDir() || fi.Mode().Perm() != 0600 {
+		t.Errorf("Stat = size %d dir %v mode %v", fi.Size(), fi.IsDir(), fi.Mode())
+	}
+
+	matches, err := conn.Glob(filepath.Join(sub, "*.txt"))
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(matches) != 1 || matches[0] != path {
+		t.Errorf("Glob = %v, want [%s]", matches, path)
+	}
+	if matches, _ := conn.Glob(filepath.Join(sub, "*.none")); len(matches) != 0 {
+		t.Errorf("Glob with no matches = %v", matches)
+	}
+
+	if ok, err := conn.Exists(path); err != nil || !ok {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+
+	if _, err := conn.ReadFile(filepath.Join(dir, "missing")); !errors.As(err, new(*NotFoundError)) {
+		t.Errorf("ReadFile(missing) err = %v, want NotFoundError", err)
+	}
+	if _, err := conn.Stat(filepath.Join(dir, "missing")); !errors.As(err, new(*NotFoundError)) {
+		t.Errorf("Stat(missing) err = %v, want NotFoundError", err)
+	}
+
+	if err := conn.Remove(path); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := conn.Remove(path); err != nil {
+		t.Errorf("Remove of missing file should be nil, got %v", err)
+	}
+	if ok, _ := conn.Exists(path); ok {
+		t.Error("file still exists after Remove")
+	}
+	if err := conn.RemoveAll(filepath.Join(dir, "a b")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if ok, _ := conn.Exists(sub); ok {
+		t.Error("directory still exists after RemoveAll")
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	conn := startTestSSHD(t)
+	dir := t.TempDir()
+
+	out, err := conn.Exec("echo", "a b", "$HOME")
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "a b $HOME" {
+		t.Errorf("Exec output = %q", got)
+	}
+
+	out, err = conn.ExecDir(dir, "pwd")
+	if err != nil {
+		t.Fatalf("ExecDir: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != dir {
+		t.Errorf("ExecDir pwd = %q, want %q", got, dir)
+	}
+
+	out, err = conn.ExecEnv(map[string]string{"GT_TEST_VAR": "x y"}, "sh", "-c", "echo $GT_TEST_VAR")
+	if err != nil {
+		t.Fatalf("ExecEnv: %v", err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "x y" {
+		t.Errorf("ExecEnv output = %q", got)
+	}
+
+	_, err = conn.Exec("sh", "-c", "exit 3")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Exec exit 3 err = %v, want ExitError with code 3", err)
+	}
+}
+
+func TestSSHConnection_Tmux(t *testing.T) {
+	conn := startTestSSHD(t)
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+
+	name := fmt.Sprintf("gt-ssh-test-%d", os.Getpid())
+	if err := conn.TmuxNewSession(name, t.TempDir()); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.TmuxKillSession(name) })
+
+	if ok, err := conn.TmuxHasSession(name); err != nil || !ok {
+		t.Fatalf("TmuxHasSession = %v, %v", ok, err)
+	}
+	sessions, err := conn.TmuxListSessions()
+	if err != nil {
+		t.Fatalf("TmuxListSessions: %v", err)
+	}
+	found := false
+	for _, s := range sessions {
+		if s == name {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("TmuxListSessions = %v, missing %s", sessions, name)
+	}
+
+	if err := conn.TmuxSendKeys(name, "echo gt-marker-$((40+2))"); err != nil {
+		t.Fatalf("TmuxSendKeys: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := conn.TmuxCapturePane(name, 50)
+		if err != nil {
+			t.Fatalf("TmuxCapturePane: %v", err)
+		}
+		if strings.Contains(out, "gt-marker-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("marker not found in pane:\n%s", out)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := conn.TmuxKillSession(name); err != nil {
+		t.Fatalf("TmuxKillSession: %v", err)
+	}
+	if ok, _ := conn.TmuxHasSession(name); ok {
+		t.Error("session still exists after TmuxKillSession")
+	}
+}
@@ -104,6 +104,9 @@ const (
 
 	// FileQuotaJSON is the quota state file in mayor/.
 	FileQuotaJSON = "quota.json"
+
+	// FileMachinesJSON is the machine registry file in mayor/.
+	FileMachinesJSON = "machines.json"
 )
 
 // Beads configuration constants.
@@ -288,6 +291,11 @@ func MayorQuotaPath(townRoot string) string {
 	return townRoot + "/" + DirMayor + "/" + FileQuotaJSON
 }
 
+// MayorMachinesPath returns the path to mayor/machines.json within a town root.
+func MayorMachinesPath(townRoot string) string {
+	return townRoot + "/" + DirMayor + "/" + FileMachinesJSON
+}
+
 // DefaultRateLimitPatterns are the default patterns that indicate a session
 // is rate-limited. These are matched against tmux pane content.
 // Note: patterns are compiled with (?i) for case-insensitive matching.
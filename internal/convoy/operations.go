@@ -150,6 +150,11 @@ func feedNextReadyIssue(ctx context.Context, store beadsdk.Storage, townRoot, co
 		if issue.Status != "open" || issue.Assignee != "" {
 			continue
 		}
+		// Work owned by another town (hop:// or beads:// URI) is
+		// dispatched by that town.
+		if beads.IsWorkURI(issue.ID) {
+			continue
+		}
 
 		// Determine target rig from issue prefix
 		rig := rigForIssue(townRoot, issue.ID)
@@ -3,6 +3,7 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -19,6 +20,7 @@ import (
 	beadsdk "github.com/steveyegge/beads"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
@@ -26,8 +28,10 @@ import (
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
 	gitpkg "github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/mayor"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/ptyd"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/session"
@@ -45,6 +49,7 @@ type Daemon struct {
 	config        *Config
 	patrolConfig  *DaemonPatrolConfig
 	tmux          *tmux.Tmux
+	sessions      session.SessionBackend // polecat sessions; tmux unless headless
 	logger        *log.Logger
 	ctx           context.Context
 	cancel        context.CancelFunc
@@ -54,6 +59,10 @@ type Daemon struct {
 	doltServer    *DoltServerManager
 	krcPruner     *KRCPruner
 
+	// Headless session supervisor, when session_backend is "pty".
+	ptySupervisor *ptyd.Supervisor
+	ptyServer     *ptyd.Server
+
 	// Mass death detection: track recent session deaths
 	deathsMu     sync.Mutex
 	recentDeaths []sessionDeath
@@ -153,6 +162,7 @@ func New(config *Config) (*Daemon, error) {
 		config:         config,
 		patrolConfig:   patrolConfig,
 		tmux:           tmux.NewTmux(),
+		sessions:       session.NewBackend(config.TownRoot),
 		logger:         logger,
 		ctx:            ctx,
 		cancel:         cancel,
@@ -291,6 +301,12 @@ func (d *Daemon) Run() error {
 		d.logger.Printf("Dolt remotes push ticker started (interval %v)", interval)
 	}
 
+	// Host headless agent sessions if the town runs without tmux.
+	// Must be up before the first heartbeat, which may restart polecats.
+	if session.BackendName(d.config.TownRoot) == session.BackendPTY {
+		d.startPTYSupervisor()
+	}
+
 	// Note: PATCH-010 uses per-session hooks in deacon/manager.go (SetAutoRespawnHook).
 	// Global pane-died hooks don't fire reliably in tmux 3.2a, so we rely on the
 	// per-session approach which has been tested to work for continuous recovery.
@@ -445,6 +461,9 @@ func (d *Daemon) heartbeat(state *State) {
 	// branches persist indefinitely. This cleans them up periodically.
 	d.pruneStaleBranches()
 
+	// 14. Copy delivery acks back for mail forwarded to other towns.
+	d.syncRemoteMailDeliveries()
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -607,6 +626,10 @@ func (d *Daemon) ensureDeaconRunning() {
 		}
 	}
 
+	if d.sessionDown(d.getDeaconSessionName()) && d.budgetBlocksStart("deacon", budget.Scope{Role: "deacon"}) {
+		return
+	}
+
 	mgr := deacon.NewManager(d.config.TownRoot)
 
 	if err := mgr.Start(""); err != nil {
@@ -786,6 +809,10 @@ func (d *Daemon) ensureWitnessRunning(rigName string) {
 		_ = t.KillSession(mgr.SessionName())
 	}
 
+	if d.sessionDown(mgr.SessionName()) && d.budgetBlocksStart("witness for "+rigName, budget.Scope{Rig: rigName, Role: "witness"}) {
+		return
+	}
+
 	if err := mgr.Start(false, "", nil); err != nil {
 		if err == witness.ErrAlreadyRunning {
 			// Already running - this is the expected case
@@ -836,6 +863,10 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 		_ = t.KillSession(mgr.SessionName())
 	}
 
+	if d.sessionDown(mgr.SessionName()) && d.budgetBlocksStart("refinery for "+rigName, budget.Scope{Rig: rigName, Role: "refinery"}) {
+		return
+	}
+
 	if err := mgr.Start(false, ""); err != nil {
 		if err == refinery.ErrAlreadyRunning {
 			// Already running - this is the expected case when fix is working
@@ -1119,6 +1150,13 @@ func (d *Daemon) shutdown(state *State) error { //nolint:unparam // error return
 		d.logger.Println("KRC pruner stopped")
 	}
 
+	// Stop the headless session supervisor (kills its sessions)
+	if d.ptyServer != nil {
+		_ = d.ptyServer.Close()
+		d.ptySupervisor.Close()
+		d.logger.Println("PTY session supervisor stopped")
+	}
+
 	// Stop Dolt server if we're managing it
 	if d.doltServer != nil && d.doltServer.IsEnabled() && !d.doltServer.IsExternal() {
 		if err := d.doltServer.Stop(); err != nil {
@@ -1431,8 +1469,8 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// Build the expected tmux session name
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
 
-	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	// Check if the session exists
+	sessionAlive, err := d.sessions.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -1491,7 +1529,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// TOCTOU guard: re-verify session is still dead before restarting.
 	// Between the initial check and now, the session may have been restarted
 	// by another heartbeat cycle, witness, or the polecat itself.
-	sessionRevived, err := d.tmux.HasSession(sessionName)
+	sessionRevived, err := d.sessions.HasSession(sessionName)
 	if err == nil && sessionRevived {
 		return // Session came back - no restart needed
 	}
@@ -1503,6 +1541,11 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	// Track this death for mass death detection
 	d.recordSessionDeath(sessionName)
 
+	// Budget guard: an exhausted rig/role/convoy budget stops respawns.
+	if d.budgetBlocksStart("polecat "+rigName+"/"+polecatName, budget.Scope{Rig: rigName, Role: "polecat", Bead: info.HookBead}) {
+		return
+	}
+
 	// Auto-restart the polecat
 	if err := d.restartPolecatSession(rigName, polecatName, sessionName); err != nil {
 		d.logger.Printf("Error restarting polecat %s/%s: %v", rigName, polecatName, err)
@@ -1513,6 +1556,29 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	}
 }
 
+// budgetBlocksStart reports whether an exhausted rig, role or convoy budget
+// stops the daemon from starting the agent described by what. Enforce
+// escalates the breach once per budget period.
+func (d *Daemon) budgetBlocksStart(what string, scope budget.Scope) bool {
+	breach, err := budget.Enforce(d.config.TownRoot, scope)
+	if err != nil {
+		d.logger.Printf("Warning: budget check for %s: %v", what, err)
+	}
+	if breach != nil {
+		d.logger.Printf("Not starting %s: %s", what, breach)
+		return true
+	}
+	return false
+}
+
+// sessionDown reports whether sessionName is known not to exist. Budgets are
+// only checked for agents that are down, so running agents are left alone
+// and the ledger isn't read on every heartbeat.
+func (d *Daemon) sessionDown(sessionName string) bool {
+	running, err := d.tmux.HasSession(sessionName)
+	return err == nil && !running
+}
+
 // recordSessionDeath records a session death and checks for mass death pattern.
 func (d *Daemon) recordSessionDeath(sessionName string) {
 	d.deathsMu.Lock()
@@ -1590,12 +1656,6 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 	// Pre-sync workspace (ensure beads are current)
 	d.syncWorkspace(workDir)
 
-	// Create new tmux session
-	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
-		return fmt.Errorf("creating session: %w", err)
-	}
-
 	// Set environment variables using centralized AgentEnv
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:      "polecat",
@@ -1604,49 +1664,80 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 		TownRoot:  d.config.TownRoot,
 	})
 
-	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
+	// Launch Claude with environment exported inline as the pane command.
+	// Pass rigPath so rig agent settings are honored (not town-level defaults)
+	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
+	if err := d.sessions.NewSessionWithCommand(sessionName, workDir, startCmd); err != nil {
+		if errors.Is(err, tmux.ErrSessionExists) {
+			// Another agent restarted it between our check and now.
+			return nil
+		}
+		return fmt.Errorf("creating session: %w", err)
+	}
+
+	// Set all env vars in the session (for debugging and respawns)
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = d.sessions.SetEnvironment(sessionName, k, v)
 	}
 
-	// Set GT_AGENT in tmux session env so tools querying tmux environment
+	// Set GT_AGENT in the session env so tools querying it
 	// (e.g., witness patrol) can detect non-Claude agents.
 	// BuildStartupCommand sets GT_AGENT in process env via exec env, but that
 	// isn't visible to tmux show-environment.
 	rc := config.ResolveRoleAgentConfig("polecat", d.config.TownRoot, rigPath)
 	if rc.ResolvedAgent != "" {
-		_ = d.tmux.SetEnvironment(sessionName, "GT_AGENT", rc.ResolvedAgent)
+		_ = d.sessions.SetEnvironment(sessionName, "GT_AGENT", rc.ResolvedAgent)
 	}
 
 	// Set GT_PROCESS_NAMES for accurate liveness detection of custom agents.
 	processNames := config.ResolveProcessNames(rc.ResolvedAgent, rc.Command)
-	_ = d.tmux.SetEnvironment(sessionName, "GT_PROCESS_NAMES", strings.Join(processNames, ","))
+	_ = d.sessions.SetEnvironment(sessionName, "GT_PROCESS_NAMES", strings.Join(processNames, ","))
 
 	// Apply theme
 	theme := tmux.AssignTheme(rigName)
-	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
+	_ = session.ApplyTheme(d.sessions, sessionName, theme, rigName, polecatName, "polecat")
 
 	// Set pane-died hook for future crash detection
 	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
-	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
-
-	// Launch Claude with environment exported inline
-	// Pass rigPath so rig agent settings are honored (not town-level defaults)
-	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
-		return fmt.Errorf("sending startup command: %w", err)
-	}
+	_ = d.sessions.SetPaneDiedHook(sessionName, agentID)
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated restarts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := d.sessions.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
 		// Non-fatal - Claude might still start
 	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	_ = d.sessions.AcceptBypassPermissionsWarning(sessionName)
 
 	return nil
 }
 
+// startPTYSupervisor serves headless agent sessions on the town's PTY
+// socket. Panes with a pane-died hook are logged with gt log crash, the
+// same command the tmux hook runs.
+func (d *Daemon) startPTYSupervisor() {
+	sup := ptyd.NewSupervisor()
+	sup.OnPaneDied = func(death ptyd.PaneDeath) {
+		cmd := exec.Command(d.gtPath, "log", "crash", //nolint:gosec // G204: args are session metadata
+			"--agent", death.AgentID,
+			"--session", death.Session,
+			"--exit-code", strconv.Itoa(death.ExitCode))
+		cmd.Dir = d.config.TownRoot
+		if out, err := cmd.CombinedOutput(); err != nil {
+			d.logger.Printf("Warning: failed to log crash of %s: %v: %s", death.Session, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	socket := ptyd.SocketPath(d.config.TownRoot)
+	srv, err := ptyd.Serve(socket, sup)
+	if err != nil {
+		d.logger.Printf("Warning: failed to start PTY session supervisor: %v", err)
+		return
+	}
+	d.ptySupervisor = sup
+	d.ptyServer = srv
+	d.logger.Printf("PTY session supervisor listening on %s", socket)
+}
+
 // notifyWitnessOfCrashedPolecat notifies the witness when a polecat restart fails.
 func (d *Daemon) notifyWitnessOfCrashedPolecat(rigName, polecatName, hookBead string, restartErr error) {
 	witnessAddr := rigName + "/witness"
@@ -1726,3 +1817,21 @@ func (d *Daemon) pruneStaleBranches() {
 	// Also prune in the town root itself (mayor clone)
 	pruneInDir(d.config.TownRoot, "town-root")
 }
+
+// syncRemoteMailDeliveries copies delivery acks for mail forwarded to towns
+// on other machines back onto the local copies. Towns without a machine
+// registry have nothing to sync.
+func (d *Daemon) syncRemoteMailDeliveries() {
+	if _, err := os.Stat(constants.MayorMachinesPath(d.config.TownRoot)); err != nil {
+		return
+	}
+
+	router := mail.NewRouterWithTownRoot(d.config.TownRoot, d.config.TownRoot)
+	acked, err := router.SyncRemoteDeliveries()
+	if acked > 0 {
+		d.logger.Printf("Remote mail: %d delivery ack(s) synced", acked)
+	}
+	if err != nil {
+		d.logger.Printf("Warning: %v", err)
+	}
+}
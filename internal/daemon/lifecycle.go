@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -356,6 +357,11 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 		}
 	}
 
+	// An exhausted rig or role budget stops respawns of every role.
+	if d.budgetBlocksStart(identity, budget.Scope{Rig: parsed.RigName, Role: parsed.RoleType}) {
+		return fmt.Errorf("cannot restart session: budget exhausted")
+	}
+
 	// Determine working directory
 	workDir := d.getWorkDir(config, parsed)
 	if workDir == "" {
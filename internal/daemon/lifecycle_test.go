@@ -6,7 +6,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/budget"
+	"github.com/steveyegge/gastown/internal/config"
 )
 
 // testDaemon creates a minimal Daemon for testing.
@@ -433,3 +438,40 @@ func TestSyncWorkspace_DirtyTreeAutoStash(t *testing.T) {
 		t.Errorf("expected 0 sync failures after successful sync, got %d", got)
 	}
 }
+
+func TestRestartSession_RoleBudgetExhausted(t *testing.T) {
+	d, _ := testDaemonWithTown(t, "budget-town")
+	t.Setenv("HOME", t.TempDir())
+
+	settings := config.NewTownSettings()
+	settings.Budgets = &config.BudgetConfig{
+		Roles: map[string]*config.BudgetLimit{"crew": {MonthlyUSD: 1}},
+	}
+	if err := config.SaveTownSettings(config.TownSettingsPath(d.config.TownRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	entry := `{"role":"crew","rig":"gastown","cost_usd":5,"ended_at":"` + now.Format(time.RFC3339) + `"}` + "\n"
+	if err := os.MkdirAll(filepath.Dir(budget.CostsLogPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(budget.CostsLogPath(), []byte(entry), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Mark the breach as already escalated so the test doesn't run gt escalate.
+	escalated := `{"role:crew:monthly:` + now.Format("2006-01") + `":"` + now.Format(time.RFC3339) + `"}`
+	if err := os.MkdirAll(filepath.Join(d.config.TownRoot, ".runtime"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d.config.TownRoot, ".runtime", "budget-escalations.json"), []byte(escalated), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := d.restartSession("gt-crew-max", "gastown-crew-max")
+	if err == nil || !strings.Contains(err.Error(), "budget exhausted") {
+		t.Fatalf("restartSession = %v, want a budget refusal", err)
+	}
+	if d.budgetBlocksStart("witness for gastown", budget.Scope{Rig: "gastown", Role: "witness"}) {
+		t.Error("the crew budget should not block the witness")
+	}
+}
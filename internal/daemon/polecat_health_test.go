@@ -65,10 +65,11 @@ func TestCheckPolecatHealth_SkipsSpawning(t *testing.T) {
 
 	var logBuf strings.Builder
 	d := &Daemon{
-		config: &Config{TownRoot: t.TempDir()},
-		logger: log.New(&logBuf, "", 0),
-		tmux:   tmux.NewTmux(),
-		bdPath: bdPath,
+		config:   &Config{TownRoot: t.TempDir()},
+		logger:   log.New(&logBuf, "", 0),
+		tmux:     tmux.NewTmux(),
+		sessions: tmux.NewTmux(),
+		bdPath:   bdPath,
 	}
 
 	d.checkPolecatHealth("myr", "mycat")
@@ -99,10 +100,11 @@ func TestCheckPolecatHealth_DetectsCrashedPolecat(t *testing.T) {
 
 	var logBuf strings.Builder
 	d := &Daemon{
-		config: &Config{TownRoot: t.TempDir()},
-		logger: log.New(&logBuf, "", 0),
-		tmux:   tmux.NewTmux(),
-		bdPath: bdPath,
+		config:   &Config{TownRoot: t.TempDir()},
+		logger:   log.New(&logBuf, "", 0),
+		tmux:     tmux.NewTmux(),
+		sessions: tmux.NewTmux(),
+		bdPath:   bdPath,
 	}
 
 	d.checkPolecatHealth("myr", "mycat")
@@ -130,10 +132,11 @@ func TestCheckPolecatHealth_SpawningGuardExpires(t *testing.T) {
 
 	var logBuf strings.Builder
 	d := &Daemon{
-		config: &Config{TownRoot: t.TempDir()},
-		logger: log.New(&logBuf, "", 0),
-		tmux:   tmux.NewTmux(),
-		bdPath: bdPath,
+		config:   &Config{TownRoot: t.TempDir()},
+		logger:   log.New(&logBuf, "", 0),
+		tmux:     tmux.NewTmux(),
+		sessions: tmux.NewTmux(),
+		bdPath:   bdPath,
 	}
 
 	d.checkPolecatHealth("myr", "mycat")
@@ -166,10 +169,11 @@ func TestCheckPolecatHealth_DBStateOverridesDescription(t *testing.T) {
 
 	var logBuf strings.Builder
 	d := &Daemon{
-		config: &Config{TownRoot: t.TempDir()},
-		logger: log.New(&logBuf, "", 0),
-		tmux:   tmux.NewTmux(),
-		bdPath: bdPath,
+		config:   &Config{TownRoot: t.TempDir()},
+		logger:   log.New(&logBuf, "", 0),
+		tmux:     tmux.NewTmux(),
+		sessions: tmux.NewTmux(),
+		bdPath:   bdPath,
 	}
 
 	d.checkPolecatHealth("myr", "mycat")
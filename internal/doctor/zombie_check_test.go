@@ -1,6 +1,8 @@
 package doctor
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -64,7 +66,13 @@ func TestZombieSessionCheck_FixProtectsCrewSessions(t *testing.T) {
 		"gt-gastown-witness",  // Would be killed (if real)
 	}
 
-	ctx := &CheckContext{TownRoot: t.TempDir()}
+	// Fix logs session deaths to the town of the working directory.
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+	ctx := &CheckContext{TownRoot: townRoot}
 
 	// Fix should skip crew sessions due to safeguard
 	// (We can't fully test this without mocking tmux, but the safeguard is in place)
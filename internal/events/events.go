@@ -54,6 +54,9 @@ const (
 	TypeSessionDeath = "session_death" // Feed-visible session termination
 	TypeMassDeath    = "mass_death"    // Multiple sessions died in short window
 
+	// Agent fallback events (session start moved to the next agent in a role's chain)
+	TypeAgentFallback = "agent_fallback"
+
 	// Witness patrol events
 	TypePatrolStarted   = "patrol_started"
 	TypePolecatChecked  = "polecat_checked"
@@ -313,6 +316,22 @@ func MassDeathPayload(count int, window string, sessions []string, possibleCause
 	return p
 }
 
+// AgentFallbackPayload creates a payload for agent fallback events.
+// session: session being started
+// role: Gas Town role (e.g., "polecat")
+// from: agent that was skipped
+// to: agent tried next
+// reason: why from was skipped (e.g., "binary_missing", "rate_limited", "ready_timeout")
+func AgentFallbackPayload(session, role, from, to, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"role":    role,
+		"from":    from,
+		"to":      to,
+		"reason":  reason,
+	}
+}
+
 // SessionPayload creates a payload for session start/end events.
 // sessionID: Claude Code session UUID
 // role: Gas Town role (e.g., "gastown/crew/joe", "deacon")
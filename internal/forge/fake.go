@@ -0,0 +1,158 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fake is an in-memory ForgeProvider for tests. Like a forge with required
+// checks, it refuses to merge a pull request whose checks are missing,
+// pending or failing, unless NoCI is set.
+type Fake struct {
+	mu       sync.Mutex
+	prs      map[int]*PullRequest
+	checks   map[int]Checks
+	comments map[int][]string
+	next     int
+
+	// MergeFunc, if set, performs the merge and returns the merge commit,
+	// e.g. by merging the head branch in a test repository.
+	MergeFunc func(pr *PullRequest, opts MergeOptions) (string, error)
+
+	// NoCI makes the fake a forge without CI: pull requests with no checks
+	// merge.
+	NoCI bool
+}
+
+// NewFake returns an empty Fake.
+func NewFake() *Fake {
+	return &Fake{
+		prs:      make(map[int]*PullRequest),
+		checks:   make(map[int]Checks),
+		comments: make(map[int][]string),
+		next:     1,
+	}
+}
+
+// Name returns "fake".
+func (f *Fake) Name() string { return "fake" }
+
+// OpenPR opens a pull request, or returns the open one for the same head
+// and base.
+func (f *Fake) OpenPR(ctx context.Context, opts OpenPROptions) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, pr := range f.prs {
+		if pr.State == PROpen && pr.Head == opts.Head && pr.Base == opts.Base {
+			cp := *pr
+			return &cp, nil
+		}
+	}
+	pr := &PullRequest{
+		Number:    f.next,
+		URL:       fmt.Sprintf("https://forge.test/pulls/%d", f.next),
+		Title:     opts.Title,
+		Head:      opts.Head,
+		Base:      opts.Base,
+		State:     PROpen,
+		CreatedAt: time.Now(),
+	}
+	f.prs[pr.Number] = pr
+	f.next++
+	cp := *pr
+	return &cp, nil
+}
+
+// GetPR returns a pull request by number.
+func (f *Fake) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, ok := f.prs[number]
+	if !ok {
+		return nil, &APIError{Status: 404, Message: "Not Found"}
+	}
+	cp := *pr
+	return &cp, nil
+}
+
+// SetCreatedAt backdates a pull request.
+func (f *Fake) SetCreatedAt(number int, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if pr, ok := f.prs[number]; ok {
+		pr.CreatedAt = at
+	}
+}
+
+// SetChecks sets the CI status Checks reports for a pull request.
+func (f *Fake) SetChecks(number int, state CheckState, checks ...Check) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.checks[number] = Checks{State: state, Checks: checks}
+}
+
+// Checks returns the status set by SetChecks, or CheckNone.
+func (f *Fake) Checks(ctx context.Context, number int) (*Checks, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.prs[number]; !ok {
+		return nil, &APIError{Status: 404, Message: "Not Found"}
+	}
+	c, ok := f.checks[number]
+	if !ok {
+		c = Checks{State: CheckNone}
+	}
+	return &c, nil
+}
+
+// Merge merges an open pull request whose checks pass, or that has none
+// on a NoCI forge.
+func (f *Fake) Merge(ctx context.Context, number int, opts MergeOptions) (*PullRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pr, ok := f.prs[number]
+	if !ok {
+		return nil, &APIError{Status: 404, Message: "Not Found"}
+	}
+	if pr.State != PROpen {
+		return nil, fmt.Errorf("%w: pull request is %s", ErrNotMergeable, pr.State)
+	}
+	c, ok := f.checks[number]
+	if !ok {
+		c.State = CheckNone
+	}
+	if c.State != CheckSuccess && !(c.State == CheckNone && f.NoCI) {
+		return nil, fmt.Errorf("%w: checks are %s", ErrNotMergeable, c.State)
+	}
+	sha := fmt.Sprintf("fake-merge-%d", number)
+	if f.MergeFunc != nil {
+		var err error
+		if sha, err = f.MergeFunc(pr, opts); err != nil {
+			return nil, err
+		}
+	}
+	pr.State = PRMerged
+	pr.MergeCommit = sha
+	cp := *pr
+	return &cp, nil
+}
+
+// Comment records a comment on a pull request.
+func (f *Fake) Comment(ctx context.Context, number int, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.prs[number]; !ok {
+		return &APIError{Status: 404, Message: "Not Found"}
+	}
+	f.comments[number] = append(f.comments[number], body)
+	return nil
+}
+
+// Comments returns the comments posted on a pull request.
+func (f *Fake) Comments(number int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.comments[number]...)
+}
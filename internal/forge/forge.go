@@ -0,0 +1,346 @@
+// Package forge talks to code forges (GitHub, GitLab) so the refinery can
+// land merge requests through pull requests instead of pushing to the target
+// branch directly.
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Forge types, as used in merge_queue.forge.type.
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+)
+
+// Merge methods, as used in merge_queue.forge.merge_method.
+const (
+	MergeSquash = "squash"
+	MergeCommit = "merge"
+	MergeRebase = "rebase"
+)
+
+// PRState is the state of a pull request.
+type PRState string
+
+const (
+	PROpen   PRState = "open"
+	PRClosed PRState = "closed"
+	PRMerged PRState = "merged"
+)
+
+// PullRequest is a pull request (GitLab: merge request) on a forge.
+type PullRequest struct {
+	Number      int     `json:"number"`
+	URL         string  `json:"url"`
+	Title       string  `json:"title"`
+	Head        string  `json:"head"`
+	HeadSHA     string  `json:"head_sha,omitempty"`
+	Base        string  `json:"base"`
+	State       PRState `json:"state"`
+	MergeCommit string  `json:"merge_commit,omitempty"`
+
+	// CreatedAt is when the pull request was opened; zero if the forge
+	// did not say.
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// CheckState is the state of a CI check, or of all of a commit's checks.
+type CheckState string
+
+const (
+	CheckNone    CheckState = "none" // the commit has no checks (yet)
+	CheckPending CheckState = "pending"
+	CheckSuccess CheckState = "success"
+	CheckFailure CheckState = "failure"
+)
+
+// Check is one CI check on a pull request's head commit.
+type Check struct {
+	Name  string     `json:"name"`
+	State CheckState `json:"state"`
+	URL   string     `json:"url,omitempty"`
+}
+
+// Checks is the CI status of a pull request's head commit.
+type Checks struct {
+	State  CheckState `json:"state"`
+	Checks []Check    `json:"checks,omitempty"`
+}
+
+// Failed returns the failed checks.
+func (c Checks) Failed() []Check {
+	var failed []Check
+	for _, ch := range c.Checks {
+		if ch.State == CheckFailure {
+			failed = append(failed, ch)
+		}
+	}
+	return failed
+}
+
+// summarizeChecks combines checks into one state: any failure fails, then
+// any pending check is pending. No checks at all is CheckNone.
+func summarizeChecks(checks []Check) Checks {
+	result := Checks{State: CheckNone, Checks: checks}
+	for _, ch := range checks {
+		switch {
+		case ch.State == CheckFailure:
+			result.State = CheckFailure
+		case ch.State == CheckPending && result.State != CheckFailure:
+			result.State = CheckPending
+		case result.State == CheckNone:
+			result.State = CheckSuccess
+		}
+	}
+	return result
+}
+
+// OpenPROptions describes a pull request to open.
+type OpenPROptions struct {
+	Head  string // source branch
+	Base  string // target branch
+	Title string
+	Body  string
+}
+
+// MergeOptions controls how a pull request is merged.
+type MergeOptions struct {
+	Method        string // MergeSquash (default), MergeCommit or MergeRebase
+	SHA           string // if set, merge only if the head is still this commit
+	CommitTitle   string
+	CommitMessage string
+}
+
+// ErrNotMergeable is returned by Merge when the forge refuses the merge:
+// required checks or reviews are missing, there are conflicts, or the head
+// moved past MergeOptions.SHA. Retrying later may succeed.
+var ErrNotMergeable = errors.New("pull request is not mergeable")
+
+// ForgeProvider is the set of pull request operations the refinery needs from
+// a code forge. GitHub and GitLab implement it over their REST APIs; Fake
+// implements it in memory for tests.
+type ForgeProvider interface {
+	// Name returns the forge type (e.g. "github").
+	Name() string
+
+	// OpenPR opens a pull request from opts.Head into opts.Base, or returns
+	// the open pull request that already exists for them.
+	OpenPR(ctx context.Context, opts OpenPROptions) (*PullRequest, error)
+
+	// GetPR returns a pull request by number.
+	GetPR(ctx context.Context, number int) (*PullRequest, error)
+
+	// Checks returns the CI status of a pull request's head commit.
+	Checks(ctx context.Context, number int) (*Checks, error)
+
+	// Merge merges a pull request and returns it with MergeCommit set.
+	Merge(ctx context.Context, number int, opts MergeOptions) (*PullRequest, error)
+
+	// Comment posts a comment on a pull request.
+	Comment(ctx context.Context, number int, body string) error
+}
+
+var (
+	_ ForgeProvider = (*GitHub)(nil)
+	_ ForgeProvider = (*GitLab)(nil)
+	_ ForgeProvider = (*Fake)(nil)
+)
+
+// Config is a rig's forge configuration (merge_queue.forge). Unset fields
+// are inferred from the rig's git remote by Resolve.
+type Config struct {
+	// Type is the forge type: "github" or "gitlab".
+	Type string `json:"type,omitempty"`
+
+	// Repo is the repository path: "owner/repo", or "group/subgroup/project"
+	// on GitLab.
+	Repo string `json:"repo,omitempty"`
+
+	// APIURL is the REST API base URL, for self-hosted forges.
+	APIURL string `json:"api_url,omitempty"`
+
+	// TokenEnv names the environment variable holding the API token.
+	// Defaults to GITHUB_TOKEN (then GH_TOKEN, then gh auth token) or
+	// GITLAB_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// MergeMethod is how pull requests are merged: "squash" (default),
+	// "merge" or "rebase".
+	MergeMethod string `json:"merge_method,omitempty"`
+
+	// NoChecks declares that the repository runs no CI on pull requests,
+	// so a pull request with no checks can merge. Otherwise no checks
+	// means CI has not started yet.
+	NoChecks bool `json:"no_checks,omitempty"`
+
+	// ChecksGrace is how long after a pull request opens its checks are
+	// expected to appear, as a duration string (default "10m"). Until then
+	// a pull request with no checks is waiting on CI; after it, the wait is
+	// reported as CI missing.
+	ChecksGrace string `json:"checks_grace,omitempty"`
+}
+
+// DefaultChecksGrace is the default ChecksGrace.
+const DefaultChecksGrace = 10 * time.Minute
+
+// ChecksGraceDuration returns ChecksGrace, or DefaultChecksGrace if unset
+// or invalid.
+func (c Config) ChecksGraceDuration() time.Duration {
+	if d, err := time.ParseDuration(c.ChecksGrace); err == nil && d >= 0 {
+		return d
+	}
+	return DefaultChecksGrace
+}
+
+// Validate checks the fields that are set.
+func (c Config) Validate() error {
+	switch c.Type {
+	case "", TypeGitHub, TypeGitLab:
+	default:
+		return fmt.Errorf("unknown forge type %q (want %s or %s)", c.Type, TypeGitHub, TypeGitLab)
+	}
+	switch c.MergeMethod {
+	case "", MergeSquash, MergeCommit, MergeRebase:
+	default:
+		return fmt.Errorf("unknown merge_method %q (want %s, %s or %s)", c.MergeMethod, MergeSquash, MergeCommit, MergeRebase)
+	}
+	if c.ChecksGrace != "" {
+		if d, err := time.ParseDuration(c.ChecksGrace); err != nil || d < 0 {
+			return fmt.Errorf("invalid checks_grace %q", c.ChecksGrace)
+		}
+	}
+	if c.APIURL != "" {
+		if u, err := url.Parse(c.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid api_url %q", c.APIURL)
+		}
+	}
+	return nil
+}
+
+// Resolve returns c with Type, Repo, APIURL and MergeMethod filled in, using
+// gitURL (the rig's remote) for any that are unset.
+func (c Config) Resolve(gitURL string) (Config, error) {
+	if err := c.Validate(); err != nil {
+		return c, err
+	}
+	host := ""
+	if c.Type == "" || c.Repo == "" || c.APIURL == "" {
+		h, repo, err := ParseRemote(gitURL)
+		if err != nil {
+			return c, err
+		}
+		host = h
+		if c.Repo == "" {
+			c.Repo = repo
+		}
+	}
+	if c.Type == "" {
+		switch {
+		case host == "github.com" || strings.HasPrefix(host, "github."):
+			c.Type = TypeGitHub
+		case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+			c.Type = TypeGitLab
+		default:
+			return c, fmt.Errorf("cannot tell the forge type of %s; set merge_queue.forge.type", host)
+		}
+	}
+	if c.APIURL == "" {
+		switch {
+		case c.Type == TypeGitHub && host == "github.com":
+			c.APIURL = "https://api.github.com"
+		case c.Type == TypeGitHub:
+			c.APIURL = "https://" + host + "/api/v3"
+		default:
+			c.APIURL = "https://" + host + "/api/v4"
+		}
+	}
+	if c.MergeMethod == "" {
+		c.MergeMethod = MergeSquash
+	}
+	return c, nil
+}
+
+// Token returns the API token for a resolved config, or "" if none is set.
+func (c Config) Token() string {
+	if c.TokenEnv != "" {
+		return os.Getenv(c.TokenEnv)
+	}
+	switch c.Type {
+	case TypeGitHub:
+		for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+			if t := os.Getenv(env); t != "" {
+				return t
+			}
+		}
+		if out, err := exec.Command("gh", "auth", "token").Output(); err == nil {
+			return strings.TrimSpace(string(out))
+		}
+	case TypeGitLab:
+		return os.Getenv("GITLAB_TOKEN")
+	}
+	return ""
+}
+
+// New returns the provider for cfg, inferring unset fields from gitURL.
+func New(cfg Config, gitURL string) (ForgeProvider, error) {
+	cfg, err := cfg.Resolve(gitURL)
+	if err != nil {
+		return nil, err
+	}
+	token := cfg.Token()
+	if token == "" {
+		return nil, fmt.Errorf("no %s API token (set %s)", cfg.Type, tokenHint(cfg))
+	}
+	switch cfg.Type {
+	case TypeGitHub:
+		return NewGitHub(cfg.APIURL, cfg.Repo, token)
+	default:
+		return NewGitLab(cfg.APIURL, cfg.Repo, token)
+	}
+}
+
+func tokenHint(cfg Config) string {
+	switch {
+	case cfg.TokenEnv != "":
+		return cfg.TokenEnv
+	case cfg.Type == TypeGitHub:
+		return "GITHUB_TOKEN or run gh auth login"
+	default:
+		return "GITLAB_TOKEN"
+	}
+}
+
+// ParseRemote splits a git remote URL into its host and repository path.
+// It accepts HTTPS (https://host/owner/repo.git), SCP-style SSH
+// (git@host:owner/repo.git) and ssh:// URLs.
+func ParseRemote(gitURL string) (host, repo string, err error) {
+	s := strings.TrimSpace(gitURL)
+	switch {
+	case strings.Contains(s, "://"):
+		u, perr := url.Parse(s)
+		if perr != nil {
+			return "", "", fmt.Errorf("parsing remote %q: %w", gitURL, perr)
+		}
+		host, repo = u.Hostname(), u.Path
+	case strings.Contains(s, ":"):
+		// git@host:owner/repo.git
+		userHost, path, _ := strings.Cut(s, ":")
+		if i := strings.LastIndex(userHost, "@"); i >= 0 {
+			userHost = userHost[i+1:]
+		}
+		host, repo = userHost, path
+	}
+	repo = strings.TrimSuffix(strings.Trim(repo, "/"), ".git")
+	if host == "" || !strings.Contains(repo, "/") {
+		return "", "", fmt.Errorf("cannot parse forge repository from remote %q", gitURL)
+	}
+	return host, repo, nil
+}
@@ -0,0 +1,143 @@
+package forge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url, host, repo string
+	}{
+		{"https://github.com/steveyegge/gastown.git", "github.com", "steveyegge/gastown"},
+		{"git@github.com:steveyegge/gastown.git", "github.com", "steveyegge/gastown"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/project.git", "gitlab.example.com", "group/sub/project"},
+		{"https://gitlab.com/group/project", "gitlab.com", "group/project"},
+	}
+	for _, tt := range tests {
+		host, repo, err := ParseRemote(tt.url)
+		if err != nil || host != tt.host || repo != tt.repo {
+			t.Errorf("ParseRemote(%q) = %q, %q, %v; want %q, %q", tt.url, host, repo, err, tt.host, tt.repo)
+		}
+	}
+	for _, bad := range []string{"", "/local/path/repo", "https://github.com/onlyowner"} {
+		if _, _, err := ParseRemote(bad); err == nil {
+			t.Errorf("ParseRemote(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConfigResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		remote string
+		want   Config
+	}{
+		{"github.com", Config{}, "git@github.com:o/r.git",
+			Config{Type: TypeGitHub, Repo: "o/r", APIURL: "https://api.github.com", MergeMethod: MergeSquash}},
+		{"github enterprise", Config{}, "https://github.corp.example/o/r.git",
+			Config{Type: TypeGitHub, Repo: "o/r", APIURL: "https://github.corp.example/api/v3", MergeMethod: MergeSquash}},
+		{"gitlab.com", Config{MergeMethod: MergeCommit}, "https://gitlab.com/g/s/p.git",
+			Config{Type: TypeGitLab, Repo: "g/s/p", APIURL: "https://gitlab.com/api/v4", MergeMethod: MergeCommit}},
+		{"self-hosted gitlab", Config{Type: TypeGitLab}, "git@code.example.com:g/p.git",
+			Config{Type: TypeGitLab, Repo: "g/p", APIURL: "https://code.example.com/api/v4", MergeMethod: MergeSquash}},
+		{"fully configured", Config{Type: TypeGitHub, Repo: "o/r", APIURL: "http://localhost:1"}, "",
+			Config{Type: TypeGitHub, Repo: "o/r", APIURL: "http://localhost:1", MergeMethod: MergeSquash}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.Resolve(tt.remote)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Config{}).Resolve("git@code.example.com:g/p.git"); err == nil {
+		t.Error("expected error for unknown forge host without a type")
+	}
+	if _, err := (Config{Type: "bitbucket"}).Resolve("git@github.com:o/r.git"); err == nil {
+		t.Error("expected error for unknown forge type")
+	}
+	if err := (Config{ChecksGrace: "soon"}).Validate(); err == nil {
+		t.Error("expected error for invalid checks_grace")
+	}
+	if d := (Config{ChecksGrace: "2m"}).ChecksGraceDuration(); d != 2*time.Minute {
+		t.Errorf("ChecksGraceDuration = %v, want 2m", d)
+	}
+	if d := (Config{}).ChecksGraceDuration(); d != DefaultChecksGrace {
+		t.Errorf("default ChecksGraceDuration = %v, want %v", d, DefaultChecksGrace)
+	}
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	tests := []struct {
+		states []CheckState
+		want   CheckState
+	}{
+		{nil, CheckNone},
+		{[]CheckState{CheckSuccess, CheckSuccess}, CheckSuccess},
+		{[]CheckState{CheckSuccess, CheckPending}, CheckPending},
+		{[]CheckState{CheckFailure, CheckPending}, CheckFailure},
+		{[]CheckState{CheckPending, CheckFailure, CheckSuccess}, CheckFailure},
+	}
+	for _, tt := range tests {
+		var checks []Check
+		for _, s := range tt.states {
+			checks = append(checks, Check{Name: string(s), State: s})
+		}
+		if got := summarizeChecks(checks).State; got != tt.want {
+			t.Errorf("summarizeChecks(%v) = %s, want %s", tt.states, got, tt.want)
+		}
+	}
+}
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+
+	pr, err := f.OpenPR(ctx, OpenPROptions{Head: "polecat/nux", Base: "main", Title: "feat"})
+	if err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	again, _ := f.OpenPR(ctx, OpenPROptions{Head: "polecat/nux", Base: "main"})
+	if again.Number != pr.Number {
+		t.Errorf("reopening returned PR %d, want existing %d", again.Number, pr.Number)
+	}
+
+	if pr.CreatedAt.IsZero() {
+		t.Error("OpenPR should set CreatedAt")
+	}
+
+	if _, err := f.Merge(ctx, pr.Number, MergeOptions{}); !errors.Is(err, ErrNotMergeable) {
+		t.Errorf("Merge with no checks = %v, want ErrNotMergeable", err)
+	}
+	f.SetChecks(pr.Number, CheckPending)
+	if _, err := f.Merge(ctx, pr.Number, MergeOptions{}); !errors.Is(err, ErrNotMergeable) {
+		t.Errorf("Merge with pending checks = %v, want ErrNotMergeable", err)
+	}
+	f.SetChecks(pr.Number, CheckSuccess)
+	merged, err := f.Merge(ctx, pr.Number, MergeOptions{})
+	if err != nil || merged.State != PRMerged || merged.MergeCommit == "" {
+		t.Fatalf("Merge = %+v, %v", merged, err)
+	}
+
+	if err := f.Comment(ctx, pr.Number, "landed"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if c := f.Comments(pr.Number); len(c) != 1 || c[0] != "landed" {
+		t.Errorf("Comments = %v", c)
+	}
+
+	f.NoCI = true
+	other, _ := f.OpenPR(ctx, OpenPROptions{Head: "polecat/toast", Base: "main"})
+	if _, err := f.Merge(ctx, other.Number, MergeOptions{}); err != nil {
+		t.Errorf("Merge with no checks on a NoCI forge = %v, want merged", err)
+	}
+}
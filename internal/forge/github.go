@@ -0,0 +1,207 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitHub is a ForgeProvider for GitHub and GitHub Enterprise, using the REST
+// API.
+type GitHub struct {
+	api   restClient
+	owner string
+	repo  string
+}
+
+// NewGitHub returns a GitHub provider for repo ("owner/repo") at apiURL
+// (https://api.github.com, or https://<host>/api/v3 for Enterprise).
+func NewGitHub(apiURL, repo, token string) (*GitHub, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid GitHub repository %q (want owner/repo)", repo)
+	}
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	header.Set("Authorization", "Bearer "+token)
+	return &GitHub{api: newRESTClient(apiURL, header), owner: owner, repo: name}, nil
+}
+
+// Name returns "github".
+func (g *GitHub) Name() string { return TypeGitHub }
+
+// ghPull is a GitHub pull request as returned by the REST API.
+type ghPull struct {
+	Number         int     `json:"number"`
+	HTMLURL        string  `json:"html_url"`
+	Title          string  `json:"title"`
+	State          string  `json:"state"`
+	Merged         bool    `json:"merged"`
+	MergedAt       *string `json:"merged_at"`
+	MergeCommitSHA string  `json:"merge_commit_sha"`
+	CreatedAt      string  `json:"created_at"`
+	Head           struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+func (p ghPull) toPR() *PullRequest {
+	pr := &PullRequest{
+		Number:  p.Number,
+		URL:     p.HTMLURL,
+		Title:   p.Title,
+		Head:    p.Head.Ref,
+		HeadSHA: p.Head.SHA,
+		Base:    p.Base.Ref,
+		State:   PRState(p.State),
+	}
+	pr.CreatedAt, _ = time.Parse(time.RFC3339, p.CreatedAt)
+	if p.Merged || p.MergedAt != nil {
+		pr.State = PRMerged
+		pr.MergeCommit = p.MergeCommitSHA
+	}
+	return pr
+}
+
+func (g *GitHub) path(format string, args ...interface{}) string {
+	return fmt.Sprintf("/repos/%s/%s", url.PathEscape(g.owner), url.PathEscape(g.repo)) + fmt.Sprintf(format, args...)
+}
+
+// OpenPR opens a pull request, or returns the open one for the same head
+// and base (GitHub answers 422 when one exists).
+func (g *GitHub) OpenPR(ctx context.Context, opts OpenPROptions) (*PullRequest, error) {
+	var p ghPull
+	err := g.api.do(ctx, http.MethodPost, g.path("/pulls"), map[string]string{
+		"title": opts.Title,
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"body":  opts.Body,
+	}, &p)
+	if err == nil {
+		return p.toPR(), nil
+	}
+	if !isStatus(err, http.StatusUnprocessableEntity) {
+		return nil, err
+	}
+	var existing []ghPull
+	query := url.Values{"state": {"open"}, "head": {g.owner + ":" + opts.Head}, "base": {opts.Base}}
+	if lerr := g.api.do(ctx, http.MethodGet, g.path("/pulls?%s", query.Encode()), nil, &existing); lerr != nil || len(existing) == 0 {
+		return nil, err
+	}
+	return existing[0].toPR(), nil
+}
+
+// GetPR returns a pull request by number.
+func (g *GitHub) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var p ghPull
+	if err := g.api.do(ctx, http.MethodGet, g.path("/pulls/%d", number), nil, &p); err != nil {
+		return nil, err
+	}
+	return p.toPR(), nil
+}
+
+// Checks combines the check runs and commit statuses on the pull request's
+// head commit.
+func (g *GitHub) Checks(ctx context.Context, number int) (*Checks, error) {
+	pr, err := g.GetPR(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs struct {
+		CheckRuns []struct {
+			Name       string `json:"name"`
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+		} `json:"check_runs"`
+	}
+	if err := g.api.do(ctx, http.MethodGet, g.path("/commits/%s/check-runs?per_page=100", pr.HeadSHA), nil, &runs); err != nil {
+		return nil, err
+	}
+	var status struct {
+		Statuses []struct {
+			Context   string `json:"context"`
+			State     string `json:"state"`
+			TargetURL string `json:"target_url"`
+		} `json:"statuses"`
+	}
+	if err := g.api.do(ctx, http.MethodGet, g.path("/commits/%s/status", pr.HeadSHA), nil, &status); err != nil {
+		return nil, err
+	}
+
+	var checks []Check
+	for _, r := range runs.CheckRuns {
+		state := CheckPending
+		if r.Status == "completed" {
+			switch r.Conclusion {
+			case "success", "neutral", "skipped":
+				state = CheckSuccess
+			default:
+				state = CheckFailure
+			}
+		}
+		checks = append(checks, Check{Name: r.Name, State: state, URL: r.HTMLURL})
+	}
+	for _, s := range status.Statuses {
+		state := CheckPending
+		switch s.State {
+		case "success":
+			state = CheckSuccess
+		case "failure", "error":
+			state = CheckFailure
+		}
+		checks = append(checks, Check{Name: s.Context, State: state, URL: s.TargetURL})
+	}
+	result := summarizeChecks(checks)
+	return &result, nil
+}
+
+// Merge merges a pull request. GitHub answers 405 when it is not mergeable
+// and 409 when the head moved past opts.SHA.
+func (g *GitHub) Merge(ctx context.Context, number int, opts MergeOptions) (*PullRequest, error) {
+	method := opts.Method
+	if method == "" {
+		method = MergeSquash
+	}
+	req := map[string]string{"merge_method": method}
+	if opts.SHA != "" {
+		req["sha"] = opts.SHA
+	}
+	if opts.CommitTitle != "" {
+		req["commit_title"] = opts.CommitTitle
+	}
+	if opts.CommitMessage != "" {
+		req["commit_message"] = opts.CommitMessage
+	}
+	var resp struct {
+		SHA    string `json:"sha"`
+		Merged bool   `json:"merged"`
+	}
+	if err := g.api.do(ctx, http.MethodPut, g.path("/pulls/%d/merge", number), req, &resp); err != nil {
+		if isStatus(err, http.StatusMethodNotAllowed, http.StatusConflict) {
+			return nil, fmt.Errorf("%w: %v", ErrNotMergeable, err)
+		}
+		return nil, err
+	}
+	pr, err := g.GetPR(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	pr.State = PRMerged
+	pr.MergeCommit = resp.SHA
+	return pr, nil
+}
+
+// Comment posts an issue comment on a pull request.
+func (g *GitHub) Comment(ctx context.Context, number int, body string) error {
+	return g.api.do(ctx, http.MethodPost, g.path("/issues/%d/comments", number), map[string]string{"body": body}, nil)
+}
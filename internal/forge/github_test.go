@@ -0,0 +1,121 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newGitHubServer serves a minimal GitHub API for repo o/r with one open
+// pull request (#7) from polecat/nux.
+func newGitHubServer(t *testing.T) (*GitHub, map[string]interface{}) {
+	t.Helper()
+	got := make(map[string]interface{})
+	pull := map[string]interface{}{
+		"number": 7, "html_url": "https://github.com/o/r/pull/7", "title": "feat", "state": "open",
+		"created_at": "2026-01-02T03:04:05Z", "head": map[string]string{"ref": "polecat/nux", "sha": "abc123"},
+		"base": map[string]string{"ref": "main"},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message":"Validation Failed","errors":[{"message":"A pull request already exists for o:polecat/nux."}]}`))
+	})
+	mux.HandleFunc("GET /repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		got["list_head"] = r.URL.Query().Get("head")
+		_ = json.NewEncoder(w).Encode([]interface{}{pull})
+	})
+	mux.HandleFunc("GET /repos/o/r/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(pull)
+	})
+	mux.HandleFunc("GET /repos/o/r/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"check_runs":[{"name":"build","status":"completed","conclusion":"success"},{"name":"test","status":"completed","conclusion":"failure","html_url":"https://ci/test"}]}`))
+	})
+	mux.HandleFunc("GET /repos/o/r/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"state":"pending","statuses":[{"context":"lint","state":"pending"}]}`))
+	})
+	mux.HandleFunc("PUT /repos/o/r/pulls/7/merge", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got["merge"] = body
+		if body["sha"] != "abc123" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"Head branch was modified"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"sha":"def456","merged":true}`))
+	})
+	mux.HandleFunc("POST /repos/o/r/issues/7/comments", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got["comment"] = body["body"]
+		w.WriteHeader(http.StatusCreated)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	g, err := NewGitHub(srv.URL, "o/r", "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, got
+}
+
+func TestGitHub(t *testing.T) {
+	ctx := context.Background()
+	g, got := newGitHubServer(t)
+
+	pr, err := g.OpenPR(ctx, OpenPROptions{Head: "polecat/nux", Base: "main", Title: "feat"})
+	if err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if pr.Number != 7 || pr.HeadSHA != "abc123" || pr.State != PROpen || pr.CreatedAt.IsZero() {
+		t.Errorf("OpenPR = %+v, want existing PR 7", pr)
+	}
+	if got["list_head"] != "o:polecat/nux" {
+		t.Errorf("existing PR lookup used head %v", got["list_head"])
+	}
+
+	checks, err := g.Checks(ctx, 7)
+	if err != nil {
+		t.Fatalf("Checks: %v", err)
+	}
+	if checks.State != CheckFailure || len(checks.Checks) != 3 {
+		t.Errorf("Checks = %+v, want failure over 3 checks", checks)
+	}
+	if failed := checks.Failed(); len(failed) != 1 || failed[0].Name != "test" {
+		t.Errorf("Failed = %+v, want [test]", failed)
+	}
+
+	if _, err := g.Merge(ctx, 7, MergeOptions{SHA: "stale"}); !errors.Is(err, ErrNotMergeable) {
+		t.Errorf("Merge with stale SHA = %v, want ErrNotMergeable", err)
+	}
+	merged, err := g.Merge(ctx, 7, MergeOptions{SHA: "abc123", CommitTitle: "feat: x"})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if merged.State != PRMerged || merged.MergeCommit != "def456" {
+		t.Errorf("Merge = %+v, want merged at def456", merged)
+	}
+	if body := got["merge"].(map[string]string); body["merge_method"] != MergeSquash || body["commit_title"] != "feat: x" {
+		t.Errorf("merge request body = %v", body)
+	}
+
+	if err := g.Comment(ctx, 7, "hello"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+	if got["comment"] != "hello" {
+		t.Errorf("comment body = %v", got["comment"])
+	}
+
+	if _, err := NewGitHub("https://api.github.com", "no-slash", "tok"); err == nil {
+		t.Error("expected error for repo without owner")
+	}
+}
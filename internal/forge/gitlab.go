@@ -0,0 +1,158 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GitLab is a ForgeProvider for GitLab merge requests, using the REST API (v4).
+type GitLab struct {
+	api     restClient
+	project string // path-escaped project path, usable as :id
+}
+
+// NewGitLab returns a GitLab provider for project ("group/project", subgroups
+// allowed) at apiURL (e.g. https://gitlab.com/api/v4).
+func NewGitLab(apiURL, project, token string) (*GitLab, error) {
+	if !strings.Contains(project, "/") {
+		return nil, fmt.Errorf("invalid GitLab project %q (want group/project)", project)
+	}
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", token)
+	return &GitLab{api: newRESTClient(apiURL, header), project: url.PathEscape(project)}, nil
+}
+
+// Name returns "gitlab".
+func (g *GitLab) Name() string { return TypeGitLab }
+
+// glMR is a GitLab merge request as returned by the REST API.
+type glMR struct {
+	IID             int    `json:"iid"`
+	WebURL          string `json:"web_url"`
+	Title           string `json:"title"`
+	State           string `json:"state"`
+	SHA             string `json:"sha"`
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	CreatedAt       string `json:"created_at"`
+}
+
+func (m glMR) toPR() *PullRequest {
+	pr := &PullRequest{
+		Number:  m.IID,
+		URL:     m.WebURL,
+		Title:   m.Title,
+		Head:    m.SourceBranch,
+		HeadSHA: m.SHA,
+		Base:    m.TargetBranch,
+	}
+	pr.CreatedAt, _ = time.Parse(time.RFC3339, m.CreatedAt)
+	switch m.State {
+	case "merged":
+		pr.State = PRMerged
+		pr.MergeCommit = m.SquashCommitSHA
+		if pr.MergeCommit == "" {
+			pr.MergeCommit = m.MergeCommitSHA
+		}
+	case "opened", "locked":
+		pr.State = PROpen
+	default:
+		pr.State = PRClosed
+	}
+	return pr
+}
+
+func (g *GitLab) path(format string, args ...interface{}) string {
+	return "/projects/" + g.project + fmt.Sprintf(format, args...)
+}
+
+// OpenPR opens a merge request, or returns the open one for the same source
+// and target (GitLab answers 409 when one exists).
+func (g *GitLab) OpenPR(ctx context.Context, opts OpenPROptions) (*PullRequest, error) {
+	var m glMR
+	err := g.api.do(ctx, http.MethodPost, g.path("/merge_requests"), map[string]string{
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+		"title":         opts.Title,
+		"description":   opts.Body,
+	}, &m)
+	if err == nil {
+		return m.toPR(), nil
+	}
+	if !isStatus(err, http.StatusConflict) {
+		return nil, err
+	}
+	var existing []glMR
+	query := url.Values{"state": {"opened"}, "source_branch": {opts.Head}, "target_branch": {opts.Base}}
+	if lerr := g.api.do(ctx, http.MethodGet, g.path("/merge_requests?%s", query.Encode()), nil, &existing); lerr != nil || len(existing) == 0 {
+		return nil, err
+	}
+	return existing[0].toPR(), nil
+}
+
+// GetPR returns a merge request by IID.
+func (g *GitLab) GetPR(ctx context.Context, number int) (*PullRequest, error) {
+	var m glMR
+	if err := g.api.do(ctx, http.MethodGet, g.path("/merge_requests/%d", number), nil, &m); err != nil {
+		return nil, err
+	}
+	return m.toPR(), nil
+}
+
+// Checks reports the merge request's latest pipeline.
+func (g *GitLab) Checks(ctx context.Context, number int) (*Checks, error) {
+	var pipelines []struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+		WebURL string `json:"web_url"`
+	}
+	if err := g.api.do(ctx, http.MethodGet, g.path("/merge_requests/%d/pipelines", number), nil, &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return &Checks{State: CheckNone}, nil
+	}
+	p := pipelines[0] // newest first
+	state := CheckPending
+	switch p.Status {
+	case "success", "skipped":
+		state = CheckSuccess
+	case "failed", "canceled":
+		state = CheckFailure
+	}
+	result := summarizeChecks([]Check{{Name: fmt.Sprintf("pipeline #%d", p.ID), State: state, URL: p.WebURL}})
+	return &result, nil
+}
+
+// Merge accepts a merge request. The project's merge method applies;
+// MergeSquash requests a squash. GitLab answers 405, 406 or 422 when the
+// request can't be merged and 409 when the head moved past opts.SHA.
+func (g *GitLab) Merge(ctx context.Context, number int, opts MergeOptions) (*PullRequest, error) {
+	req := map[string]interface{}{"squash": opts.Method == "" || opts.Method == MergeSquash}
+	if opts.SHA != "" {
+		req["sha"] = opts.SHA
+	}
+	if msg := strings.TrimSpace(opts.CommitTitle + "\n\n" + opts.CommitMessage); msg != "" {
+		req["squash_commit_message"] = msg
+		req["merge_commit_message"] = msg
+	}
+	var m glMR
+	if err := g.api.do(ctx, http.MethodPut, g.path("/merge_requests/%d/merge", number), req, &m); err != nil {
+		if isStatus(err, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusConflict, http.StatusUnprocessableEntity) {
+			return nil, fmt.Errorf("%w: %v", ErrNotMergeable, err)
+		}
+		return nil, err
+	}
+	return m.toPR(), nil
+}
+
+// Comment posts a note on a merge request.
+func (g *GitLab) Comment(ctx context.Context, number int, body string) error {
+	return g.api.do(ctx, http.MethodPost, g.path("/merge_requests/%d/notes", number), map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,96 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGitLab(t *testing.T) {
+	ctx := context.Background()
+	var gotPaths []string
+	var mergeBody map[string]interface{}
+	mr := map[string]interface{}{
+		"iid": 3, "web_url": "https://gitlab.com/g/p/-/merge_requests/3", "title": "feat",
+		"state": "opened", "sha": "abc123", "source_branch": "polecat/nux", "target_branch": "main",
+		"created_at": "2026-01-02T03:04:05.000Z",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.Method+" "+r.URL.EscapedPath())
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/g%2Fp") {
+		case "POST /merge_requests":
+			_ = json.NewEncoder(w).Encode(mr)
+		case "GET /merge_requests/3/pipelines":
+			_, _ = w.Write([]byte(`[{"id":12,"status":"running"},{"id":11,"status":"failed"}]`))
+		case "PUT /merge_requests/3/merge":
+			_ = json.NewDecoder(r.Body).Decode(&mergeBody)
+			if mergeBody["sha"] != "abc123" {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				_, _ = w.Write([]byte(`{"message":"Method Not Allowed"}`))
+				return
+			}
+			merged := map[string]interface{}{}
+			for k, v := range mr {
+				merged[k] = v
+			}
+			merged["state"] = "merged"
+			merged["squash_commit_sha"] = "def456"
+			_ = json.NewEncoder(w).Encode(merged)
+		case "POST /merge_requests/3/notes":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	g, err := NewGitLab(srv.URL+"/api/v4", "g/p", "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := g.OpenPR(ctx, OpenPROptions{Head: "polecat/nux", Base: "main", Title: "feat"})
+	if err != nil {
+		t.Fatalf("OpenPR: %v", err)
+	}
+	if pr.Number != 3 || pr.State != PROpen || pr.Head != "polecat/nux" || pr.CreatedAt.IsZero() {
+		t.Errorf("OpenPR = %+v", pr)
+	}
+	if gotPaths[0] != "POST /api/v4/projects/g%2Fp/merge_requests" {
+		t.Errorf("project path not escaped: %s", gotPaths[0])
+	}
+
+	checks, err := g.Checks(ctx, 3)
+	if err != nil {
+		t.Fatalf("Checks: %v", err)
+	}
+	if checks.State != CheckPending || checks.Checks[0].Name != "pipeline #12" {
+		t.Errorf("Checks = %+v, want the latest pipeline pending", checks)
+	}
+
+	if _, err := g.Merge(ctx, 3, MergeOptions{SHA: "stale"}); !errors.Is(err, ErrNotMergeable) {
+		t.Errorf("Merge with stale SHA = %v, want ErrNotMergeable", err)
+	}
+	merged, err := g.Merge(ctx, 3, MergeOptions{SHA: "abc123", CommitTitle: "feat: x"})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if merged.State != PRMerged || merged.MergeCommit != "def456" {
+		t.Errorf("Merge = %+v, want merged at squash commit def456", merged)
+	}
+	if mergeBody["squash"] != true || mergeBody["squash_commit_message"] != "feat: x" {
+		t.Errorf("merge request body = %v", mergeBody)
+	}
+
+	if err := g.Comment(ctx, 3, "hello"); err != nil {
+		t.Fatalf("Comment: %v", err)
+	}
+}
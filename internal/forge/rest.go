@@ -0,0 +1,132 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIError is a non-2xx response from a forge API.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("forge API error (HTTP %d)", e.Status)
+	}
+	return fmt.Sprintf("forge API error (HTTP %d): %s", e.Status, e.Message)
+}
+
+// restClient is a minimal JSON REST client shared by the forge providers.
+type restClient struct {
+	base   string
+	header http.Header
+	client *http.Client
+}
+
+func newRESTClient(base string, header http.Header) restClient {
+	return restClient{
+		base:   strings.TrimSuffix(base, "/"),
+		header: header,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a request with in as the JSON body (if non-nil) and decodes the
+// JSON response into out (if non-nil).
+func (c restClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range c.header {
+		req.Header[k] = v
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{Status: resp.StatusCode, Message: errorMessage(data)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parsing response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// errorMessage extracts the message from a GitHub or GitLab error body.
+func errorMessage(data []byte) string {
+	var body struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return strings.TrimSpace(string(data))
+	}
+	var parts []string
+	if len(body.Message) > 0 {
+		var s string
+		if json.Unmarshal(body.Message, &s) == nil {
+			parts = append(parts, s)
+		} else {
+			// GitLab returns validation errors as an array or object.
+			parts = append(parts, string(body.Message))
+		}
+	}
+	if body.Error != "" {
+		parts = append(parts, body.Error)
+	}
+	for _, e := range body.Errors {
+		if e.Message != "" {
+			parts = append(parts, e.Message)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// isStatus reports whether err is an APIError with one of statuses.
+func isStatus(err error, statuses ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, s := range statuses {
+		if apiErr.Status == s {
+			return true
+		}
+	}
+	return false
+}
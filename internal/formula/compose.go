@@ -0,0 +1,413 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// StringList is a list of strings that may also be written as a single
+// string in TOML (extends = "shiny" or extends = ["shiny"]).
+type StringList []string
+
+// UnmarshalTOML decodes a string or an array of strings.
+func (l *StringList) UnmarshalTOML(data any) error {
+	switch val := data.(type) {
+	case string:
+		*l = StringList{val}
+		return nil
+	case []any:
+		out := make(StringList, 0, len(val))
+		for _, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected string in list, got %T", item)
+			}
+			out = append(out, s)
+		}
+		*l = out
+		return nil
+	default:
+		return fmt.Errorf("expected string or array of strings, got %T", data)
+	}
+}
+
+// Include pulls another formula's steps, vars and legs into a formula.
+// With After or Before, the included steps are spliced into the step graph
+// at that step; otherwise they are added alongside the formula's own.
+type Include struct {
+	Formula string `toml:"formula"`
+	After   string `toml:"after,omitempty"`  // Included steps run after this step, and its dependents wait for them
+	Before  string `toml:"before,omitempty"` // Included steps run before this step, taking over its needs
+}
+
+// Compose is a formula's [compose] table.
+type Compose struct {
+	Aspects []string        `toml:"aspects,omitempty"` // Aspect formulas woven around matching steps
+	Expand  []ComposeExpand `toml:"expand,omitempty"`
+}
+
+// ComposeExpand replaces a step with an expansion formula's templates.
+type ComposeExpand struct {
+	Target string `toml:"target"`
+	With   string `toml:"with"`
+}
+
+// Empty reports whether c applies nothing.
+func (c *Compose) Empty() bool {
+	return c == nil || len(c.Aspects) == 0 && len(c.Expand) == 0
+}
+
+// ErrFormulaNotFound is returned by a Loader for an unknown formula name.
+var ErrFormulaNotFound = errors.New("formula not found")
+
+// Loader returns the TOML content of the named formula, for resolving
+// extends and include.
+type Loader func(name string) ([]byte, error)
+
+// EmbeddedLoader loads the formulas embedded in the binary.
+func EmbeddedLoader(name string) ([]byte, error) {
+	data, err := formulasFS.ReadFile("formulas/" + name + ".formula.toml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrFormulaNotFound, name)
+	}
+	return data, err
+}
+
+// DirLoader returns a Loader that looks for <name>.formula.toml in each of
+// dirs in order, then among the embedded formulas.
+func DirLoader(dirs ...string) Loader {
+	return func(name string) ([]byte, error) {
+		if strings.ContainsAny(name, `/\`) || name == "" || name == "." || name == ".." {
+			return nil, fmt.Errorf("invalid formula name %q", name)
+		}
+		for _, dir := range dirs {
+			data, err := os.ReadFile(filepath.Join(dir, name+".formula.toml")) //nolint:gosec // G304: name is a bare formula name
+			if err == nil {
+				return data, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("reading formula %s: %w", name, err)
+			}
+		}
+		return EmbeddedLoader(name)
+	}
+}
+
+// composer resolves extends and include across formula files.
+type composer struct {
+	load  Loader
+	stack []string // formulas being resolved, outermost first
+}
+
+// resolve merges f's parents and includes into f. name identifies f for
+// cycle detection.
+func (c *composer) resolve(f *Formula, name string) error {
+	for _, n := range c.stack {
+		if n == name {
+			return fmt.Errorf("formula cycle: %s -> %s", strings.Join(c.stack, " -> "), name)
+		}
+	}
+	c.stack = append(c.stack, name)
+	defer func() { c.stack = c.stack[:len(c.stack)-1] }()
+
+	if len(f.Extends) > 0 {
+		var base *Formula
+		for _, parentName := range f.Extends {
+			parent, err := c.loadResolved(parentName)
+			if err != nil {
+				return fmt.Errorf("formula %s extends %s: %w", name, parentName, err)
+			}
+			if base == nil {
+				base = parent
+			} else {
+				base = overlay(base, parent)
+			}
+		}
+		merged := overlay(base, f)
+		merged.Extends, merged.Include = f.Extends, f.Include
+		*f = *merged
+	}
+
+	for _, inc := range f.Include {
+		if inc.Formula == "" {
+			return fmt.Errorf("formula %s: include missing required formula field", name)
+		}
+		other, err := c.loadResolved(inc.Formula)
+		if err != nil {
+			return fmt.Errorf("formula %s includes %s: %w", name, inc.Formula, err)
+		}
+		if err := f.include(other, inc); err != nil {
+			return fmt.Errorf("formula %s includes %s: %w", name, inc.Formula, err)
+		}
+	}
+	return nil
+}
+
+// loadResolved loads and resolves the named formula.
+func (c *composer) loadResolved(name string) (*Formula, error) {
+	data, err := c.load(name)
+	if err != nil {
+		return nil, err
+	}
+	var f Formula
+	if _, err := toml.Decode(string(data), &f); err != nil {
+		return nil, fmt.Errorf("parsing TOML: %w", err)
+	}
+	if err := c.resolve(&f, name); err != nil {
+		return nil, err
+	}
+	f.inferType()
+	return &f, nil
+}
+
+// overlay returns base with own's fields laid over it. Items with the same ID
+// are merged field by field, own's set fields winning; new items are appended.
+func overlay(base, own *Formula) *Formula {
+	out := *base
+	out.Name = own.Name
+	if own.Description != "" {
+		out.Description = own.Description
+	}
+	if own.Type != "" {
+		out.Type = own.Type
+	}
+	if own.Version != 0 {
+		out.Version = own.Version
+	}
+	if own.Output != nil {
+		out.Output = own.Output
+	}
+	if own.Synthesis != nil {
+		out.Synthesis = own.Synthesis
+	}
+	if !own.Compose.Empty() {
+		out.Compose = own.Compose
+	}
+	out.Inputs = mergeMap(base.Inputs, own.Inputs)
+	out.Prompts = mergeMap(base.Prompts, own.Prompts)
+	out.Vars = mergeMap(base.Vars, own.Vars)
+	out.Steps = mergeByID(base.Steps, own.Steps, func(s Step) string { return s.ID }, mergeStep)
+	out.Legs = mergeByID(base.Legs, own.Legs, func(l Leg) string { return l.ID }, mergeLeg)
+	out.Template = mergeByID(base.Template, own.Template, func(t Template) string { return t.ID }, mergeTemplate)
+	out.Aspects = mergeByID(base.Aspects, own.Aspects, func(a Aspect) string { return a.ID }, mergeAspect)
+	return &out
+}
+
+// include adds other's steps, vars and legs to f. f's own vars, inputs and
+// prompts win; duplicate step, leg or aspect IDs are an error.
+func (f *Formula) include(other *Formula, inc Include) error {
+	if inc.After != "" && inc.Before != "" {
+		return fmt.Errorf("include sets both after and before")
+	}
+	f.Inputs = mergeMap(other.Inputs, f.Inputs)
+	f.Prompts = mergeMap(other.Prompts, f.Prompts)
+	f.Vars = mergeMap(other.Vars, f.Vars)
+
+	seen := make(map[string]bool)
+	for _, id := range f.allItemIDs() {
+		seen[id] = true
+	}
+	for _, id := range other.allItemIDs() {
+		if seen[id] {
+			return fmt.Errorf("duplicate id: %s", id)
+		}
+	}
+	f.Legs = append(f.Legs, other.Legs...)
+	f.Aspects = append(f.Aspects, other.Aspects...)
+	f.Template = append(f.Template, other.Template...)
+
+	steps := make([]Step, len(other.Steps))
+	copy(steps, other.Steps)
+	if len(steps) == 0 {
+		return nil
+	}
+	target := inc.After
+	if target == "" {
+		target = inc.Before
+	}
+	if target == "" {
+		f.Steps = append(f.Steps, steps...)
+		return nil
+	}
+	at := -1
+	for i := range f.Steps {
+		if f.Steps[i].ID == target {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return fmt.Errorf("unknown step: %s", target)
+	}
+
+	// Included steps with no needs start at the splice point, and the step
+	// on its far side waits for the included steps nothing else needs.
+	needed := make(map[string]bool)
+	for _, s := range steps {
+		for _, need := range s.Needs {
+			needed[need] = true
+		}
+	}
+	var leaves []string
+	for _, s := range steps {
+		if !needed[s.ID] {
+			leaves = append(leaves, s.ID)
+		}
+	}
+	var roots []int
+	for i := range steps {
+		if len(steps[i].Needs) == 0 {
+			roots = append(roots, i)
+		}
+	}
+
+	if inc.After != "" {
+		for _, i := range roots {
+			steps[i].Needs = []string{target}
+		}
+		for i := range f.Steps {
+			f.Steps[i].Needs = replaceNeed(f.Steps[i].Needs, target, leaves)
+		}
+		at++
+	} else {
+		for _, i := range roots {
+			steps[i].Needs = append([]string(nil), f.Steps[at].Needs...)
+		}
+		f.Steps[at].Needs = append([]string(nil), leaves...)
+	}
+	f.Steps = append(f.Steps[:at], append(steps, f.Steps[at:]...)...)
+	return nil
+}
+
+// allItemIDs returns the IDs of f's steps, legs, templates and aspects.
+func (f *Formula) allItemIDs() []string {
+	var ids []string
+	for _, s := range f.Steps {
+		ids = append(ids, s.ID)
+	}
+	for _, l := range f.Legs {
+		ids = append(ids, l.ID)
+	}
+	for _, t := range f.Template {
+		ids = append(ids, t.ID)
+	}
+	for _, a := range f.Aspects {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+// replaceNeed replaces need old with news, keeping needs unique.
+func replaceNeed(needs []string, old string, news []string) []string {
+	found := false
+	for _, n := range needs {
+		if n == old {
+			found = true
+		}
+	}
+	if !found {
+		return needs
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, n := range needs {
+		add := []string{n}
+		if n == old {
+			add = news
+		}
+		for _, a := range add {
+			if !seen[a] {
+				seen[a] = true
+				out = append(out, a)
+			}
+		}
+	}
+	return out
+}
+
+func mergeMap[V any](base, own map[string]V) map[string]V {
+	if len(base) == 0 && len(own) == 0 {
+		return own
+	}
+	out := make(map[string]V, len(base)+len(own))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range own {
+		out[k] = v
+	}
+	return out
+}
+
+func mergeByID[T any](base, own []T, id func(T) string, merge func(base, own T) T) []T {
+	if len(base) == 0 {
+		return own
+	}
+	out := make([]T, len(base), len(base)+len(own))
+	copy(out, base)
+	index := make(map[string]int, len(base))
+	for i, item := range base {
+		index[id(item)] = i
+	}
+	for _, item := range own {
+		if i, ok := index[id(item)]; ok {
+			out[i] = merge(out[i], item)
+			continue
+		}
+		index[id(item)] = len(out)
+		out = append(out, item)
+	}
+	return out
+}
+
+func mergeStep(base, own Step) Step {
+	base.Title = pick(base.Title, own.Title)
+	base.Description = pick(base.Description, own.Description)
+	base.Acceptance = pick(base.Acceptance, own.Acceptance)
+	base.When = pick(base.When, own.When)
+	base.Foreach = pick(base.Foreach, own.Foreach)
+	if own.Needs != nil {
+		base.Needs = own.Needs
+	}
+	if own.Parallel {
+		base.Parallel = true
+	}
+	return base
+}
+
+func mergeLeg(base, own Leg) Leg {
+	base.Title = pick(base.Title, own.Title)
+	base.Focus = pick(base.Focus, own.Focus)
+	base.Description = pick(base.Description, own.Description)
+	return base
+}
+
+func mergeTemplate(base, own Template) Template {
+	base.Title = pick(base.Title, own.Title)
+	base.Description = pick(base.Description, own.Description)
+	if own.Needs != nil {
+		base.Needs = own.Needs
+	}
+	return base
+}
+
+func mergeAspect(base, own Aspect) Aspect {
+	base.Title = pick(base.Title, own.Title)
+	base.Focus = pick(base.Focus, own.Focus)
+	base.Description = pick(base.Description, own.Description)
+	return base
+}
+
+// pick returns own if set, else base.
+func pick(base, own string) string {
+	if own != "" {
+		return own
+	}
+	return base
+}
@@ -0,0 +1,247 @@
+package formula
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+// mapLoader loads formulas from a map of name to TOML content.
+func mapLoader(formulas map[string]string) Loader {
+	return func(name string) ([]byte, error) {
+		data, ok := formulas[name]
+		if !ok {
+			return nil, ErrFormulaNotFound
+		}
+		return []byte(data), nil
+	}
+}
+
+func stepIDs(f *Formula) []string {
+	var ids []string
+	for _, s := range f.Steps {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func TestParse_ExtendsEmbedded(t *testing.T) {
+	for _, name := range []string{"shiny-secure", "shiny-enterprise"} {
+		f, err := ParseFile(filepath.Join("formulas", name+".formula.toml"))
+		if err != nil {
+			t.Fatalf("ParseFile(%s): %v", name, err)
+		}
+		if f.Name != name || f.Type != TypeWorkflow {
+			t.Errorf("%s: name %q type %q", name, f.Name, f.Type)
+		}
+		want := []string{"design", "implement", "review", "test", "submit"}
+		if got := stepIDs(f); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s steps = %v, want shiny's %v", name, got, want)
+		}
+		if _, ok := f.Vars["feature"]; !ok {
+			t.Errorf("%s should inherit var feature", name)
+		}
+		if f.Compose.Empty() {
+			t.Errorf("%s should keep its [compose] table", name)
+		}
+	}
+}
+
+func TestParse_ExtendsOverride(t *testing.T) {
+	load := mapLoader(map[string]string{"shiny": `
+formula = "shiny"
+type = "workflow"
+
+[vars]
+feature = "x"
+
+[[steps]]
+id = "design"
+title = "Design {{feature}}"
+description = "Think first."
+
+[[steps]]
+id = "implement"
+title = "Implement"
+needs = ["design"]
+`})
+	f, err := ParseWithLoader([]byte(`
+formula = "shiny-fast"
+extends = "shiny"
+
+[vars]
+feature = "y"
+speed = "fast"
+
+[[steps]]
+id = "design"
+title = "Sketch {{feature}}"
+
+[[steps]]
+id = "lint"
+title = "Lint"
+needs = ["implement"]
+`), load)
+	if err != nil {
+		t.Fatalf("ParseWithLoader: %v", err)
+	}
+	if got := stepIDs(f); !reflect.DeepEqual(got, []string{"design", "implement", "lint"}) {
+		t.Errorf("steps = %v", got)
+	}
+	design := f.GetStep("design")
+	if design.Title != "Sketch {{feature}}" || design.Description != "Think first." {
+		t.Errorf("design = %+v, want title overridden and description inherited", design)
+	}
+	if f.Vars["feature"].Default != "y" || f.Vars["speed"].Default != "fast" {
+		t.Errorf("vars = %+v", f.Vars)
+	}
+	if f.Type != TypeWorkflow {
+		t.Errorf("type = %q, want inherited workflow", f.Type)
+	}
+}
+
+func TestParse_Include(t *testing.T) {
+	load := mapLoader(map[string]string{
+		"checks": `
+formula = "checks"
+
+[vars]
+linter = "golangci-lint"
+
+[[steps]]
+id = "lint"
+title = "Run {{linter}}"
+
+[[steps]]
+id = "vet"
+title = "Vet"
+
+[[steps]]
+id = "report"
+title = "Report"
+needs = ["lint", "vet"]
+`,
+		"docs": `
+formula = "docs"
+
+[[steps]]
+id = "docs"
+title = "Write docs"
+`,
+	})
+	f, err := ParseWithLoader([]byte(`
+formula = "release"
+
+[[include]]
+formula = "checks"
+after = "build"
+
+[[include]]
+formula = "docs"
+before = "publish"
+
+[[steps]]
+id = "build"
+title = "Build"
+
+[[steps]]
+id = "publish"
+title = "Publish"
+needs = ["build"]
+`), load)
+	if err != nil {
+		t.Fatalf("ParseWithLoader: %v", err)
+	}
+	if got := stepIDs(f); !reflect.DeepEqual(got, []string{"build", "lint", "vet", "report", "docs", "publish"}) {
+		t.Errorf("steps = %v", got)
+	}
+	needs := map[string][]string{
+		"lint":    {"build"},
+		"vet":     {"build"},
+		"docs":    {"report"},
+		"publish": {"docs"},
+	}
+	for id, want := range needs {
+		if got := f.GetStep(id).Needs; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s needs = %v, want %v", id, got, want)
+		}
+	}
+	if _, ok := f.Vars["linter"]; !ok {
+		t.Error("included var linter missing")
+	}
+}
+
+func TestParse_CompositionErrors(t *testing.T) {
+	load := mapLoader(map[string]string{
+		"a":    "formula = \"a\"\nextends = \"b\"\n[[steps]]\nid = \"x\"\n",
+		"b":    "formula = \"b\"\nextends = [\"a\"]\n",
+		"base": "formula = \"base\"\n[[steps]]\nid = \"build\"\n",
+	})
+	tests := []struct {
+		name, data, want string
+	}{
+		{"cycle", `formula = "top"` + "\nextends = \"a\"", "formula cycle: top -> a -> b -> a"},
+		{"missing parent", `formula = "top"` + "\nextends = \"nope\"", "extends nope"},
+		{"duplicate include", "formula = \"top\"\n[[include]]\nformula = \"base\"\n[[steps]]\nid = \"build\"\n", "duplicate id: build"},
+		{"unknown splice", "formula = \"top\"\n[[include]]\nformula = \"base\"\nafter = \"zz\"\n[[steps]]\nid = \"s\"\n", "unknown step: zz"},
+		{"self include", "formula = \"top\"\n[[include]]\nformula = \"top\"\n", "formula cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := load
+			if tt.name == "self include" {
+				loader = mapLoader(map[string]string{"top": tt.data})
+			}
+			_, err := ParseWithLoader([]byte(tt.data), loader)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := EmbeddedLoader("no-such-formula"); !errors.Is(err, ErrFormulaNotFound) {
+		t.Errorf("EmbeddedLoader error = %v, want ErrFormulaNotFound", err)
+	}
+}
+
+func TestDirLoader_PrefersDirs(t *testing.T) {
+	dir := t.TempDir()
+	local := "formula = \"shiny\"\n[[steps]]\nid = \"only\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "shiny.formula.toml"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ParseWithLoader([]byte("formula = \"mine\"\nextends = \"shiny\"\n"), DirLoader(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stepIDs(f); !reflect.DeepEqual(got, []string{"only"}) {
+		t.Errorf("steps = %v, want the local shiny's", got)
+	}
+	if _, err := DirLoader(dir)("../etc/passwd"); err == nil {
+		t.Error("expected error for a path as formula name")
+	}
+}
+
+func TestResolvedFormulaRoundTrip(t *testing.T) {
+	f, err := ParseFile("formulas/shiny-enterprise.formula.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Extends, f.Include = nil, nil
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("re-parsing resolved formula: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(again, f) {
+		t.Errorf("resolved formula did not round-trip:\n%s", buf.String())
+	}
+}
@@ -85,6 +85,8 @@
 //   - Unique IDs within steps/legs/templates/aspects
 //   - Valid dependency references (needs/depends_on)
 //   - Cycle detection in dependency graphs
+//   - Cycle detection across extends and include
+//   - Known var and input types, with defaults that pass their checks
 //
 // # Cycle Detection
 //
@@ -114,6 +116,41 @@
 //	ready := f.ReadySteps(completed)
 //	// Returns: ["build"] (test is done, build can run)
 //
+// Simulate dispatches a formula's ready steps wave by wave in memory,
+// given estimated step durations, and reports the waves, the largest wave
+// (MaxParallel), the critical path and the estimated wall-clock time.
+//
+// # Composition
+//
+// A formula may extend others (extends = "shiny") and include them
+// ([[include]] with formula, after and before). Parse resolves both, so the
+// returned Formula is fully expanded; cycles across files are an error.
+// ParseWithLoader controls where the named formulas are found.
+//
+// # Typed Vars and Inputs
+//
+// Vars and inputs may set type (string, int, bool, enum, bead-id, rig-name
+// or path), values for an enum, and a pattern. ResolveParams checks the
+// values given for a run, reporting unknown names, missing required params
+// and type errors together in a *ParamError:
+//
+//	vars, err := f.ResolveParams(given, formula.ParamOptions{})
+//
+// # Conditional and Iterative Steps
+//
+// Workflow steps may set when, an expression over vars and the outcomes of
+// steps they need, and foreach, a list var or prior step output to fan out
+// over. A Run records step outcomes and expands the graph as they become
+// known; its ReadySteps skips steps whose when is false:
+//
+//	run, err := f.NewRun(map[string]string{"targets": "linux,darwin"})
+//	for !run.Done() {
+//	    for _, id := range run.ReadySteps() {
+//	        // Execute run.GetStep(id)...
+//	        run.Record(id, formula.StepOutcome{Status: formula.OutcomeSuccess})
+//	    }
+//	}
+//
 // # Embedded Formulas
 //
 // The package includes embedded formula files that can be provisioned
@@ -190,9 +190,17 @@ func CheckFormulaHealth(beadsPath string) (*HealthReport, error) {
 		return nil, err
 	}
 
+	lock, err := LoadLock(formulasDir)
+	if err != nil {
+		return nil, err
+	}
+
 	report := &HealthReport{}
 
 	for filename, embeddedHash := range embedded {
+		if lock.Owns(filename) {
+			continue // installed from a registry in its place
+		}
 		status := FormulaStatus{
 			Name:         filename,
 			EmbeddedHash: embeddedHash,
@@ -268,7 +276,15 @@ func UpdateFormulas(beadsPath string) (updated, skipped, reinstalled int, err er
 		return 0, 0, 0, err
 	}
 
+	lock, err := LoadLock(formulasDir)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
 	for filename, embeddedHash := range embedded {
+		if lock.Owns(filename) {
+			continue // installed from a registry in its place
+		}
 		installedHash, wasInstalled := installed.Formulas[filename]
 		destPath := filepath.Join(formulasDir, filename)
 		currentHash, fileErr := computeFileHash(destPath)
@@ -0,0 +1,337 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Step `when` expressions.
+//
+// Grammar:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | primary
+//	primary = "(" expr ")" | operand [ ("==" | "!=") operand ]
+//	operand = 'string' | "string" | true | false | ref
+//	ref     = NAME | vars.NAME | item | steps.ID.outcome | steps.ID.output
+//
+// Every value is a string. A value is true unless it is empty, "false", "0"
+// or "no". A bare NAME is the formula var NAME; item is the current element
+// of a foreach step.
+
+// refKind is the kind of value a reference reads.
+type refKind int
+
+const (
+	refVar refKind = iota
+	refItem
+	refStep
+)
+
+// Step reference fields.
+const (
+	fieldOutcome = "outcome"
+	fieldOutput  = "output"
+)
+
+// exprRef is a reference to a var, the foreach item, or a prior step's
+// outcome or output.
+type exprRef struct {
+	kind  refKind
+	name  string // var name or step ID
+	field string // fieldOutcome or fieldOutput, for refStep
+}
+
+func (r exprRef) String() string {
+	switch r.kind {
+	case refItem:
+		return "item"
+	case refStep:
+		return "steps." + r.name + "." + r.field
+	default:
+		return "vars." + r.name
+	}
+}
+
+// exprEnv resolves references while evaluating an expression.
+type exprEnv interface {
+	lookup(ref exprRef) string
+}
+
+type exprNode interface {
+	eval(env exprEnv) string
+}
+
+type litNode struct{ val string }
+
+func (n litNode) eval(exprEnv) string { return n.val }
+
+type refNode struct{ ref exprRef }
+
+func (n refNode) eval(env exprEnv) string { return env.lookup(n.ref) }
+
+type notNode struct{ x exprNode }
+
+func (n notNode) eval(env exprEnv) string { return boolString(!truthy(n.x.eval(env))) }
+
+type binNode struct {
+	op   string
+	l, r exprNode
+}
+
+func (n binNode) eval(env exprEnv) string {
+	switch n.op {
+	case "&&":
+		return boolString(truthy(n.l.eval(env)) && truthy(n.r.eval(env)))
+	case "||":
+		return boolString(truthy(n.l.eval(env)) || truthy(n.r.eval(env)))
+	case "==":
+		return boolString(n.l.eval(env) == n.r.eval(env))
+	default: // "!="
+		return boolString(n.l.eval(env) != n.r.eval(env))
+	}
+}
+
+func truthy(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "false", "0", "no":
+		return false
+	}
+	return true
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// condition is a parsed `when` expression.
+type condition struct {
+	root exprNode
+	refs []exprRef
+}
+
+// eval reports whether the condition holds in env.
+func (c *condition) eval(env exprEnv) bool {
+	return truthy(c.root.eval(env))
+}
+
+// parseCondition parses a `when` expression.
+func parseCondition(src string) (*condition, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	return &condition{root: root, refs: p.refs}, nil
+}
+
+// parseRef parses a single reference, as used by foreach.
+func parseRef(src string) (exprRef, error) {
+	s := strings.TrimSpace(src)
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return !isIdentRune(r) }) >= 0 {
+		return exprRef{}, fmt.Errorf("invalid reference %q", src)
+	}
+	return refFromIdent(s)
+}
+
+func refFromIdent(s string) (exprRef, error) {
+	switch {
+	case s == "item":
+		return exprRef{kind: refItem}, nil
+	case strings.HasPrefix(s, "vars."):
+		name := strings.TrimPrefix(s, "vars.")
+		if name == "" || strings.Contains(name, ".") {
+			return exprRef{}, fmt.Errorf("invalid var reference %q", s)
+		}
+		return exprRef{kind: refVar, name: name}, nil
+	case strings.HasPrefix(s, "steps."):
+		rest := strings.TrimPrefix(s, "steps.")
+		i := strings.LastIndex(rest, ".")
+		if i <= 0 {
+			return exprRef{}, fmt.Errorf("invalid step reference %q (want steps.<id>.outcome or steps.<id>.output)", s)
+		}
+		field := rest[i+1:]
+		if field != fieldOutcome && field != fieldOutput {
+			return exprRef{}, fmt.Errorf("invalid step reference %q (want steps.<id>.outcome or steps.<id>.output)", s)
+		}
+		return exprRef{kind: refStep, name: rest[:i], field: field}, nil
+	case strings.Contains(s, "."):
+		return exprRef{}, fmt.Errorf("unknown reference %q", s)
+	}
+	return exprRef{kind: refVar, name: s}, nil
+}
+
+type tokKind int
+
+const (
+	tokIdent tokKind = iota
+	tokString
+	tokOp
+)
+
+type exprToken struct {
+	kind tokKind
+	text string
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '-' || r == '.' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			toks = append(toks, exprToken{kind: tokString, text: src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '(' || c == ')':
+			toks = append(toks, exprToken{kind: tokOp, text: string(c)})
+			i++
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"),
+			strings.HasPrefix(src[i:], "=="), strings.HasPrefix(src[i:], "!="):
+			toks = append(toks, exprToken{kind: tokOp, text: src[i : i+2]})
+			i += 2
+		case c == '!':
+			toks = append(toks, exprToken{kind: tokOp, text: "!"})
+			i++
+		case isIdentRune(rune(c)):
+			j := i
+			for j < len(src) && isIdentRune(rune(src[j])) {
+				j++
+			}
+			toks = append(toks, exprToken{kind: tokIdent, text: src[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	if len(toks) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return toks, nil
+}
+
+type exprParser struct {
+	toks []exprToken
+	pos  int
+	refs []exprRef
+}
+
+func (p *exprParser) peekOp(op string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos].kind == tokOp && p.toks[p.pos].text == op
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("||") {
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = binNode{op: "||", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	l, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekOp("&&") {
+		p.pos++
+		r, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l = binNode{op: "&&", l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peekOp("!") {
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if p.peekOp("(") {
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekOp(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	}
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peekOp("==") || p.peekOp("!=") {
+		op := p.toks[p.pos].text
+		p.pos++
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return binNode{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	switch {
+	case tok.kind == tokString:
+		return litNode{val: tok.text}, nil
+	case tok.kind == tokOp:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	case tok.text == "true" || tok.text == "false":
+		return litNode{val: tok.text}, nil
+	}
+	ref, err := refFromIdent(tok.text)
+	if err != nil {
+		return nil, err
+	}
+	p.refs = append(p.refs, ref)
+	return refNode{ref: ref}, nil
+}
@@ -34,12 +34,9 @@ func TestParseRealFormulas(t *testing.T) {
 	}
 
 	// Known files that use advanced features not yet supported:
-	// - Composition (extends, compose): shiny-enterprise, shiny-secure
 	// - Aspect-oriented (advice, pointcuts): security-audit
 	skipAdvanced := map[string]string{
-		"shiny-enterprise.formula.toml": "uses formula composition (extends)",
-		"shiny-secure.formula.toml":     "uses formula composition (extends)",
-		"security-audit.formula.toml":   "uses aspect-oriented features (advice/pointcuts)",
+		"security-audit.formula.toml": "uses aspect-oriented features (advice/pointcuts)",
 	}
 
 	for _, path := range formulaFiles {
@@ -0,0 +1,86 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// LockFileName is the lock file recording formulas installed from a
+// registry, kept in the formulas directory they were installed to.
+const LockFileName = "formulas.lock"
+
+// CurrentLockVersion is the lock file schema version.
+const CurrentLockVersion = 1
+
+// Lock records the formulas installed from registries.
+type Lock struct {
+	Version  int                   `json:"version"`
+	Formulas map[string]*LockEntry `json:"formulas"`
+}
+
+// LockEntry is one installed formula.
+type LockEntry struct {
+	Version     string            `json:"version"`
+	Pinned      bool              `json:"pinned"`           // installed at an explicit version; upgrade skips it
+	Checksum    string            `json:"checksum"`         // sha256:<hex> of the downloaded file or bundle
+	Registry    string            `json:"registry"`         // registry name from town settings
+	Source      string            `json:"source"`           // URL or path downloaded from
+	Bundle      bool              `json:"bundle,omitempty"` // installed from a .tar.gz bundle
+	Files       map[string]string `json:"files"`            // path relative to the formulas dir -> sha256:<hex>
+	InstalledAt time.Time         `json:"installed_at"`
+}
+
+// LoadLock reads the lock file in dir. A missing file is an empty lock.
+func LoadLock(dir string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(dir, LockFileName)) //nolint:gosec // G304: dir is a formulas directory
+	if os.IsNotExist(err) {
+		return &Lock{Version: CurrentLockVersion, Formulas: make(map[string]*LockEntry)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", LockFileName, err)
+	}
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", LockFileName, err)
+	}
+	if l.Version > CurrentLockVersion {
+		return nil, fmt.Errorf("%s version %d is newer than supported (%d)", LockFileName, l.Version, CurrentLockVersion)
+	}
+	if l.Formulas == nil {
+		l.Formulas = make(map[string]*LockEntry)
+	}
+	return &l, nil
+}
+
+// Save writes the lock file to dir.
+func (l *Lock) Save(dir string) error {
+	l.Version = CurrentLockVersion
+	return util.AtomicWriteJSON(filepath.Join(dir, LockFileName), l)
+}
+
+// Names returns the locked formula names, sorted.
+func (l *Lock) Names() []string {
+	names := make([]string, 0, len(l.Formulas))
+	for name := range l.Formulas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Owns reports whether a file in the formulas directory was installed from
+// a registry.
+func (l *Lock) Owns(file string) bool {
+	for _, e := range l.Formulas {
+		if _, ok := e.Files[file]; ok {
+			return true
+		}
+	}
+	return false
+}
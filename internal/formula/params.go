@@ -0,0 +1,346 @@
+package formula
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Param types for vars and inputs. An empty type is a string.
+const (
+	ParamString  = "string"
+	ParamInt     = "int"
+	ParamBool    = "bool"
+	ParamEnum    = "enum"     // one of Values
+	ParamBeadID  = "bead-id"  // an existing bead, e.g. gt-abc12
+	ParamRigName = "rig-name" // an existing rig
+	ParamPath    = "path"
+)
+
+// paramTypeAliases maps alternate spellings to param types.
+var paramTypeAliases = map[string]string{
+	"":        ParamString,
+	"number":  ParamInt,
+	"integer": ParamInt,
+	"boolean": ParamBool,
+}
+
+var (
+	beadIDPattern  = regexp.MustCompile(`^[a-z][a-z0-9]*-[a-z0-9][a-zA-Z0-9._-]*$`)
+	rigNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+// Param is a var or input of a formula, with its type.
+type Param struct {
+	Name           string
+	Description    string
+	Type           string // normalized, one of the Param* constants
+	Values         []string
+	Pattern        string
+	Required       bool
+	RequiredUnless []string
+	Default        string
+}
+
+// Params returns the formula's vars and inputs sorted by name.
+func (f *Formula) Params() []Param {
+	var params []Param
+	for name, v := range f.Vars {
+		params = append(params, Param{
+			Name:        name,
+			Description: v.Description,
+			Type:        normalizeParamType(v.Type),
+			Values:      v.Values,
+			Pattern:     v.Pattern,
+			Required:    v.Required,
+			Default:     v.Default,
+		})
+	}
+	for name, in := range f.Inputs {
+		params = append(params, Param{
+			Name:           name,
+			Description:    in.Description,
+			Type:           normalizeParamType(in.Type),
+			Values:         in.Values,
+			Pattern:        in.Pattern,
+			Required:       in.Required,
+			RequiredUnless: in.RequiredUnless,
+			Default:        in.Default,
+		})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// Param returns the named var or input, or false if the formula has none.
+func (f *Formula) Param(name string) (Param, bool) {
+	for _, p := range f.Params() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+func normalizeParamType(t string) string {
+	if alias, ok := paramTypeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// Check reports whether value is valid for the param's type, allowed values
+// and pattern. It does not check that beads or rigs exist.
+func (p Param) Check(value string) error {
+	switch p.Type {
+	case ParamString, ParamPath:
+		if p.Type == ParamPath && strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return fmt.Errorf("%s: %q is not a valid path", p.Name, value)
+		}
+	case ParamInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s: %q is not an integer", p.Name, value)
+		}
+	case ParamBool:
+		if _, ok := parseBool(value); !ok {
+			return fmt.Errorf("%s: %q is not a boolean (use true or false)", p.Name, value)
+		}
+	case ParamEnum:
+		found := false
+		for _, v := range p.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %q is not one of %s", p.Name, value, strings.Join(p.Values, ", "))
+		}
+	case ParamBeadID:
+		if !beadIDPattern.MatchString(value) {
+			return fmt.Errorf("%s: %q is not a bead ID (e.g. gt-abc12)", p.Name, value)
+		}
+	case ParamRigName:
+		if !rigNamePattern.MatchString(value) {
+			return fmt.Errorf("%s: %q is not a valid rig name", p.Name, value)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %q", p.Name, p.Type)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile(`^(?:` + p.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", p.Name, p.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s: %q does not match pattern %s", p.Name, value, p.Pattern)
+		}
+	}
+	return nil
+}
+
+// parseBool accepts the spellings truthy treats as false, plus their
+// opposites.
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "1", "yes", "y", "on":
+		return true, true
+	case "false", "0", "no", "n", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// validateParams checks param declarations: known types, values for enums,
+// patterns that compile, and defaults that pass their own checks.
+func (f *Formula) validateParams() error {
+	for _, p := range f.Params() {
+		switch p.Type {
+		case ParamString, ParamInt, ParamBool, ParamEnum, ParamBeadID, ParamRigName, ParamPath:
+		default:
+			return fmt.Errorf("param %q has unknown type %q (want string, int, bool, enum, bead-id, rig-name or path)", p.Name, p.Type)
+		}
+		if p.Type == ParamEnum && len(p.Values) == 0 {
+			return fmt.Errorf("param %q is an enum with no values", p.Name)
+		}
+		if p.Type != ParamEnum && len(p.Values) > 0 {
+			return fmt.Errorf("param %q has values but is not an enum", p.Name)
+		}
+		if p.Pattern != "" {
+			if _, err := regexp.Compile(p.Pattern); err != nil {
+				return fmt.Errorf("param %q has invalid pattern: %w", p.Name, err)
+			}
+		}
+		if p.Default != "" {
+			if err := p.Check(p.Default); err != nil {
+				return fmt.Errorf("param %q default: %w", p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParamOptions configures ResolveParams.
+type ParamOptions struct {
+	// Injected names params the caller sets itself (e.g. issue when slinging
+	// a formula on a bead). They count as given but are not checked.
+	Injected []string
+
+	// BeadExists and RigExists, if set, check that bead-id and rig-name
+	// values refer to something real.
+	BeadExists func(id string) error
+	RigExists  func(name string) error
+}
+
+// ParamError lists everything wrong with the values given for a formula.
+type ParamError struct {
+	Formula  string
+	Problems []string
+}
+
+func (e *ParamError) Error() string {
+	if len(e.Problems) == 1 {
+		return fmt.Sprintf("formula %s: %s", e.Formula, e.Problems[0])
+	}
+	return fmt.Sprintf("formula %s:\n  %s", e.Formula, strings.Join(e.Problems, "\n  "))
+}
+
+// MissingParams returns the required params not set in given or injected,
+// honoring required_unless.
+func (f *Formula) MissingParams(given map[string]string, injected []string) []Param {
+	set := make(map[string]bool)
+	for name, val := range given {
+		if val != "" {
+			set[name] = true
+		}
+	}
+	for _, name := range injected {
+		set[name] = true
+	}
+	var missing []Param
+	for _, p := range f.Params() {
+		if set[p.Name] || p.Default != "" {
+			continue
+		}
+		if p.Required {
+			missing = append(missing, p)
+			continue
+		}
+		if len(p.RequiredUnless) == 0 {
+			continue
+		}
+		satisfied := false
+		for _, other := range p.RequiredUnless {
+			if set[other] {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// ResolveParams checks given values against the formula's params and
+// returns them merged over the defaults. Unknown names, missing required
+// params and values of the wrong type are all reported in one *ParamError.
+func (f *Formula) ResolveParams(given map[string]string, opts ParamOptions) (map[string]string, error) {
+	params := f.Params()
+	byName := make(map[string]Param, len(params))
+	for _, p := range params {
+		byName[p.Name] = p
+	}
+
+	var problems []string
+	names := make([]string, 0, len(given))
+	for name := range given {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p, ok := byName[name]
+		if !ok {
+			problem := fmt.Sprintf("unknown var %q", name)
+			if s := suggestParam(name, params); s != "" {
+				problem += fmt.Sprintf(" (did you mean %q?)", s)
+			}
+			problems = append(problems, problem)
+			continue
+		}
+		if err := p.Check(given[name]); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		var exists func(string) error
+		switch p.Type {
+		case ParamBeadID:
+			exists = opts.BeadExists
+		case ParamRigName:
+			exists = opts.RigExists
+		}
+		if exists != nil {
+			if err := exists(given[name]); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+	}
+	for _, p := range f.MissingParams(given, opts.Injected) {
+		problem := "missing required var " + p.Name
+		if len(p.RequiredUnless) > 0 && !p.Required {
+			problem += " (or " + strings.Join(p.RequiredUnless, ", ") + ")"
+		}
+		problems = append(problems, problem)
+	}
+	if len(problems) > 0 {
+		return nil, &ParamError{Formula: f.Name, Problems: problems}
+	}
+
+	out := make(map[string]string)
+	for _, p := range params {
+		if p.Default != "" {
+			out[p.Name] = p.Default
+		}
+	}
+	for name, val := range given {
+		out[name] = val
+	}
+	return out, nil
+}
+
+// suggestParam returns the param name closest to name, if any is close
+// enough to be a likely typo.
+func suggestParam(name string, params []Param) string {
+	best, bestDist := "", len(name)/2+1
+	for _, p := range params {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(p.Name)); d < bestDist {
+			best, bestDist = p.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,155 @@
+package formula
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const typedFormula = `
+formula = "deploy"
+type = "workflow"
+
+[vars.env]
+type = "enum"
+values = ["staging", "production"]
+default = "staging"
+
+[vars.replicas]
+type = "int"
+default = "2"
+
+[vars.canary]
+type = "bool"
+
+[vars.issue]
+type = "bead-id"
+required = true
+
+[vars.rig]
+type = "rig-name"
+
+[vars.version]
+pattern = 'v\d+\.\d+\.\d+'
+required = true
+
+[vars.manifest]
+type = "path"
+
+[[steps]]
+id = "deploy"
+title = "Deploy {{version}} to {{env}}"
+`
+
+func TestResolveParams(t *testing.T) {
+	f, err := Parse([]byte(typedFormula))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	got, err := f.ResolveParams(map[string]string{"issue": "gt-abc12", "version": "v1.2.3", "canary": "yes"}, ParamOptions{})
+	if err != nil {
+		t.Fatalf("ResolveParams: %v", err)
+	}
+	want := map[string]string{"env": "staging", "replicas": "2", "canary": "yes", "issue": "gt-abc12", "version": "v1.2.3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveParams = %v, want %v", got, want)
+	}
+
+	_, err = f.ResolveParams(map[string]string{
+		"env":      "prod",
+		"replicas": "many",
+		"canary":   "maybe",
+		"rig":      "my/rig",
+		"version":  "1.2",
+		"relicas":  "3",
+		"manifest": "a\x00b",
+	}, ParamOptions{})
+	var perr *ParamError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *ParamError", err)
+	}
+	wantProblems := []string{
+		`canary: "maybe" is not a boolean`,
+		`env: "prod" is not one of staging, production`,
+		`manifest: "a\x00b" is not a valid path`,
+		`unknown var "relicas" (did you mean "replicas"?)`,
+		`replicas: "many" is not an integer`,
+		`rig: "my/rig" is not a valid rig name`,
+		`version: "1.2" does not match pattern`,
+		`missing required var issue`,
+	}
+	if len(perr.Problems) != len(wantProblems) {
+		t.Fatalf("problems = %q, want %d", perr.Problems, len(wantProblems))
+	}
+	for i, want := range wantProblems {
+		if !strings.HasPrefix(perr.Problems[i], want) {
+			t.Errorf("problem %d = %q, want prefix %q", i, perr.Problems[i], want)
+		}
+	}
+}
+
+func TestResolveParams_InjectedAndExistence(t *testing.T) {
+	f, err := Parse([]byte(typedFormula))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	opts := ParamOptions{
+		Injected:   []string{"issue"},
+		BeadExists: func(id string) error { return errors.New("bead not found") },
+		RigExists:  func(name string) error { return errors.New("rig not found") },
+	}
+	if _, err := f.ResolveParams(map[string]string{"version": "v1.0.0"}, opts); err != nil {
+		t.Errorf("injected issue should satisfy required: %v", err)
+	}
+	_, err = f.ResolveParams(map[string]string{"version": "v1.0.0", "issue": "gt-zzz", "rig": "gastown"}, opts)
+	if err == nil || !strings.Contains(err.Error(), "issue: bead not found") || !strings.Contains(err.Error(), "rig: rig not found") {
+		t.Errorf("error = %v, want bead and rig existence failures", err)
+	}
+}
+
+func TestMissingParams_RequiredUnless(t *testing.T) {
+	f, err := ParseFile("formulas/code-review.formula.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range f.MissingParams(nil, nil) {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"branch", "files", "pr"}) {
+		t.Errorf("missing = %v, want all three alternatives", names)
+	}
+	if missing := f.MissingParams(map[string]string{"branch": "feature"}, nil); len(missing) != 0 {
+		t.Errorf("missing = %v, want none once branch is set", missing)
+	}
+	if p, _ := f.Param("pr"); p.Type != ParamInt {
+		t.Errorf("pr type = %q, want number normalized to int", p.Type)
+	}
+	if _, err := f.ResolveParams(map[string]string{"pr": "12a"}, ParamOptions{}); err == nil {
+		t.Error("expected error for non-integer pr")
+	}
+}
+
+func TestParse_InvalidParamDeclarations(t *testing.T) {
+	tests := []struct {
+		name, vars, want string
+	}{
+		{"unknown type", "[vars.x]\ntype = \"float\"", `unknown type "float"`},
+		{"enum without values", "[vars.x]\ntype = \"enum\"", "enum with no values"},
+		{"values on string", "[vars.x]\nvalues = [\"a\"]", "not an enum"},
+		{"bad pattern", "[vars.x]\npattern = \"(\"", "invalid pattern"},
+		{"bad default", "[vars.x]\ntype = \"int\"\ndefault = \"ten\"", `default: x: "ten" is not an integer`},
+		{"bad input default", "[inputs.x]\ntype = \"enum\"\nvalues = [\"a\"]\ndefault = \"b\"", "not one of a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "formula = \"bad\"\n" + tt.vars + "\n[[steps]]\nid = \"s\"\ntitle = \"S\"\n"
+			_, err := Parse([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -3,27 +3,43 @@ package formula
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 
 	"github.com/BurntSushi/toml"
 )
 
-// ParseFile reads and parses a formula.toml file.
+// ParseFile reads and parses a formula.toml file. Formulas it extends or
+// includes are looked up next to it, then among the embedded formulas.
 func ParseFile(path string) (*Formula, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
 	if err != nil {
 		return nil, fmt.Errorf("reading formula file: %w", err)
 	}
-	return Parse(data)
+	return ParseWithLoader(data, DirLoader(filepath.Dir(path)))
 }
 
-// Parse parses formula.toml content from bytes.
+// Parse parses formula.toml content from bytes. Formulas it extends or
+// includes are looked up among the embedded formulas.
 func Parse(data []byte) (*Formula, error) {
+	return ParseWithLoader(data, EmbeddedLoader)
+}
+
+// ParseWithLoader parses formula.toml content from bytes, resolving extends
+// and include with load.
+func ParseWithLoader(data []byte, load Loader) (*Formula, error) {
 	var f Formula
 	if _, err := toml.Decode(string(data), &f); err != nil {
 		return nil, fmt.Errorf("parsing TOML: %w", err)
 	}
 
+	if len(f.Extends) > 0 || len(f.Include) > 0 {
+		c := &composer{load: load}
+		if err := c.resolve(&f, f.Name); err != nil {
+			return nil, err
+		}
+	}
+
 	// Infer type from content if not explicitly set
 	f.inferType()
 
@@ -63,6 +79,10 @@ func (f *Formula) Validate() error {
 		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
 	}
 
+	if err := f.validateParams(); err != nil {
+		return err
+	}
+
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
@@ -147,9 +167,83 @@ func (f *Formula) validateWorkflow() error {
 		return err
 	}
 
+	return f.validateDynamicSteps()
+}
+
+// validateDynamicSteps checks the when and foreach fields of workflow steps.
+// Steps they reference must be among the step's (transitive) needs, so their
+// outcome is known by the time the step is considered.
+func (f *Formula) validateDynamicSteps() error {
+	for _, step := range f.Steps {
+		if step.When == "" && step.Foreach == "" {
+			continue
+		}
+		ancestors := f.ancestors(step.ID)
+		checkRef := func(field string, ref exprRef) error {
+			switch ref.kind {
+			case refVar:
+				if _, ok := f.Vars[ref.name]; !ok {
+					return fmt.Errorf("step %q %s references undefined var: %s", step.ID, field, ref.name)
+				}
+			case refItem:
+				if step.Foreach == "" {
+					return fmt.Errorf("step %q %s references item but has no foreach", step.ID, field)
+				}
+			case refStep:
+				if !ancestors[ref.name] {
+					return fmt.Errorf("step %q %s references step %s, which it does not need", step.ID, field, ref.name)
+				}
+			}
+			return nil
+		}
+
+		if step.When != "" {
+			cond, err := parseCondition(step.When)
+			if err != nil {
+				return fmt.Errorf("step %q when: %w", step.ID, err)
+			}
+			for _, ref := range cond.refs {
+				if err := checkRef("when", ref); err != nil {
+					return err
+				}
+			}
+		}
+		if step.Foreach != "" {
+			ref, err := parseRef(step.Foreach)
+			if err != nil {
+				return fmt.Errorf("step %q foreach: %w", step.ID, err)
+			}
+			if ref.kind == refItem || (ref.kind == refStep && ref.field != fieldOutput) {
+				return fmt.Errorf("step %q foreach must name a var or steps.<id>.output, got %s", step.ID, step.Foreach)
+			}
+			if err := checkRef("foreach", ref); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// ancestors returns the IDs of the steps id needs, directly or transitively.
+func (f *Formula) ancestors(id string) map[string]bool {
+	seen := make(map[string]bool)
+	var visit func(string)
+	visit = func(id string) {
+		step := f.GetStep(id)
+		if step == nil {
+			return
+		}
+		for _, need := range step.Needs {
+			if !seen[need] {
+				seen[need] = true
+				visit(need)
+			}
+		}
+	}
+	visit(id)
+	return seen
+}
+
 func (f *Formula) validateExpansion() error {
 	if len(f.Template) == 0 {
 		return fmt.Errorf("expansion formula requires at least one template")
@@ -267,6 +361,8 @@ func checkDependencyCycles(deps map[string][]string) error {
 // TopologicalSort returns steps in dependency order (dependencies before dependents).
 // Only applicable to workflow and expansion formulas.
 // Returns an error if there are cycles.
+// Steps with when or foreach are treated as plain steps; use a Run for the
+// expanded graph.
 func (f *Formula) TopologicalSort() ([]string, error) {
 	var items []string
 	var deps map[string][]string
@@ -357,6 +453,7 @@ func (f *Formula) TopologicalSort() ([]string, error) {
 
 // ReadySteps returns steps that have no unmet dependencies.
 // completed is a set of step IDs that have been completed.
+// It does not evaluate when or expand foreach; see Run.ReadySteps.
 func (f *Formula) ReadySteps(completed map[string]bool) []string {
 	var ready []string
 
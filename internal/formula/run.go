@@ -0,0 +1,508 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Step outcome statuses.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeSkipped = "skipped" // set by the Run when a step's when is false
+)
+
+// StepOutcome is the result of a finished step.
+type StepOutcome struct {
+	Status string `json:"status"`
+	Output string `json:"output,omitempty"`
+}
+
+// Run tracks one execution of a workflow formula whose graph depends on its
+// vars and on step outcomes.
+//
+// A foreach step fans out into one step per item once its list is known:
+// step "test" over three packages becomes "test.0", "test.1" and "test.2",
+// with {{item}} and {{index}} filled in, and steps needing "test" need all
+// three. A foreach over a step's output stays a single pending step until
+// that output is recorded.
+//
+// A step whose when is false once its needs have finished is skipped, which
+// satisfies its dependents like a finished step. A need is met whatever its
+// outcome; use when to branch on failures.
+//
+// bd does not know when or foreach. Pour resolves the ones that read only
+// vars before a formula is poured; those that read other steps can only be
+// evaluated by a Run. See RunOnlySteps.
+type Run struct {
+	formula  *Formula
+	vars     map[string]string
+	outcomes map[string]StepOutcome
+	conds    map[string]*condition
+	sources  map[string]exprRef
+}
+
+// RunOnlySteps returns the IDs of the steps whose when or foreach reads
+// another step's outcome or output. Only a Run evaluates those; Pour can't.
+func (f *Formula) RunOnlySteps() []string {
+	var ids []string
+	for _, step := range f.Steps {
+		if stepReadsSteps(step) {
+			ids = append(ids, step.ID)
+		}
+	}
+	return ids
+}
+
+// stepReadsSteps reports whether step's when or foreach references a step.
+// Unparseable expressions count, since Parse rejects them anyway.
+func stepReadsSteps(step Step) bool {
+	if step.Foreach != "" {
+		if ref, err := parseRef(step.Foreach); err != nil || ref.kind == refStep {
+			return true
+		}
+	}
+	if step.When != "" {
+		cond, err := parseCondition(step.When)
+		if err != nil {
+			return true
+		}
+		for _, ref := range cond.refs {
+			if ref.kind == refStep {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Pour returns f with its when and foreach resolved against vars, which
+// override the var defaults, for pouring with bd. A foreach over a var
+// becomes one step per item as in a Run; a step whose when is false is
+// dropped, and steps that needed it need its needs instead. Other
+// {{placeholders}} are left for bd. Pour fails for RunOnlySteps.
+func (f *Formula) Pour(vars map[string]string) (*Formula, error) {
+	if ids := f.RunOnlySteps(); len(ids) > 0 {
+		return nil, fmt.Errorf("formula %s: when or foreach of steps %s read other steps' results, which are only known during a run",
+			f.Name, strings.Join(ids, ", "))
+	}
+	env := pourEnv{vars: make(map[string]string)}
+	for name, v := range f.Vars {
+		env.vars[name] = v.Default
+	}
+	for name, val := range vars {
+		env.vars[name] = val
+	}
+
+	order, err := f.TopologicalSort()
+	if err != nil {
+		order = f.GetAllIDs()
+	}
+	children := make(map[string][]string) // foreach step -> kept item steps
+	dropped := make(map[string][]string)  // dropped step -> needs that replace it
+	resolveNeeds := func(needs []string) []string {
+		var out []string
+		seen := make(map[string]bool)
+		add := func(ids ...string) {
+			for _, id := range ids {
+				if !seen[id] {
+					seen[id] = true
+					out = append(out, id)
+				}
+			}
+		}
+		for _, need := range needs {
+			if c, ok := children[need]; ok {
+				add(c...)
+			} else if d, ok := dropped[need]; ok {
+				add(d...)
+			} else {
+				add(need)
+			}
+		}
+		return out
+	}
+
+	expanded := make(map[string][]Step)
+	for _, id := range order {
+		step := *f.GetStep(id)
+		step.Needs = resolveNeeds(step.Needs)
+		var cond *condition
+		if step.When != "" {
+			if cond, err = parseCondition(step.When); err != nil {
+				return nil, fmt.Errorf("step %q when: %w", step.ID, err)
+			}
+			step.When = ""
+		}
+
+		if step.Foreach == "" {
+			if cond != nil && !cond.eval(env) {
+				dropped[id] = step.Needs
+				continue
+			}
+			expanded[id] = []Step{step}
+			continue
+		}
+		ref, err := parseRef(step.Foreach)
+		if err != nil {
+			return nil, fmt.Errorf("step %q foreach: %w", step.ID, err)
+		}
+		step.Foreach = ""
+		for i, item := range splitList(env.vars[ref.name]) {
+			if cond != nil && !cond.eval(pourEnv{vars: env.vars, item: item}) {
+				continue
+			}
+			child := step
+			child.ID = id + "." + strconv.Itoa(i)
+			child.Title = renderItem(step.Title, item, i)
+			child.Description = renderItem(step.Description, item, i)
+			child.Acceptance = renderItem(step.Acceptance, item, i)
+			expanded[id] = append(expanded[id], child)
+			children[id] = append(children[id], child.ID)
+		}
+		if len(children[id]) == 0 {
+			dropped[id] = step.Needs
+		}
+	}
+
+	out := *f
+	out.Steps = nil
+	for _, step := range f.Steps {
+		out.Steps = append(out.Steps, expanded[step.ID]...)
+	}
+	return &out, nil
+}
+
+// pourEnv evaluates when expressions that read only vars and the item.
+type pourEnv struct {
+	vars map[string]string
+	item string
+}
+
+func (e pourEnv) lookup(ref exprRef) string {
+	if ref.kind == refItem {
+		return e.item
+	}
+	return e.vars[ref.name]
+}
+
+// NewRun starts a run of a workflow formula. vars override the formula's
+// var defaults; required vars must be set.
+func (f *Formula) NewRun(vars map[string]string) (*Run, error) {
+	if f.Type != TypeWorkflow {
+		return nil, fmt.Errorf("formula %s is a %s formula; runs need a workflow", f.Name, f.Type)
+	}
+	r := &Run{
+		formula:  f,
+		vars:     make(map[string]string),
+		outcomes: make(map[string]StepOutcome),
+		conds:    make(map[string]*condition),
+		sources:  make(map[string]exprRef),
+	}
+	for name, v := range f.Vars {
+		r.vars[name] = v.Default
+	}
+	for name, val := range vars {
+		r.vars[name] = val
+	}
+	for name, v := range f.Vars {
+		if v.Required && r.vars[name] == "" {
+			return nil, fmt.Errorf("missing required var: %s", name)
+		}
+	}
+	for _, step := range f.Steps {
+		if step.When != "" {
+			cond, err := parseCondition(step.When)
+			if err != nil {
+				return nil, fmt.Errorf("step %q when: %w", step.ID, err)
+			}
+			r.conds[step.ID] = cond
+		}
+		if step.Foreach != "" {
+			ref, err := parseRef(step.Foreach)
+			if err != nil {
+				return nil, fmt.Errorf("step %q foreach: %w", step.ID, err)
+			}
+			r.sources[step.ID] = ref
+		}
+	}
+	return r, nil
+}
+
+// Record sets the outcome of a ready step. Status must be OutcomeSuccess or
+// OutcomeFailure.
+func (r *Run) Record(id string, outcome StepOutcome) error {
+	if outcome.Status != OutcomeSuccess && outcome.Status != OutcomeFailure {
+		return fmt.Errorf("invalid outcome status %q (want %s or %s)", outcome.Status, OutcomeSuccess, OutcomeFailure)
+	}
+	p := r.plan()
+	switch {
+	case p.formula.GetStep(id) == nil:
+		return fmt.Errorf("unknown step: %s", id)
+	case p.skipped[id]:
+		return fmt.Errorf("step %s was skipped", id)
+	case r.hasOutcome(id):
+		return fmt.Errorf("step %s already has an outcome", id)
+	}
+	for _, ready := range p.formula.ReadySteps(p.finished()) {
+		if ready == id {
+			r.outcomes[id] = outcome
+			return nil
+		}
+	}
+	return fmt.Errorf("step %s is not ready", id)
+}
+
+func (r *Run) hasOutcome(id string) bool {
+	_, ok := r.outcomes[id]
+	return ok
+}
+
+// Outcome returns the outcome of a step: recorded, skipped, or for an
+// expanded foreach step, the combined outcome of its items.
+func (r *Run) Outcome(id string) (StepOutcome, bool) {
+	return r.plan().outcome(id)
+}
+
+// Plan returns the formula as currently expanded.
+func (r *Run) Plan() *Formula {
+	return r.plan().formula
+}
+
+// GetStep returns a step of the current plan by ID, or nil if not found.
+func (r *Run) GetStep(id string) *Step {
+	return r.plan().formula.GetStep(id)
+}
+
+// TopologicalSort returns the steps of the current plan in dependency order.
+func (r *Run) TopologicalSort() ([]string, error) {
+	return r.plan().formula.TopologicalSort()
+}
+
+// ReadySteps returns the plan steps whose needs have all finished or been
+// skipped, whose when holds, and that have no outcome yet.
+func (r *Run) ReadySteps() []string {
+	p := r.plan()
+	return p.formula.ReadySteps(p.finished())
+}
+
+// ParallelReadySteps is ReadySteps grouped as by Formula.ParallelReadySteps.
+func (r *Run) ParallelReadySteps() (parallel []string, sequential string) {
+	p := r.plan()
+	return p.formula.ParallelReadySteps(p.finished())
+}
+
+// Done reports whether every step has finished or been skipped.
+func (r *Run) Done() bool {
+	p := r.plan()
+	finished := p.finished()
+	for _, step := range p.formula.Steps {
+		if !finished[step.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// runPlan is a Run's formula expanded as far as its outcomes allow.
+type runPlan struct {
+	run      *Run
+	formula  *Formula
+	children map[string][]string // expanded foreach step -> item step IDs
+	items    map[string]string   // item step ID -> item
+	pending  map[string]bool     // foreach steps whose list is not yet known
+	skipped  map[string]bool
+}
+
+// plan expands the run's formula. Steps are visited in dependency order, so
+// everything a step's when or foreach reads is settled before it.
+func (r *Run) plan() *runPlan {
+	p := &runPlan{
+		run:      r,
+		children: make(map[string][]string),
+		items:    make(map[string]string),
+		pending:  make(map[string]bool),
+		skipped:  make(map[string]bool),
+	}
+	order, err := r.formula.TopologicalSort()
+	if err != nil {
+		// Parse rejects cycles; keep file order for hand-built formulas.
+		order = r.formula.GetAllIDs()
+	}
+
+	expanded := make(map[string][]Step)
+	for _, id := range order {
+		step := *r.formula.GetStep(id)
+		step.Needs = p.expandNeeds(step.Needs)
+
+		if step.Foreach == "" {
+			p.settle(step.ID, "")
+			expanded[id] = []Step{step}
+			continue
+		}
+		list, ok := p.foreachList(r.sources[id])
+		if !ok {
+			p.pending[id] = true
+			expanded[id] = []Step{step}
+			continue
+		}
+		p.children[id] = []string{}
+		for i, item := range list {
+			child := step
+			child.ID = id + "." + strconv.Itoa(i)
+			child.Foreach = ""
+			child.Title = renderItem(step.Title, item, i)
+			child.Description = renderItem(step.Description, item, i)
+			child.Acceptance = renderItem(step.Acceptance, item, i)
+			p.children[id] = append(p.children[id], child.ID)
+			p.items[child.ID] = item
+			p.settle(child.ID, id)
+			expanded[id] = append(expanded[id], child)
+		}
+	}
+
+	p.formula = &Formula{
+		Name:        r.formula.Name,
+		Description: r.formula.Description,
+		Type:        r.formula.Type,
+		Version:     r.formula.Version,
+		Vars:        r.formula.Vars,
+	}
+	for _, step := range r.formula.Steps {
+		p.formula.Steps = append(p.formula.Steps, expanded[step.ID]...)
+	}
+	return p
+}
+
+// expandNeeds replaces needs on expanded foreach steps with their items.
+func (p *runPlan) expandNeeds(needs []string) []string {
+	var out []string
+	for _, need := range needs {
+		if children, ok := p.children[need]; ok {
+			out = append(out, children...)
+		} else {
+			out = append(out, need)
+		}
+	}
+	return out
+}
+
+// settle marks a plan step skipped if it has no outcome, all its needs have
+// finished, and its when (that of origin, for a foreach item) is false.
+func (p *runPlan) settle(id, origin string) {
+	if origin == "" {
+		origin = id
+	}
+	cond := p.run.conds[origin]
+	if cond == nil || p.run.hasOutcome(id) {
+		return
+	}
+	for _, need := range p.expandNeeds(p.run.formula.GetStep(origin).Needs) {
+		if _, ok := p.outcome(need); !ok {
+			return
+		}
+	}
+	if !cond.eval(planEnv{plan: p, item: p.items[id]}) {
+		p.skipped[id] = true
+	}
+}
+
+// foreachList returns a foreach step's items, or false if its source step
+// has not finished.
+func (p *runPlan) foreachList(src exprRef) ([]string, bool) {
+	if src.kind == refVar {
+		return splitList(p.run.vars[src.name]), true
+	}
+	o, ok := p.outcome(src.name)
+	if !ok {
+		return nil, false
+	}
+	return splitList(o.Output), true
+}
+
+// outcome returns a step's outcome. An expanded foreach step has finished
+// once all its items have: it failed if any item failed, was skipped if all
+// were (or it had none), and its output is theirs joined by newlines.
+func (p *runPlan) outcome(id string) (StepOutcome, bool) {
+	children, ok := p.children[id]
+	if !ok {
+		if o, ok := p.run.outcomes[id]; ok {
+			return o, true
+		}
+		if p.skipped[id] {
+			return StepOutcome{Status: OutcomeSkipped}, true
+		}
+		return StepOutcome{}, false
+	}
+
+	agg := StepOutcome{Status: OutcomeSkipped}
+	var outputs []string
+	for _, child := range children {
+		o, ok := p.outcome(child)
+		if !ok {
+			return StepOutcome{}, false
+		}
+		switch {
+		case o.Status == OutcomeFailure:
+			agg.Status = OutcomeFailure
+		case o.Status == OutcomeSuccess && agg.Status == OutcomeSkipped:
+			agg.Status = OutcomeSuccess
+		}
+		if o.Output != "" {
+			outputs = append(outputs, o.Output)
+		}
+	}
+	agg.Output = strings.Join(outputs, "\n")
+	return agg, true
+}
+
+// finished returns the plan steps that have an outcome or were skipped.
+func (p *runPlan) finished() map[string]bool {
+	done := make(map[string]bool)
+	for id := range p.run.outcomes {
+		done[id] = true
+	}
+	for id := range p.skipped {
+		done[id] = true
+	}
+	return done
+}
+
+// planEnv evaluates when expressions against a plan.
+type planEnv struct {
+	plan *runPlan
+	item string
+}
+
+func (e planEnv) lookup(ref exprRef) string {
+	switch ref.kind {
+	case refItem:
+		return e.item
+	case refStep:
+		o, _ := e.plan.outcome(ref.name)
+		if ref.field == fieldOutput {
+			return o.Output
+		}
+		return o.Status
+	default:
+		return e.plan.run.vars[ref.name]
+	}
+}
+
+// splitList splits a foreach list on commas and whitespace.
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// renderItem fills in {{item}} and {{index}} for a foreach item.
+func renderItem(text, item string, index int) string {
+	if text == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, "{{item}}", item)
+	return strings.ReplaceAll(text, "{{index}}", strconv.Itoa(index))
+}
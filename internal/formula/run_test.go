@@ -0,0 +1,295 @@
+package formula
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// releaseFormula fans out over a var and over a step's output, and branches
+// on outcomes.
+const releaseFormula = `
+formula = "release"
+type = "workflow"
+
+[vars]
+targets = "linux,darwin"
+dry_run = "false"
+
+[[steps]]
+id = "detect"
+title = "Detect changed packages"
+
+[[steps]]
+id = "test"
+title = "Test {{item}}"
+needs = ["detect"]
+foreach = "steps.detect.output"
+when = "item != 'docs'"
+parallel = true
+
+[[steps]]
+id = "build"
+title = "Build {{item}} ({{index}})"
+needs = ["test"]
+foreach = "targets"
+parallel = true
+
+[[steps]]
+id = "publish"
+title = "Publish"
+needs = ["build"]
+when = "!dry_run && steps.test.outcome == 'success'"
+
+[[steps]]
+id = "rollback"
+title = "Roll back"
+needs = ["build"]
+when = "steps.test.outcome == 'failure'"
+`
+
+func TestFormula_Pour(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "ship"
+type = "workflow"
+
+[vars]
+targets = "linux,darwin,windows"
+notarize = "false"
+
+[[steps]]
+id = "prepare"
+title = "Prepare {{version}}"
+
+[[steps]]
+id = "build"
+title = "Build {{item}} ({{index}})"
+needs = ["prepare"]
+foreach = "targets"
+when = "item != 'windows'"
+
+[[steps]]
+id = "notarize"
+title = "Notarize"
+needs = ["build"]
+when = "notarize"
+
+[[steps]]
+id = "publish"
+title = "Publish"
+needs = ["notarize"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	poured, err := f.Pour(map[string]string{"version": "1.2.0"})
+	if err != nil {
+		t.Fatalf("Pour failed: %v", err)
+	}
+	var ids []string
+	for _, step := range poured.Steps {
+		ids = append(ids, step.ID)
+		if step.When != "" || step.Foreach != "" {
+			t.Errorf("step %s keeps when %q / foreach %q", step.ID, step.When, step.Foreach)
+		}
+	}
+	if want := []string{"prepare", "build.0", "build.1", "publish"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("poured steps = %v, want %v", ids, want)
+	}
+	if got := poured.GetStep("build.1").Title; got != "Build darwin (1)" {
+		t.Errorf("build.1 title = %q", got)
+	}
+	if got := poured.GetStep("prepare").Title; got != "Prepare {{version}}" {
+		t.Errorf("prepare title = %q, want the var left for bd", got)
+	}
+	// publish needed the dropped notarize step, so it needs the builds.
+	if got := poured.GetStep("publish").Needs; !reflect.DeepEqual(got, []string{"build.0", "build.1"}) {
+		t.Errorf("publish needs = %v", got)
+	}
+	if len(f.Steps) != 4 || f.Steps[1].Foreach == "" {
+		t.Error("Pour modified the formula")
+	}
+
+	withNotarize, err := f.Pour(map[string]string{"notarize": "true", "targets": "linux"})
+	if err != nil {
+		t.Fatalf("Pour failed: %v", err)
+	}
+	if got := withNotarize.GetStep("publish").Needs; !reflect.DeepEqual(got, []string{"notarize"}) {
+		t.Errorf("publish needs = %v, want [notarize]", got)
+	}
+
+	release, err := Parse([]byte(releaseFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := release.Pour(nil); err == nil || !strings.Contains(err.Error(), "test, publish, rollback") {
+		t.Errorf("Pour(release) = %v, want a refusal naming the steps reading other steps", err)
+	}
+}
+
+func TestRun_ForeachAndWhen(t *testing.T) {
+	f, err := Parse([]byte(releaseFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := f.RunOnlySteps(); !reflect.DeepEqual(got, []string{"test", "publish", "rollback"}) {
+		t.Errorf("RunOnlySteps = %v, want the steps reading other steps", got)
+	}
+	r, err := f.NewRun(nil)
+	if err != nil {
+		t.Fatalf("NewRun failed: %v", err)
+	}
+
+	// Build expands from its var right away; test waits on detect.
+	order, err := r.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort failed: %v", err)
+	}
+	want := []string{"detect", "test", "build.0", "build.1", "publish", "rollback"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	if got := r.GetStep("build.1").Title; got != "Build darwin (1)" {
+		t.Errorf("build.1 title = %q", got)
+	}
+	if ready := r.ReadySteps(); !reflect.DeepEqual(ready, []string{"detect"}) {
+		t.Errorf("ready = %v, want [detect]", ready)
+	}
+	if err := r.Record("test", StepOutcome{Status: OutcomeSuccess}); err == nil {
+		t.Error("expected error recording a step that is not ready")
+	}
+
+	if err := r.Record("detect", StepOutcome{Status: OutcomeSuccess, Output: "api\ndocs\ncli\n"}); err != nil {
+		t.Fatal(err)
+	}
+	parallel, sequential := r.ParallelReadySteps()
+	if !reflect.DeepEqual(parallel, []string{"test.0", "test.2"}) || sequential != "" {
+		t.Errorf("ParallelReadySteps = %v, %q; want [test.0 test.2] (docs skipped)", parallel, sequential)
+	}
+	if o, _ := r.Outcome("test.1"); o.Status != OutcomeSkipped {
+		t.Errorf("test.1 (docs) outcome = %+v, want skipped", o)
+	}
+	if got := r.Plan().GetStep("build.0").Needs; !reflect.DeepEqual(got, []string{"test.0", "test.1", "test.2"}) {
+		t.Errorf("build.0 needs = %v, want the test items", got)
+	}
+
+	for _, id := range []string{"test.0", "test.2", "build.0", "build.1"} {
+		if err := r.Record(id, StepOutcome{Status: OutcomeSuccess}); err != nil {
+			t.Fatalf("Record(%s): %v", id, err)
+		}
+	}
+	if o, _ := r.Outcome("test"); o.Status != OutcomeSuccess {
+		t.Errorf("test outcome = %+v, want success", o)
+	}
+	if ready := r.ReadySteps(); !reflect.DeepEqual(ready, []string{"publish"}) {
+		t.Errorf("ready = %v, want [publish] (rollback skipped)", ready)
+	}
+	if err := r.Record("publish", StepOutcome{Status: OutcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Done() {
+		t.Error("run should be done")
+	}
+}
+
+func TestRun_FailureBranchAndEmptyForeach(t *testing.T) {
+	f, err := Parse([]byte(releaseFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	r, err := f.NewRun(map[string]string{"targets": ""})
+	if err != nil {
+		t.Fatalf("NewRun failed: %v", err)
+	}
+	if err := r.Record("detect", StepOutcome{Status: OutcomeSuccess, Output: "api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Record("test.0", StepOutcome{Status: OutcomeFailure}); err != nil {
+		t.Fatal(err)
+	}
+
+	// No targets: build has no items, so its dependents are ready at once.
+	if o, _ := r.Outcome("build"); o.Status != OutcomeSkipped {
+		t.Errorf("build outcome = %+v, want skipped", o)
+	}
+	if ready := r.ReadySteps(); !reflect.DeepEqual(ready, []string{"rollback"}) {
+		t.Errorf("ready = %v, want [rollback]", ready)
+	}
+}
+
+func TestParse_DynamicStepValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		step string
+		want string
+	}{
+		{"undefined var", `when = "missing == 'x'"`, "undefined var: missing"},
+		{"step not needed", `when = "steps.b.outcome == 'success'"`, "does not need"},
+		{"item without foreach", `when = "item == 'x'"`, "has no foreach"},
+		{"bad syntax", `when = "mode == "`, "when:"},
+		{"foreach outcome", "foreach = \"steps.a.outcome\"\nneeds = [\"a\"]", "foreach must name"},
+		{"foreach unknown step", `foreach = "steps.zz.output"`, "does not need"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `
+formula = "bad"
+
+[vars]
+mode = "fast"
+
+[[steps]]
+id = "a"
+title = "A"
+
+[[steps]]
+id = "b"
+title = "B"
+
+[[steps]]
+id = "c"
+title = "C"
+` + tt.step + "\n"
+			_, err := Parse([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	env := mapEnv{"vars.mode": "fast", "vars.flag": "no", "steps.a.outcome": "failure", "item": "x"}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"mode == 'fast'", true},
+		{`vars.mode != "fast"`, false},
+		{"flag", false},
+		{"!flag && (mode == 'slow' || item == 'x')", true},
+		{"steps.a.outcome == 'success' || false", false},
+		{"true && !''", true},
+	}
+	for _, tt := range tests {
+		cond, err := parseCondition(tt.expr)
+		if err != nil {
+			t.Errorf("parseCondition(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := cond.eval(env); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "(mode", "mode ==", "mode = 'x'", "'open", "steps.a.status", "foo.bar"} {
+		if _, err := parseCondition(bad); err == nil {
+			t.Errorf("parseCondition(%q) should fail", bad)
+		}
+	}
+}
+
+// mapEnv resolves references by their string form.
+type mapEnv map[string]string
+
+func (m mapEnv) lookup(ref exprRef) string { return m[ref.String()] }
@@ -0,0 +1,302 @@
+package formula
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultSimDuration is the duration assumed for steps with no estimate.
+const DefaultSimDuration = 10 * time.Minute
+
+// SimOptions configures Simulate.
+type SimOptions struct {
+	// Vars are the formula vars, as for NewRun.
+	Vars map[string]string
+
+	// Durations estimates step durations by ID. A foreach item ("test.0")
+	// falls back to its step ("test"), then to DefaultDuration.
+	Durations map[string]time.Duration
+
+	// DefaultDuration is used for steps missing from Durations. Zero means
+	// DefaultSimDuration.
+	DefaultDuration time.Duration
+
+	// Outputs are step outputs, for foreach lists and when conditions that
+	// read them. Steps without one finish with no output.
+	Outputs map[string]string
+
+	// Failures lists steps that should finish with a failure outcome.
+	Failures map[string]bool
+}
+
+// SimStep is one step of a simulated schedule.
+type SimStep struct {
+	ID       string
+	Title    string
+	Wave     int // 1-based; 0 for skipped steps
+	Start    time.Duration
+	Duration time.Duration
+	Needs    []string
+	Skipped  bool
+	Critical bool
+}
+
+// End returns when the step finishes, relative to the start of the run.
+func (s SimStep) End() time.Duration {
+	return s.Start + s.Duration
+}
+
+// Simulation is the schedule of a formula run in which every ready step
+// starts at once, as dispatched wave by wave from ParallelReadySteps.
+type Simulation struct {
+	Formula string
+	Type    FormulaType
+
+	// Waves lists the steps dispatched together, in order. A wave ends
+	// when its longest step does.
+	Waves [][]string
+
+	// Steps lists the scheduled steps in dispatch order, then the skipped.
+	Steps []SimStep
+
+	// MaxParallel is the largest wave: the workers needed at peak.
+	MaxParallel int
+
+	// CriticalPath is the longest chain of dependent steps, and
+	// CriticalDuration its length: the wall-clock time with no waiting
+	// between waves.
+	CriticalPath     []string
+	CriticalDuration time.Duration
+
+	// WallClock is the estimated time from first dispatch to last finish.
+	WallClock time.Duration
+}
+
+// Step returns a simulated step by ID, or nil if not found.
+func (s *Simulation) Step(id string) *SimStep {
+	for i := range s.Steps {
+		if s.Steps[i].ID == id {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Simulate runs the formula in memory: no beads are created. Workflow
+// formulas run through a Run, so when and foreach are applied using
+// opts.Vars and opts.Outputs; other types are scheduled from their static
+// graph, with a convoy's synthesis after its legs.
+func (f *Formula) Simulate(opts SimOptions) (*Simulation, error) {
+	if opts.DefaultDuration <= 0 {
+		opts.DefaultDuration = DefaultSimDuration
+	}
+	sim := &Simulation{Formula: f.Name, Type: f.Type}
+
+	var err error
+	if f.Type == TypeWorkflow {
+		err = sim.runWorkflow(f, opts)
+	} else {
+		err = sim.runStatic(f, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wave := range sim.Waves {
+		if len(wave) > sim.MaxParallel {
+			sim.MaxParallel = len(wave)
+		}
+	}
+	sim.criticalPath()
+	return sim, nil
+}
+
+// runWorkflow schedules a workflow formula through a Run.
+func (s *Simulation) runWorkflow(f *Formula, opts SimOptions) error {
+	r, err := f.NewRun(opts.Vars)
+	if err != nil {
+		return err
+	}
+	for !r.Done() {
+		parallel, sequential := r.ParallelReadySteps()
+		wave := parallel
+		if sequential != "" {
+			wave = []string{sequential}
+		}
+		if len(wave) == 0 {
+			return fmt.Errorf("simulating %s: no step is ready but the run is not done", f.Name)
+		}
+		s.addWave(wave, func(id string) (string, []string) {
+			step := r.GetStep(id)
+			return step.Title, step.Needs
+		}, opts)
+		for _, id := range wave {
+			outcome := StepOutcome{Status: OutcomeSuccess, Output: opts.Outputs[id]}
+			if opts.Failures[id] {
+				outcome.Status = OutcomeFailure
+			}
+			if err := r.Record(id, outcome); err != nil {
+				return fmt.Errorf("simulating %s: %w", f.Name, err)
+			}
+		}
+	}
+
+	for _, step := range r.Plan().Steps {
+		if o, ok := r.Outcome(step.ID); ok && o.Status == OutcomeSkipped {
+			s.Steps = append(s.Steps, SimStep{ID: step.ID, Title: step.Title, Needs: step.Needs, Skipped: true})
+		}
+	}
+	return nil
+}
+
+// runStatic schedules a convoy, expansion or aspect formula.
+func (s *Simulation) runStatic(f *Formula, opts SimOptions) error {
+	info := func(id string) (string, []string) {
+		switch f.Type {
+		case TypeConvoy:
+			if leg := f.GetLeg(id); leg != nil {
+				return leg.Title, nil
+			}
+		case TypeExpansion:
+			if tmpl := f.GetTemplate(id); tmpl != nil {
+				return tmpl.Title, tmpl.Needs
+			}
+		case TypeAspect:
+			if aspect := f.GetAspect(id); aspect != nil {
+				return aspect.Title, nil
+			}
+		}
+		return "", nil
+	}
+
+	completed := make(map[string]bool)
+	for {
+		wave, _ := f.ParallelReadySteps(completed)
+		if len(wave) == 0 {
+			break
+		}
+		s.addWave(wave, info, opts)
+		for _, id := range wave {
+			completed[id] = true
+		}
+	}
+	if len(completed) != len(f.GetAllIDs()) {
+		return fmt.Errorf("simulating %s: dependency cycle", f.Name)
+	}
+
+	if f.Type == TypeConvoy && f.Synthesis != nil {
+		needs := f.Synthesis.DependsOn
+		if len(needs) == 0 {
+			needs = f.GetAllIDs()
+		}
+		s.addWave([]string{"synthesis"}, func(string) (string, []string) {
+			return f.Synthesis.Title, needs
+		}, opts)
+	}
+	return nil
+}
+
+// addWave schedules steps to start together after the previous wave.
+func (s *Simulation) addWave(ids []string, info func(id string) (title string, needs []string), opts SimOptions) {
+	start := s.WallClock
+	n := len(s.Waves) + 1
+	for _, id := range ids {
+		title, needs := info(id)
+		step := SimStep{
+			ID:       id,
+			Title:    title,
+			Wave:     n,
+			Start:    start,
+			Duration: opts.duration(id),
+			Needs:    needs,
+		}
+		s.Steps = append(s.Steps, step)
+		if step.End() > s.WallClock {
+			s.WallClock = step.End()
+		}
+	}
+	s.Waves = append(s.Waves, ids)
+}
+
+// duration returns the estimated duration of a step.
+func (o SimOptions) duration(id string) time.Duration {
+	if d, ok := o.Durations[id]; ok {
+		return d
+	}
+	if origin, ok := ForeachOrigin(id); ok {
+		if d, ok := o.Durations[origin]; ok {
+			return d
+		}
+	}
+	return o.DefaultDuration
+}
+
+// ForeachOrigin returns the step a foreach item ("test.0") expanded from,
+// or false if id is not an item.
+func ForeachOrigin(id string) (string, bool) {
+	for i := len(id) - 1; i > 0; i-- {
+		c := id[i]
+		if c == '.' {
+			return id[:i], i < len(id)-1
+		}
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// criticalPath finds the longest chain of dependent steps. Skipped steps
+// take no time but still pass their needs on to their dependents.
+func (s *Simulation) criticalPath() {
+	byID := make(map[string]*SimStep, len(s.Steps))
+	for i := range s.Steps {
+		byID[s.Steps[i].ID] = &s.Steps[i]
+	}
+
+	finish := make(map[string]time.Duration)
+	prev := make(map[string]string)
+	var visit func(id string) time.Duration
+	visit = func(id string) time.Duration {
+		if d, ok := finish[id]; ok {
+			return d
+		}
+		step := byID[id]
+		if step == nil {
+			return 0
+		}
+		finish[id] = 0 // guards against cycles in hand-built formulas
+		var longest time.Duration
+		for _, need := range step.Needs {
+			if d := visit(need); d > longest || prev[id] == "" {
+				longest = d
+				prev[id] = need
+			}
+		}
+		finish[id] = longest + step.Duration
+		return finish[id]
+	}
+
+	var last string
+	for _, step := range s.Steps {
+		if d := visit(step.ID); last == "" || d > finish[last] {
+			last = step.ID
+		}
+	}
+	if last == "" {
+		return
+	}
+
+	s.CriticalDuration = finish[last]
+	var path []string
+	for id := last; id != ""; id = prev[id] {
+		if step := byID[id]; step != nil && !step.Skipped {
+			step.Critical = true
+			path = append(path, id)
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	s.CriticalPath = path
+}
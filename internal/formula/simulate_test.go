@@ -0,0 +1,112 @@
+package formula
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSimulate_Workflow(t *testing.T) {
+	f, err := Parse([]byte(releaseFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	sim, err := f.Simulate(SimOptions{
+		Durations: map[string]time.Duration{
+			"detect":  5 * time.Minute,
+			"test":    20 * time.Minute,
+			"test.2":  30 * time.Minute,
+			"build":   10 * time.Minute,
+			"publish": 5 * time.Minute,
+		},
+		Outputs: map[string]string{"detect": "api docs web"},
+	})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+
+	wantWaves := [][]string{{"detect"}, {"test.0", "test.2"}, {"build.0", "build.1"}, {"publish"}}
+	if !reflect.DeepEqual(sim.Waves, wantWaves) {
+		t.Errorf("Waves = %v, want %v", sim.Waves, wantWaves)
+	}
+	if sim.MaxParallel != 2 {
+		t.Errorf("MaxParallel = %d, want 2", sim.MaxParallel)
+	}
+	if sim.WallClock != 50*time.Minute {
+		t.Errorf("WallClock = %v, want 50m", sim.WallClock)
+	}
+	wantPath := []string{"detect", "test.2", "build.0", "publish"}
+	if !reflect.DeepEqual(sim.CriticalPath, wantPath) || sim.CriticalDuration != 50*time.Minute {
+		t.Errorf("CriticalPath = %v (%v), want %v (50m)", sim.CriticalPath, sim.CriticalDuration, wantPath)
+	}
+	for _, id := range []string{"test.1", "rollback"} {
+		if step := sim.Step(id); step == nil || !step.Skipped {
+			t.Errorf("step %s should be skipped, got %+v", id, step)
+		}
+	}
+	if step := sim.Step("build.1"); step.Start != 35*time.Minute || step.Critical {
+		t.Errorf("build.1 = %+v, want start 35m, not critical", step)
+	}
+}
+
+func TestSimulate_ConvoyCriticalPathShorterThanWaves(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "review"
+type = "convoy"
+version = 1
+[[legs]]
+id = "a"
+[[legs]]
+id = "b"
+[[legs]]
+id = "c"
+[synthesis]
+title = "Combine"
+depends_on = ["a"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	sim, err := f.Simulate(SimOptions{
+		Durations:       map[string]time.Duration{"b": 20 * time.Minute, "synthesis": 5 * time.Minute},
+		DefaultDuration: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+
+	if want := [][]string{{"a", "b", "c"}, {"synthesis"}}; !reflect.DeepEqual(sim.Waves, want) {
+		t.Errorf("Waves = %v, want %v", sim.Waves, want)
+	}
+	if sim.MaxParallel != 3 || sim.WallClock != 25*time.Minute {
+		t.Errorf("MaxParallel = %d, WallClock = %v; want 3, 25m", sim.MaxParallel, sim.WallClock)
+	}
+	// Synthesis only needs "a", but waits for the whole wave.
+	if want := []string{"b"}; !reflect.DeepEqual(sim.CriticalPath, want) || sim.CriticalDuration != 20*time.Minute {
+		t.Errorf("CriticalPath = %v (%v), want %v (20m)", sim.CriticalPath, sim.CriticalDuration, want)
+	}
+}
+
+func TestSimulate_MissingVar(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "needs-var"
+type = "workflow"
+[vars.issue]
+required = true
+[[steps]]
+id = "work"
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := f.Simulate(SimOptions{}); err == nil {
+		t.Error("Simulate should fail without a required var")
+	}
+	sim, err := f.Simulate(SimOptions{Vars: map[string]string{"issue": "gt-1"}})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if sim.WallClock != DefaultSimDuration {
+		t.Errorf("WallClock = %v, want the default %v", sim.WallClock, DefaultSimDuration)
+	}
+}
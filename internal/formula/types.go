@@ -28,92 +28,107 @@ const (
 type Formula struct {
 	// Common fields
 	Name        string      `toml:"formula"`
-	Description string      `toml:"description"`
-	Type        FormulaType `toml:"type"`
-	Version     int         `toml:"version"`
+	Description string      `toml:"description,omitempty"`
+	Type        FormulaType `toml:"type,omitempty"`
+	Version     int         `toml:"version,omitempty"`
+
+	// Composition, resolved by Parse
+	Extends StringList `toml:"extends,omitempty"` // Formulas whose content this one inherits and overrides by ID
+	Include []Include  `toml:"include,omitempty"` // Formulas whose steps, vars and legs are added to this one
+
+	// Compose lists aspects and expansions that bd cook applies. Parse
+	// keeps them, inheriting a parent's, but does not apply them.
+	Compose *Compose `toml:"compose,omitempty"`
 
 	// Convoy-specific
-	Inputs    map[string]Input `toml:"inputs"`
-	Prompts   map[string]string `toml:"prompts"`
-	Output    *Output           `toml:"output"`
-	Legs      []Leg             `toml:"legs"`
-	Synthesis *Synthesis        `toml:"synthesis"`
+	Inputs    map[string]Input  `toml:"inputs,omitempty"`
+	Prompts   map[string]string `toml:"prompts,omitempty"`
+	Output    *Output           `toml:"output,omitempty"`
+	Legs      []Leg             `toml:"legs,omitempty"`
+	Synthesis *Synthesis        `toml:"synthesis,omitempty"`
 
 	// Workflow-specific
-	Steps []Step           `toml:"steps"`
-	Vars  map[string]Var   `toml:"vars"`
+	Steps []Step         `toml:"steps,omitempty"`
+	Vars  map[string]Var `toml:"vars,omitempty"`
 
 	// Expansion-specific
-	Template []Template `toml:"template"`
+	Template []Template `toml:"template,omitempty"`
 
 	// Aspect-specific (similar to convoy but for analysis)
-	Aspects []Aspect `toml:"aspects"`
+	Aspects []Aspect `toml:"aspects,omitempty"`
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
 type Aspect struct {
 	ID          string `toml:"id"`
-	Title       string `toml:"title"`
-	Focus       string `toml:"focus"`
-	Description string `toml:"description"`
+	Title       string `toml:"title,omitempty"`
+	Focus       string `toml:"focus,omitempty"`
+	Description string `toml:"description,omitempty"`
 }
 
 // Input represents an input parameter for a formula.
 type Input struct {
-	Description    string   `toml:"description"`
-	Type           string   `toml:"type"`
-	Required       bool     `toml:"required"`
-	RequiredUnless []string `toml:"required_unless"`
-	Default        string   `toml:"default"`
+	Description    string   `toml:"description,omitempty"`
+	Type           string   `toml:"type,omitempty"`   // See the Param* constants; empty means string
+	Values         []string `toml:"values,omitempty"` // Allowed values, for enum
+	Pattern        string   `toml:"pattern,omitempty"`
+	Required       bool     `toml:"required,omitempty"`
+	RequiredUnless []string `toml:"required_unless,omitempty"`
+	Default        string   `toml:"default,omitempty"`
 }
 
 // Output configures where formula outputs are written.
 type Output struct {
-	Directory  string `toml:"directory"`
-	LegPattern string `toml:"leg_pattern"`
-	Synthesis  string `toml:"synthesis"`
+	Directory  string `toml:"directory,omitempty"`
+	LegPattern string `toml:"leg_pattern,omitempty"`
+	Synthesis  string `toml:"synthesis,omitempty"`
 }
 
 // Leg represents a parallel execution unit in a convoy formula.
 type Leg struct {
 	ID          string `toml:"id"`
-	Title       string `toml:"title"`
-	Focus       string `toml:"focus"`
-	Description string `toml:"description"`
+	Title       string `toml:"title,omitempty"`
+	Focus       string `toml:"focus,omitempty"`
+	Description string `toml:"description,omitempty"`
 }
 
 // Synthesis represents the synthesis step that combines leg outputs.
 type Synthesis struct {
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	DependsOn   []string `toml:"depends_on"`
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	DependsOn   []string `toml:"depends_on,omitempty"`
 }
 
 // Step represents a sequential step in a workflow formula.
 type Step struct {
 	ID          string   `toml:"id"`
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	Needs       []string `toml:"needs"`
-	Parallel    bool     `toml:"parallel"`   // If true, this step can run concurrently with other parallel steps that share the same needs
-	Acceptance  string   `toml:"acceptance"` // Exit criteria for this step (used by Ralph loop mode)
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	Needs       []string `toml:"needs,omitempty"`
+	Parallel    bool     `toml:"parallel,omitempty"`   // If true, this step can run concurrently with other parallel steps that share the same needs
+	Acceptance  string   `toml:"acceptance,omitempty"` // Exit criteria for this step (used by Ralph loop mode)
+	When        string   `toml:"when,omitempty"`       // Condition on vars and prior step outcomes; the step is skipped when false (see Run)
+	Foreach     string   `toml:"foreach,omitempty"`    // List var or prior step output to fan the step out over, one step per item (see Run)
 }
 
 // Template represents a template step in an expansion formula.
 type Template struct {
 	ID          string   `toml:"id"`
-	Title       string   `toml:"title"`
-	Description string   `toml:"description"`
-	Needs       []string `toml:"needs"`
+	Title       string   `toml:"title,omitempty"`
+	Description string   `toml:"description,omitempty"`
+	Needs       []string `toml:"needs,omitempty"`
 }
 
 // Var represents a variable definition for formulas.
 // Supports both shorthand string syntax (wisp_type = "gc_report")
 // and full table syntax ([vars.wisp_type] with description/required/default).
 type Var struct {
-	Description string `toml:"description"`
-	Required    bool   `toml:"required"`
-	Default     string `toml:"default"`
+	Description string   `toml:"description,omitempty"`
+	Type        string   `toml:"type,omitempty"`   // See the Param* constants; empty means string
+	Values      []string `toml:"values,omitempty"` // Allowed values, for enum
+	Pattern     string   `toml:"pattern,omitempty"`
+	Required    bool     `toml:"required,omitempty"`
+	Default     string   `toml:"default,omitempty"`
 }
 
 // UnmarshalTOML allows Var to be decoded from either a plain string
@@ -129,6 +144,29 @@ func (v *Var) UnmarshalTOML(data any) error {
 				v.Description = s
 			}
 		}
+		if t, ok := val["type"]; ok {
+			if s, ok := t.(string); ok {
+				v.Type = s
+			}
+		}
+		if vals, ok := val["values"]; ok {
+			list, ok := vals.([]any)
+			if !ok {
+				return fmt.Errorf("expected array for Var values, got %T", vals)
+			}
+			for _, item := range list {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("expected string in Var values, got %T", item)
+				}
+				v.Values = append(v.Values, s)
+			}
+		}
+		if p, ok := val["pattern"]; ok {
+			if s, ok := p.(string); ok {
+				v.Pattern = s
+			}
+		}
 		if r, ok := val["required"]; ok {
 			if b, ok := r.(bool); ok {
 				v.Required = b
@@ -68,12 +68,13 @@ func (f *Formula) ValidateTemplateVariables() error {
 	allText.WriteString(f.Description)
 	allText.WriteString("\n")
 
-	// Steps (workflow)
+	// Steps (workflow). A foreach step's {{item}} is filled in per item.
 	for _, step := range f.Steps {
-		allText.WriteString(step.Title)
-		allText.WriteString("\n")
-		allText.WriteString(step.Description)
-		allText.WriteString("\n")
+		text := step.Title + "\n" + step.Description + "\n"
+		if step.Foreach != "" {
+			text = strings.ReplaceAll(text, "{{item}}", "")
+		}
+		allText.WriteString(text)
 	}
 
 	// Legs (convoy)
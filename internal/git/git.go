@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -727,6 +728,17 @@ func (g *Git) CheckConflicts(source, target string) ([]string, error) {
 	return nil, nil
 }
 
+// MergeTree returns the tree a merge of source into target would produce,
+// without touching the working directory or index.
+func (g *Git) MergeTree(target, source string) (string, error) {
+	out, err := g.run("merge-tree", "--write-tree", target, source)
+	if err != nil {
+		return "", err
+	}
+	tree, _, _ := strings.Cut(out, "\n")
+	return strings.TrimSpace(tree), nil
+}
+
 // runMergeCheck runs a git merge command and returns error info from both stdout and stderr.
 // ZFC: Returns GitError with raw output for agent observation.
 func (g *Git) runMergeCheck(args ...string) (string, error) {
@@ -1112,6 +1124,120 @@ func (g *Git) CommitsAhead(base, branch string) (int, error) {
 	return count, nil
 }
 
+// FirstParentCommits returns the commits on head's first-parent chain after
+// base, oldest first. On a branch that only receives squash merges these are
+// the individual merges.
+func (g *Git) FirstParentCommits(base, head string) ([]string, error) {
+	out, err := g.run("rev-list", "--first-parent", "--reverse", base+".."+head)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// ChangedFiles returns the files head changes relative to its merge base
+// with base, i.e. what merging head into base would touch.
+func (g *Git) ChangedFiles(base, head string) ([]string, error) {
+	out, err := g.run("diff", "--name-only", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// DiffLines returns the number of lines added plus deleted between the
+// merge base of base and head, and head. Binary files count as zero lines.
+func (g *Git) DiffLines(base, head string) (int, error) {
+	out, err := g.run("diff", "--numstat", base+"..."+head)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		for _, n := range fields[:2] {
+			if v, err := strconv.Atoi(n); err == nil {
+				total += v
+			}
+		}
+	}
+	return total, nil
+}
+
+// DiffStat is one file's line counts from git diff --numstat.
+type DiffStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool // numstat reports no line counts for binary files
+}
+
+// DiffStats returns line counts for the files added or modified between the
+// merge base of base and head, and head. Deleted files are omitted.
+func (g *Git) DiffStats(base, head string) ([]DiffStat, error) {
+	out, err := g.run("diff", "--numstat", "-z", "--no-renames", "--diff-filter=d", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	var stats []DiffStat
+	for _, rec := range strings.Split(out, "\x00") {
+		fields := strings.SplitN(rec, "\t", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		st := DiffStat{Path: fields[2], Binary: fields[0] == "-"}
+		st.Added, _ = strconv.Atoi(fields[0])
+		st.Deleted, _ = strconv.Atoi(fields[1])
+		stats = append(stats, st)
+	}
+	return stats, nil
+}
+
+// CommitPatches returns the patches, without context lines or commit
+// headers, of each non-merge commit in head but not base, for the files the
+// commit adds or modifies. Unlike a diff of the end trees, it includes lines
+// a later commit removes again.
+func (g *Git) CommitPatches(base, head string) (string, error) {
+	return g.run("log", "-p", "--no-merges", "--format=", "--no-color", "--no-ext-diff", "--unified=0", "--no-renames", "--diff-filter=d", base+".."+head)
+}
+
+// BlobSize returns the size in bytes of path at rev.
+func (g *Git) BlobSize(rev, path string) (int64, error) {
+	out, err := g.run("cat-file", "-s", rev+":"+path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(out, 10, 64)
+}
+
+// Revert commits the inverse of commit on the current branch. Merge commits
+// are reverted against their first parent. A revert that doesn't apply is
+// aborted, leaving the branch unchanged.
+func (g *Git) Revert(commit string) error {
+	args := []string{"revert", "--no-edit"}
+	parents, err := g.run("rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return err
+	}
+	if len(strings.Fields(parents)) > 2 {
+		args = append(args, "-m", "1")
+	}
+	if _, err := g.run(append(args, commit)...); err != nil {
+		_, _ = g.run("revert", "--abort")
+		return err
+	}
+	return nil
+}
+
 // CountCommitsBehind returns the number of commits that HEAD is behind the given ref.
 // For example, CountCommitsBehind("origin/main") returns how many commits
 // are on origin/main that are not on the current HEAD.
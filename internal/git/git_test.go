@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -298,6 +299,135 @@ func TestRev(t *testing.T) {
 	}
 }
 
+func TestFirstParentCommitsAndRevert(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("Rev: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	commits, err := g.FirstParentCommits(base, "HEAD")
+	if err != nil {
+		t.Fatalf("FirstParentCommits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2", len(commits))
+	}
+	if head, _ := g.Rev("HEAD"); commits[1] != head {
+		t.Errorf("last commit = %s, want HEAD %s", commits[1], head)
+	}
+	if none, err := g.FirstParentCommits("HEAD", "HEAD"); err != nil || len(none) != 0 {
+		t.Errorf("FirstParentCommits(HEAD, HEAD) = %v, %v; want none", none, err)
+	}
+
+	if err := g.Revert(commits[0]); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt should be gone after reverting its commit")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.txt")); err != nil {
+		t.Errorf("b.txt should survive the revert: %v", err)
+	}
+}
+
+func TestChangedFilesAndDiffLines(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("Rev: %v", err)
+	}
+	for _, name := range []string{"b.txt", "a.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	changed, err := g.ChangedFiles(base, "HEAD")
+	if err != nil {
+		t.Fatalf("ChangedFiles: %v", err)
+	}
+	if len(changed) != 2 || changed[0] != "a.txt" || changed[1] != "b.txt" {
+		t.Errorf("ChangedFiles = %v, want [a.txt b.txt]", changed)
+	}
+	if lines, err := g.DiffLines(base, "HEAD"); err != nil || lines != 2 {
+		t.Errorf("DiffLines = %d, %v; want 2", lines, err)
+	}
+}
+
+func TestDiffStatsPatchAndBlobSize(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("Rev: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blob.bin"), []byte{0, 1, 2, 0, 3}, 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("."); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("add files"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	stats, err := g.DiffStats(base, "HEAD")
+	if err != nil {
+		t.Fatalf("DiffStats: %v", err)
+	}
+	want := []DiffStat{{Path: "blob.bin", Binary: true}, {Path: "notes.txt", Added: 2}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("DiffStats = %+v, want %+v", stats, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("notes.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("drop two"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	patch, err := g.CommitPatches(base, "HEAD")
+	if err != nil {
+		t.Fatalf("CommitPatches: %v", err)
+	}
+	if !strings.Contains(patch, "+++ b/notes.txt") || !strings.Contains(patch, "+two") {
+		t.Errorf("CommitPatches missing a line added then removed:\n%s", patch)
+	}
+
+	if size, err := g.BlobSize("HEAD", "blob.bin"); err != nil || size != 5 {
+		t.Errorf("BlobSize = %d, %v; want 5", size, err)
+	}
+}
+
 func TestFetchBranch(t *testing.T) {
 	// Create a "remote" repo
 	remoteDir := t.TempDir()
@@ -0,0 +1,341 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Labels recorded on the local copy of a message forwarded to another town.
+const (
+	// RemoteLabelMachinePrefix names the machine the message was forwarded to.
+	RemoteLabelMachinePrefix = "remote-machine:"
+	// RemoteLabelIDPrefix holds the bead ID of the message in the remote town.
+	RemoteLabelIDPrefix = "remote-id:"
+)
+
+// remoteAddressSchemes are address prefixes that look like "machine:" but
+// name local routing targets instead.
+var remoteAddressSchemes = map[string]bool{
+	"list":     true,
+	"queue":    true,
+	"announce": true,
+	"channel":  true,
+	"group":    true,
+}
+
+// ParseRemoteAddress splits a machine-qualified address ("machine:rig/name")
+// into the machine name and the address within that machine's town.
+// Returns ok=false for plain addresses and local routing schemes
+// (list:, queue:, announce:, channel:, group:, @group).
+//
+// The machine "local" is returned like any other; callers treat it as the
+// local town.
+func ParseRemoteAddress(address string) (machine, local string, ok bool) {
+	if isGroupAddress(address) {
+		return "", "", false
+	}
+	idx := strings.Index(address, ":")
+	if idx <= 0 {
+		return "", "", false
+	}
+	machine, local = address[:idx], address[idx+1:]
+	if remoteAddressSchemes[machine] || strings.ContainsAny(machine, "/@ ") {
+		return "", "", false
+	}
+	// Reject URI-style addresses (scheme://...) and empty targets.
+	if local == "" || strings.HasPrefix(local, "/") {
+		return "", "", false
+	}
+	return machine, local, true
+}
+
+// QualifyAddress prefixes a local address with this town's name so that a
+// remote town can route replies back. The remote town must register this
+// town in its mayor/machines.json under the same name. Addresses that are
+// already machine-qualified, and towns without a name, are returned unchanged.
+func QualifyAddress(townRoot, address string) string {
+	if _, _, ok := ParseRemoteAddress(address); ok {
+		return address
+	}
+	if townRoot == "" {
+		return address
+	}
+	name, err := workspace.GetTownName(townRoot)
+	if err != nil || name == "" {
+		return address
+	}
+	return name + ":" + address
+}
+
+// RemoteTown is another Gas Town reachable through the machine registry.
+type RemoteTown struct {
+	// Machine is the registry entry name.
+	Machine string
+
+	// Conn executes commands on the remote machine.
+	Conn connection.Connection
+
+	// TownPath is the remote town root.
+	TownPath string
+}
+
+// OpenRemoteTown looks up machine in the town's registry (mayor/machines.json)
+// and returns a handle on the town it hosts.
+func OpenRemoteTown(townRoot, machine string) (*RemoteTown, error) {
+	if townRoot == "" {
+		return nil, fmt.Errorf("cross-town delivery to %q requires a Gas Town workspace", machine)
+	}
+	registryPath := constants.MayorMachinesPath(townRoot)
+	reg, err := connection.NewMachineRegistry(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	m, err := reg.Get(machine)
+	if err != nil {
+		return nil, err
+	}
+	if m.TownPath == "" {
+		return nil, fmt.Errorf("machine %q has no town_path configured in %s", machine, registryPath)
+	}
+	conn, err := reg.Connection(machine)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteTown{Machine: machine, Conn: conn, TownPath: m.TownPath}, nil
+}
+
+// Gt runs a gt command in the remote town root.
+func (rt *RemoteTown) Gt(args ...string) ([]byte, error) {
+	out, err := rt.Conn.ExecDir(rt.TownPath, "gt", args...)
+	if err != nil {
+		return out, fmt.Errorf("gt %s on %s: %w: %s", args[0], rt.Machine, err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// bd runs a bd command against the remote town's beads database.
+func (rt *RemoteTown) bd(args ...string) ([]byte, error) {
+	env := map[string]string{"BEADS_DIR": path.Join(rt.TownPath, ".beads")}
+	out, err := rt.Conn.ExecEnv(env, "bd", args...)
+	if err != nil {
+		return out, &bdError{Err: err, Stderr: strings.TrimSpace(string(out))}
+	}
+	return out, nil
+}
+
+// sendToRemote forwards a message to an agent in another town.
+//
+// The message is written into the remote town's mail beads with the sender
+// qualified by this town's name, so the recipient can reply. A local copy
+// assigned to the qualified recipient records the remote bead ID;
+// SyncRemoteDeliveries later copies the remote delivery ack back onto it.
+//
+// The recipient is validated by the remote town's agents, not ours; an
+// unknown recipient leaves the delivery pending.
+func (r *Router) sendToRemote(msg *Message, machine, address string) error {
+	if msg.ID == "" {
+		msg.ID = generateID()
+	}
+	if err := msg.Validate(); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	if isListAddress(address) || isQueueAddress(address) || isAnnounceAddress(address) ||
+		isChannelAddress(address) || isGroupAddress(address) {
+		return fmt.Errorf("invalid recipient %q: cross-town mail must name a single agent", msg.To)
+	}
+
+	rt, err := OpenRemoteTown(r.townRoot, machine)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	// The remote copy speaks the remote town's addresses: the recipient is
+	// local there, and our sender and CCs are qualified with our town name.
+	remoteMsg := *msg
+	remoteMsg.To = address
+	remoteMsg.From = QualifyAddress(r.townRoot, msg.From)
+	remoteMsg.CC = nil
+	for _, cc := range msg.CC {
+		remoteMsg.CC = append(remoteMsg.CC, QualifyAddress(r.townRoot, cc))
+	}
+
+	out, err := rt.bd(r.messageCreateArgs(&remoteMsg, AddressToIdentity(address), nil, "--json")...)
+	if err != nil {
+		return fmt.Errorf("sending message to %s: %w", machine, err)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(jsonPayload(out), &created); err != nil || created.ID == "" {
+		return fmt.Errorf("sending message to %s: unexpected bd create output: %s", machine, strings.TrimSpace(string(out)))
+	}
+
+	// Record the local copy. It is assigned to the qualified identity, which
+	// no local mailbox matches, so it only serves as the delivery record.
+	toIdentity := machine + ":" + AddressToIdentity(address)
+	args := r.messageCreateArgs(msg, toIdentity, []string{
+		RemoteLabelMachinePrefix + machine,
+		RemoteLabelIDPrefix + created.ID,
+	})
+
+	beadsDir := r.resolveBeadsDir()
+	if err := r.ensureCustomTypes(beadsDir); err != nil {
+		return err
+	}
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	if _, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir); err != nil {
+		return fmt.Errorf("message delivered to %s as %s, but recording local copy failed: %w", machine, created.ID, err)
+	}
+
+	if !msg.SuppressNotify {
+		msgCopy := remoteMsg
+		r.notifyWg.Add(1)
+		go func() {
+			defer r.notifyWg.Done()
+			notifyRemoteRecipient(rt, &msgCopy) //nolint:errcheck
+		}()
+	}
+
+	return nil
+}
+
+// notifyRemoteRecipient nudges the recipient through the remote town's own
+// gt nudge, which applies that town's DND settings and session lookup.
+func notifyRemoteRecipient(rt *RemoteTown, msg *Message) error {
+	notification := fmt.Sprintf("📬 You have new mail from %s. Subject: %s. Run 'gt mail inbox' to read.", msg.From, msg.Subject)
+	_, err := rt.Gt("nudge", msg.To, "--mode", "wait-idle", "--from", msg.From, "-m", notification)
+	return err
+}
+
+// SyncRemoteDeliveries copies delivery acks for messages forwarded to other
+// towns back onto their local copies. For each pending local copy, the
+// remote message's delivery labels are read; once the remote recipient has
+// acked, the same ack sequence (with the machine-qualified recipient) is
+// written locally. Returns the number of deliveries newly marked acked.
+//
+// Unreachable machines are skipped and reported together in the error;
+// their deliveries stay pending until a later sync succeeds.
+func (r *Router) SyncRemoteDeliveries() (int, error) {
+	beadsDir := r.resolveBeadsDir()
+
+	ctx, cancel := bdReadCtx()
+	stdout, err := runBdCommand(ctx, []string{"list", "--label", "gt:message", "--json", "--limit", "0"}, r.workDir, beadsDir)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	var msgs []BeadsMessage
+	if len(stdout) > 0 && string(stdout) != "null" {
+		if err := json.Unmarshal(stdout, &msgs); err != nil {
+			return 0, fmt.Errorf("parsing messages: %w", err)
+		}
+	}
+
+	towns := make(map[string]*RemoteTown)
+	var errs []string
+	acked := 0
+	for i := range msgs {
+		bm := &msgs[i]
+		machine, remoteID := remoteDeliveryRef(bm.Labels)
+		if remoteID == "" {
+			continue
+		}
+		if state, _, _ := ParseDeliveryLabels(bm.Labels); state != DeliveryStatePending {
+			continue
+		}
+
+		rt, seen := towns[machine]
+		if !seen {
+			rt, err = OpenRemoteTown(r.townRoot, machine)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", machine, err))
+			}
+			towns[machine] = rt
+		}
+		if rt == nil {
+			continue
+		}
+
+		out, err := rt.bd("show", remoteID, "--json")
+		if err != nil {
+			var connErr *connection.ConnectionError
+			if errors.As(err, &connErr) {
+				errs = append(errs, fmt.Sprintf("%s: %v", machine, err))
+				towns[machine] = nil
+			}
+			continue
+		}
+		var remote []BeadsMessage
+		if err := json.Unmarshal(jsonPayload(out), &remote); err != nil || len(remote) == 0 {
+			continue
+		}
+		state, ackedBy, ackedAt := ParseDeliveryLabels(remote[0].Labels)
+		if state != DeliveryStateAcked {
+			continue
+		}
+		at := time.Now()
+		if ackedAt != nil {
+			at = *ackedAt
+		}
+		if err := r.writeRemoteAck(beadsDir, bm.ID, machine+":"+ackedBy, at); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", bm.ID, err))
+			continue
+		}
+		acked++
+	}
+
+	if len(errs) > 0 {
+		return acked, fmt.Errorf("syncing remote deliveries: %s", strings.Join(errs, "; "))
+	}
+	return acked, nil
+}
+
+// writeRemoteAck writes the phase-2 ack label sequence onto a local copy.
+func (r *Router) writeRemoteAck(beadsDir, id, ackedBy string, at time.Time) error {
+	for _, label := range DeliveryAckLabelSequence(ackedBy, at) {
+		ctx, cancel := bdWriteCtx()
+		_, err := runBdCommand(ctx, []string{"label", "add", id, label}, r.workDir, beadsDir)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remoteDeliveryRef extracts the forwarding machine and remote bead ID from
+// a local copy's labels. Returns empty strings for ordinary messages.
+func remoteDeliveryRef(labels []string) (machine, remoteID string) {
+	for _, label := range labels {
+		switch {
+		case strings.HasPrefix(label, RemoteLabelMachinePrefix):
+			machine = strings.TrimPrefix(label, RemoteLabelMachinePrefix)
+		case strings.HasPrefix(label, RemoteLabelIDPrefix):
+			remoteID = strings.TrimPrefix(label, RemoteLabelIDPrefix)
+		}
+	}
+	if machine == "" {
+		return "", ""
+	}
+	return machine, remoteID
+}
+
+// jsonPayload strips anything a remote command printed before its JSON
+// output. Remote commands run with stdout and stderr combined, so warnings
+// can precede the document.
+func jsonPayload(out []byte) []byte {
+	if i := strings.IndexAny(string(out), "{["); i > 0 {
+		return out[i:]
+	}
+	return out
+}
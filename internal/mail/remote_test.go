@@ -0,0 +1,121 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRemoteAddress(t *testing.T) {
+	tests := []struct {
+		address     string
+		wantMachine string
+		wantLocal   string
+		wantOK      bool
+	}{
+		{"platform:gastown/alpha", "platform", "gastown/alpha", true},
+		{"platform:mayor/", "platform", "mayor/", true},
+		{"platform:mayor", "platform", "mayor", true},
+		{"local:gastown/alpha", "local", "gastown/alpha", true},
+		{"gastown/alpha", "", "", false},
+		{"mayor/", "", "", false},
+		{"list:oncall", "", "", false},
+		{"queue:work", "", "", false},
+		{"announce:news", "", "", false},
+		{"channel:alerts", "", "", false},
+		{"group:leads", "", "", false},
+		{"@overseers", "", "", false},
+		{"hop://town/rig", "", "", false},
+		{"platform:", "", "", false},
+		{":gastown/alpha", "", "", false},
+		{"gastown/crew:max", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			machine, local, ok := ParseRemoteAddress(tt.address)
+			if machine != tt.wantMachine || local != tt.wantLocal || ok != tt.wantOK {
+				t.Errorf("ParseRemoteAddress(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.address, machine, local, ok, tt.wantMachine, tt.wantLocal, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestQualifyAddress(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	townJSON := `{"type":"town","version":1,"name":"hq"}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(townJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := QualifyAddress(townRoot, "gastown/alpha"); got != "hq:gastown/alpha" {
+		t.Errorf("QualifyAddress = %q, want hq:gastown/alpha", got)
+	}
+	if got := QualifyAddress(townRoot, "platform:mayor/"); got != "platform:mayor/" {
+		t.Errorf("already-qualified address changed: %q", got)
+	}
+	if got := QualifyAddress("", "gastown/alpha"); got != "gastown/alpha" {
+		t.Errorf("QualifyAddress without town = %q, want unchanged", got)
+	}
+}
+
+func TestRemoteDeliveryRef(t *testing.T) {
+	machine, id := remoteDeliveryRef([]string{"gt:message", "from:mayor/", "remote-machine:platform", "remote-id:hq-wisp-abc"})
+	if machine != "platform" || id != "hq-wisp-abc" {
+		t.Errorf("remoteDeliveryRef = (%q, %q), want (platform, hq-wisp-abc)", machine, id)
+	}
+
+	machine, id = remoteDeliveryRef([]string{"gt:message", "from:mayor/"})
+	if machine != "" || id != "" {
+		t.Errorf("remoteDeliveryRef on local message = (%q, %q), want empty", machine, id)
+	}
+}
+
+func TestJSONPayload(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{"id":"x"}`, `{"id":"x"}`},
+		{"warning: slow\n{\"id\":\"x\"}", `{"id":"x"}`},
+		{"note\n[{\"id\":\"x\"}]", `[{"id":"x"}]`},
+		{"no json", "no json"},
+	}
+	for _, tt := range tests {
+		if got := string(jsonPayload([]byte(tt.in))); got != tt.want {
+			t.Errorf("jsonPayload(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSendToRemote_UnknownMachine(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	machines := `{"version":1,"machines":{"nopath":{"type":"ssh","host":"gt@nopath"}}}`
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "machines.json"), []byte(machines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRouterWithTownRoot(townRoot, townRoot)
+	tests := []struct {
+		to      string
+		wantErr string
+	}{
+		{"platform:gastown/alpha", "not found"},
+		{"nopath:gastown/alpha", "town_path"},
+		{"nopath:list:oncall", "single agent"},
+	}
+	for _, tt := range tests {
+		err := r.Send(&Message{From: "mayor/", To: tt.to, Subject: "hi"})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Send(%q) error = %v, want containing %q", tt.to, err, tt.wantErr)
+		}
+	}
+}
@@ -77,6 +77,11 @@ func (r *Resolver) resolveWithVisited(address string, visited map[string]bool) (
 		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
 	}
 
+	// Machine-qualified addresses are resolved by the town on that machine
+	if _, _, ok := ParseRemoteAddress(address); ok {
+		return []Recipient{{Address: address, Type: RecipientAgent}}, nil
+	}
+
 	// 2. Contains '/' → agent address or pattern
 	if strings.Contains(address, "/") {
 		return r.resolveAgentAddress(address)
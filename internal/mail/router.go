@@ -766,6 +766,8 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
+// Supports cross-town delivery for:
+// - Machine-qualified addresses (machine:rig/name) - forwarded via mayor/machines.json
 func (r *Router) Send(msg *Message) error {
 	// Check for mailing list address
 	if isListAddress(msg.To) {
@@ -787,6 +789,14 @@ func (r *Router) Send(msg *Message) error {
 		return r.sendToChannel(msg)
 	}
 
+	// Check for machine-qualified address - forward to that machine's town
+	if machine, address, ok := ParseRemoteAddress(msg.To); ok {
+		if machine != "local" {
+			return r.sendToRemote(msg, machine, address)
+		}
+		msg.To = address
+	}
+
 	// Check for @group address - resolve and fan-out
 	if isGroupAddress(msg.To) {
 		return r.sendToGroup(msg)
@@ -897,6 +907,42 @@ func (r *Router) sendToSingle(msg *Message) error {
 		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
 	}
 
+	args := r.messageCreateArgs(msg, toIdentity, nil)
+
+	beadsDir := r.resolveBeadsDir()
+	if err := r.ensureCustomTypes(beadsDir); err != nil {
+		return err
+	}
+	ctx, cancel := bdWriteCtx()
+	defer cancel()
+	_, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir)
+	if err != nil {
+		return fmt.Errorf("sending message: %w", err)
+	}
+
+	// Notify recipient if they have an active session (best-effort notification).
+	// Skip when the caller explicitly suppressed notification (--no-notify)
+	// or for self-mail (handoffs to future-self don't need present-self notified).
+	// Notification is async: the durable write is complete, so the caller
+	// doesn't block on idle probing (up to 1s per recipient in fan-out).
+	// Callers that exit soon after Send should call WaitPendingNotifications.
+	if !msg.SuppressNotify && !isSelfMail(msg.From, msg.To) {
+		msgCopy := *msg // copy to avoid data race if caller mutates msg
+		r.notifyWg.Add(1)
+		go func() {
+			defer r.notifyWg.Done()
+			r.notifyRecipient(&msgCopy) //nolint:errcheck
+		}()
+	}
+
+	return nil
+}
+
+// messageCreateArgs builds the bd create arguments that store msg as a
+// gt:message bead assigned to toIdentity. extraLabels are added to the
+// message labels; extraFlags are inserted before the "--" that ends flag
+// parsing.
+func (r *Router) messageCreateArgs(msg *Message, toIdentity string, extraLabels []string, extraFlags ...string) []string {
 	// Build labels for type, from/thread/reply-to/cc
 	var labels []string
 	labels = append(labels, "gt:message")
@@ -913,6 +959,7 @@ func (r *Router) sendToSingle(msg *Message) error {
 		ccIdentity := AddressToIdentity(cc)
 		labels = append(labels, "cc:"+ccIdentity)
 	}
+	labels = append(labels, extraLabels...)
 
 	// Build command: bd create --assignee=<recipient> -d <body> --labels=gt:message,... -- <subject>
 	// Flags go first, then -- to end flag parsing, then the positional subject.
@@ -939,37 +986,13 @@ func (r *Router) sendToSingle(msg *Message) error {
 		args = append(args, "--ephemeral")
 	}
 
+	args = append(args, extraFlags...)
+
 	// End flag parsing with --, then add subject as positional argument.
 	// This prevents subjects like "--help" or "--json" from being parsed as flags.
 	args = append(args, "--", msg.Subject)
 
-	beadsDir := r.resolveBeadsDir()
-	if err := r.ensureCustomTypes(beadsDir); err != nil {
-		return err
-	}
-	ctx, cancel := bdWriteCtx()
-	defer cancel()
-	_, err := runBdCommand(ctx, args, filepath.Dir(beadsDir), beadsDir)
-	if err != nil {
-		return fmt.Errorf("sending message: %w", err)
-	}
-
-	// Notify recipient if they have an active session (best-effort notification).
-	// Skip when the caller explicitly suppressed notification (--no-notify)
-	// or for self-mail (handoffs to future-self don't need present-self notified).
-	// Notification is async: the durable write is complete, so the caller
-	// doesn't block on idle probing (up to 1s per recipient in fan-out).
-	// Callers that exit soon after Send should call WaitPendingNotifications.
-	if !msg.SuppressNotify && !isSelfMail(msg.From, msg.To) {
-		msgCopy := *msg // copy to avoid data race if caller mutates msg
-		r.notifyWg.Add(1)
-		go func() {
-			defer r.notifyWg.Done()
-			r.notifyRecipient(&msgCopy) //nolint:errcheck
-		}()
-	}
-
-	return nil
+	return args
 }
 
 // sendToList expands a mailing list and sends individual copies to each recipient.
@@ -1557,4 +1580,3 @@ func AddressToSessionIDs(address string) []string {
 		session.PolecatSessionName(rigPrefix, target), // <prefix>-name
 	}
 }
-
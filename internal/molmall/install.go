@@ -0,0 +1,440 @@
+package molmall
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+// bundleFormulaFile is the main formula file inside a bundle.
+const bundleFormulaFile = "formula.toml"
+
+// Installer installs formulas from registries into a formulas directory
+// and keeps its lock file.
+type Installer struct {
+	// Dir is the formulas directory, normally <town>/.beads/formulas.
+	Dir string
+
+	// Registries are searched in order for formulas not yet installed.
+	Registries []Registry
+
+	// Force overwrites files that were changed locally or were not
+	// installed from a registry.
+	Force bool
+
+	// Now returns the install time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// Result describes what an install, upgrade or removal did.
+type Result struct {
+	Name      string
+	Version   string // installed version ("" after Remove)
+	Previous  string // version replaced, if any
+	Pinned    bool
+	Registry  string
+	Changelog string
+	Skipped   string // why nothing was done, if nothing was
+}
+
+// Status is an installed formula with a newer release available.
+type Status struct {
+	Name     string
+	Current  string
+	Latest   string
+	Pinned   bool
+	Registry string
+	Err      error // the registry could not be checked
+}
+
+// Install installs the formula spec names, from registry if set, else from
+// the registry it was installed from or the first that has it. An explicit
+// version pins the formula; "latest" unpins it.
+func (in *Installer) Install(ctx context.Context, spec Spec, registry string) (*Result, error) {
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if registry == "" {
+		if entry := lock.Formulas[spec.Name]; entry != nil {
+			registry = entry.Registry
+		}
+	}
+	reg, idx, err := in.find(ctx, spec.Name, registry)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := idx.Select(spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	return in.install(ctx, lock, reg, spec.Name, rel, spec.Pinned())
+}
+
+// Upgrade moves an installed formula to its latest release, or to spec's
+// version if it has one (pinning it there). Pinned formulas are skipped
+// unless a version is given.
+func (in *Installer) Upgrade(ctx context.Context, spec Spec) (*Result, error) {
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	entry := lock.Formulas[spec.Name]
+	if entry == nil {
+		return nil, fmt.Errorf("%s is not installed from a registry", spec.Name)
+	}
+	result := &Result{Name: spec.Name, Version: entry.Version, Pinned: entry.Pinned, Registry: entry.Registry}
+	if entry.Pinned && spec.Version == "" {
+		result.Skipped = fmt.Sprintf("pinned at %s", entry.Version)
+		return result, nil
+	}
+	reg, idx, err := in.find(ctx, spec.Name, entry.Registry)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := idx.Select(spec.Version)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Version == "" && CompareVersions(rel.Version, entry.Version) <= 0 {
+		result.Skipped = "up to date"
+		return result, nil
+	}
+	return in.install(ctx, lock, reg, spec.Name, rel, spec.Pinned())
+}
+
+// Outdated returns the installed formulas whose registry has a newer
+// latest release, and those whose registry could not be checked.
+func (in *Installer) Outdated(ctx context.Context) ([]Status, error) {
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var out []Status
+	for _, name := range lock.Names() {
+		entry := lock.Formulas[name]
+		st := Status{Name: name, Current: entry.Version, Pinned: entry.Pinned, Registry: entry.Registry}
+		_, idx, err := in.find(ctx, name, entry.Registry)
+		if err == nil {
+			var rel Release
+			if rel, err = idx.Select(""); err == nil {
+				st.Latest = rel.Version
+			}
+		}
+		if err != nil {
+			st.Err = err
+			out = append(out, st)
+			continue
+		}
+		if CompareVersions(st.Latest, st.Current) > 0 {
+			out = append(out, st)
+		}
+	}
+	return out, nil
+}
+
+// Installed returns the names of the formulas installed from registries.
+func (in *Installer) Installed() ([]string, error) {
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return lock.Names(), nil
+}
+
+// Remove deletes an installed formula's files and its lock entry.
+func (in *Installer) Remove(name string) (*Result, error) {
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		return nil, err
+	}
+	entry := lock.Formulas[name]
+	if entry == nil {
+		return nil, fmt.Errorf("%s is not installed from a registry", name)
+	}
+	if err := in.checkUnchanged(entry); err != nil {
+		return nil, err
+	}
+	if err := in.removeFiles(entry, nil); err != nil {
+		return nil, err
+	}
+	delete(lock.Formulas, name)
+	if err := lock.Save(in.Dir); err != nil {
+		return nil, err
+	}
+	return &Result{Name: name, Previous: entry.Version, Registry: entry.Registry}, nil
+}
+
+// find returns the registry with the formula and its index: the named
+// registry, or the first configured registry that has it.
+func (in *Installer) find(ctx context.Context, name, registry string) (Registry, *Index, error) {
+	if len(in.Registries) == 0 {
+		return nil, nil, fmt.Errorf("no formula registries configured (add formula_registries to settings/config.json)")
+	}
+	for _, reg := range in.Registries {
+		if registry != "" && reg.Name() != registry {
+			continue
+		}
+		idx, err := reg.Index(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			if registry != "" {
+				return nil, nil, fmt.Errorf("%s not found in registry %s", name, registry)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("registry %s: %w", reg.Name(), err)
+		}
+		return reg, idx, nil
+	}
+	if registry != "" {
+		return nil, nil, fmt.Errorf("unknown formula registry %q", registry)
+	}
+	return nil, nil, fmt.Errorf("%s not found in any formula registry", name)
+}
+
+// install downloads, verifies and writes a release.
+func (in *Installer) install(ctx context.Context, lock *formula.Lock, reg Registry, name string, rel Release, pinned bool) (*Result, error) {
+	data, source, err := reg.Download(ctx, rel)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChecksum(data, rel.Checksum); err != nil {
+		return nil, fmt.Errorf("%s@%s: %w", name, rel.Version, err)
+	}
+
+	main := name + ".formula.toml"
+	files := map[string][]byte{}
+	if rel.Bundle() {
+		bundle, err := extractBundle(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s@%s: %w", name, rel.Version, err)
+		}
+		for p, content := range bundle {
+			files[name+"/"+p] = content
+		}
+		// A copy at the top level is what formula lookup finds.
+		files[main] = bundle[bundleFormulaFile]
+	} else {
+		files[main] = data
+	}
+
+	f, err := formula.ParseWithLoader(files[main], formula.DirLoader(in.Dir))
+	if err != nil {
+		return nil, fmt.Errorf("%s@%s is not a valid formula: %w", name, rel.Version, err)
+	}
+	if f.Name != name {
+		return nil, fmt.Errorf("%s@%s declares formula %q", name, rel.Version, f.Name)
+	}
+
+	old := lock.Formulas[name]
+	if old != nil {
+		if err := in.checkUnchanged(old); err != nil {
+			return nil, err
+		}
+	}
+	for p := range files {
+		if old != nil {
+			if _, owned := old.Files[p]; owned {
+				continue
+			}
+		}
+		if err := in.checkReplaceable(p); err != nil {
+			return nil, err
+		}
+	}
+
+	entry := &formula.LockEntry{
+		Version:     rel.Version,
+		Pinned:      pinned,
+		Checksum:    rel.Checksum,
+		Registry:    reg.Name(),
+		Source:      source,
+		Bundle:      rel.Bundle(),
+		Files:       make(map[string]string, len(files)),
+		InstalledAt: in.now().UTC(),
+	}
+	if old != nil {
+		if err := in.removeFiles(old, files); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range sortedKeys(files) {
+		dest := filepath.Join(in.Dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("creating directory for %s: %w", p, err)
+		}
+		if err := os.WriteFile(dest, files[p], 0644); err != nil { //nolint:gosec // G306: formulas are not secret
+			return nil, fmt.Errorf("writing %s: %w", p, err)
+		}
+		entry.Files[p] = checksum(files[p])
+	}
+	lock.Formulas[name] = entry
+	if err := lock.Save(in.Dir); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Name: name, Version: rel.Version, Pinned: pinned, Registry: reg.Name(), Changelog: rel.Changelog}
+	if old != nil {
+		result.Previous = old.Version
+	}
+	return result, nil
+}
+
+// checkUnchanged fails, unless forced, if an installed formula's files were
+// edited since install.
+func (in *Installer) checkUnchanged(entry *formula.LockEntry) error {
+	if in.Force {
+		return nil
+	}
+	for _, p := range sortedKeys(entry.Files) {
+		data, err := os.ReadFile(filepath.Join(in.Dir, filepath.FromSlash(p))) //nolint:gosec // G304: p is from the lock file
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", p, err)
+		}
+		if checksum(data) != entry.Files[p] {
+			return fmt.Errorf("%s has local changes (use --force to discard them)", p)
+		}
+	}
+	return nil
+}
+
+// checkReplaceable fails, unless forced, if p exists and did not come from
+// a registry. A provisioned copy of an embedded formula may be replaced.
+func (in *Installer) checkReplaceable(p string) error {
+	data, err := os.ReadFile(filepath.Join(in.Dir, filepath.FromSlash(p))) //nolint:gosec // G304: p is a formula file name
+	if os.IsNotExist(err) || in.Force {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", p, err)
+	}
+	if name, ok := strings.CutSuffix(p, ".formula.toml"); ok && !strings.Contains(name, "/") {
+		if embedded, err := formula.EmbeddedLoader(name); err == nil && bytes.Equal(embedded, data) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s already exists and was not installed from a registry (use --force to replace it)", p)
+}
+
+// removeFiles deletes an entry's files except those in keep, then any
+// bundle directories left empty.
+func (in *Installer) removeFiles(entry *formula.LockEntry, keep map[string][]byte) error {
+	dirs := map[string]bool{}
+	for p := range entry.Files {
+		if _, ok := keep[p]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(in.Dir, filepath.FromSlash(p))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", p, err)
+		}
+		for d := path.Dir(p); d != "."; d = path.Dir(d) {
+			dirs[d] = true
+		}
+	}
+	// Deepest first, so parents are empty by the time they are tried.
+	ordered := sortedKeys(dirs)
+	sort.Slice(ordered, func(i, j int) bool { return len(ordered[i]) > len(ordered[j]) })
+	for _, d := range ordered {
+		_ = os.Remove(filepath.Join(in.Dir, filepath.FromSlash(d))) // fails if not empty
+	}
+	return nil
+}
+
+func (in *Installer) now() time.Time {
+	if in.Now != nil {
+		return in.Now()
+	}
+	return time.Now()
+}
+
+// extractBundle returns the regular files in a .tar.gz bundle by path. A
+// single top-level directory is stripped. The bundle must contain
+// formula.toml and no links or paths outside it.
+func extractBundle(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	total := int64(0)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle: %w", err)
+		}
+		name := strings.TrimPrefix(hdr.Name, "./")
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("bundle entry %s: only regular files are allowed", hdr.Name)
+		}
+		clean := path.Clean(name)
+		if name == "" || path.IsAbs(name) || clean != name || clean == ".." || strings.HasPrefix(clean, "../") {
+			return nil, fmt.Errorf("bundle entry %s: invalid path", hdr.Name)
+		}
+		total += hdr.Size
+		if total > maxDownload {
+			return nil, fmt.Errorf("bundle larger than %d bytes", maxDownload)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, hdr.Size))
+		if err != nil {
+			return nil, fmt.Errorf("reading bundle entry %s: %w", hdr.Name, err)
+		}
+		files[clean] = content
+	}
+
+	if _, ok := files[bundleFormulaFile]; !ok {
+		// Strip a single top-level directory.
+		var top string
+		for p := range files {
+			dir, _, found := strings.Cut(p, "/")
+			if !found || (top != "" && dir != top) {
+				top = ""
+				break
+			}
+			top = dir
+		}
+		if top != "" {
+			stripped := make(map[string][]byte, len(files))
+			for p, content := range files {
+				stripped[strings.TrimPrefix(p, top+"/")] = content
+			}
+			files = stripped
+		}
+	}
+	if _, ok := files[bundleFormulaFile]; !ok {
+		return nil, fmt.Errorf("bundle has no %s", bundleFormulaFile)
+	}
+	return files, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,388 @@
+package molmall
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+func formulaTOML(name, title string) []byte {
+	return []byte("formula = \"" + name + "\"\n\n[[steps]]\nid = \"s\"\ntitle = \"" + title + "\"\n")
+}
+
+// testRegistry builds a registry directory. Each release maps a version to
+// the file content to publish; bundle contents are tarred.
+type testRegistry struct {
+	t    *testing.T
+	root string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+	return &testRegistry{t: t, root: t.TempDir()}
+}
+
+func (r *testRegistry) publish(name, version, file string, content []byte, changelog string) {
+	r.t.Helper()
+	dir := filepath.Join(r.root, "formulas")
+	rel := name + "/" + version + "/" + file
+	if err := os.MkdirAll(filepath.Join(dir, name, version), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(rel)), content, 0644); err != nil {
+		r.t.Fatal(err)
+	}
+	idxPath := filepath.Join(dir, name+".json")
+	idx := Index{Name: name}
+	if data, err := os.ReadFile(idxPath); err == nil {
+		if err := json.Unmarshal(data, &idx); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	idx.Versions = append(idx.Versions, Release{Version: version, File: rel, Checksum: checksum(content), Changelog: changelog})
+	data, _ := json.Marshal(idx)
+	if err := os.WriteFile(idxPath, data, 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newInstaller(t *testing.T, cfgs ...Config) *Installer {
+	t.Helper()
+	in := &Installer{Dir: filepath.Join(t.TempDir(), ".beads", "formulas")}
+	for _, cfg := range cfgs {
+		reg, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		in.Registries = append(in.Registries, reg)
+	}
+	return in
+}
+
+func TestInstallUpgradeRemove(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+	reg.publish("mol-review", "1.0.0", "mol-review.formula.toml", formulaTOML("mol-review", "v1"), "")
+	reg.publish("mol-review", "1.2.0", "mol-review.formula.toml", formulaTOML("mol-review", "v1.2"), "Faster")
+	in := newInstaller(t, Config{Name: "local", URL: reg.root})
+
+	res, err := in.Install(ctx, Spec{Name: "mol-review", Version: "1.0.0"}, "")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if res.Version != "1.0.0" || !res.Pinned {
+		t.Errorf("Install = %+v, want pinned 1.0.0", res)
+	}
+	main := filepath.Join(in.Dir, "mol-review.formula.toml")
+	if data, _ := os.ReadFile(main); !bytes.Equal(data, formulaTOML("mol-review", "v1")) {
+		t.Errorf("installed content = %q", data)
+	}
+	lock, err := formula.LoadLock(in.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := lock.Formulas["mol-review"]
+	if entry == nil || entry.Registry != "local" || entry.Files["mol-review.formula.toml"] != checksum(formulaTOML("mol-review", "v1")) {
+		t.Fatalf("lock entry = %+v", entry)
+	}
+
+	// Pinned: upgrade and outdated leave it alone, but outdated reports it.
+	if res, err := in.Upgrade(ctx, Spec{Name: "mol-review"}); err != nil || res.Skipped == "" {
+		t.Errorf("Upgrade of pinned = %+v, %v; want skipped", res, err)
+	}
+	outdated, err := in.Outdated(ctx)
+	if err != nil || len(outdated) != 1 || outdated[0].Latest != "1.2.0" || !outdated[0].Pinned {
+		t.Errorf("Outdated = %+v, %v", outdated, err)
+	}
+
+	// Unpin by installing latest, then a newer release shows up.
+	if res, err := in.Install(ctx, Spec{Name: "mol-review", Version: "latest"}, ""); err != nil || res.Version != "1.2.0" || res.Pinned || res.Previous != "1.0.0" {
+		t.Fatalf("Install latest = %+v, %v", res, err)
+	}
+	reg.publish("mol-review", "1.10.0", "mol-review.formula.toml", formulaTOML("mol-review", "v1.10"), "Numeric ordering")
+	res, err = in.Upgrade(ctx, Spec{Name: "mol-review"})
+	if err != nil || res.Version != "1.10.0" || res.Changelog != "Numeric ordering" {
+		t.Fatalf("Upgrade = %+v, %v", res, err)
+	}
+	if res, _ := in.Upgrade(ctx, Spec{Name: "mol-review"}); res.Skipped != "up to date" {
+		t.Errorf("second Upgrade = %+v, want up to date", res)
+	}
+
+	// Local edits block upgrade and removal unless forced.
+	if err := os.WriteFile(main, []byte("# edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Remove("mol-review"); err == nil || !strings.Contains(err.Error(), "local changes") {
+		t.Errorf("Remove of edited formula error = %v", err)
+	}
+	in.Force = true
+	if _, err := in.Remove("mol-review"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := os.Stat(main); !os.IsNotExist(err) {
+		t.Error("formula file should be removed")
+	}
+	if lock, _ := formula.LoadLock(in.Dir); len(lock.Formulas) != 0 {
+		t.Errorf("lock = %+v, want empty", lock.Formulas)
+	}
+}
+
+func TestInstallBundle(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.publish("mol-deploy", "2.0.0", "mol-deploy.bundle.tar.gz", tarball(t, map[string]string{
+		"mol-deploy/formula.toml":           string(formulaTOML("mol-deploy", "Deploy")),
+		"mol-deploy/templates/service.tmpl": "kind: Service\n",
+		"mol-deploy/scripts/healthcheck.sh": "#!/bin/sh\n",
+	}), "")
+	in := newInstaller(t, Config{Name: "local", URL: "file://" + filepath.ToSlash(reg.root)})
+
+	if _, err := in.Install(context.Background(), Spec{Name: "mol-deploy"}, ""); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	for _, p := range []string{"mol-deploy.formula.toml", "mol-deploy/formula.toml", "mol-deploy/templates/service.tmpl", "mol-deploy/scripts/healthcheck.sh"} {
+		if _, err := os.Stat(filepath.Join(in.Dir, filepath.FromSlash(p))); err != nil {
+			t.Errorf("missing %s: %v", p, err)
+		}
+	}
+	if _, err := in.Remove("mol-deploy"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(in.Dir, "mol-deploy")); !os.IsNotExist(err) {
+		t.Error("bundle directory should be removed")
+	}
+}
+
+func TestInstallRejects(t *testing.T) {
+	ctx := context.Background()
+	reg := newTestRegistry(t)
+	reg.publish("wrong-name", "1.0.0", "wrong-name.formula.toml", formulaTOML("other", "x"), "")
+	reg.publish("evil", "1.0.0", "evil.bundle.tar.gz", tarball(t, map[string]string{
+		"formula.toml":  string(formulaTOML("evil", "x")),
+		"../escape.txt": "gotcha",
+	}), "")
+	reg.publish("mine", "1.0.0", "mine.formula.toml", formulaTOML("mine", "registry"), "")
+	in := newInstaller(t, Config{Name: "local", URL: reg.root})
+
+	// Tamper with a published file after its checksum was recorded.
+	reg.publish("tampered", "1.0.0", "tampered.formula.toml", formulaTOML("tampered", "x"), "")
+	if err := os.WriteFile(filepath.Join(reg.root, "formulas", "tampered", "1.0.0", "tampered.formula.toml"), []byte("formula = \"tampered\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec Spec
+		want string
+	}{
+		{Spec{Name: "wrong-name"}, `declares formula "other"`},
+		{Spec{Name: "evil"}, "invalid path"},
+		{Spec{Name: "tampered"}, "checksum mismatch"},
+		{Spec{Name: "mine", Version: "2"}, "no version matching 2"},
+		{Spec{Name: "missing"}, "not found in any formula registry"},
+	}
+	for _, tt := range tests {
+		if _, err := in.Install(ctx, tt.spec, ""); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Install(%s) error = %v, want containing %q", tt.spec, err, tt.want)
+		}
+	}
+	if _, err := in.Install(ctx, Spec{Name: "mine"}, "elsewhere"); err == nil || !strings.Contains(err.Error(), `unknown formula registry "elsewhere"`) {
+		t.Errorf("unknown registry error = %v", err)
+	}
+
+	// A hand-written local formula is not overwritten without force.
+	if err := os.MkdirAll(in.Dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(in.Dir, "mine.formula.toml")
+	if err := os.WriteFile(local, formulaTOML("mine", "local"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(ctx, Spec{Name: "mine"}, ""); err == nil || !strings.Contains(err.Error(), "not installed from a registry") {
+		t.Errorf("overwrite error = %v", err)
+	}
+}
+
+func TestHTTPRegistry(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.publish("mol-review", "1.0.0", "mol-review.formula.toml", formulaTOML("mol-review", "v1"), "")
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		http.FileServer(http.Dir(reg.root)).ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	t.Setenv("MOLMALL_TEST_TOKEN", "s3cret")
+
+	in := newInstaller(t, Config{Name: "remote", URL: srv.URL, TokenEnv: "MOLMALL_TEST_TOKEN"})
+	res, err := in.Install(context.Background(), Spec{Name: "mol-review"}, "")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if res.Registry != "remote" || auth != "Bearer s3cret" {
+		t.Errorf("result %+v, auth %q", res, auth)
+	}
+	lock, _ := formula.LoadLock(in.Dir)
+	if src := lock.Formulas["mol-review"].Source; src != srv.URL+"/formulas/mol-review/1.0.0/mol-review.formula.toml" {
+		t.Errorf("source = %q", src)
+	}
+}
+
+func TestHTTPRegistryTokenStaysOnHost(t *testing.T) {
+	content := formulaTOML("mol-review", "v1")
+	var mirrorAuth, registryAuth string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorAuth = r.Header.Get("Authorization")
+		_, _ = w.Write(content)
+	}))
+	defer mirror.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		registryAuth = r.Header.Get("Authorization")
+		idx := Index{Versions: []Release{{Version: "1.0.0", File: mirror.URL + "/mol-review.formula.toml", Checksum: checksum(content)}}}
+		_ = json.NewEncoder(w).Encode(idx)
+	}))
+	defer srv.Close()
+	t.Setenv("MOLMALL_TEST_TOKEN", "s3cret")
+
+	in := newInstaller(t, Config{Name: "remote", URL: srv.URL, TokenEnv: "MOLMALL_TEST_TOKEN"})
+	if _, err := in.Install(context.Background(), Spec{Name: "mol-review"}, ""); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if registryAuth != "Bearer s3cret" {
+		t.Errorf("registry auth = %q, want the token", registryAuth)
+	}
+	if mirrorAuth != "" {
+		t.Errorf("token sent to another host: %q", mirrorAuth)
+	}
+}
+
+// roundTripFunc lets a test observe requests without a network.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestHTTPRegistryTokenNeedsRegistryScheme(t *testing.T) {
+	t.Setenv("MOLMALL_TEST_TOKEN", "s3cret")
+	reg, err := New(Config{Name: "remote", URL: "https://molmall.example", TokenEnv: "MOLMALL_TEST_TOKEN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := reg.(*httpRegistry)
+	auth := make(map[string]string)
+	r.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		auth[req.URL.String()] = req.Header.Get("Authorization")
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	})
+
+	for _, u := range []string{"https://molmall.example/formulas/x", "http://molmall.example/formulas/x"} {
+		if _, err := r.get(context.Background(), u); err != nil {
+			t.Fatalf("get %s: %v", u, err)
+		}
+	}
+	if got := auth["https://molmall.example/formulas/x"]; got != "Bearer s3cret" {
+		t.Errorf("https auth = %q, want the token", got)
+	}
+	if got := auth["http://molmall.example/formulas/x"]; got != "" {
+		t.Errorf("token sent over http: %q", got)
+	}
+
+	// A redirect that downgrades to http drops the token too.
+	next, _ := http.NewRequest(http.MethodGet, "http://molmall.example/formulas/y", nil)
+	next.Header.Set("Authorization", "Bearer s3cret")
+	if err := r.checkRedirect(next, []*http.Request{{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := next.Header.Get("Authorization"); got != "" {
+		t.Errorf("token kept across an http redirect: %q", got)
+	}
+}
+
+func TestLockOwnsSkipsEmbeddedUpdate(t *testing.T) {
+	reg := newTestRegistry(t)
+	reg.publish("shiny", "9.0.0", "shiny.formula.toml", formulaTOML("shiny", "Custom shiny"), "")
+	town := t.TempDir()
+	in := newInstaller(t, Config{Name: "local", URL: reg.root})
+	in.Dir = filepath.Join(town, ".beads", "formulas")
+
+	// The provisioned embedded copy may be replaced without force.
+	if _, err := formula.ProvisionFormulas(town); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := in.Install(context.Background(), Spec{Name: "shiny"}, ""); err != nil {
+		t.Fatalf("Install over provisioned copy: %v", err)
+	}
+	if _, _, _, err := formula.UpdateFormulas(town); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(in.Dir, "shiny.formula.toml"))
+	if !bytes.Equal(data, formulaTOML("shiny", "Custom shiny")) {
+		t.Error("gt formula update overwrote a registry-installed formula")
+	}
+}
+
+func TestParseSpecAndSelect(t *testing.T) {
+	for in, want := range map[string]Spec{
+		"mol-x":        {Name: "mol-x"},
+		"mol-x@4":      {Name: "mol-x", Version: "4"},
+		"mol-x@v4.0.1": {Name: "mol-x", Version: "4.0.1"},
+		"mol-x@latest": {Name: "mol-x", Version: "latest"},
+	} {
+		got, err := ParseSpec(in)
+		if err != nil || got != want {
+			t.Errorf("ParseSpec(%q) = %+v, %v", in, got, err)
+		}
+	}
+	for _, bad := range []string{"", "a/b", "hop://host/formulas/x@1", "x@1@2"} {
+		if _, err := ParseSpec(bad); err == nil {
+			t.Errorf("ParseSpec(%q) should fail", bad)
+		}
+	}
+
+	idx := &Index{Name: "x", Versions: []Release{{Version: "3.9.0"}, {Version: "4.0.0"}, {Version: "4.2.1"}, {Version: "4.10.0"}, {Version: "5.0.0-rc1"}}}
+	tests := map[string]string{"": "5.0.0-rc1", "4": "4.10.0", "4.2": "4.2.1", "3.9.0": "3.9.0"}
+	for c, want := range tests {
+		rel, err := idx.Select(c)
+		if err != nil || rel.Version != want {
+			t.Errorf("Select(%q) = %s, %v; want %s", c, rel.Version, err, want)
+		}
+	}
+	idx.Latest = "4.10.0"
+	if rel, _ := idx.Select("latest"); rel.Version != "4.10.0" {
+		t.Errorf("Select(latest) = %s, want the index's latest", rel.Version)
+	}
+	if got := []int{CompareVersions("1.10", "1.9"), CompareVersions("v1.0.0", "1.0.0"), CompareVersions("1.0.0", "1.0.1")}; !reflect.DeepEqual(got, []int{1, 0, -1}) {
+		t.Errorf("CompareVersions = %v", got)
+	}
+}
@@ -0,0 +1,282 @@
+// Package molmall installs formulas from Mol Mall registries into a town's
+// formulas directory, recording them in a lock file (see
+// docs/mol-mall-design.md).
+//
+// A registry is a tree of static files, served from a local directory or
+// over HTTP:
+//
+//	formulas/<name>.json                        index of released versions
+//	formulas/<name>/<version>/<name>.formula.toml
+//	formulas/<name>/<version>/<name>.bundle.tar.gz
+//
+// The index lists each version's file (relative to formulas/) and sha256
+// checksum, which is verified on download.
+package molmall
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by a Registry that does not have a formula.
+var ErrNotFound = errors.New("formula not in registry")
+
+// maxDownload caps the size of an index or formula download.
+const maxDownload = 32 << 20
+
+// Config describes a registry, as configured in town settings
+// (formula_registries).
+type Config struct {
+	// Name identifies the registry in the lock file and --registry.
+	Name string
+
+	// URL is the registry root: an http(s) URL, a file:// URL or a local
+	// directory path.
+	URL string
+
+	// TokenEnv names the environment variable holding a bearer token for
+	// HTTP registries that require one.
+	TokenEnv string
+}
+
+// Index lists the released versions of a formula.
+type Index struct {
+	Name     string    `json:"name"`
+	Latest   string    `json:"latest,omitempty"` // defaults to the highest version
+	Versions []Release `json:"versions"`
+}
+
+// Release is one released version of a formula.
+type Release struct {
+	Version     string    `json:"version"`
+	File        string    `json:"file"`     // relative to formulas/, or an absolute URL
+	Checksum    string    `json:"checksum"` // sha256:<hex>
+	Changelog   string    `json:"changelog,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+}
+
+// Bundle reports whether the release is a tarball bundle rather than a
+// single formula file.
+func (r Release) Bundle() bool {
+	return strings.HasSuffix(r.File, ".tar.gz") || strings.HasSuffix(r.File, ".tgz")
+}
+
+// Registry is a source of formulas.
+type Registry interface {
+	// Name returns the registry name from its Config.
+	Name() string
+
+	// Index returns the released versions of a formula, or ErrNotFound.
+	Index(ctx context.Context, name string) (*Index, error)
+
+	// Download returns the content of a release and where it came from.
+	Download(ctx context.Context, rel Release) (data []byte, source string, err error)
+}
+
+var (
+	_ Registry = (*dirRegistry)(nil)
+	_ Registry = (*httpRegistry)(nil)
+)
+
+// New returns the registry for cfg.
+func New(cfg Config) (Registry, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("formula registry missing name")
+	}
+	u, err := url.Parse(cfg.URL)
+	switch {
+	case cfg.URL == "":
+		return nil, fmt.Errorf("formula registry %s: missing url", cfg.Name)
+	case err == nil && (u.Scheme == "http" || u.Scheme == "https"):
+		token := ""
+		if cfg.TokenEnv != "" {
+			token = os.Getenv(cfg.TokenEnv)
+		}
+		r := &httpRegistry{
+			name:   cfg.Name,
+			base:   strings.TrimSuffix(cfg.URL, "/") + "/formulas/",
+			scheme: u.Scheme,
+			host:   u.Host,
+			token:  token,
+		}
+		r.client = &http.Client{Timeout: 60 * time.Second, CheckRedirect: r.checkRedirect}
+		return r, nil
+	case err == nil && u.Scheme == "file":
+		return &dirRegistry{name: cfg.Name, dir: filepath.Join(filepath.FromSlash(u.Path), "formulas")}, nil
+	case err == nil && u.Scheme != "" && len(u.Scheme) > 1:
+		return nil, fmt.Errorf("formula registry %s: unsupported url scheme %q", cfg.Name, u.Scheme)
+	}
+	return &dirRegistry{name: cfg.Name, dir: filepath.Join(cfg.URL, "formulas")}, nil
+}
+
+// checkName rejects formula names that are not plain file names.
+func checkName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid formula name %q", name)
+	}
+	return nil
+}
+
+// dirRegistry reads a registry from a local directory.
+type dirRegistry struct {
+	name string
+	dir  string // the registry's formulas/ directory
+}
+
+func (r *dirRegistry) Name() string { return r.name }
+
+func (r *dirRegistry) Index(_ context.Context, name string) (*Index, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(r.dir, name+".json")) //nolint:gosec // G304: name is a bare formula name
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	return decodeIndex(data, name)
+}
+
+func (r *dirRegistry) Download(_ context.Context, rel Release) ([]byte, string, error) {
+	clean := path.Clean("/" + rel.File)
+	if rel.File == "" || strings.Contains(rel.File, "://") || clean != "/"+rel.File {
+		return nil, "", fmt.Errorf("invalid release file %q", rel.File)
+	}
+	p := filepath.Join(r.dir, filepath.FromSlash(rel.File))
+	data, err := os.ReadFile(p) //nolint:gosec // G304: p is inside the registry directory
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", rel.File, err)
+	}
+	return data, p, nil
+}
+
+// httpRegistry reads a registry over HTTP.
+type httpRegistry struct {
+	name   string
+	base   string // URL of the registry's formulas/ directory, with trailing slash
+	scheme string // only requests with this scheme and host carry the token
+	host   string
+	token  string
+	client *http.Client
+}
+
+func (r *httpRegistry) Name() string { return r.name }
+
+func (r *httpRegistry) Index(ctx context.Context, name string) (*Index, error) {
+	if err := checkName(name); err != nil {
+		return nil, err
+	}
+	data, err := r.get(ctx, r.base+url.PathEscape(name)+".json")
+	if err != nil {
+		return nil, err
+	}
+	return decodeIndex(data, name)
+}
+
+func (r *httpRegistry) Download(ctx context.Context, rel Release) ([]byte, string, error) {
+	base, err := url.Parse(r.base)
+	if err != nil {
+		return nil, "", err
+	}
+	ref, err := url.Parse(rel.File)
+	if err != nil || rel.File == "" {
+		return nil, "", fmt.Errorf("invalid release file %q", rel.File)
+	}
+	src := base.ResolveReference(ref).String()
+	data, err := r.get(ctx, src)
+	if errors.Is(err, ErrNotFound) {
+		return nil, "", fmt.Errorf("downloading %s: not found", src)
+	}
+	return data, src, err
+}
+
+func (r *httpRegistry) get(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	// Releases may point at absolute URLs; never hand the token to
+	// another host, or to the registry's host over another scheme.
+	if r.token != "" && r.ownsURL(req.URL) {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: HTTP %d", u, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", u, err)
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("GET %s: response larger than %d bytes", u, maxDownload)
+	}
+	return data, nil
+}
+
+// ownsURL reports whether u has the registry's scheme and host.
+func (r *httpRegistry) ownsURL(u *url.URL) bool {
+	return u.Scheme == r.scheme && u.Host == r.host
+}
+
+// checkRedirect drops the token when a redirect leaves the registry's
+// scheme and host; net/http only drops it when the host changes.
+func (r *httpRegistry) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if !r.ownsURL(req.URL) {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+func decodeIndex(data []byte, name string) (*Index, error) {
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing index for %s: %w", name, err)
+	}
+	if idx.Name != "" && idx.Name != name {
+		return nil, fmt.Errorf("index for %s names formula %s", name, idx.Name)
+	}
+	idx.Name = name
+	return &idx, nil
+}
+
+// checksum returns the sha256:<hex> checksum of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// verifyChecksum checks data against a sha256:<hex> checksum.
+func verifyChecksum(data []byte, want string) error {
+	if !strings.HasPrefix(want, "sha256:") {
+		return fmt.Errorf("unsupported checksum %q (want sha256:<hex>)", want)
+	}
+	if got := checksum(data); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", got, want)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package molmall
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Spec is a formula to install: a name and an optional version constraint.
+type Spec struct {
+	Name    string
+	Version string // "", "latest", a major ("4"), major.minor ("4.1") or exact version
+}
+
+// ParseSpec parses name, name@version or name@latest.
+func ParseSpec(s string) (Spec, error) {
+	if strings.Contains(s, "://") {
+		return Spec{}, fmt.Errorf("%s: registry URIs are not supported; configure the registry in formula_registries and install by name", s)
+	}
+	name, version, _ := strings.Cut(s, "@")
+	if err := checkName(name); err != nil {
+		return Spec{}, err
+	}
+	if strings.Contains(version, "@") {
+		return Spec{}, fmt.Errorf("invalid formula spec %q", s)
+	}
+	return Spec{Name: name, Version: strings.TrimPrefix(version, "v")}, nil
+}
+
+// Pinned reports whether the spec asks for a particular version.
+func (s Spec) Pinned() bool {
+	return s.Version != "" && s.Version != "latest"
+}
+
+func (s Spec) String() string {
+	if s.Version == "" {
+		return s.Name
+	}
+	return s.Name + "@" + s.Version
+}
+
+// Select returns the release in idx matching the spec: the index's latest
+// for no version, else the highest version the constraint matches.
+func (idx *Index) Select(version string) (Release, error) {
+	if len(idx.Versions) == 0 {
+		return Release{}, fmt.Errorf("%s has no released versions", idx.Name)
+	}
+	if version == "" || version == "latest" {
+		if idx.Latest != "" {
+			for _, rel := range idx.Versions {
+				if trimV(rel.Version) == trimV(idx.Latest) {
+					return rel, nil
+				}
+			}
+			return Release{}, fmt.Errorf("%s: latest version %s is not in the index", idx.Name, idx.Latest)
+		}
+		version = ""
+	}
+	var best *Release
+	for i, rel := range idx.Versions {
+		if !matchVersion(rel.Version, version) {
+			continue
+		}
+		if best == nil || CompareVersions(rel.Version, best.Version) > 0 {
+			best = &idx.Versions[i]
+		}
+	}
+	if best == nil {
+		return Release{}, fmt.Errorf("%s has no version matching %s", idx.Name, version)
+	}
+	return *best, nil
+}
+
+// matchVersion reports whether v matches constraint c: equal, or c is a
+// leading part of v ("4" and "4.1" match "4.1.2").
+func matchVersion(v, c string) bool {
+	if c == "" {
+		return true
+	}
+	v, c = trimV(v), trimV(c)
+	return v == c || strings.HasPrefix(v, c+".")
+}
+
+// CompareVersions compares dotted versions numerically part by part,
+// returning -1, 0 or 1. Non-numeric parts compare as strings.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(trimV(a), "."), strings.Split(trimV(b), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(x)
+		yn, yerr := strconv.Atoi(y)
+		switch {
+		case x == y:
+			continue
+		case xerr == nil && yerr == nil:
+			if xn < yn {
+				return -1
+			}
+			if xn > yn {
+				return 1
+			}
+		case x < y:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return 0
+}
+
+func trimV(v string) string {
+	return strings.TrimPrefix(v, "v")
+}
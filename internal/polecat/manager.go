@@ -120,7 +120,12 @@ type Manager struct {
 	git      *git.Git
 	beads    *beads.Beads
 	namePool *NamePool
-	tmux     *tmux.Tmux
+	sessions session.SessionBackend // nil when session checks are disabled
+
+	// remote is set for rigs whose polecats run on another machine.
+	// remoteErr records why it couldn't be resolved.
+	remote    *rig.RemoteMachine
+	remoteErr error
 }
 
 // NewManager creates a new polecat manager.
@@ -151,12 +156,21 @@ func NewManager(r *rig.Rig, g *git.Git, t *tmux.Tmux) *Manager {
 	}
 	_ = pool.Load() // non-fatal: state file may not exist for new rigs
 
+	remote, remoteErr := resolveRemote(r)
+
+	var sessions session.SessionBackend
+	if t != nil {
+		sessions = session.ResolveBackend(filepath.Dir(r.Path), t)
+	}
+
 	return &Manager{
-		rig:      r,
-		git:      g,
-		beads:    beads.NewWithBeadsDir(beadsPath, resolvedBeads),
-		namePool: pool,
-		tmux:     t,
+		rig:       r,
+		git:       g,
+		beads:     beads.NewWithBeadsDir(beadsPath, resolvedBeads),
+		namePool:  pool,
+		sessions:  sessions,
+		remote:    remote,
+		remoteErr: remoteErr,
 	}
 }
 
@@ -426,6 +440,24 @@ func (m *Manager) repoBase() (*git.Git, error) {
 	return git.NewGit(mayorPath), nil
 }
 
+// hasSession checks for a polecat tmux session on the machine the rig's
+// polecats run on.
+func (m *Manager) hasSession(sessionName string) (bool, error) {
+	if m.remote != nil {
+		return m.remote.Conn.TmuxHasSession(sessionName)
+	}
+	return m.sessions.HasSession(sessionName)
+}
+
+// killSession kills a polecat tmux session and its processes on the machine
+// the rig's polecats run on.
+func (m *Manager) killSession(sessionName string) error {
+	if m.remote != nil {
+		return m.remote.Conn.TmuxKillSession(sessionName)
+	}
+	return m.sessions.KillSessionWithProcesses(sessionName)
+}
+
 // polecatDir returns the parent directory for a polecat.
 // This is polecats/<name>/ - the polecat's home directory.
 func (m *Manager) polecatDir(name string) string {
@@ -602,6 +634,10 @@ func (m *Manager) Add(name string) (*Polecat, error) {
 // This allows setting hook_bead atomically at creation time, avoiding
 // cross-beads routing issues when slinging work to new polecats.
 func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error) {
+	if m.remoteErr != nil {
+		return nil, m.remoteErr
+	}
+
 	// Acquire per-polecat file lock to prevent concurrent Add/Remove/Repair races
 	fl, err := m.lockPolecat(name)
 	if err != nil {
@@ -645,7 +681,9 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 		// Remove git worktree registration if worktree was successfully added.
 		// Must happen before directory removal so git can clean up properly.
-		if worktreeCreated {
+		if worktreeCreated && m.remote != nil {
+			_ = m.removeRemoteWorktree(clonePath, polecatDir)
+		} else if worktreeCreated {
 			if rg, repoErr := m.repoBase(); repoErr == nil {
 				_ = rg.WorktreeRemove(clonePath, true)
 			}
@@ -660,6 +698,30 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		_ = m.namePool.Save()
 	}
 
+	// Remote rigs create the worktree on the rig's machine instead
+	if m.remote != nil {
+		startPoint := opts.BaseBranch
+		if startPoint == "" {
+			defaultBranch := "main"
+			if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
+				defaultBranch = rigCfg.DefaultBranch
+			}
+			startPoint = fmt.Sprintf("origin/%s", defaultBranch)
+		}
+		if err := m.addRemoteWorktree(clonePath, branchName, startPoint); err != nil {
+			cleanupOnError()
+			return nil, fmt.Errorf("creating worktree on %s: %w", m.remote.Name, err)
+		}
+		worktreeCreated = true
+
+		runtimeConfig := config.ResolveRoleAgentConfig("polecat", filepath.Dir(m.rig.Path), m.rig.Path)
+		if err := m.syncRuntimeSettingsToRemote(polecatDir, clonePath, runtimeConfig); err != nil {
+			// Non-fatal - log warning but continue
+			style.PrintWarning("could not install runtime settings on %s: %v", m.remote.Name, err)
+		}
+		return m.finishAdd(name, clonePath, branchName, opts, cleanupOnError)
+	}
+
 	// Get the repo base (bare repo or mayor/rig)
 	repoGit, err := m.repoBase()
 	if err != nil {
@@ -761,13 +823,19 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
 	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
 
+	return m.finishAdd(name, clonePath, branchName, opts, cleanupOnError)
+}
+
+// finishAdd creates the agent bead for a freshly provisioned polecat and
+// returns it. Shared by the local and remote spawn paths.
+func (m *Manager) finishAdd(name, clonePath, branchName string, opts AddOptions, cleanupOnError func()) (*Polecat, error) {
 	// Create or reopen agent bead for ZFC compliance (self-report state).
 	// State starts as "spawning" - will be updated to "working" when Claude starts.
 	// HookBead is set atomically at creation time if provided (avoids cross-beads routing issues).
 	// Uses CreateOrReopenAgentBead to handle re-spawning with same name (GH #332).
 	// Retries with backoff — a polecat without an agent bead is untrackable (gt-94llt7).
 	agentID := m.agentBeadID(name)
-	if err = m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
+	if err := m.createAgentBeadWithRetry(agentID, &beads.AgentFields{
 		RoleType:   "polecat",
 		Rig:        m.rig.Name,
 		AgentState: "spawning",
@@ -809,6 +877,10 @@ func (m *Manager) Remove(name string, force bool) error {
 // ZFC #10: Uses cleanup_status from agent bead if available (polecat self-report),
 // falls back to git check for backward compatibility.
 func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool) error {
+	if m.remoteErr != nil {
+		return m.remoteErr
+	}
+
 	// Acquire per-polecat file lock to prevent concurrent Remove races
 	fl, err := m.lockPolecat(name)
 	if err != nil {
@@ -838,8 +910,12 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 			}
 		} else {
 			// Fallback path: Check git directly (for polecats that haven't reported yet)
-			polecatGit := git.NewGit(clonePath)
-			status, err := polecatGit.CheckUncommittedWork()
+			var status *git.UncommittedWorkStatus
+			if m.remote != nil {
+				status, err = m.remoteUncommittedWork(clonePath)
+			} else {
+				status, err = git.NewGit(clonePath).CheckUncommittedWork()
+			}
 			if err == nil && !status.Clean() {
 				// For backward compatibility: force only bypasses uncommitted changes, not stashes/unpushed
 				if force {
@@ -908,6 +984,18 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear, selfNuke bool)
 		}
 	}
 
+	// Remote rigs: the worktree lives on the rig's machine; locally there is
+	// only the polecat home directory marking the name as in use.
+	if m.remote != nil {
+		if err := m.removeRemoteWorktree(clonePath, polecatDir); err != nil {
+			return err
+		}
+		_ = os.RemoveAll(polecatDir)
+		m.namePool.Release(name)
+		_ = m.namePool.Save()
+		return nil
+	}
+
 	// Get repo base to remove the worktree properly
 	repoGit, err := m.repoBase()
 	if err != nil {
@@ -1068,10 +1156,10 @@ func (m *Manager) AllocateName() (string, error) {
 	// can be allocated after its directory was cleaned up while the tmux session
 	// lingers (race between cleanup and allocation). This extra check ensures
 	// no stale session blocks the new polecat's session creation.
-	if m.tmux != nil {
+	if m.sessions != nil {
 		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-		if alive, _ := m.tmux.HasSession(sessionName); alive {
-			_ = m.tmux.KillSessionWithProcesses(sessionName)
+		if alive, _ := m.hasSession(sessionName); alive {
+			_ = m.killSession(sessionName)
 		}
 	}
 
@@ -1308,11 +1396,11 @@ func (m *Manager) reconcilePoolInternal() {
 
 	// Get names with tmux sessions
 	var namesWithSessions []string
-	if m.tmux != nil {
+	if m.sessions != nil {
 		poolNames := m.namePool.getNames()
 		for _, name := range poolNames {
 			sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-			hasSession, _ := m.tmux.HasSession(sessionName)
+			hasSession, _ := m.hasSession(sessionName)
 			if hasSession {
 				namesWithSessions = append(namesWithSessions, name)
 			}
@@ -1345,15 +1433,16 @@ func (m *Manager) ReconcilePoolWith(namesWithDirs, namesWithSessions []string) {
 	// - No directory: orphan session, always kill (worktree was removed but tmux lingered)
 	// - Has directory but dead process: stale session from crashed startup (gt-jn40ft)
 	// Use KillSessionWithProcesses to ensure all descendant processes are killed.
-	if m.tmux != nil {
+	if m.sessions != nil {
 		for _, name := range namesWithSessions {
 			sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
 			if !dirSet[name] {
 				// Orphan: session exists but no directory
-				_ = m.tmux.KillSessionWithProcesses(sessionName)
-			} else if isSessionProcessDead(m.tmux, sessionName) {
-				// Stale: directory exists but session's process has died
-				_ = m.tmux.KillSessionWithProcesses(sessionName)
+				_ = m.killSession(sessionName)
+			} else if m.remote == nil && isSessionProcessDead(m.sessions, sessionName) {
+				// Stale: directory exists but session's process has died.
+				// Process liveness can't be inspected on remote machines.
+				_ = m.sessions.KillSessionWithProcesses(sessionName)
 			}
 		}
 	}
@@ -1368,7 +1457,7 @@ func (m *Manager) ReconcilePoolWith(namesWithDirs, namesWithSessions []string) {
 // isSessionProcessDead checks if a tmux session's pane process has exited.
 // Returns true only when we can confirm the process is dead, not on transient
 // tmux query failures (gt-kncti: permission denied false positives).
-func isSessionProcessDead(t *tmux.Tmux, sessionName string) bool {
+func isSessionProcessDead(t session.SessionBackend, sessionName string) bool {
 	pidStr, err := t.GetPanePID(sessionName)
 	if err != nil {
 		// Tmux query failed — could be permission denied, server busy, etc.
@@ -1682,9 +1771,9 @@ func (m *Manager) loadFromBeads(name string) (*Polecat, error) {
 	if issue != nil {
 		issueID = issue.ID
 		state = StateWorking
-	} else if m.tmux != nil {
+	} else if m.sessions != nil {
 		sessionName := session.PolecatSessionName(session.PrefixFor(m.rig.Name), name)
-		if running, _ := m.tmux.HasSession(sessionName); running {
+		if running, _ := m.hasSession(sessionName); running {
 			state = StateWorking
 		}
 	}
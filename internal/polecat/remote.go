@@ -0,0 +1,309 @@
+package polecat
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/runtime"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Remote rigs keep their container (config, beads, name pool, agent beads)
+// in the local town, but polecat worktrees and tmux sessions live on the
+// rig's machine under the same relative layout. The local polecats/<name>/
+// directory is still created so the name pool and exists() checks behave
+// identically; it just never holds a worktree.
+
+// resolveRemote loads the remote machine for a rig. Returns nil for local rigs.
+// Constructors store the error and surface it from the first operation that
+// needs the remote, so local-only paths (listing, status) keep working.
+func resolveRemote(r *rig.Rig) (*rig.RemoteMachine, error) {
+	if !r.IsRemote() {
+		return nil, nil
+	}
+	rm, err := r.RemoteMachine()
+	if err != nil {
+		return nil, fmt.Errorf("rig %s runs on machine %q: %w", r.Name, r.Machine, err)
+	}
+	return rm, nil
+}
+
+// remoteRepoBase returns the remote path of the rig's shared bare repo.
+func (m *Manager) remoteRepoBase() string {
+	return m.remote.Path(filepath.Join(m.rig.Path, ".repo.git"))
+}
+
+// addRemoteWorktree creates the polecat worktree on the rig's machine and
+// writes its beads redirect.
+func (m *Manager) addRemoteWorktree(clonePath, branchName, startPoint string) error {
+	repo := m.remoteRepoBase()
+	remoteClone := m.remote.Path(clonePath)
+
+	if _, err := m.remote.Git(repo, "fetch", "origin"); err != nil {
+		// Non-fatal - proceed with potentially stale code
+		fmt.Fprintf(os.Stderr, "Warning: could not fetch origin on %s: %v\n", m.remote.Name, err)
+	}
+	if _, err := m.remote.Git(repo, "rev-parse", "--verify", "--quiet", startPoint); err != nil {
+		return fmt.Errorf("%s not found in %s:%s (run 'git fetch origin' there)", startPoint, m.remote.Name, repo)
+	}
+	if err := m.remote.Conn.MkdirAll(path.Dir(remoteClone), 0755); err != nil {
+		return fmt.Errorf("creating remote polecat dir: %w", err)
+	}
+	if _, err := m.remote.Git(repo, "worktree", "add", "-b", branchName, remoteClone, startPoint); err != nil {
+		return err
+	}
+
+	// Beads redirect: computed against the local rig layout, which the
+	// remote town mirrors, so the relative target is valid there too.
+	townRoot := filepath.Dir(m.rig.Path)
+	if target, err := beads.ComputeRedirectTarget(townRoot, clonePath); err == nil {
+		beadsDir := path.Join(remoteClone, ".beads")
+		if err := m.remote.Conn.MkdirAll(beadsDir, 0755); err == nil {
+			_ = m.remote.Conn.WriteFile(path.Join(beadsDir, "redirect"), []byte(target+"\n"), 0644)
+		}
+	}
+	return nil
+}
+
+// removeRemoteWorktree removes the polecat worktree from the rig's machine.
+func (m *Manager) removeRemoteWorktree(clonePath, polecatDir string) error {
+	repo := m.remoteRepoBase()
+	remoteClone := m.remote.Path(clonePath)
+
+	if _, err := m.remote.Git(repo, "worktree", "remove", "--force", remoteClone); err != nil {
+		// Fall back to direct removal (worktree may already be gone)
+		if rmErr := m.remote.Conn.RemoveAll(remoteClone); rmErr != nil {
+			return fmt.Errorf("removing remote clone: %w", rmErr)
+		}
+	}
+	_ = m.remote.Conn.RemoveAll(m.remote.Path(polecatDir))
+	_, _ = m.remote.Git(repo, "worktree", "prune")
+	return nil
+}
+
+// remoteUncommittedWork checks a remote worktree for uncommitted changes.
+// Only the porcelain status is checked; stash and unpushed-commit detection
+// rely on the polecat's self-reported cleanup_status.
+func (m *Manager) remoteUncommittedWork(clonePath string) (*git.UncommittedWorkStatus, error) {
+	out, err := m.remote.Git(m.remote.Path(clonePath), "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	status := &git.UncommittedWorkStatus{}
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" {
+			continue
+		}
+		status.HasUncommittedChanges = true
+		if strings.HasPrefix(line, "??") {
+			status.UntrackedFiles = append(status.UntrackedFiles, strings.TrimSpace(line[2:]))
+		} else if len(line) > 3 {
+			status.ModifiedFiles = append(status.ModifiedFiles, strings.TrimSpace(line[3:]))
+		}
+	}
+	return status, nil
+}
+
+// syncRuntimeSettingsToRemote installs runtime settings locally (the hook
+// installers only write to the local filesystem) and mirrors the results to
+// the rig's machine: the hidden settings dirs in the shared polecats/ dir and
+// everything provisioned into the polecat's own directory.
+func (m *Manager) syncRuntimeSettingsToRemote(polecatDir, clonePath string, rc *config.RuntimeConfig) error {
+	settingsDir := config.RoleSettingsDir("polecat", m.rig.Path)
+	if err := runtime.EnsureSettingsForRole(settingsDir, clonePath, "polecat", rc); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(settingsDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") && e.IsDir() {
+			if err := syncDirToRemote(m.remote, filepath.Join(settingsDir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return syncDirToRemote(m.remote, polecatDir)
+}
+
+// syncDirToRemote copies the regular files under localDir to the same
+// location on the remote machine.
+func syncDirToRemote(rm *rig.RemoteMachine, localDir string) error {
+	return filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return rm.Conn.MkdirAll(rm.Path(p), 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p) //nolint:gosec // G304: path comes from walking the rig's own directories
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return rm.Conn.WriteFile(rm.Path(p), data, info.Mode().Perm())
+	})
+}
+
+// hasSession checks for a polecat tmux session on the machine the rig's
+// polecats run on.
+func (m *SessionManager) hasSession(sessionID string) (bool, error) {
+	if m.remote != nil {
+		return m.remote.Conn.TmuxHasSession(sessionID)
+	}
+	return m.backend.HasSession(sessionID)
+}
+
+// startRemote starts a polecat session on the rig's machine.
+//
+// The remote tmux server has none of the local session hooks (theme,
+// pane-died, environment table), so everything the agent needs is carried in
+// the startup command itself. The agent is exec'd so the session dies with
+// it, which is what IsRunning relies on for remote rigs.
+func (m *SessionManager) startRemote(polecat string, opts SessionStartOptions) error {
+	conn := m.remote.Conn
+	sessionID := m.SessionName(polecat)
+
+	running, err := conn.TmuxHasSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("checking session on %s: %w", m.remote.Name, err)
+	}
+	if running {
+		return fmt.Errorf("%w: %s", ErrSessionRunning, sessionID)
+	}
+
+	localWorkDir := opts.WorkDir
+	if localWorkDir == "" {
+		localWorkDir = m.clonePath(polecat)
+	}
+	workDir := m.remote.Path(localWorkDir)
+
+	// Beads live in the local town; validate and hook against the rig root
+	// since the worktree itself only exists remotely.
+	if opts.Issue != "" {
+		if err := m.validateIssue(opts.Issue, m.rig.Path); err != nil {
+			return err
+		}
+	}
+
+	townRoot := filepath.Dir(m.rig.Path)
+	runtimeConfig := config.ResolveRoleAgentConfig("polecat", townRoot, m.rig.Path)
+	fallbackInfo := runtime.GetStartupFallbackInfo(runtimeConfig)
+
+	address := session.BeaconRecipient("polecat", polecat, m.rig.Name)
+	beacon := session.FormatStartupBeacon(session.BeaconConfig{
+		Recipient:               address,
+		Sender:                  "witness",
+		Topic:                   "assigned",
+		MolID:                   opts.Issue,
+		IncludePrimeInstruction: fallbackInfo.IncludePrimeInBeacon,
+		ExcludeWorkInstructions: fallbackInfo.SendStartupNudge,
+	})
+
+	command := opts.Command
+	if command == "" {
+		// Resolve the command against the local config, then rebase the
+		// embedded town paths (GT_ROOT, settings dir) onto the remote town,
+		// which mirrors the local layout.
+		command = config.BuildPolecatStartupCommand(m.rig.Name, polecat, m.rig.Path, beacon)
+		command = strings.ReplaceAll(command, townRoot, m.remote.TownPath)
+	}
+	env := map[string]string{
+		"GT_RIG":              m.rig.Name,
+		"GT_POLECAT":          polecat,
+		"GT_ROLE":             fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat),
+		"GT_POLECAT_PATH":     workDir,
+		"GT_TOWN_ROOT":        m.remote.TownPath,
+		"GT_MACHINE":          m.remote.Name,
+		"BD_DOLT_AUTO_COMMIT": "off",
+	}
+	if opts.DoltBranch != "" {
+		env["BD_BRANCH"] = opts.DoltBranch
+	}
+	if opts.Agent != "" {
+		env["GT_AGENT"] = opts.Agent
+	}
+	if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && opts.RuntimeConfigDir != "" {
+		env[runtimeConfig.Session.ConfigDirEnv] = opts.RuntimeConfigDir
+	}
+	if out, err := conn.ExecDir(workDir, "git", "rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		env["GT_BRANCH"] = strings.TrimSpace(string(out))
+	}
+	command = config.PrependEnv(command, env)
+
+	if err := conn.TmuxNewSession(sessionID, workDir); err != nil {
+		return fmt.Errorf("creating session on %s: %w", m.remote.Name, err)
+	}
+	if err := conn.TmuxSendKeys(sessionID, "exec sh -c "+config.ShellQuote(command)); err != nil {
+		_ = conn.TmuxKillSession(sessionID)
+		return fmt.Errorf("starting agent on %s: %w", m.remote.Name, err)
+	}
+
+	if opts.Issue != "" {
+		agentID := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
+		if err := m.hookIssue(opts.Issue, agentID, m.rig.Path); err != nil {
+			style.PrintWarning("could not hook issue %s: %v", opts.Issue, err)
+		}
+	}
+
+	// Wait for runtime to be ready at the prompt, then deliver the same
+	// fallback nudges as the local path.
+	runtime.SleepForReadyDelay(runtimeConfig)
+	if fallbackInfo.SendBeaconNudge && fallbackInfo.SendStartupNudge && fallbackInfo.StartupNudgeDelayMs == 0 {
+		debugSession("SendCombinedNudge", conn.TmuxSendKeys(sessionID, beacon+"\n\n"+runtime.StartupNudgeContent()))
+	} else {
+		if fallbackInfo.SendBeaconNudge {
+			debugSession("SendBeaconNudge", conn.TmuxSendKeys(sessionID, beacon))
+		}
+		if fallbackInfo.StartupNudgeDelayMs > 0 {
+			time.Sleep(time.Duration(fallbackInfo.StartupNudgeDelayMs) * time.Millisecond)
+		}
+		if fallbackInfo.SendStartupNudge {
+			debugSession("SendStartupNudge", conn.TmuxSendKeys(sessionID, runtime.StartupNudgeContent()))
+		}
+	}
+
+	running, err = conn.TmuxHasSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("verifying session: %w", err)
+	}
+	if !running {
+		return fmt.Errorf("session %s on %s died during startup (agent command may have failed)", sessionID, m.remote.Name)
+	}
+	return nil
+}
+
+// stopRemote terminates a polecat session on the rig's machine.
+// The remote kill already sends SIGTERM before SIGKILL, so force only
+// matters for the local path.
+func (m *SessionManager) stopRemote(sessionID string, _ bool) error {
+	running, err := m.remote.Conn.TmuxHasSession(sessionID)
+	if err != nil {
+		return fmt.Errorf("checking session: %w", err)
+	}
+	if !running {
+		return ErrSessionNotFound
+	}
+	if err := m.remote.Conn.TmuxKillSession(sessionID); err != nil {
+		return fmt.Errorf("killing session on %s: %w", m.remote.Name, err)
+	}
+	return nil
+}
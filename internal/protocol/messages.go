@@ -89,9 +89,20 @@ func formatMergedBody(p MergedPayload) string {
 	return sb.String()
 }
 
+// GateOutputMarker introduces the gate output tail at the end of a
+// MERGE_FAILED body. Everything after it is output, not header fields.
+const GateOutputMarker = "--- Gate output (tail) ---"
+
 // NewMergeFailedMessage creates a MERGE_FAILED protocol message.
 // Sent by Refinery to Witness when merge fails (tests, build, etc.).
 func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureType, errorMsg string) *mail.Message {
+	return NewMergeFailedMessageWithOutput(rig, polecat, branch, issue, targetBranch, failureType, errorMsg, "", "")
+}
+
+// NewMergeFailedMessageWithOutput creates a MERGE_FAILED protocol message
+// that also points at the gate output artifacts and quotes the tail of the
+// failing gates' output.
+func NewMergeFailedMessageWithOutput(rig, polecat, branch, issue, targetBranch, failureType, errorMsg, artifacts, outputTail string) *mail.Message {
 	payload := MergeFailedPayload{
 		Branch:       branch,
 		Issue:        issue,
@@ -101,6 +112,8 @@ func NewMergeFailedMessage(rig, polecat, branch, issue, targetBranch, failureTyp
 		FailureType:  failureType,
 		Error:        errorMsg,
 		TargetBranch: targetBranch,
+		Artifacts:    artifacts,
+		OutputTail:   outputTail,
 	}
 
 	body := formatMergeFailedBody(payload)
@@ -128,6 +141,13 @@ func formatMergeFailedBody(p MergeFailedPayload) string {
 	sb.WriteString(fmt.Sprintf("Failed-At: %s\n", p.FailedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("Failure-Type: %s\n", p.FailureType))
 	sb.WriteString(fmt.Sprintf("Error: %s\n", p.Error))
+	if p.Artifacts != "" {
+		sb.WriteString(fmt.Sprintf("Artifacts: %s\n", p.Artifacts))
+	}
+	if p.OutputTail != "" {
+		sb.WriteString("\n" + GateOutputMarker + "\n")
+		sb.WriteString(strings.TrimRight(p.OutputTail, "\n") + "\n")
+	}
 	return sb.String()
 }
 
@@ -323,6 +343,7 @@ func ParseMergedPayload(body string) (*MergedPayload, error) {
 // ParseMergeFailedPayload parses a MERGE_FAILED message body into a payload.
 // Returns an error if required fields (Branch, Polecat, Rig) are missing.
 func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
+	body, outputTail := SplitGateOutput(body)
 	payload := &MergeFailedPayload{
 		Branch:       parseField(body, "Branch"),
 		Issue:        parseField(body, "Issue"),
@@ -331,6 +352,8 @@ func ParseMergeFailedPayload(body string) (*MergeFailedPayload, error) {
 		TargetBranch: parseField(body, "Target"),
 		FailureType:  parseField(body, "Failure-Type"),
 		Error:        parseField(body, "Error"),
+		Artifacts:    parseField(body, "Artifacts"),
+		OutputTail:   outputTail,
 	}
 
 	// Parse timestamp
@@ -419,6 +442,16 @@ func ParsePolecatDonePayload(polecatName, body string) *PolecatDonePayload {
 	return payload
 }
 
+// SplitGateOutput splits a MERGE_FAILED body into its header fields and the
+// gate output tail following GateOutputMarker (empty if there is none).
+func SplitGateOutput(body string) (fields, outputTail string) {
+	idx := strings.Index(body, GateOutputMarker)
+	if idx == -1 {
+		return body, ""
+	}
+	return body[:idx], strings.Trim(body[idx+len(GateOutputMarker):], "\n")
+}
+
 // parseField extracts a field value from a key-value body format.
 // Format: "Key: value"
 func parseField(body, key string) string {
@@ -142,6 +142,27 @@ func TestNewMergeFailedMessage(t *testing.T) {
 	}
 }
 
+func TestMergeFailedMessage_GateOutputRoundTrip(t *testing.T) {
+	tail := "--- FAIL: TestX\nError: want 1, got 2\nFAIL"
+	msg := NewMergeFailedMessageWithOutput("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", "tests",
+		"quality gates failed", "/town/gastown/.runtime/refinery/gate-output/gt-mr1", tail)
+
+	payload, err := ParseMergeFailedPayload(msg.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.Artifacts != "/town/gastown/.runtime/refinery/gate-output/gt-mr1" {
+		t.Errorf("Artifacts = %q", payload.Artifacts)
+	}
+	if payload.OutputTail != tail {
+		t.Errorf("OutputTail = %q, want %q", payload.OutputTail, tail)
+	}
+	// Output lines that look like fields must not override the header.
+	if payload.Error != "quality gates failed" {
+		t.Errorf("Error = %q, want header value", payload.Error)
+	}
+}
+
 func TestNewReworkRequestMessage(t *testing.T) {
 	conflicts := []string{"file1.go", "file2.go"}
 	msg := NewReworkRequestMessage("gastown", "nux", "polecat/nux/gt-abc", "gt-abc", "main", conflicts)
@@ -143,6 +143,13 @@ type MergeFailedPayload struct {
 
 	// TargetBranch is the branch we tried to merge into.
 	TargetBranch string `json:"target_branch"`
+
+	// Artifacts is the directory holding the full output of each quality
+	// gate run for the MR, if any was captured.
+	Artifacts string `json:"artifacts,omitempty"`
+
+	// OutputTail is the last lines of the failing gates' output.
+	OutputTail string `json:"output_tail,omitempty"`
 }
 
 // ReworkRequestPayload contains the data for a REWORK_REQUEST message.
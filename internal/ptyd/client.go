@@ -0,0 +1,389 @@
+package ptyd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// ErrNotRunning is returned when no daemon is serving PTY sessions.
+var ErrNotRunning = errors.New("pty supervisor not running (start it with 'gt daemon start')")
+
+// remoteErrors maps error text from the supervisor back to sentinel errors,
+// so callers can use errors.Is the same way they do with *tmux.Tmux.
+var remoteErrors = []error{tmux.ErrSessionNotFound, tmux.ErrSessionExists}
+
+// nudgeLocks serializes nudges per session within this process, like tmux.
+var nudgeLocks sync.Map // map[string]*sync.Mutex
+
+// Client talks to the daemon's PTY supervisor. It implements the session
+// operations Gas Town uses on *tmux.Tmux, so it can stand in for tmux
+// wherever a session backend is accepted.
+type Client struct {
+	socket string
+}
+
+// NewClient returns a client for the supervisor of townRoot.
+func NewClient(townRoot string) *Client {
+	return &Client{socket: SocketPath(townRoot)}
+}
+
+func (c *Client) call(method string, req *Request) (*Reply, error) {
+	conn, err := net.DialTimeout("unix", c.socket, 2*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	client := rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn))
+	defer client.Close()
+
+	var reply Reply
+	if err := client.Call(serviceName+"."+method, req, &reply); err != nil {
+		var serverErr rpc.ServerError
+		if errors.As(err, &serverErr) {
+			for _, sentinel := range remoteErrors {
+				if string(serverErr) == sentinel.Error() {
+					return &reply, sentinel
+				}
+			}
+		}
+		return &reply, err
+	}
+	return &reply, nil
+}
+
+// IsAvailable reports whether the supervisor is reachable.
+func (c *Client) IsAvailable() bool {
+	_, err := c.call("List", &Request{})
+	return err == nil
+}
+
+// NewSessionWithCommand starts a session whose pane runs command.
+func (c *Client) NewSessionWithCommand(name, workDir, command string) error {
+	_, err := c.call("Start", &Request{Name: name, WorkDir: workDir, Command: command})
+	return err
+}
+
+// HasSession reports whether a session exists. A stopped supervisor has
+// no sessions, like a tmux server that isn't running.
+func (c *Client) HasSession(name string) (bool, error) {
+	reply, err := c.call("Has", &Request{Name: name})
+	if err != nil {
+		if errors.Is(err, ErrNotRunning) {
+			return false, nil
+		}
+		return false, err
+	}
+	return reply.Found, nil
+}
+
+// ListSessions returns all session names.
+func (c *Client) ListSessions() ([]string, error) {
+	reply, err := c.call("List", &Request{})
+	if err != nil {
+		if errors.Is(err, ErrNotRunning) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reply.Names, nil
+}
+
+// KillSessionWithProcesses terminates a session and its process group.
+func (c *Client) KillSessionWithProcesses(name string) error {
+	_, err := c.call("Kill", &Request{Name: name})
+	return err
+}
+
+// SetEnvironment sets a variable in the session environment.
+func (c *Client) SetEnvironment(session, key, value string) error {
+	_, err := c.call("SetEnv", &Request{Name: session, Key: key, Value: value})
+	return err
+}
+
+// GetEnvironment returns a variable from the session environment.
+func (c *Client) GetEnvironment(session, key string) (string, error) {
+	reply, err := c.call("GetEnv", &Request{Name: session, Key: key})
+	if err != nil {
+		return "", err
+	}
+	if !reply.Found {
+		return "", fmt.Errorf("unknown variable: %s", key)
+	}
+	return reply.Text, nil
+}
+
+// SendKeysRaw sends a tmux-style key name (e.g. "C-c", "Enter", "Down"),
+// or literal text if keys isn't a key name, without a trailing Enter.
+func (c *Client) SendKeysRaw(session, keys string) error {
+	return c.send(session, keyBytes(keys))
+}
+
+// SendKeys sends literal text followed by Enter.
+func (c *Client) SendKeys(session, keys string) error {
+	return c.SendKeysDebounced(session, keys, constants.DefaultDebounceMs)
+}
+
+// SendKeysDebounced sends literal text, waits debounceMs, then sends Enter.
+func (c *Client) SendKeysDebounced(session, keys string, debounceMs int) error {
+	if err := c.send(session, []byte(keys)); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	return c.send(session, []byte("\r"))
+}
+
+// NudgeSession delivers a message to the agent the way tmux nudges do:
+// paste, pause, Escape (leaves vim insert mode), then Enter.
+func (c *Client) NudgeSession(session, message string) error {
+	mu, _ := nudgeLocks.LoadOrStore(session, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	if err := c.send(session, []byte(message)); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = c.send(session, []byte("\x1b"))
+	time.Sleep(100 * time.Millisecond)
+	return c.send(session, []byte("\r"))
+}
+
+func (c *Client) send(session string, data []byte) error {
+	_, err := c.call("Send", &Request{Name: session, Data: data})
+	return err
+}
+
+// CapturePane returns the last lines of the session's scrollback.
+func (c *Client) CapturePane(session string, lines int) (string, error) {
+	reply, err := c.call("Capture", &Request{Name: session, Lines: lines})
+	if err != nil {
+		return "", err
+	}
+	return reply.Text, nil
+}
+
+// CapturePaneLines returns the last lines of the session's scrollback as a slice.
+func (c *Client) CapturePaneLines(session string, lines int) ([]string, error) {
+	out, err := c.CapturePane(session, lines)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func (c *Client) info(session string) (*Info, error) {
+	reply, err := c.call("Info", &Request{Name: session})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Info, nil
+}
+
+// GetSessionActivity returns when the pane last produced output.
+func (c *Client) GetSessionActivity(session string) (time.Time, error) {
+	info, err := c.info(session)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.Activity, nil
+}
+
+// GetSessionInfo returns session details in the tmux format.
+func (c *Client) GetSessionInfo(name string) (*tmux.SessionInfo, error) {
+	info, err := c.info(name)
+	if err != nil {
+		return nil, err
+	}
+	return &tmux.SessionInfo{
+		Name:     info.Name,
+		Windows:  1,
+		Created:  info.Created.Format("2006-01-02 15:04:05"),
+		Activity: strconv.FormatInt(info.Activity.Unix(), 10),
+	}, nil
+}
+
+// GetPanePID returns the PID of the pane process, or "" if it has exited.
+func (c *Client) GetPanePID(target string) (string, error) {
+	info, err := c.info(target)
+	if err != nil {
+		return "", err
+	}
+	if info.PID == 0 {
+		return "", nil
+	}
+	return strconv.Itoa(info.PID), nil
+}
+
+// GetPaneCommand returns the command in the foreground of the pane.
+func (c *Client) GetPaneCommand(session string) (string, error) {
+	info, err := c.info(session)
+	if err != nil {
+		return "", err
+	}
+	if info.Command == "" {
+		return "", fmt.Errorf("empty command for session %s (pane may be dead)", session)
+	}
+	return info.Command, nil
+}
+
+// IsAgentAlive reports whether the agent is running in the session.
+// The pane's foreground command must match the session's agent process
+// names, or at least not be a shell: headless panes only ever run the
+// agent, so anything else in the foreground is the agent under another
+// name (e.g. Claude's version-as-argv[0]).
+func (c *Client) IsAgentAlive(session string) bool {
+	cmd, err := c.GetPaneCommand(session)
+	if err != nil {
+		return false
+	}
+	for _, name := range c.processNames(session) {
+		if cmd == name {
+			return true
+		}
+	}
+	for _, shell := range constants.SupportedShells {
+		if cmd == shell {
+			return false
+		}
+	}
+	return true
+}
+
+// processNames mirrors tmux's lookup: GT_PROCESS_NAMES, else the names of
+// the GT_AGENT preset.
+func (c *Client) processNames(session string) []string {
+	if names, err := c.GetEnvironment(session, "GT_PROCESS_NAMES"); err == nil && names != "" {
+		return strings.Split(names, ",")
+	}
+	agentName, _ := c.GetEnvironment(session, "GT_AGENT")
+	return config.GetProcessNames(agentName)
+}
+
+// CheckSessionHealth classifies a session as healthy, dead, agent-dead or
+// hung, using the same levels as (*tmux.Tmux).CheckSessionHealth.
+func (c *Client) CheckSessionHealth(session string, maxInactivity time.Duration) tmux.ZombieStatus {
+	alive, err := c.HasSession(session)
+	if err != nil || !alive {
+		return tmux.SessionDead
+	}
+	if !c.IsAgentAlive(session) {
+		return tmux.AgentDead
+	}
+	if maxInactivity > 0 {
+		if last, err := c.GetSessionActivity(session); err == nil && !last.IsZero() && time.Since(last) > maxInactivity {
+			return tmux.AgentHung
+		}
+	}
+	return tmux.SessionHealthy
+}
+
+// WaitForCommand polls until the pane's foreground command is not one of
+// excludeCommands.
+func (c *Client) WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		cmd, err := c.GetPaneCommand(session)
+		if err == nil && !contains(excludeCommands, cmd) {
+			return nil
+		}
+		time.Sleep(constants.PollInterval)
+	}
+	return fmt.Errorf("timeout waiting for command (still running excluded command)")
+}
+
+// AcceptBypassPermissionsWarning dismisses Claude's bypass permissions
+// dialog if it is showing.
+func (c *Client) AcceptBypassPermissionsWarning(session string) error {
+	time.Sleep(1 * time.Second)
+	content, err := c.CapturePane(session, 30)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(content, "Bypass Permissions mode") {
+		return nil
+	}
+	if err := c.SendKeysRaw(session, "Down"); err != nil {
+		return err
+	}
+	time.Sleep(200 * time.Millisecond)
+	return c.SendKeysRaw(session, "Enter")
+}
+
+// SetRemainOnExit keeps the session after its process exits.
+func (c *Client) SetRemainOnExit(pane string, on bool) error {
+	_, err := c.call("SetRemainOnExit", &Request{Name: pane, On: on})
+	return err
+}
+
+// SetAutoRespawnHook restarts the session's command whenever it exits.
+func (c *Client) SetAutoRespawnHook(session string) error {
+	_, err := c.call("SetAutoRespawn", &Request{Name: session})
+	return err
+}
+
+// SetPaneDiedHook has the daemon record a crash for agentID when the
+// session's process exits.
+func (c *Client) SetPaneDiedHook(session, agentID string) error {
+	_, err := c.call("SetPaneDiedHook", &Request{Name: session, Value: agentID})
+	return err
+}
+
+// AttachSession is not available for headless sessions.
+func (c *Client) AttachSession(session string) error {
+	return fmt.Errorf("session %s is headless and can't be attached; use 'gt peek' to read its output", session)
+}
+
+// keyBytes translates a tmux key name into terminal input bytes.
+// Anything that isn't a known key name is sent literally.
+func keyBytes(keys string) []byte {
+	switch keys {
+	case "Enter", "C-m":
+		return []byte("\r")
+	case "Escape":
+		return []byte("\x1b")
+	case "Tab":
+		return []byte("\t")
+	case "BSpace":
+		return []byte("\x7f")
+	case "Space":
+		return []byte(" ")
+	case "Up":
+		return []byte("\x1b[A")
+	case "Down":
+		return []byte("\x1b[B")
+	case "Right":
+		return []byte("\x1b[C")
+	case "Left":
+		return []byte("\x1b[D")
+	}
+	if len(keys) == 3 && strings.HasPrefix(keys, "C-") {
+		if k := keys[2] | 0x20; k >= 'a' && k <= 'z' {
+			return []byte{k - 'a' + 1}
+		}
+	}
+	return []byte(keys)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package ptyd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestKeyBytes(t *testing.T) {
+	tests := []struct {
+		keys string
+		want string
+	}{
+		{"Enter", "\r"},
+		{"C-c", "\x03"},
+		{"C-u", "\x15"},
+		{"Escape", "\x1b"},
+		{"Down", "\x1b[B"},
+		{"hello", "hello"},
+		{"C-", "C-"},
+	}
+	for _, tt := range tests {
+		if got := string(keyBytes(tt.keys)); got != tt.want {
+			t.Errorf("keyBytes(%q) = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestClientNotRunning(t *testing.T) {
+	c := NewClient(t.TempDir())
+
+	if has, err := c.HasSession("gt-x"); has || err != nil {
+		t.Errorf("HasSession = (%v, %v), want (false, nil)", has, err)
+	}
+	if names, err := c.ListSessions(); names != nil || err != nil {
+		t.Errorf("ListSessions = (%v, %v), want (nil, nil)", names, err)
+	}
+	if err := c.NewSessionWithCommand("gt-x", "", "true"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("NewSessionWithCommand error = %v, want ErrNotRunning", err)
+	}
+	if c.CheckSessionHealth("gt-x", 0) != tmux.SessionDead {
+		t.Error("CheckSessionHealth without supervisor should be SessionDead")
+	}
+}
+
+func TestClientOverSocket(t *testing.T) {
+	sup := newTestSupervisor(t)
+	// Keep the socket path short: unix socket paths are limited to ~100 bytes.
+	townRoot, err := os.MkdirTemp("", "gt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(townRoot) })
+
+	srv, err := Serve(SocketPath(townRoot), sup)
+	if err != nil {
+		t.Fatalf("Serve: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	if fi, err := os.Stat(SocketPath(townRoot)); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("socket stat = %v, %v; want mode 0600", fi, err)
+	}
+	if leftover, _ := filepath.Glob(filepath.Join(townRoot, "daemon", ".pty-sock-*")); len(leftover) != 0 {
+		t.Errorf("bind directories left behind: %v", leftover)
+	}
+	if _, err := Serve(SocketPath(townRoot), sup); err == nil {
+		t.Error("second Serve on a live socket succeeded")
+	}
+
+	c := NewClient(townRoot)
+	if !c.IsAvailable() {
+		t.Fatal("client can't reach supervisor")
+	}
+	if err := c.NewSessionWithCommand("gt-test-sock", townRoot, "exec cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	if err := c.NewSessionWithCommand("gt-test-sock", townRoot, "exec cat"); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Errorf("duplicate session error = %v, want ErrSessionExists", err)
+	}
+	if has, err := c.HasSession("gt-test-sock"); !has || err != nil {
+		t.Fatalf("HasSession = (%v, %v)", has, err)
+	}
+
+	if err := c.SetEnvironment("gt-test-sock", "GT_PROCESS_NAMES", "cat"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.GetEnvironment("gt-test-sock", "GT_PROCESS_NAMES"); v != "cat" || err != nil {
+		t.Errorf("GetEnvironment = (%q, %v)", v, err)
+	}
+	if _, err := c.GetEnvironment("gt-test-sock", "UNSET"); err == nil {
+		t.Error("GetEnvironment of unset variable succeeded")
+	}
+
+	if err := c.WaitForCommand("gt-test-sock", []string{"sh", "bash"}, 5*time.Second); err != nil {
+		t.Fatalf("WaitForCommand: %v", err)
+	}
+	if !c.IsAgentAlive("gt-test-sock") {
+		t.Error("IsAgentAlive = false for running cat")
+	}
+	if status := c.CheckSessionHealth("gt-test-sock", time.Hour); status != tmux.SessionHealthy {
+		t.Errorf("CheckSessionHealth = %v", status)
+	}
+
+	if err := c.SendKeysDebounced("gt-test-sock", "ping over socket", 0); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "echoed keys", func() bool {
+		out, err := c.CapturePane("gt-test-sock", 10)
+		return err == nil && strings.Contains(out, "ping over socket")
+	})
+
+	pid, err := c.GetPanePID("gt-test-sock")
+	if err != nil || pid == "" {
+		t.Errorf("GetPanePID = (%q, %v)", pid, err)
+	}
+	info, err := c.GetSessionInfo("gt-test-sock")
+	if err != nil || info.Name != "gt-test-sock" {
+		t.Errorf("GetSessionInfo = (%+v, %v)", info, err)
+	}
+
+	if err := c.KillSessionWithProcesses("gt-test-sock"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CapturePane("gt-test-sock", 1); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("CapturePane after kill error = %v, want ErrSessionNotFound", err)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(townRoot, "daemon", "pty.sock")); !os.IsNotExist(err) {
+		t.Errorf("socket not removed on Close: %v", err)
+	}
+}
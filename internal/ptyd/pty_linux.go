@@ -0,0 +1,105 @@
+//go:build linux
+
+package ptyd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY allocates a pseudo-terminal pair from /dev/ptmx.
+// The caller owns both files; the tty end is handed to the child process
+// and should be closed in the parent once the child has started.
+func openPTY() (master, tty *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var n int
+	err = control(master, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		var err error
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, err
+	}
+	tty, err = os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, err
+	}
+	if err := unix.IoctlSetWinsize(int(tty.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: paneRows, Col: paneCols}); err != nil {
+		_ = master.Close()
+		_ = tty.Close()
+		return nil, nil, err
+	}
+	return master, tty, nil
+}
+
+// startInPTY starts cmd as a session leader with tty as its controlling
+// terminal, so the whole process tree can be signalled as a group.
+func startInPTY(cmd *exec.Cmd, tty *os.File) error {
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	return cmd.Start()
+}
+
+// foregroundPID returns the foreground process group of the terminal,
+// which is the process the "pane" is currently running.
+func foregroundPID(master *os.File) (int, error) {
+	var pgrp int
+	err := control(master, func(fd int) error {
+		var err error
+		pgrp, err = unix.IoctlGetInt(fd, unix.TIOCGPGRP)
+		return err
+	})
+	return pgrp, err
+}
+
+// control runs fn on the file's descriptor without taking it out of
+// non-blocking mode (as Fd would), so a concurrent Close still interrupts
+// the output pump's Read.
+func control(f *os.File, fn func(fd int) error) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := rc.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// processName returns the command name of a process the way tmux reports
+// pane_current_command: the base name of argv[0], falling back to comm.
+func processName(pid int) string {
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/cmdline"); err == nil && len(data) > 0 {
+		if argv0, _, _ := bytes.Cut(data, []byte{0}); len(argv0) > 0 {
+			return filepath.Base(string(argv0))
+		}
+	}
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return string(bytes.TrimSpace(data))
+}
+
+// signalGroup sends sig to the process group led by pid.
+func signalGroup(pid int, sig syscall.Signal) error {
+	return syscall.Kill(-pid, sig)
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package ptyd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func openPTY() (master, tty *os.File, err error) {
+	return nil, nil, ErrUnsupported
+}
+
+func startInPTY(cmd *exec.Cmd, tty *os.File) error {
+	return ErrUnsupported
+}
+
+func foregroundPID(master *os.File) (int, error) {
+	return 0, ErrUnsupported
+}
+
+func processName(pid int) string {
+	return ""
+}
+
+func signalGroup(pid int, sig syscall.Signal) error {
+	return ErrUnsupported
+}
@@ -0,0 +1,185 @@
+package ptyd
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// scrollback turns a terminal output stream into plain text lines.
+//
+// It is not a terminal emulator: escape sequences are dropped, and only
+// carriage return, backspace and "erase to end of line" move the cursor.
+// That is enough for the pane checks Gas Town does (prompt detection,
+// bypass dialogs, peeking at output), which all match on text.
+type scrollback struct {
+	lines []string // completed lines, oldest first
+	cur   []rune   // line being written
+	col   int      // cursor column within cur
+	max   int      // maximum completed lines retained
+
+	esc     escState
+	csi     []byte // parameter bytes of the CSI sequence being parsed
+	pending []byte // incomplete UTF-8 sequence carried between writes
+}
+
+type escState int
+
+const (
+	escNone escState = iota
+	escStart
+	escCSI
+	escOSC
+	escOSCEsc
+	escCharset
+)
+
+func newScrollback(max int) *scrollback {
+	return &scrollback{max: max}
+}
+
+// Write appends terminal output to the buffer. It never fails.
+func (s *scrollback) Write(p []byte) (int, error) {
+	n := len(p)
+	if len(s.pending) > 0 {
+		p = append(s.pending, p...)
+		s.pending = nil
+	}
+	for len(p) > 0 {
+		b := p[0]
+		if s.esc != escNone || b < utf8.RuneSelf {
+			s.writeByte(b)
+			p = p[1:]
+			continue
+		}
+		if !utf8.FullRune(p) {
+			s.pending = append([]byte(nil), p...)
+			break
+		}
+		r, size := utf8.DecodeRune(p)
+		s.put(r)
+		p = p[size:]
+	}
+	return n, nil
+}
+
+func (s *scrollback) writeByte(b byte) {
+	switch s.esc {
+	case escStart:
+		switch b {
+		case '[':
+			s.esc = escCSI
+			s.csi = s.csi[:0]
+		case ']':
+			s.esc = escOSC
+		case '(', ')', '*', '+':
+			s.esc = escCharset
+		default:
+			s.esc = escNone
+		}
+		return
+	case escCSI:
+		if b >= 0x40 && b <= 0x7e {
+			s.esc = escNone
+			s.applyCSI(b)
+			return
+		}
+		s.csi = append(s.csi, b)
+		return
+	case escOSC:
+		switch b {
+		case 0x07:
+			s.esc = escNone
+		case 0x1b:
+			s.esc = escOSCEsc
+		}
+		return
+	case escOSCEsc:
+		s.esc = escNone
+		return
+	case escCharset:
+		s.esc = escNone
+		return
+	}
+
+	switch b {
+	case 0x1b:
+		s.esc = escStart
+	case '\n':
+		s.newline()
+	case '\r':
+		s.col = 0
+	case '\b':
+		if s.col > 0 {
+			s.col--
+		}
+	case '\t':
+		s.put('\t')
+	default:
+		if b >= 0x20 && b != 0x7f {
+			s.put(rune(b))
+		}
+	}
+}
+
+// applyCSI handles the few control sequences that affect line content.
+func (s *scrollback) applyCSI(final byte) {
+	switch final {
+	case 'K': // erase in line
+		switch string(s.csi) {
+		case "", "0":
+			if s.col < len(s.cur) {
+				s.cur = s.cur[:s.col]
+			}
+		case "2":
+			s.cur = s.cur[:0]
+			s.col = 0
+		}
+	case 'G': // cursor to column
+		col := 1
+		if len(s.csi) > 0 {
+			n, err := strconv.Atoi(string(s.csi))
+			if err != nil {
+				return
+			}
+			col = n
+		}
+		if col > 0 {
+			s.col = col - 1
+		}
+	}
+}
+
+func (s *scrollback) put(r rune) {
+	for len(s.cur) < s.col {
+		s.cur = append(s.cur, ' ')
+	}
+	if s.col < len(s.cur) {
+		s.cur[s.col] = r
+	} else {
+		s.cur = append(s.cur, r)
+	}
+	s.col++
+}
+
+func (s *scrollback) newline() {
+	s.lines = append(s.lines, strings.TrimRight(string(s.cur), " "))
+	if over := len(s.lines) - s.max; over > 0 {
+		s.lines = append(s.lines[:0], s.lines[over:]...)
+	}
+	s.cur = s.cur[:0]
+	s.col = 0
+}
+
+// Lines returns the last n lines, including the line being written if it
+// has content. n <= 0 returns everything retained.
+func (s *scrollback) Lines(n int) []string {
+	all := s.lines
+	if len(s.cur) > 0 {
+		all = append(all[:len(all):len(all)], strings.TrimRight(string(s.cur), " "))
+	}
+	if n > 0 && len(all) > n {
+		all = all[len(all)-n:]
+	}
+	return append([]string(nil), all...)
+}
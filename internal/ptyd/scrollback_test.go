@@ -0,0 +1,49 @@
+package ptyd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScrollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{"plain lines", []string{"one\ntwo\n"}, []string{"one", "two"}},
+		{"partial line", []string{"done\n$ "}, []string{"done", "$"}},
+		{"crlf", []string{"a\r\nb\r\n"}, []string{"a", "b"}},
+		{"carriage return overwrites", []string{"50%\r100%\n"}, []string{"100%"}},
+		{"erase to end of line", []string{"hello world\r\x1b[Khi\n"}, []string{"hi"}},
+		{"colors stripped", []string{"\x1b[1;32mok\x1b[0m\n"}, []string{"ok"}},
+		{"osc title stripped", []string{"\x1b]0;title\x07prompt\n"}, []string{"prompt"}},
+		{"backspace", []string{"abc\b\bX\n"}, []string{"aXc"}},
+		{"utf8 split across writes", []string{"\xe2\x9d", "\xaf go\n"}, []string{"❯ go"}},
+		{"escape split across writes", []string{"a\x1b[3", "1mb\n"}, []string{"ab"}},
+		{"cursor to column", []string{"abcdef\x1b[3GX\n"}, []string{"abXdef"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb := newScrollback(100)
+			for _, w := range tt.writes {
+				_, _ = sb.Write([]byte(w))
+			}
+			if got := sb.Lines(0); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScrollbackLimits(t *testing.T) {
+	sb := newScrollback(3)
+	_, _ = sb.Write([]byte("1\n2\n3\n4\n5\n6"))
+
+	if got, want := sb.Lines(0), []string{"3", "4", "5", "6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines(0) = %q, want %q", got, want)
+	}
+	if got, want := sb.Lines(2), []string{"5", "6"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines(2) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,170 @@
+package ptyd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// serviceName is the net/rpc service the supervisor is registered under.
+const serviceName = "PTY"
+
+// SocketPath returns the supervisor socket for a town.
+func SocketPath(townRoot string) string {
+	return filepath.Join(townRoot, "daemon", "pty.sock")
+}
+
+// Request carries the arguments of every supervisor RPC; each method reads
+// the fields it needs.
+type Request struct {
+	Name    string
+	WorkDir string
+	Command string
+	Env     map[string]string
+	Lines   int
+	Data    []byte
+	Key     string
+	Value   string
+	On      bool
+}
+
+// Reply carries the results of every supervisor RPC.
+type Reply struct {
+	Found bool
+	Names []string
+	Text  string
+	Info  *Info
+}
+
+// service adapts a Supervisor to net/rpc.
+type service struct {
+	sup *Supervisor
+}
+
+func (s *service) Start(req *Request, _ *Reply) error {
+	return s.sup.Start(Spec{Name: req.Name, WorkDir: req.WorkDir, Command: req.Command, Env: req.Env})
+}
+
+func (s *service) Kill(req *Request, _ *Reply) error {
+	return s.sup.Kill(req.Name)
+}
+
+func (s *service) Has(req *Request, reply *Reply) error {
+	reply.Found = s.sup.Has(req.Name)
+	return nil
+}
+
+func (s *service) List(_ *Request, reply *Reply) error {
+	reply.Names = s.sup.List()
+	return nil
+}
+
+func (s *service) Info(req *Request, reply *Reply) error {
+	info, err := s.sup.Info(req.Name)
+	reply.Info = info
+	return err
+}
+
+func (s *service) Capture(req *Request, reply *Reply) error {
+	text, err := s.sup.Capture(req.Name, req.Lines)
+	reply.Text = text
+	return err
+}
+
+func (s *service) Send(req *Request, _ *Reply) error {
+	return s.sup.Send(req.Name, req.Data)
+}
+
+func (s *service) SetEnv(req *Request, _ *Reply) error {
+	return s.sup.SetEnv(req.Name, req.Key, req.Value)
+}
+
+func (s *service) GetEnv(req *Request, reply *Reply) error {
+	v, found, err := s.sup.GetEnv(req.Name, req.Key)
+	reply.Text, reply.Found = v, found
+	return err
+}
+
+func (s *service) SetRemainOnExit(req *Request, _ *Reply) error {
+	return s.sup.SetRemainOnExit(req.Name, req.On)
+}
+
+func (s *service) SetAutoRespawn(req *Request, _ *Reply) error {
+	return s.sup.SetAutoRespawn(req.Name)
+}
+
+func (s *service) SetPaneDiedHook(req *Request, _ *Reply) error {
+	return s.sup.SetPaneDiedHook(req.Name, req.Value)
+}
+
+// Server serves a Supervisor on a unix socket.
+type Server struct {
+	ln   net.Listener
+	path string
+}
+
+// Serve listens on path and serves sup until Close. A socket file left by a
+// previous daemon is replaced; a live one is an error.
+func Serve(path string, sup *Supervisor) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("pty supervisor already listening on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("removing stale socket: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(serviceName, &service{sup: sup}); err != nil {
+		return nil, err
+	}
+	// Anyone who can write to the socket can run commands as the daemon, so
+	// it is bound in a private (0700) directory and only moved into place
+	// once it is 0600.
+	bindDir, err := os.MkdirTemp(filepath.Dir(path), ".pty-sock-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(bindDir) }()
+	bindPath := filepath.Join(bindDir, "sock")
+	ln, err := net.Listen("unix", bindPath)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is unlinked by Close, at its final path.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(bindPath, 0600); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+	if err := os.Rename(bindPath, path); err != nil {
+		_ = ln.Close()
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+	return &Server{ln: ln, path: path}, nil
+}
+
+// Close stops accepting connections and removes the socket.
+func (s *Server) Close() error {
+	err := s.ln.Close()
+	_ = os.Remove(s.path)
+	return err
+}
@@ -0,0 +1,447 @@
+// Package ptyd is a headless session backend for hosts that can't run a
+// tmux server, such as CI runners and containers.
+//
+// A Supervisor owns agent processes directly, each on its own pseudo-terminal,
+// and keeps a plain-text scrollback of their output. The daemon hosts the
+// supervisor and serves it on a unix socket; gt commands reach it through a
+// Client, which implements the same session operations as *tmux.Tmux.
+// Sessions live as long as the daemon that supervises them.
+package ptyd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+const (
+	// Pane geometry reported to agents. Wide enough that TUIs don't wrap
+	// prompts, tall enough for dialogs to render completely.
+	paneRows = 50
+	paneCols = 200
+
+	// scrollbackLines is how many completed output lines each session keeps.
+	scrollbackLines = 10000
+
+	// killGracePeriod is how long Kill waits after SIGTERM before SIGKILL.
+	killGracePeriod = 2 * time.Second
+
+	// respawnDelay debounces auto-respawn, matching the tmux respawn hook.
+	respawnDelay = 3 * time.Second
+
+	// drainTimeout bounds how long to wait for trailing output after exit.
+	drainTimeout = 500 * time.Millisecond
+)
+
+// ErrUnsupported is returned on platforms without PTY support.
+var ErrUnsupported = errors.New("headless PTY sessions are not supported on this platform")
+
+// errPaneDead is returned when writing to a session whose process has exited.
+var errPaneDead = errors.New("pane is dead")
+
+// validNameRe matches the session names tmux accepts from Gas Town.
+var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Spec describes a session to start.
+type Spec struct {
+	Name    string
+	WorkDir string
+	Command string
+	Env     map[string]string
+}
+
+// Info is a snapshot of a session's state.
+type Info struct {
+	Name     string
+	Created  time.Time
+	Activity time.Time // last output from the pane
+	PID      int       // pane process (session leader); 0 once dead
+	Command  string    // foreground command, like tmux pane_current_command
+	Dead     bool      // process exited and remain-on-exit kept the session
+	ExitCode int
+}
+
+// PaneDeath describes a session process exit that had a pane-died hook.
+type PaneDeath struct {
+	Session  string
+	AgentID  string
+	ExitCode int
+}
+
+// Supervisor runs and tracks headless sessions.
+type Supervisor struct {
+	mu       sync.Mutex
+	sessions map[string]*ptySession
+
+	// OnPaneDied is called in its own goroutine when the process of a
+	// session with a pane-died hook exits on its own (not via Kill).
+	OnPaneDied func(PaneDeath)
+}
+
+// ptySession is one supervised session. All fields are guarded by the
+// supervisor's mutex.
+type ptySession struct {
+	name    string
+	workDir string
+	command string
+	env     map[string]string
+	created time.Time
+
+	master   *os.File
+	cmd      *exec.Cmd
+	exited   chan struct{} // closed when the current process has exited
+	activity time.Time
+	buf      *scrollback
+	dead     bool
+	exitCode int
+
+	remainOnExit  bool
+	autoRespawn   bool
+	paneDiedAgent string
+}
+
+// NewSupervisor creates an empty supervisor.
+func NewSupervisor() *Supervisor {
+	return &Supervisor{sessions: make(map[string]*ptySession)}
+}
+
+// Start creates a session running spec.Command under /bin/sh.
+func (sup *Supervisor) Start(spec Spec) error {
+	if spec.Name == "" || !validNameRe.MatchString(spec.Name) {
+		return fmt.Errorf("%w %q: must match %s", tmux.ErrInvalidSessionName, spec.Name, validNameRe.String())
+	}
+
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	if _, ok := sup.sessions[spec.Name]; ok {
+		return tmux.ErrSessionExists
+	}
+
+	env := make(map[string]string, len(spec.Env))
+	for k, v := range spec.Env {
+		env[k] = v
+	}
+	s := &ptySession{
+		name:    spec.Name,
+		workDir: spec.WorkDir,
+		command: spec.Command,
+		env:     env,
+		created: time.Now(),
+		buf:     newScrollback(scrollbackLines),
+	}
+	if err := sup.spawnLocked(s); err != nil {
+		return err
+	}
+	sup.sessions[s.name] = s
+	return nil
+}
+
+// spawnLocked starts the session's command on a fresh PTY.
+func (sup *Supervisor) spawnLocked(s *ptySession) error {
+	master, tty, err := openPTY()
+	if err != nil {
+		return fmt.Errorf("allocating pty: %w", err)
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", s.command) //nolint:gosec // G204: command comes from the session owner
+	cmd.Dir = s.workDir
+	cmd.Env = mergeEnv(os.Environ(), s.env)
+	err = startInPTY(cmd, tty)
+	_ = tty.Close()
+	if err != nil {
+		_ = master.Close()
+		return fmt.Errorf("starting %s: %w", s.name, err)
+	}
+
+	exited := make(chan struct{})
+	drained := make(chan struct{})
+	s.master = master
+	s.cmd = cmd
+	s.exited = exited
+	s.activity = time.Now()
+	s.dead = false
+	s.exitCode = 0
+
+	go sup.pump(s, master, drained)
+	go sup.wait(s, cmd, master, exited, drained)
+	return nil
+}
+
+// pump copies pane output into the session's scrollback.
+func (sup *Supervisor) pump(s *ptySession, master *os.File, drained chan<- struct{}) {
+	defer close(drained)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := master.Read(buf)
+		if n > 0 {
+			sup.mu.Lock()
+			_, _ = s.buf.Write(buf[:n])
+			s.activity = time.Now()
+			sup.mu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// wait reaps the session process and applies its exit options: fire the
+// pane-died hook, then respawn, keep the dead pane, or drop the session.
+func (sup *Supervisor) wait(s *ptySession, cmd *exec.Cmd, master *os.File, exited chan struct{}, drained <-chan struct{}) {
+	code := 0
+	if err := cmd.Wait(); err != nil {
+		code = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+	}
+	select {
+	case <-drained:
+	case <-time.After(drainTimeout):
+	}
+	_ = master.Close()
+
+	sup.mu.Lock()
+	close(exited)
+	if sup.sessions[s.name] != s || s.cmd != cmd {
+		// Killed, or already replaced by a respawn.
+		sup.mu.Unlock()
+		return
+	}
+	s.master = nil
+	s.dead = true
+	s.exitCode = code
+	agentID := s.paneDiedAgent
+	respawn := s.autoRespawn
+	if !s.remainOnExit && !respawn {
+		delete(sup.sessions, s.name)
+	}
+	onPaneDied := sup.OnPaneDied
+	sup.mu.Unlock()
+
+	if agentID != "" && onPaneDied != nil {
+		go onPaneDied(PaneDeath{Session: s.name, AgentID: agentID, ExitCode: code})
+	}
+	if respawn {
+		time.AfterFunc(respawnDelay, func() {
+			sup.mu.Lock()
+			defer sup.mu.Unlock()
+			if sup.sessions[s.name] == s && s.dead {
+				_ = sup.spawnLocked(s)
+			}
+		})
+	}
+}
+
+// Kill terminates a session and its process group: SIGTERM, then SIGKILL
+// after a grace period. The session is removed before signalling so exit
+// hooks and auto-respawn don't fire.
+func (sup *Supervisor) Kill(name string) error {
+	sup.mu.Lock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		sup.mu.Unlock()
+		return tmux.ErrSessionNotFound
+	}
+	delete(sup.sessions, name)
+	dead := s.dead
+	exited := s.exited
+	var pids []int
+	if !dead {
+		pids = append(pids, s.cmd.Process.Pid)
+		if fg, err := foregroundPID(s.master); err == nil && fg > 0 && fg != pids[0] {
+			pids = append(pids, fg)
+		}
+	}
+	sup.mu.Unlock()
+
+	if dead {
+		return nil
+	}
+	for _, pid := range pids {
+		_ = signalGroup(pid, syscall.SIGHUP)
+		_ = signalGroup(pid, syscall.SIGTERM)
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(killGracePeriod):
+	}
+	for _, pid := range pids {
+		_ = signalGroup(pid, syscall.SIGKILL)
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(killGracePeriod):
+		return fmt.Errorf("session %s: process %d did not exit after SIGKILL", name, pids[0])
+	}
+}
+
+// Close kills every session. Used when the daemon shuts down.
+func (sup *Supervisor) Close() {
+	for _, name := range sup.List() {
+		_ = sup.Kill(name)
+	}
+}
+
+// Has reports whether a session exists (alive, or dead with remain-on-exit).
+func (sup *Supervisor) Has(name string) bool {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	_, ok := sup.sessions[name]
+	return ok
+}
+
+// List returns all session names, sorted.
+func (sup *Supervisor) List() []string {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	names := make([]string, 0, len(sup.sessions))
+	for name := range sup.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Info returns a snapshot of a session.
+func (sup *Supervisor) Info(name string) (*Info, error) {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		return nil, tmux.ErrSessionNotFound
+	}
+	info := &Info{
+		Name:     s.name,
+		Created:  s.created,
+		Activity: s.activity,
+		Dead:     s.dead,
+		ExitCode: s.exitCode,
+	}
+	if !s.dead {
+		info.PID = s.cmd.Process.Pid
+		fg := info.PID
+		if pid, err := foregroundPID(s.master); err == nil && pid > 0 {
+			fg = pid
+		}
+		info.Command = processName(fg)
+	}
+	return info, nil
+}
+
+// Capture returns the last n lines of a session's scrollback.
+// n <= 0 returns all retained lines.
+func (sup *Supervisor) Capture(name string, n int) (string, error) {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		return "", tmux.ErrSessionNotFound
+	}
+	return strings.Join(s.buf.Lines(n), "\n"), nil
+}
+
+// Send writes raw bytes to a session's terminal input.
+func (sup *Supervisor) Send(name string, data []byte) error {
+	sup.mu.Lock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		sup.mu.Unlock()
+		return tmux.ErrSessionNotFound
+	}
+	master := s.master
+	sup.mu.Unlock()
+	if master == nil {
+		return fmt.Errorf("session %s: %w", name, errPaneDead)
+	}
+	_, err := master.Write(data)
+	return err
+}
+
+// SetEnv sets a session environment variable. Like tmux, this affects
+// processes started afterwards (respawns), not the running one.
+func (sup *Supervisor) SetEnv(name, key, value string) error {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		return tmux.ErrSessionNotFound
+	}
+	s.env[key] = value
+	return nil
+}
+
+// GetEnv returns a session environment variable and whether it is set.
+func (sup *Supervisor) GetEnv(name, key string) (string, bool, error) {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		return "", false, tmux.ErrSessionNotFound
+	}
+	v, set := s.env[key]
+	return v, set, nil
+}
+
+// SetRemainOnExit controls whether a session is kept after its process exits.
+func (sup *Supervisor) SetRemainOnExit(name string, on bool) error {
+	return sup.update(name, func(s *ptySession) { s.remainOnExit = on })
+}
+
+// SetAutoRespawn makes a session restart its command whenever it exits.
+func (sup *Supervisor) SetAutoRespawn(name string) error {
+	return sup.update(name, func(s *ptySession) {
+		s.remainOnExit = true
+		s.autoRespawn = true
+	})
+}
+
+// SetPaneDiedHook records the agent ID reported to OnPaneDied when the
+// session's process exits.
+func (sup *Supervisor) SetPaneDiedHook(name, agentID string) error {
+	return sup.update(name, func(s *ptySession) { s.paneDiedAgent = agentID })
+}
+
+func (sup *Supervisor) update(name string, fn func(*ptySession)) error {
+	sup.mu.Lock()
+	defer sup.mu.Unlock()
+	s, ok := sup.sessions[name]
+	if !ok {
+		return tmux.ErrSessionNotFound
+	}
+	fn(s)
+	return nil
+}
+
+// mergeEnv overlays session variables on a base environment.
+func mergeEnv(base []string, overlay map[string]string) []string {
+	env := make([]string, 0, len(base)+len(overlay))
+	for _, kv := range base {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, ok := overlay[k]; ok {
+			continue
+		}
+		env = append(env, kv)
+	}
+	keys := make([]string, 0, len(overlay))
+	for k := range overlay {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, k+"="+overlay[k])
+	}
+	return env
+}
@@ -0,0 +1,175 @@
+package ptyd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// newTestSupervisor returns a supervisor that is closed with the test,
+// skipping on platforms without PTY support.
+func newTestSupervisor(t *testing.T) *Supervisor {
+	t.Helper()
+	master, tty, err := openPTY()
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Skipf("no pty available: %v", err)
+	}
+	_ = master.Close()
+	_ = tty.Close()
+
+	sup := NewSupervisor()
+	t.Cleanup(sup.Close)
+	return sup
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func captureContains(sup *Supervisor, name, text string) func() bool {
+	return func() bool {
+		out, err := sup.Capture(name, 0)
+		return err == nil && strings.Contains(out, text)
+	}
+}
+
+func TestSupervisorStartCaptureSend(t *testing.T) {
+	sup := newTestSupervisor(t)
+	dir := t.TempDir()
+
+	err := sup.Start(Spec{
+		Name:    "gt-test-cat",
+		WorkDir: dir,
+		Command: `echo "started in $PWD as $GT_ROLE"; exec cat`,
+		Env:     map[string]string{"GT_ROLE": "polecat"},
+	})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitFor(t, "startup output", captureContains(sup, "gt-test-cat", "started in "+dir+" as polecat"))
+
+	if err := sup.Start(Spec{Name: "gt-test-cat", Command: "true"}); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Errorf("duplicate Start error = %v, want ErrSessionExists", err)
+	}
+
+	if err := sup.Send("gt-test-cat", []byte("hello pty\r")); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	waitFor(t, "echoed input", captureContains(sup, "gt-test-cat", "hello pty"))
+
+	info, err := sup.Info("gt-test-cat")
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.PID == 0 || info.Dead || info.Command != "cat" {
+		t.Errorf("Info = %+v, want live cat", info)
+	}
+	if time.Since(info.Activity) > 5*time.Second {
+		t.Errorf("Activity = %v, want recent", info.Activity)
+	}
+
+	if got := sup.List(); len(got) != 1 || got[0] != "gt-test-cat" {
+		t.Errorf("List = %v", got)
+	}
+	if err := sup.Kill("gt-test-cat"); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if sup.Has("gt-test-cat") {
+		t.Error("session still present after Kill")
+	}
+	if err := sup.Kill("gt-test-cat"); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("second Kill error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSupervisorInvalidName(t *testing.T) {
+	sup := NewSupervisor()
+	if err := sup.Start(Spec{Name: "bad.name", Command: "true"}); !errors.Is(err, tmux.ErrInvalidSessionName) {
+		t.Errorf("Start error = %v, want ErrInvalidSessionName", err)
+	}
+}
+
+func TestSupervisorExitOptions(t *testing.T) {
+	sup := newTestSupervisor(t)
+	deaths := make(chan PaneDeath, 1)
+	sup.OnPaneDied = func(d PaneDeath) { deaths <- d }
+
+	// Without remain-on-exit the session disappears with its process.
+	if err := sup.Start(Spec{Name: "gt-test-exit", Command: "sleep 0.2; exit 3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sup.SetPaneDiedHook("gt-test-exit", "rig/Toast"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "session removal", func() bool { return !sup.Has("gt-test-exit") })
+	select {
+	case d := <-deaths:
+		if d.Session != "gt-test-exit" || d.AgentID != "rig/Toast" || d.ExitCode != 3 {
+			t.Errorf("PaneDeath = %+v", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("pane-died hook did not fire")
+	}
+
+	// With remain-on-exit the dead pane and its output are kept.
+	if err := sup.Start(Spec{Name: "gt-test-remain", Command: "sleep 0.2; echo last words"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sup.SetRemainOnExit("gt-test-remain", true); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "dead pane", func() bool {
+		info, err := sup.Info("gt-test-remain")
+		return err == nil && info.Dead
+	})
+	if out, _ := sup.Capture("gt-test-remain", 5); !strings.Contains(out, "last words") {
+		t.Errorf("Capture after exit = %q, want last words", out)
+	}
+	if err := sup.Send("gt-test-remain", []byte("x")); !errors.Is(err, errPaneDead) {
+		t.Errorf("Send to dead pane error = %v, want errPaneDead", err)
+	}
+}
+
+func TestSupervisorKillsProcessGroup(t *testing.T) {
+	sup := newTestSupervisor(t)
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+
+	// The shell and its background child (which inherits ignored signals)
+	// shrug off SIGTERM and SIGHUP, so Kill must SIGKILL the whole group.
+	cmd := `trap "" TERM HUP; sleep 60 & echo $! > ` + pidFile + `; wait`
+	if err := sup.Start(Spec{Name: "gt-test-group", Command: cmd}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "child pid", func() bool {
+		data, err := os.ReadFile(pidFile)
+		return err == nil && len(strings.TrimSpace(string(data))) > 0
+	})
+
+	if err := sup.Kill("gt-test-group"); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	data, _ := os.ReadFile(pidFile)
+	pid := strings.TrimSpace(string(data))
+	waitFor(t, "child exit", func() bool {
+		// A zombie awaiting its reaper counts as dead.
+		stat, err := os.ReadFile("/proc/" + pid + "/stat")
+		return err != nil || strings.Contains(string(stat), ") Z ")
+	})
+}
@@ -0,0 +1,268 @@
+package refinery
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mail"
+)
+
+// ApprovalRequirement is one merge_queue.approvals rule matched by an MR's
+// changed files. Any one of Approvers satisfies it.
+type ApprovalRequirement struct {
+	Pattern    string   `json:"pattern"`
+	Approvers  []string `json:"approvers"`
+	Files      []string `json:"files"`
+	ApprovedBy string   `json:"approved_by,omitempty"`
+}
+
+// Satisfied reports whether one of the rule's approvers has approved.
+func (r ApprovalRequirement) Satisfied() bool {
+	return r.ApprovedBy != ""
+}
+
+// validateApprovalRules checks merge_queue.approvals patterns and approvers.
+func validateApprovalRules(rules map[string][]string) error {
+	for pattern, approvers := range rules {
+		if err := validatePathPattern(pattern); err != nil {
+			return fmt.Errorf("approvals: %w", err)
+		}
+		if len(approvers) == 0 {
+			return fmt.Errorf("approvals pattern %q has no approvers", pattern)
+		}
+	}
+	return nil
+}
+
+// validatePathPattern checks a MatchPathPattern pattern.
+func validatePathPattern(pattern string) error {
+	if strings.Trim(pattern, "/") == "" {
+		return fmt.Errorf("invalid pattern %q", pattern)
+	}
+	for _, seg := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// MatchPathPattern reports whether file, a slash-separated path relative
+// to the repo root, matches a CODEOWNERS-style pattern:
+//   - a pattern with no slash other than a trailing one ("*.sql", "auth/")
+//     matches at any depth; otherwise it is anchored at the root ("infra/*.tf")
+//   - "**" matches any number of directories
+//   - a pattern matching a directory covers everything under it; a trailing
+//     slash matches directories only
+func MatchPathPattern(pattern, file string) bool {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	pat := strings.Split(pattern, "/")
+	parts := strings.Split(file, "/")
+	n := len(parts)
+	if dirOnly {
+		n--
+	}
+	for ; n > 0; n-- {
+		if matchSegments(pat, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, file []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(pattern[1:], file[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], file[0]); err != nil || !ok {
+			return false
+		}
+		pattern, file = pattern[1:], file[1:]
+	}
+	return len(file) == 0
+}
+
+// RequiredApprovals returns the rules matched by files, sorted by pattern,
+// with ApprovedBy set for those approvedBy satisfies.
+func RequiredApprovals(rules map[string][]string, files, approvedBy []string) []ApprovalRequirement {
+	var reqs []ApprovalRequirement
+	for pattern, approvers := range rules {
+		var matched []string
+		for _, f := range files {
+			if MatchPathPattern(pattern, f) {
+				matched = append(matched, f)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		req := ApprovalRequirement{Pattern: pattern, Approvers: approvers, Files: matched}
+		for _, name := range approvedBy {
+			if slices.Contains(approvers, name) {
+				req.ApprovedBy = name
+				break
+			}
+		}
+		reqs = append(reqs, req)
+	}
+	sort.Slice(reqs, func(i, j int) bool { return reqs[i].Pattern < reqs[j].Pattern })
+	return reqs
+}
+
+// PendingApprovals returns the unsatisfied requirements in reqs.
+func PendingApprovals(reqs []ApprovalRequirement) []ApprovalRequirement {
+	var pending []ApprovalRequirement
+	for _, r := range reqs {
+		if !r.Satisfied() {
+			pending = append(pending, r)
+		}
+	}
+	return pending
+}
+
+// ApprovalStatus returns the approval rules the MR's changes against its
+// target trigger, with the approvals recorded on the MR applied. It returns
+// nil when the rig has no approval rules.
+func (e *Engineer) ApprovalStatus(fields *beads.MRFields) ([]ApprovalRequirement, error) {
+	if len(e.config.Approvals) == 0 {
+		return nil, nil
+	}
+	files, err := e.git.ChangedFiles(fields.Target, fields.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("listing files changed by %s: %w", fields.Branch, err)
+	}
+	return RequiredApprovals(e.config.Approvals, files, fields.ApprovedBy), nil
+}
+
+// ApprovalHead returns the MR branch's current head. Approvals recorded on
+// fields for another head are dropped, since the branch has changed (new
+// commits or a force-push) after sign-off; it reports whether it dropped any.
+func (e *Engineer) ApprovalHead(fields *beads.MRFields) (string, bool, error) {
+	head, err := e.git.Rev(fields.Branch)
+	if err != nil {
+		return "", false, fmt.Errorf("resolving %s: %w", fields.Branch, err)
+	}
+	if len(fields.ApprovedBy) == 0 || fields.ApprovedHead == head {
+		return head, false, nil
+	}
+	fields.ApprovedBy = nil
+	fields.ApprovedHead = ""
+	return head, true, nil
+}
+
+// holdForApproval reports whether the MR must wait for human approval. An
+// MR entering the awaiting-approval phase is marked on its bead and the
+// overseer is mailed; an MR whose approvals are complete is moved back to
+// ready. Approvals given for an earlier branch head are cleared first. MRs
+// whose changes can't be listed are held without a phase change.
+func (e *Engineer) holdForApproval(issue *beads.Issue, fields *beads.MRFields) bool {
+	if _, reset, err := e.ApprovalHead(fields); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: holding %s, could not check approvals: %v\n", issue.ID, err)
+		return true
+	} else if reset {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] %s changed since it was approved; approvals cleared\n", issue.ID)
+		desc := beads.SetMRFields(issue, fields)
+		if err := e.beads.Update(issue.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to clear approvals on %s: %v\n", issue.ID, err)
+		}
+	}
+	reqs, err := e.ApprovalStatus(fields)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: holding %s, could not check approvals: %v\n", issue.ID, err)
+		return true
+	}
+	pending := PendingApprovals(reqs)
+	awaiting := fields.Phase == string(MRPhaseAwaitingApproval)
+	switch {
+	case len(pending) > 0 && !awaiting:
+		if err := e.setMRPhase(issue, fields, MRPhaseAwaitingApproval); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to mark %s awaiting approval: %v\n", issue.ID, err)
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Holding %s for approval (%s)\n", issue.ID, approvalPatterns(pending))
+		e.notifyApprovalNeeded(issue, fields, pending)
+	case len(pending) == 0 && awaiting:
+		if err := e.setMRPhase(issue, fields, MRPhaseReady); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to mark %s ready: %v\n", issue.ID, err)
+		}
+	}
+	return len(pending) > 0
+}
+
+// setMRPhase records phase on the MR bead. The ready phase is stored as no
+// phase.
+func (e *Engineer) setMRPhase(issue *beads.Issue, fields *beads.MRFields, phase MRPhase) error {
+	current := MRPhase(fields.Phase)
+	if current == "" {
+		current = MRPhaseReady
+	}
+	if err := ValidatePhaseTransition(current, phase); err != nil {
+		return err
+	}
+	fields.Phase = string(phase)
+	if phase == MRPhaseReady {
+		fields.Phase = ""
+	}
+	desc := beads.SetMRFields(issue, fields)
+	return e.beads.Update(issue.ID, beads.UpdateOptions{Description: &desc})
+}
+
+// notifyApprovalNeeded mails the overseer the approvals an MR is waiting on.
+func (e *Engineer) notifyApprovalNeeded(issue *beads.Issue, fields *beads.MRFields, pending []ApprovalRequirement) {
+	if e.router == nil {
+		return
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "Merge request %s (%s → %s) needs human approval before the refinery merges it.\n\n", issue.ID, fields.Branch, fields.Target)
+	if issue.Title != "" {
+		fmt.Fprintf(&body, "Title: %s\n", issue.Title)
+	}
+	if fields.Worker != "" {
+		fmt.Fprintf(&body, "Worker: %s\n", fields.Worker)
+	}
+	body.WriteString("\n")
+	for _, r := range pending {
+		fmt.Fprintf(&body, "%s (any of: %s)\n", r.Pattern, strings.Join(r.Approvers, ", "))
+		for _, f := range r.Files {
+			fmt.Fprintf(&body, "  %s\n", f)
+		}
+	}
+	fmt.Fprintf(&body, "\nApprove with: gt mq approve %s %s --as <approver>\n", e.rig.Name, issue.ID)
+
+	msg := mail.NewMessage(
+		e.rig.Name+"/refinery",
+		"overseer",
+		fmt.Sprintf("APPROVAL_NEEDED %s", issue.ID),
+		body.String(),
+	)
+	msg.Priority = mail.PriorityHigh
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to mail overseer about %s: %v\n", issue.ID, err)
+	}
+}
+
+// approvalPatterns lists the patterns of reqs for display.
+func approvalPatterns(reqs []ApprovalRequirement) string {
+	patterns := make([]string, len(reqs))
+	for i, r := range reqs {
+		patterns[i] = r.Pattern
+	}
+	return strings.Join(patterns, ", ")
+}
@@ -0,0 +1,130 @@
+package refinery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"migrations/", "migrations/001_init.sql", true},
+		{"migrations/", "db/migrations/001_init.sql", true},
+		{"migrations/", "migrations", false},
+		{"/infra/", "infra/main.tf", true},
+		{"/infra/", "deploy/infra/main.tf", false},
+		{"infra/*.tf", "infra/main.tf", true},
+		{"infra/*.tf", "infra/modules/vpc.tf", false},
+		{"infra/**/*.tf", "infra/modules/vpc.tf", true},
+		{"*.sql", "db/schema.sql", true},
+		{"auth", "internal/auth/login.go", true},
+		{"internal/auth/**", "internal/auth/login.go", true},
+		{"internal/auth/**", "internal/authz/login.go", false},
+		{"docs/*.md", "README.md", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPathPattern(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("MatchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestRequiredApprovals(t *testing.T) {
+	rules := map[string][]string{
+		"migrations/": {"alice", "bob"},
+		"infra/":      {"carol"},
+		"docs/":       {"dave"},
+	}
+	files := []string{"migrations/002.sql", "infra/main.tf", "main.go"}
+
+	reqs := RequiredApprovals(rules, files, []string{"bob"})
+	if len(reqs) != 2 || reqs[0].Pattern != "infra/" || reqs[1].Pattern != "migrations/" {
+		t.Fatalf("RequiredApprovals = %+v, want infra/ and migrations/", reqs)
+	}
+	if reqs[0].Satisfied() || reqs[1].ApprovedBy != "bob" {
+		t.Errorf("expected migrations/ approved by bob and infra/ pending, got %+v", reqs)
+	}
+	if pending := PendingApprovals(reqs); len(pending) != 1 || pending[0].Pattern != "infra/" {
+		t.Errorf("PendingApprovals = %+v, want infra/", pending)
+	}
+}
+
+func TestValidateApprovalRules(t *testing.T) {
+	if err := validateApprovalRules(map[string][]string{"migrations/": {"alice"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateApprovalRules(map[string][]string{"migrations/": nil}); err == nil {
+		t.Error("expected error for pattern without approvers")
+	}
+	if err := validateApprovalRules(map[string][]string{"infra/[": {"alice"}}); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
+
+func TestApprovalStatus(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	e.config.Approvals = map[string][]string{"migrations/": {"alice"}}
+
+	plain := addMRBranch(t, work, "feat-plain", "plain.txt", "x")
+	reqs, err := e.ApprovalStatus(&beads.MRFields{Branch: plain.Branch, Target: "main"})
+	if err != nil || len(reqs) != 0 {
+		t.Errorf("ApprovalStatus(plain) = %+v, %v; want none", reqs, err)
+	}
+
+	runGit(t, work, "checkout", "-q", "-b", "feat-migration", "main")
+	if err := os.MkdirAll(filepath.Join(work, "migrations"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTrainFile(t, work, "migrations/001.sql", "create table t();")
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-q", "-m", "add migration")
+	runGit(t, work, "checkout", "-q", "main")
+
+	fields := &beads.MRFields{Branch: "feat-migration", Target: "main"}
+	reqs, err = e.ApprovalStatus(fields)
+	if err != nil {
+		t.Fatalf("ApprovalStatus: %v", err)
+	}
+	if len(PendingApprovals(reqs)) != 1 || reqs[0].Files[0] != "migrations/001.sql" {
+		t.Errorf("ApprovalStatus = %+v, want pending migrations/", reqs)
+	}
+
+	fields.ApprovedBy = []string{"alice"}
+	if reqs, _ = e.ApprovalStatus(fields); len(PendingApprovals(reqs)) != 0 {
+		t.Errorf("expected alice's approval to satisfy migrations/, got %+v", reqs)
+	}
+}
+
+func TestApprovalHead_ClearsApprovalsWhenBranchMoves(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	mr := addMRBranch(t, work, "feat-approved", "a.txt", "a")
+
+	fields := &beads.MRFields{Branch: mr.Branch, Target: "main"}
+	head, reset, err := e.ApprovalHead(fields)
+	if err != nil || reset {
+		t.Fatalf("ApprovalHead = %s, %v, %v; want no reset", head, reset, err)
+	}
+	fields.ApprovedBy = []string{"alice"}
+	fields.ApprovedHead = head
+	if _, reset, _ := e.ApprovalHead(fields); reset || len(fields.ApprovedBy) != 1 {
+		t.Errorf("approval for the current head should stand, got %+v", fields)
+	}
+
+	runGit(t, work, "checkout", "-q", mr.Branch)
+	writeTrainFile(t, work, "a.txt", "changed after approval")
+	runGit(t, work, "commit", "-q", "-am", "sneak in a change")
+	runGit(t, work, "checkout", "-q", "main")
+
+	newHead, reset, err := e.ApprovalHead(fields)
+	if err != nil || !reset || newHead == head {
+		t.Fatalf("ApprovalHead after a new commit = %s, %v, %v; want a reset", newHead, reset, err)
+	}
+	if len(fields.ApprovedBy) != 0 || fields.ApprovedHead != "" {
+		t.Errorf("approvals should be cleared, got %+v", fields)
+	}
+}
@@ -0,0 +1,255 @@
+package refinery
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// MaxGateOutputBytes caps the combined stdout/stderr kept for one gate run.
+// Output beyond the cap is dropped from the front: the end of a test run is
+// where the failures are.
+const MaxGateOutputBytes = 256 * 1024
+
+// GateOutputTailLines is how many trailing lines of a failed gate's output
+// are quoted in MERGE_FAILED mail and conflict/failure tasks.
+const GateOutputTailLines = 40
+
+// GateArtifact describes the persisted output of one gate run for an MR.
+type GateArtifact struct {
+	Gate    string        `json:"gate"`
+	Path    string        `json:"path"`
+	Failed  bool          `json:"failed"`
+	Elapsed time.Duration `json:"elapsed,omitempty"`
+	Size    int64         `json:"size"`
+	ModTime time.Time     `json:"mod_time"`
+}
+
+var unsafeArtifactChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// artifactName makes an MR ID or gate name safe to use as a path element.
+func artifactName(s string) string {
+	s = unsafeArtifactChars.ReplaceAllString(s, "_")
+	if s == "" || strings.Trim(s, ".") == "" {
+		return "_"
+	}
+	return s
+}
+
+// GateArtifactDir returns the directory holding an MR's gate output:
+// <rig>/.runtime/refinery/gate-output/<mr-id>/.
+func GateArtifactDir(rigPath, mrID string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, constants.DirRefinery, "gate-output", artifactName(mrID))
+}
+
+// GateArtifactPath returns the artifact file for one gate of an MR.
+func GateArtifactPath(rigPath, mrID, gate string) string {
+	return filepath.Join(GateArtifactDir(rigPath, mrID), artifactName(gate)+".log")
+}
+
+// WriteGateArtifacts replaces the stored gate output for mrID with gates,
+// one file per gate. Each file starts with a header line recording the
+// gate's name, result and duration. It returns the written artifacts.
+func WriteGateArtifacts(rigPath, mrID string, gates []GateResult) ([]GateArtifact, error) {
+	dir := GateArtifactDir(rigPath, mrID)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("clearing gate output: %w", err)
+	}
+	if len(gates) == 0 {
+		return nil, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating gate output dir: %w", err)
+	}
+
+	artifacts := make([]GateArtifact, 0, len(gates))
+	for _, g := range gates {
+		status := "passed"
+		if !g.Success {
+			status = "failed"
+		}
+		path := GateArtifactPath(rigPath, mrID, g.Name)
+		content := fmt.Sprintf("# gate: %s status: %s elapsed: %s\n%s", g.Name, status, g.Elapsed.Truncate(time.Millisecond), g.Output)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // G306: gate logs are not secret
+			return artifacts, fmt.Errorf("writing gate output %s: %w", g.Name, err)
+		}
+		artifacts = append(artifacts, GateArtifact{
+			Gate:    g.Name,
+			Path:    path,
+			Failed:  !g.Success,
+			Elapsed: g.Elapsed,
+			Size:    int64(len(content)),
+			ModTime: time.Now(),
+		})
+	}
+	return artifacts, nil
+}
+
+// ListGateArtifacts returns the stored gate output for mrID, sorted by gate
+// name. A missing directory yields no artifacts and no error.
+func ListGateArtifacts(rigPath, mrID string) ([]GateArtifact, error) {
+	dir := GateArtifactDir(rigPath, mrID)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var artifacts []GateArtifact
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".log") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		a := GateArtifact{
+			Gate:    strings.TrimSuffix(entry.Name(), ".log"),
+			Path:    filepath.Join(dir, entry.Name()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}
+		readArtifactHeader(&a)
+		artifacts = append(artifacts, a)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Gate < artifacts[j].Gate })
+	return artifacts, nil
+}
+
+// readArtifactHeader fills the result and duration from the header line
+// written by WriteGateArtifacts.
+func readArtifactHeader(a *GateArtifact) {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "# ") {
+		return
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "# "))
+	for i := 0; i+1 < len(fields); i++ {
+		switch fields[i] {
+		case "status:":
+			a.Failed = fields[i+1] == "failed"
+		case "elapsed:":
+			if d, err := time.ParseDuration(fields[i+1]); err == nil {
+				a.Elapsed = d
+			}
+		}
+	}
+}
+
+// OutputTail returns the last n lines of output.
+func OutputTail(output string, n int) string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" || n <= 0 {
+		return ""
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FailedGateTail returns the output tail of each failed gate in gates,
+// headed by the gate name, for quoting in mail and tasks.
+func FailedGateTail(gates []GateResult) string {
+	var parts []string
+	for _, g := range gates {
+		if g.Success {
+			continue
+		}
+		tail := OutputTail(g.Output, GateOutputTailLines)
+		if tail == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("[%s]\n%s", g.Name, tail))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// tailBuffer is an io.Writer that keeps only the last max bytes written.
+// It is safe for concurrent writes (a command's stdout and stderr).
+type tailBuffer struct {
+	mu      sync.Mutex
+	max     int
+	buf     []byte
+	dropped int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.dropped += int64(over)
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, noting how much was dropped.
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped > 0 {
+		return fmt.Sprintf("... (%d bytes of earlier output truncated)\n%s", b.dropped, b.buf)
+	}
+	return string(b.buf)
+}
+
+// saveGateArtifacts persists the gate output behind result for mr and
+// returns the artifact directory, or "" when there was nothing to save.
+func (e *Engineer) saveGateArtifacts(mr *MRInfo, result ProcessResult) string {
+	if mr.ID == "" || len(result.Gates) == 0 {
+		return ""
+	}
+	if _, err := WriteGateArtifacts(e.rig.Path, mr.ID, result.Gates); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save gate output for %s: %v\n", mr.ID, err)
+		return ""
+	}
+	return GateArtifactDir(e.rig.Path, mr.ID)
+}
+
+// gateOutputSection renders the failing gates' output tail as a markdown
+// section for task descriptions, or "" when there is none.
+func gateOutputSection(mr *MRInfo, result ProcessResult) string {
+	tail := FailedGateTail(result.Gates)
+	if tail == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\n## Gate Output (tail)\nFull output: gt mq status %s\n\n```\n%s\n```", mr.ID, tail)
+}
+
+// ReadGateOutput returns the captured output of an artifact, without its
+// header line.
+func ReadGateOutput(a GateArtifact) (string, error) {
+	data, err := os.ReadFile(a.Path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return "", err
+	}
+	out := string(data)
+	if strings.HasPrefix(out, "# ") {
+		if idx := strings.IndexByte(out, '\n'); idx != -1 {
+			out = out[idx+1:]
+		} else {
+			out = ""
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package refinery
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestGateArtifacts_RoundTrip(t *testing.T) {
+	rigPath := t.TempDir()
+	gates := []GateResult{
+		{Name: "lint", Success: true, Elapsed: 1500 * time.Millisecond, Output: "ok\n"},
+		{Name: "test", Success: false, Elapsed: 2 * time.Second, Output: "--- FAIL: TestX\nFAIL\n"},
+	}
+	if _, err := WriteGateArtifacts(rigPath, "gt-mr1", gates); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := ListGateArtifacts(rigPath, "gt-mr1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 2 || artifacts[0].Gate != "lint" || artifacts[1].Gate != "test" {
+		t.Fatalf("artifacts = %+v", artifacts)
+	}
+	if artifacts[0].Failed || !artifacts[1].Failed {
+		t.Errorf("failed flags = %v, %v; want false, true", artifacts[0].Failed, artifacts[1].Failed)
+	}
+	if artifacts[1].Elapsed != 2*time.Second {
+		t.Errorf("elapsed = %v, want 2s", artifacts[1].Elapsed)
+	}
+	out, err := ReadGateOutput(artifacts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "--- FAIL: TestX\nFAIL\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	// A later run replaces the earlier artifacts.
+	if _, err := WriteGateArtifacts(rigPath, "gt-mr1", gates[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if artifacts, _ := ListGateArtifacts(rigPath, "gt-mr1"); len(artifacts) != 1 {
+		t.Errorf("after rewrite: %d artifacts, want 1", len(artifacts))
+	}
+	if artifacts, err := ListGateArtifacts(rigPath, "gt-none"); err != nil || artifacts != nil {
+		t.Errorf("missing MR: %v, %v", artifacts, err)
+	}
+}
+
+func TestGateArtifactPath_Sanitizes(t *testing.T) {
+	path := GateArtifactPath("/rig", "../../etc", "a/b")
+	want := filepath.Join("/rig", ".runtime", "refinery", "gate-output")
+	if filepath.Dir(filepath.Dir(path)) != want {
+		t.Errorf("path %q escapes %s", path, want)
+	}
+	if got := GateArtifactDir("/rig", ".."); filepath.Dir(got) != want {
+		t.Errorf("dir %q escapes %s", got, want)
+	}
+}
+
+func TestFailedGateTail(t *testing.T) {
+	var lines []string
+	for i := 1; i <= GateOutputTailLines+10; i++ {
+		lines = append(lines, "line")
+	}
+	lines[len(lines)-1] = "last"
+	gates := []GateResult{
+		{Name: "ok", Success: true, Output: "fine"},
+		{Name: "test", Output: strings.Join(lines, "\n") + "\n"},
+	}
+	tail := FailedGateTail(gates)
+	if !strings.HasPrefix(tail, "[test]\n") || !strings.HasSuffix(tail, "last") {
+		t.Errorf("tail = %q", tail)
+	}
+	if got := strings.Count(tail, "\n"); got != GateOutputTailLines {
+		t.Errorf("tail has %d lines after header, want %d", got, GateOutputTailLines)
+	}
+}
+
+func TestTailBuffer_KeepsEnd(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	_, _ = b.Write([]byte("0123456789"))
+	_, _ = b.Write([]byte("ab"))
+	if got := b.String(); got != "... (4 bytes of earlier output truncated)\n456789ab" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestRunGate_CapturesOutput(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	e.workDir = t.TempDir()
+
+	result := e.runGate(context.Background(), "noisy", &GateConfig{Cmd: "echo out; echo err >&2; exit 3"})
+	if result.Success {
+		t.Fatal("expected failure")
+	}
+	if !strings.Contains(result.Output, "out") || !strings.Contains(result.Output, "err") {
+		t.Errorf("Output = %q, want stdout and stderr", result.Output)
+	}
+	if !strings.Contains(result.Error, "err") {
+		t.Errorf("Error = %q, want stderr", result.Error)
+	}
+}
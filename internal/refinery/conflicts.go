@@ -0,0 +1,252 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// conflictProbeBranch is the scratch branch used to test-merge MR pairs.
+const conflictProbeBranch = "refinery/conflict-probe"
+
+// ConflictPrediction records, for the ready MRs, the files each changes
+// against its target and the MR pairs predicted to conflict. Pairs are only
+// test-merged (git.CheckConflicts) when their changed files overlap.
+type ConflictPrediction struct {
+	ComputedAt time.Time           `json:"computed_at"`
+	Files      map[string][]string `json:"files"`
+	Conflicts  map[string][]string `json:"conflicts"`
+}
+
+// ConflictPredictionPath returns the rig's conflict prediction file.
+func ConflictPredictionPath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, constants.DirRefinery, "conflicts.json")
+}
+
+// LoadConflictPrediction loads the rig's last conflict prediction. A missing
+// file yields an empty prediction.
+func LoadConflictPrediction(rigPath string) (*ConflictPrediction, error) {
+	p := &ConflictPrediction{}
+	data, err := os.ReadFile(ConflictPredictionPath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("parsing conflict prediction: %w", err)
+		}
+	}
+	if p.Files == nil {
+		p.Files = make(map[string][]string)
+	}
+	if p.Conflicts == nil {
+		p.Conflicts = make(map[string][]string)
+	}
+	return p, nil
+}
+
+// Save writes the prediction atomically.
+func (p *ConflictPrediction) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(ConflictPredictionPath(rigPath), p)
+}
+
+// ConflictsWith reports whether MRs a and b are predicted to conflict.
+func (p *ConflictPrediction) ConflictsWith(a, b string) bool {
+	if p == nil {
+		return false
+	}
+	for _, id := range p.Conflicts[a] {
+		if id == b {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ConflictPrediction) addConflict(a, b string) {
+	p.Conflicts[a] = append(p.Conflicts[a], b)
+	p.Conflicts[b] = append(p.Conflicts[b], a)
+}
+
+// overlappingFiles returns the files in both a and b, which must be sorted.
+func overlappingFiles(a, b []string) []string {
+	var both []string
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			both = append(both, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return both
+}
+
+// PredictConflicts computes each MR's changed files against its target and
+// test-merges every same-target pair whose files overlap. The prediction is
+// saved for gt mq list and used by SelectTrain until the next call. The
+// refinery worktree is left on the last MR's target.
+func (e *Engineer) PredictConflicts(mrs []*MRInfo) *ConflictPrediction {
+	p := &ConflictPrediction{
+		ComputedAt: time.Now(),
+		Files:      make(map[string][]string),
+		Conflicts:  make(map[string][]string),
+	}
+	for _, mr := range mrs {
+		files, err := e.git.ChangedFiles(mr.Target, mr.Branch)
+		if err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not list files changed by %s: %v\n", mr.ID, err)
+			continue
+		}
+		sort.Strings(files)
+		p.Files[mr.ID] = files
+	}
+
+	for i, a := range mrs {
+		for _, b := range mrs[i+1:] {
+			if a.Target != b.Target || len(overlappingFiles(p.Files[a.ID], p.Files[b.ID])) == 0 {
+				continue
+			}
+			if e.pairConflicts(a, b) {
+				p.addConflict(a.ID, b.ID)
+			}
+		}
+	}
+	if len(mrs) > 0 {
+		_ = e.git.Checkout(mrs[len(mrs)-1].Target)
+	}
+	_ = e.git.DeleteBranch(conflictProbeBranch, true)
+
+	e.conflicts = p
+	if err := p.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save conflict prediction: %v\n", err)
+	}
+	return p
+}
+
+// pairConflicts test-merges b onto a's branch.
+func (e *Engineer) pairConflicts(a, b *MRInfo) bool {
+	_ = e.git.Checkout(a.Target)
+	_ = e.git.DeleteBranch(conflictProbeBranch, true)
+	if err := e.git.CreateBranchFrom(conflictProbeBranch, a.Branch); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not probe %s against %s: %v\n", a.ID, b.ID, err)
+		return false
+	}
+	conflicts, err := e.git.CheckConflicts(b.Branch, conflictProbeBranch)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not probe %s against %s: %v\n", a.ID, b.ID, err)
+		return false
+	}
+	return len(conflicts) > 0
+}
+
+// OrderForConflicts returns mrs by descending score under config, reordered
+// by SpaceConflicts.
+func OrderForConflicts(mrs []*MRInfo, p *ConflictPrediction, config ScoreConfig, now time.Time) []*MRInfo {
+	sorted := make([]*MRInfo, len(mrs))
+	copy(sorted, mrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ScoreWith(config, now) > sorted[j].ScoreWith(config, now)
+	})
+	ids := make([]string, len(sorted))
+	for i, mr := range sorted {
+		ids[i] = mr.ID
+	}
+	ordered := make([]*MRInfo, 0, len(sorted))
+	for _, idx := range p.SpaceConflicts(ids) {
+		ordered = append(ordered, sorted[idx])
+	}
+	return ordered
+}
+
+// SpaceConflicts reorders ids, given in queue order, so that no MR directly
+// follows one it is predicted to conflict with while a non-conflicting MR is
+// available to go in between. It returns the new order as indexes into ids.
+func (p *ConflictPrediction) SpaceConflicts(ids []string) []int {
+	remaining := make([]int, len(ids))
+	for i := range ids {
+		remaining[i] = i
+	}
+	order := make([]int, 0, len(ids))
+	for len(remaining) > 0 {
+		pick := 0
+		if n := len(order); n > 0 {
+			for i, idx := range remaining {
+				if !p.ConflictsWith(ids[order[n-1]], ids[idx]) {
+					pick = i
+					break
+				}
+			}
+		}
+		order = append(order, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return order
+}
+
+// SetConflictPrediction makes the Engineer use p (e.g. a saved prediction)
+// until the next PredictConflicts.
+func (e *Engineer) SetConflictPrediction(p *ConflictPrediction) {
+	e.conflicts = p
+}
+
+// notifyConflictingMRs asks the polecats of open MRs that touch the same
+// files as landed, which went ahead of them in the queue, to rebase now
+// rather than finding out when their turn comes. Each candidate is
+// test-merged against the new target head first.
+func (e *Engineer) notifyConflictingMRs(landed *MRInfo) {
+	p := e.conflicts
+	if p == nil {
+		var err error
+		if p, err = LoadConflictPrediction(e.rig.Path); err != nil {
+			return
+		}
+	}
+	files := p.Files[landed.ID]
+	if len(files) == 0 || e.beads == nil {
+		return
+	}
+	var ids []string
+	for id, other := range p.Files {
+		if id != landed.ID && len(overlappingFiles(files, other)) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		issue, err := e.beads.Show(id)
+		if err != nil || issue.Status != "open" {
+			continue
+		}
+		fields := beads.ParseMRFields(issue)
+		if fields == nil || fields.Target != landed.Target || fields.Worker == "" {
+			continue
+		}
+		conflicts, err := e.git.CheckConflicts(fields.Branch, landed.Target)
+		if err != nil || len(conflicts) == 0 {
+			continue
+		}
+		msg := protocol.NewReworkRequestMessage(e.rig.Name, fields.Worker, fields.Branch, fields.SourceIssue, landed.Target, conflicts)
+		if e.router == nil {
+			continue
+		}
+		if err := e.router.Send(msg); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send REWORK_REQUEST for %s: %v\n", id, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Asked %s to rebase %s: %s landed touching %v\n", fields.Worker, id, landed.ID, conflicts)
+	}
+}
@@ -0,0 +1,87 @@
+package refinery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverlappingFiles(t *testing.T) {
+	got := overlappingFiles([]string{"a.go", "b.go", "d.go"}, []string{"b.go", "c.go", "d.go"})
+	if !reflect.DeepEqual(got, []string{"b.go", "d.go"}) {
+		t.Errorf("overlappingFiles = %v, want [b.go d.go]", got)
+	}
+	if got := overlappingFiles([]string{"a.go"}, nil); got != nil {
+		t.Errorf("overlappingFiles with empty side = %v", got)
+	}
+}
+
+func TestSpaceConflicts(t *testing.T) {
+	p := &ConflictPrediction{Conflicts: map[string][]string{}}
+	p.addConflict("a", "b")
+
+	if got := p.SpaceConflicts([]string{"a", "b", "c"}); !reflect.DeepEqual(got, []int{0, 2, 1}) {
+		t.Errorf("SpaceConflicts = %v, want [0 2 1]", got)
+	}
+	// With nothing to put in between, queue order wins.
+	if got := p.SpaceConflicts([]string{"a", "b"}); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("SpaceConflicts = %v, want [0 1]", got)
+	}
+	var none *ConflictPrediction
+	if got := none.SpaceConflicts([]string{"x", "y"}); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("nil prediction SpaceConflicts = %v, want [0 1]", got)
+	}
+}
+
+func TestOrderForConflicts(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mrs := []*MRInfo{
+		{ID: "p2", Priority: 2, CreatedAt: now},
+		{ID: "p0", Priority: 0, CreatedAt: now},
+		{ID: "p1", Priority: 1, CreatedAt: now},
+	}
+	p := &ConflictPrediction{Conflicts: map[string][]string{}}
+	p.addConflict("p0", "p1")
+
+	var ids []string
+	for _, mr := range OrderForConflicts(mrs, p, DefaultScoreConfig(), now) {
+		ids = append(ids, mr.ID)
+	}
+	if want := []string{"p0", "p2", "p1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("OrderForConflicts = %v, want %v", ids, want)
+	}
+}
+
+func TestPredictConflicts(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	a := addMRBranch(t, work, "a", "README", "from a\n")
+	b := addMRBranch(t, work, "b", "README", "from b\n")
+	c := addMRBranch(t, work, "c", "other.txt", "c\n")
+
+	p := e.PredictConflicts([]*MRInfo{a, b, c})
+	if !p.ConflictsWith(a.ID, b.ID) || !p.ConflictsWith(b.ID, a.ID) {
+		t.Errorf("expected a and b to conflict: %v", p.Conflicts)
+	}
+	if p.ConflictsWith(a.ID, c.ID) || len(p.Conflicts[c.ID]) != 0 {
+		t.Errorf("c touches no shared files: %v", p.Conflicts)
+	}
+	if !reflect.DeepEqual(p.Files[c.ID], []string{"other.txt"}) {
+		t.Errorf("files for c = %v", p.Files[c.ID])
+	}
+
+	if branch := strings.TrimSpace(runGit(t, work, "rev-parse", "--abbrev-ref", "HEAD")); branch != "main" {
+		t.Errorf("work tree left on %q, want main", branch)
+	}
+	if out := runGit(t, work, "branch", "--list", conflictProbeBranch); strings.TrimSpace(out) != "" {
+		t.Error("probe branch should be deleted")
+	}
+
+	saved, err := LoadConflictPrediction(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.ConflictsWith(a.ID, b.ID) {
+		t.Error("prediction should be saved")
+	}
+}
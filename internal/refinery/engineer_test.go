@@ -316,6 +316,226 @@ func TestEngineer_LoadConfig_WithGates(t *testing.T) {
 	}
 }
 
+func TestEngineer_LoadConfig_GateReports(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"merge_queue": map[string]interface{}{
+			"gates": map[string]interface{}{
+				"test": map[string]interface{}{
+					"cmd":    "go test -json ./...",
+					"report": "go-test-json",
+				},
+			},
+			"flake_quarantine_threshold": 5,
+		},
+	}
+
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	if e.config.Gates["test"].Report != ReportGoTestJSON {
+		t.Errorf("expected test gate report %q, got %q", ReportGoTestJSON, e.config.Gates["test"].Report)
+	}
+	if e.config.FlakeQuarantineThreshold != 5 {
+		t.Errorf("expected flake_quarantine_threshold 5, got %d", e.config.FlakeQuarantineThreshold)
+	}
+}
+
+func TestEngineer_LoadConfig_GateCacheAndPostMergeGates(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"merge_queue": map[string]interface{}{
+			"gate_cache_ttl": "2h",
+			"post_merge_gates": map[string]interface{}{
+				"smoke": map[string]interface{}{"cmd": "make smoke", "timeout": "1m"},
+			},
+		},
+	}
+
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+
+	if e.config.GateCacheTTL != 2*time.Hour {
+		t.Errorf("expected gate_cache_ttl 2h, got %v", e.config.GateCacheTTL)
+	}
+	if pm := e.config.PostMergeGates["smoke"]; pm == nil || pm.Cmd != "make smoke" || pm.Timeout != time.Minute {
+		t.Errorf("expected post-merge gate smoke, got %+v", pm)
+	}
+}
+
+func TestEngineer_LoadConfig_Scoring(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := map[string]interface{}{
+		"merge_queue": map[string]interface{}{
+			"scoring": map[string]interface{}{
+				"convoy_age_weight": 25,
+				"label_boosts":      map[string]interface{}{"hotfix": 500},
+				"role_weights":      map[string]interface{}{"crew": 50},
+				"diff_size_weight":  10,
+			},
+		},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	sc := e.config.Scoring
+	if sc.ConvoyAgeWeight != 25 || sc.DiffSizeWeight != 10 {
+		t.Errorf("expected convoy_age_weight 25 and diff_size_weight 10, got %+v", sc)
+	}
+	if sc.LabelBoosts["hotfix"] != 500 || sc.RoleWeights["crew"] != 50 {
+		t.Errorf("expected label and role weights, got %+v", sc)
+	}
+	if sc.PriorityWeight != DefaultScoreConfig().PriorityWeight {
+		t.Errorf("expected unset priority_weight to keep default, got %v", sc.PriorityWeight)
+	}
+
+	config["merge_queue"] = map[string]interface{}{
+		"scoring": map[string]interface{}{"retry_penalty": -1},
+	}
+	data, _ = json.MarshalIndent(config, "", "  ")
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir}).LoadConfig(); err == nil {
+		t.Error("expected error for negative retry_penalty")
+	}
+}
+
+func TestEngineer_LoadConfig_Approvals(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(approvals interface{}) {
+		t.Helper()
+		data, _ := json.Marshal(map[string]interface{}{
+			"merge_queue": map[string]interface{}{"approvals": approvals},
+		})
+		if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(map[string]interface{}{"migrations/": []string{"alice", "bob"}})
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if got := e.config.Approvals["migrations/"]; len(got) != 2 || got[0] != "alice" {
+		t.Errorf("expected migrations/ approvers [alice bob], got %v", got)
+	}
+
+	write(map[string]interface{}{"infra/": []string{}})
+	if err := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir}).LoadConfig(); err == nil {
+		t.Error("expected error for approvals pattern without approvers")
+	}
+}
+
+func TestEngineer_LoadConfig_Scan(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(scan interface{}) {
+		t.Helper()
+		data, _ := json.Marshal(map[string]interface{}{
+			"merge_queue": map[string]interface{}{"scan": scan},
+		})
+		if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if e.config.Scan != nil {
+		t.Error("scan should be disabled without merge_queue.scan")
+	}
+
+	write(map[string]interface{}{"max_diff_lines": 500, "forbidden_paths": []string{"dist/"}})
+	e = NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if e.config.Scan == nil {
+		t.Fatal("scan should be enabled")
+	}
+	if e.config.Scan.MaxDiffLines != 500 || len(e.config.Scan.ForbiddenPaths) != 1 {
+		t.Errorf("scan = %+v, want max_diff_lines 500 and forbidden_paths [dist/]", e.config.Scan)
+	}
+	if !e.config.Scan.Secrets || e.config.Scan.MaxBinaryBytes != DefaultScanConfig().MaxBinaryBytes {
+		t.Errorf("unset scan fields should keep defaults, got %+v", e.config.Scan)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"max_files": -1},
+		{"secret_entropy": -2},
+		{"forbidden_paths": []string{"dist/["}},
+	} {
+		write(bad)
+		if err := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir}).LoadConfig(); err == nil {
+			t.Errorf("expected error for scan config %v", bad)
+		}
+	}
+}
+
+func TestEngineer_LoadConfig_MergeMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(mq map[string]interface{}) {
+		t.Helper()
+		data, _ := json.Marshal(map[string]interface{}{"merge_queue": mq})
+		if err := os.WriteFile(filepath.Join(tmpDir, "config.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir})
+	if e.config.MergeMode != MergeModeDirect {
+		t.Errorf("default merge_mode = %q, want %q", e.config.MergeMode, MergeModeDirect)
+	}
+
+	write(map[string]interface{}{
+		"merge_mode": "pull_request",
+		"forge":      map[string]interface{}{"type": "gitlab", "repo": "group/project", "merge_method": "rebase"},
+	})
+	if err := e.LoadConfig(); err != nil {
+		t.Fatalf("unexpected error loading config: %v", err)
+	}
+	if e.config.MergeMode != MergeModePullRequest {
+		t.Errorf("merge_mode = %q, want %q", e.config.MergeMode, MergeModePullRequest)
+	}
+	if e.config.Forge.Type != "gitlab" || e.config.Forge.Repo != "group/project" || e.config.Forge.MergeMethod != "rebase" {
+		t.Errorf("forge = %+v", e.config.Forge)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"merge_mode": "pr"},
+		{"forge": map[string]interface{}{"type": "bitbucket"}},
+		{"forge": map[string]interface{}{"merge_method": "fast-forward"}},
+	} {
+		write(bad)
+		if err := NewEngineer(&rig.Rig{Name: "test-rig", Path: tmpDir}).LoadConfig(); err == nil {
+			t.Errorf("expected error for merge_queue %v", bad)
+		}
+	}
+}
+
 func TestEngineer_LoadConfig_GateInvalidTimeout(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "engineer-gates-test-*")
 	if err != nil {
@@ -621,6 +841,12 @@ func TestNotifyDeaconConvoyFeeding_AttemptsWhenConvoyID(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
+	// Sending mail logs an event to the town of the working directory.
+	if err := os.MkdirAll(filepath.Join(tmpDir, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(tmpDir)
+
 	rigDir := filepath.Join(tmpDir, "testrig")
 	if err := os.MkdirAll(rigDir, 0755); err != nil {
 		t.Fatal(err)
@@ -0,0 +1,215 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DefaultFlakeQuarantineThreshold is how many recorded flakes quarantine a
+// test unless MergeQueueConfig.FlakeQuarantineThreshold overrides it.
+const DefaultFlakeQuarantineThreshold = 3
+
+// FlakeRecord is the flake history of one test, keyed "<gate>/<test>".
+type FlakeRecord struct {
+	Test          string     `json:"test"`
+	Gate          string     `json:"gate"`
+	Flakes        int        `json:"flakes"`
+	FirstFlake    time.Time  `json:"first_flake"`
+	LastFlake     time.Time  `json:"last_flake"`
+	Quarantined   bool       `json:"quarantined,omitempty"`
+	QuarantinedAt *time.Time `json:"quarantined_at,omitempty"`
+	Bead          string     `json:"bead,omitempty"` // Bead reporting the quarantine to the witness
+}
+
+// FlakeHistory is a rig's record of tests that failed and then passed on
+// retry within one gate run.
+type FlakeHistory struct {
+	Version int                     `json:"version"`
+	Tests   map[string]*FlakeRecord `json:"tests"`
+}
+
+// FlakeHistoryPath returns the rig's flake history file.
+func FlakeHistoryPath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, constants.DirRefinery, "flakes.json")
+}
+
+func flakeKey(gate, test string) string {
+	return gate + "/" + test
+}
+
+// LoadFlakeHistory loads the rig's flake history. A missing file yields an
+// empty history.
+func LoadFlakeHistory(rigPath string) (*FlakeHistory, error) {
+	h := &FlakeHistory{Version: 1, Tests: make(map[string]*FlakeRecord)}
+	data, err := os.ReadFile(FlakeHistoryPath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("parsing flake history: %w", err)
+	}
+	if h.Tests == nil {
+		h.Tests = make(map[string]*FlakeRecord)
+	}
+	return h, nil
+}
+
+// Save writes the flake history atomically.
+func (h *FlakeHistory) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(FlakeHistoryPath(rigPath), h)
+}
+
+// Record counts one flake of test in gate. It quarantines the test when its
+// flake count reaches threshold (0 disables quarantine) and reports whether
+// this flake newly quarantined it.
+func (h *FlakeHistory) Record(gate, test string, threshold int, now time.Time) (*FlakeRecord, bool) {
+	key := flakeKey(gate, test)
+	rec := h.Tests[key]
+	if rec == nil {
+		rec = &FlakeRecord{Test: test, Gate: gate, FirstFlake: now}
+		h.Tests[key] = rec
+	}
+	rec.Flakes++
+	rec.LastFlake = now
+	if threshold > 0 && !rec.Quarantined && rec.Flakes >= threshold {
+		rec.Quarantined = true
+		rec.QuarantinedAt = &now
+		return rec, true
+	}
+	return rec, false
+}
+
+// Quarantined reports whether test in gate is quarantined.
+func (h *FlakeHistory) Quarantined(gate, test string) bool {
+	rec := h.Tests[flakeKey(gate, test)]
+	return rec != nil && rec.Quarantined
+}
+
+// Reset removes a test's history ("<gate>/<test>"), lifting any quarantine.
+// It reports whether the test had a record.
+func (h *FlakeHistory) Reset(key string) bool {
+	if _, ok := h.Tests[key]; !ok {
+		return false
+	}
+	delete(h.Tests, key)
+	return true
+}
+
+// Sorted returns the records with the most flakes first.
+func (h *FlakeHistory) Sorted() []*FlakeRecord {
+	records := make([]*FlakeRecord, 0, len(h.Tests))
+	for _, rec := range h.Tests {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Flakes != records[j].Flakes {
+			return records[i].Flakes > records[j].Flakes
+		}
+		return flakeKey(records[i].Gate, records[i].Test) < flakeKey(records[j].Gate, records[j].Test)
+	})
+	return records
+}
+
+// quarantineThreshold returns the configured flake quarantine threshold.
+func (c *MergeQueueConfig) quarantineThreshold() int {
+	if c.FlakeQuarantineThreshold < 0 {
+		return 0
+	}
+	return c.FlakeQuarantineThreshold
+}
+
+// recordFlakes adds the flakes seen in gate results to the rig's history,
+// and reports tests that became quarantined to the witness.
+func (e *Engineer) recordFlakes(results []GateResult) {
+	var any bool
+	for _, r := range results {
+		if len(r.Flakes) > 0 {
+			any = true
+			break
+		}
+	}
+	if !any {
+		return
+	}
+
+	history, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to load flake history: %v\n", err)
+		return
+	}
+	now := time.Now()
+	for _, r := range results {
+		for _, test := range r.Flakes {
+			rec, quarantined := history.Record(r.Name, test, e.config.quarantineThreshold(), now)
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: flaky test %s (%d flake(s))\n", r.Name, test, rec.Flakes)
+			if quarantined {
+				rec.Bead = e.reportQuarantine(rec)
+			}
+		}
+	}
+	if err := history.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save flake history: %v\n", err)
+	}
+}
+
+// reportQuarantine files a bead for a newly quarantined test and notifies
+// the witness. It returns the bead ID, or "" if the bead was not created.
+func (e *Engineer) reportQuarantine(rec *FlakeRecord) string {
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Quarantined flaky test %s in gate %q after %d flakes\n", rec.Test, rec.Gate, rec.Flakes)
+	if e.beads == nil {
+		return ""
+	}
+
+	description := fmt.Sprintf(`Test %s in quality gate %q failed and then passed on retry %d times (first %s, last %s).
+
+The refinery has quarantined it: failures of this test alone no longer block merges in %s.
+
+## Metadata
+- Gate: %s
+- Test: %s
+- Flakes: %d
+
+Fix the flake, then lift the quarantine: gt mq flakes %s --reset %q`,
+		rec.Test, rec.Gate, rec.Flakes,
+		rec.FirstFlake.Format(time.RFC3339), rec.LastFlake.Format(time.RFC3339),
+		e.rig.Name, rec.Gate, rec.Test, rec.Flakes,
+		e.rig.Name, flakeKey(rec.Gate, rec.Test))
+
+	issue, err := e.beads.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Flaky test quarantined: %s", rec.Test),
+		Type:        "bug",
+		Priority:    2,
+		Description: description,
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create flaky test bead: %v\n", err)
+		return ""
+	}
+
+	if e.router != nil {
+		msg := mail.NewMessage(
+			e.rig.Name+"/refinery",
+			e.rig.Name+"/witness",
+			fmt.Sprintf("FLAKY_TEST_QUARANTINED %s", rec.Test),
+			fmt.Sprintf("Gate: %s\nTest: %s\nFlakes: %d\nBead: %s\n", rec.Gate, rec.Test, rec.Flakes, issue.ID),
+		)
+		msg.Type = mail.TypeNotification
+		if err := e.router.Send(msg); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to notify witness of quarantine: %v\n", err)
+		}
+	}
+	return issue.ID
+}
@@ -0,0 +1,95 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestFlakeHistory_RecordQuarantines(t *testing.T) {
+	h := &FlakeHistory{Tests: make(map[string]*FlakeRecord)}
+	now := time.Now()
+	for i := 1; i <= 2; i++ {
+		if _, q := h.Record("test", "TestX", 3, now); q {
+			t.Fatalf("quarantined after %d flakes", i)
+		}
+	}
+	rec, q := h.Record("test", "TestX", 3, now)
+	if !q || !rec.Quarantined || rec.Flakes != 3 {
+		t.Fatalf("third flake: %+v, quarantined=%v", rec, q)
+	}
+	if _, q := h.Record("test", "TestX", 3, now); q {
+		t.Error("already-quarantined test reported as newly quarantined")
+	}
+	if !h.Quarantined("test", "TestX") || h.Quarantined("lint", "TestX") {
+		t.Error("quarantine should be per gate and test")
+	}
+	if !h.Reset("test/TestX") || h.Quarantined("test", "TestX") {
+		t.Error("Reset should lift the quarantine")
+	}
+
+	if _, q := (&FlakeHistory{Tests: map[string]*FlakeRecord{}}).Record("g", "T", 0, now); q {
+		t.Error("threshold 0 should disable quarantine")
+	}
+}
+
+// flakyGateEngineer returns an Engineer whose work dir counts gate attempts
+// in a file, for gates that fail on their first attempt only.
+func flakyGateEngineer(t *testing.T) *Engineer {
+	t.Helper()
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	e.workDir = t.TempDir()
+	e.output = io.Discard
+	e.config.RetryFlakyTests = 2
+	return e
+}
+
+const flakyTAPGate = `if [ -f attempted ]; then echo "ok 1 - TestFlaky"; else touch attempted; echo "not ok 1 - TestFlaky"; exit 1; fi`
+
+func TestRunGate_RecordsFlakes(t *testing.T) {
+	e := flakyGateEngineer(t)
+	e.config.Gates = map[string]*GateConfig{"test": {Cmd: flakyTAPGate, Report: ReportTAP}}
+
+	result := e.runGates(context.Background())
+	if !result.Success {
+		t.Fatalf("gates failed: %s", result.Error)
+	}
+	if got := result.Gates[0].Flakes; !reflect.DeepEqual(got, []string{"TestFlaky"}) {
+		t.Errorf("Flakes = %v, want [TestFlaky]", got)
+	}
+
+	history, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := history.Tests["test/TestFlaky"]; rec == nil || rec.Flakes != 1 || rec.Quarantined {
+		t.Errorf("history record = %+v, want 1 flake", rec)
+	}
+}
+
+func TestRunGate_QuarantinedFailuresDoNotBlock(t *testing.T) {
+	e := flakyGateEngineer(t)
+	history := &FlakeHistory{Version: 1, Tests: make(map[string]*FlakeRecord)}
+	history.Record("test", "TestFlaky", 1, time.Now())
+	if err := history.Save(e.rig.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	quarantined := e.runGate(context.Background(), "test", &GateConfig{
+		Cmd: `echo '<testsuite><testcase name="TestFlaky"><failure/></testcase></testsuite>' > report.xml; exit 1`, Report: ReportJUnit, ReportFile: "report.xml",
+	})
+	if !quarantined.Success || !reflect.DeepEqual(quarantined.Quarantined, []string{"TestFlaky"}) {
+		t.Errorf("quarantined-only failure: %+v", quarantined)
+	}
+
+	mixed := e.runGate(context.Background(), "test", &GateConfig{
+		Cmd: `printf 'not ok 1 - TestFlaky\nnot ok 2 - TestReal\n'; exit 1`, Report: ReportTAP,
+	})
+	if mixed.Success || !reflect.DeepEqual(mixed.FailedTests, []string{"TestFlaky", "TestReal"}) {
+		t.Errorf("mixed failure: %+v", mixed)
+	}
+}
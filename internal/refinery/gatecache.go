@@ -0,0 +1,226 @@
+package refinery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// DefaultGateCacheTTL is how long a passing gate result is reused for the
+// same tree unless MergeQueueConfig.GateCacheTTL overrides it.
+const DefaultGateCacheTTL = 24 * time.Hour
+
+// GateCacheEntry is a passing gate run recorded for one tree.
+type GateCacheEntry struct {
+	Gate       string        `json:"gate"`
+	Cmd        string        `json:"cmd"`
+	Tree       string        `json:"tree"`
+	Elapsed    time.Duration `json:"elapsed"`
+	RecordedAt time.Time     `json:"recorded_at"`
+}
+
+// GateCache records passing gate results keyed by (gate name, gate command,
+// tree SHA), so a gate isn't rerun against a tree it already passed on.
+// Only passes are cached: failures are always re-verified.
+type GateCache struct {
+	Version int                        `json:"version"`
+	Entries map[string]*GateCacheEntry `json:"entries"`
+
+	// Bypass holds MR IDs whose next run must ignore the cache, mapped to
+	// when the bypass was requested (gt mq retry --no-cache).
+	Bypass map[string]time.Time `json:"bypass,omitempty"`
+}
+
+// GateCachePath returns the rig's gate result cache file.
+func GateCachePath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, constants.DirRefinery, "gate-cache.json")
+}
+
+func gateCacheKey(gate, cmd, tree string) string {
+	sum := sha256.Sum256([]byte(gate + "\x00" + cmd + "\x00" + tree))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadGateCache loads the rig's gate result cache. A missing file yields an
+// empty cache.
+func LoadGateCache(rigPath string) (*GateCache, error) {
+	c := &GateCache{Version: 1, Entries: make(map[string]*GateCacheEntry)}
+	data, err := os.ReadFile(GateCachePath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("parsing gate cache: %w", err)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]*GateCacheEntry)
+	}
+	return c, nil
+}
+
+// Save writes the gate cache atomically.
+func (c *GateCache) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(GateCachePath(rigPath), c)
+}
+
+// Lookup returns the entry for gate and cmd on tree if one was recorded
+// within ttl of now.
+func (c *GateCache) Lookup(gate, cmd, tree string, ttl time.Duration, now time.Time) (*GateCacheEntry, bool) {
+	entry := c.Entries[gateCacheKey(gate, cmd, tree)]
+	if entry == nil || now.Sub(entry.RecordedAt) > ttl {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Record stores a passing run of gate and cmd on tree.
+func (c *GateCache) Record(gate, cmd, tree string, elapsed time.Duration, now time.Time) {
+	c.Entries[gateCacheKey(gate, cmd, tree)] = &GateCacheEntry{
+		Gate:       gate,
+		Cmd:        cmd,
+		Tree:       tree,
+		Elapsed:    elapsed,
+		RecordedAt: now,
+	}
+}
+
+// Prune drops entries older than ttl and reports how many were removed.
+func (c *GateCache) Prune(ttl time.Duration, now time.Time) int {
+	removed := 0
+	for key, entry := range c.Entries {
+		if now.Sub(entry.RecordedAt) > ttl {
+			delete(c.Entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// RequestGateCacheBypass marks mrID so that its next refinery run reruns
+// every gate instead of reusing cached results.
+func RequestGateCacheBypass(rigPath, mrID string) error {
+	c, err := LoadGateCache(rigPath)
+	if err != nil {
+		return err
+	}
+	if c.Bypass == nil {
+		c.Bypass = make(map[string]time.Time)
+	}
+	c.Bypass[mrID] = time.Now()
+	return c.Save(rigPath)
+}
+
+// consumeGateCacheBypass reports whether a cache bypass was requested for
+// any of mrs, clearing the requests it finds.
+func (e *Engineer) consumeGateCacheBypass(mrs ...*MRInfo) bool {
+	if e.config.GateCacheTTL <= 0 {
+		return false
+	}
+	c, err := LoadGateCache(e.rig.Path)
+	if err != nil || len(c.Bypass) == 0 {
+		return false
+	}
+	found := false
+	for _, mr := range mrs {
+		if _, ok := c.Bypass[mr.ID]; ok {
+			delete(c.Bypass, mr.ID)
+			found = true
+		}
+	}
+	if !found {
+		return false
+	}
+	if err := c.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save gate cache: %v\n", err)
+	}
+	return true
+}
+
+// cachedGates returns cached results for the gates in names that already
+// passed on the tree under test: e.gateTree's, else the checked-out tree.
+// It returns the tree SHA (or "" when the cache is off or the tree can't be
+// resolved) for recording new results.
+func (e *Engineer) cachedGates(gates map[string]*GateConfig, names []string) (map[string]GateResult, string) {
+	if e.config.GateCacheTTL <= 0 {
+		return nil, ""
+	}
+	resolve := e.gateTree
+	if resolve == nil {
+		resolve = func() (string, error) { return e.git.Rev("HEAD^{tree}") }
+	}
+	tree, err := resolve()
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: gate cache disabled, could not resolve tree: %v\n", err)
+		return nil, ""
+	}
+	if e.bypassGateCache {
+		_, _ = fmt.Fprintln(e.output, "[Engineer] Gate cache bypassed for this run")
+		return nil, tree
+	}
+	c, err := LoadGateCache(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to load gate cache: %v\n", err)
+		return nil, tree
+	}
+
+	now := time.Now()
+	hits := make(map[string]GateResult)
+	for _, name := range names {
+		gate := gates[name]
+		entry, ok := c.Lookup(name, gate.Cmd, tree, e.config.GateCacheTTL, now)
+		if !ok {
+			continue
+		}
+		hits[name] = GateResult{
+			Name:    name,
+			Success: true,
+			Cached:  true,
+			Output: fmt.Sprintf("(cached: passed on tree %s at %s in %v)\n",
+				shortSHA(tree), entry.RecordedAt.Format(time.RFC3339), entry.Elapsed.Truncate(time.Millisecond)),
+		}
+	}
+	return hits, tree
+}
+
+// recordGateCache stores the passing, freshly run gates in results as
+// results for tree. Passes that relied on quarantined tests aren't cached,
+// since lifting the quarantine would change the outcome.
+func (e *Engineer) recordGateCache(gates map[string]*GateConfig, tree string, results []GateResult) {
+	if tree == "" {
+		return
+	}
+	c, err := LoadGateCache(e.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to load gate cache: %v\n", err)
+		return
+	}
+	now := time.Now()
+	for _, r := range results {
+		gate := gates[r.Name]
+		if gate == nil || r.Cached || !r.Success || len(r.Quarantined) > 0 {
+			continue
+		}
+		c.Record(r.Name, gate.Cmd, tree, r.Elapsed, now)
+	}
+	c.Prune(e.config.GateCacheTTL, now)
+	if err := c.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save gate cache: %v\n", err)
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,140 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGateCache_LookupHonorsTTL(t *testing.T) {
+	c := &GateCache{Entries: make(map[string]*GateCacheEntry)}
+	now := time.Now()
+	c.Record("test", "go test ./...", "tree1", time.Second, now.Add(-2*time.Hour))
+
+	if _, ok := c.Lookup("test", "go test ./...", "tree1", 3*time.Hour, now); !ok {
+		t.Error("expected a hit within the TTL")
+	}
+	if _, ok := c.Lookup("test", "go test ./...", "tree1", time.Hour, now); ok {
+		t.Error("expected a miss past the TTL")
+	}
+	if _, ok := c.Lookup("test", "go test -race ./...", "tree1", 3*time.Hour, now); ok {
+		t.Error("a different gate command must miss")
+	}
+	if _, ok := c.Lookup("test", "go test ./...", "tree2", 3*time.Hour, now); ok {
+		t.Error("a different tree must miss")
+	}
+	if n := c.Prune(time.Hour, now); n != 1 || len(c.Entries) != 0 {
+		t.Errorf("Prune removed %d, left %d entries", n, len(c.Entries))
+	}
+}
+
+// countingGate returns a gate command that appends a line to a counter file
+// outside the work tree, and a func reading how often it ran.
+func countingGate(t *testing.T, extra string) (string, func() int) {
+	t.Helper()
+	counter := filepath.Join(t.TempDir(), "runs")
+	count := func() int {
+		data, err := os.ReadFile(counter)
+		if err != nil {
+			return 0
+		}
+		return strings.Count(string(data), "\n")
+	}
+	return fmt.Sprintf("echo run >> %s%s", counter, extra), count
+}
+
+func TestRunGates_SkipsGatesPassedOnSameTree(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	cmd, runs := countingGate(t, "")
+	e.config.Gates = map[string]*GateConfig{"check": {Cmd: cmd}}
+
+	for i := 0; i < 2; i++ {
+		if r := e.runGates(context.Background()); !r.Success {
+			t.Fatalf("run %d failed: %s", i, r.Error)
+		}
+	}
+	if runs() != 1 {
+		t.Fatalf("gate ran %d times on an unchanged tree, want 1", runs())
+	}
+	r := e.runGates(context.Background())
+	if !r.Gates[0].Cached {
+		t.Error("expected the result to be marked cached")
+	}
+
+	// A new tree reruns the gate.
+	writeTrainFile(t, work, "new.txt", "x\n")
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-q", "-m", "change")
+	_ = e.runGates(context.Background())
+	if runs() != 2 {
+		t.Errorf("gate ran %d times after the tree changed, want 2", runs())
+	}
+}
+
+func TestPrepareMerge_CachesGatesPerMergedTree(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	cmd, runs := countingGate(t, "")
+	e.config.Gates = map[string]*GateConfig{"check": {Cmd: cmd}}
+	a := addMRBranch(t, work, "polecat/a", "a.txt", "a\n")
+	b := addMRBranch(t, work, "polecat/b", "b.txt", "b\n")
+
+	for _, mr := range []*MRInfo{a, b, a} {
+		if r := e.prepareMerge(context.Background(), mr.Branch, mr.Target); !r.Success {
+			t.Fatalf("prepareMerge %s failed: %s", mr.Branch, r.Error)
+		}
+	}
+	// Both MRs are gated with main checked out, but b must not reuse a's
+	// pass; rechecking a hits its own entry.
+	if runs() != 2 {
+		t.Errorf("gate ran %d times for two MRs against one target head, want 2", runs())
+	}
+}
+
+func TestRunGates_DoesNotCacheFailures(t *testing.T) {
+	e, _ := newTrainEngineer(t)
+	cmd, runs := countingGate(t, "; exit 1")
+	e.config.Gates = map[string]*GateConfig{"check": {Cmd: cmd}}
+
+	for i := 0; i < 2; i++ {
+		if r := e.runGates(context.Background()); r.Success {
+			t.Fatal("expected the gate to fail")
+		}
+	}
+	if runs() != 2 {
+		t.Errorf("failing gate ran %d times, want 2", runs())
+	}
+}
+
+func TestRunGates_CacheBypassAndDisable(t *testing.T) {
+	e, _ := newTrainEngineer(t)
+	cmd, runs := countingGate(t, "")
+	e.config.Gates = map[string]*GateConfig{"check": {Cmd: cmd}}
+	mr := &MRInfo{ID: "gt-mr1"}
+
+	_ = e.runGates(context.Background())
+	if err := RequestGateCacheBypass(e.rig.Path, mr.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !e.consumeGateCacheBypass(mr) {
+		t.Fatal("expected the bypass request to be found")
+	}
+	if e.consumeGateCacheBypass(mr) {
+		t.Error("a bypass request should apply to one run only")
+	}
+	e.bypassGateCache = true
+	_ = e.runGates(context.Background())
+	e.bypassGateCache = false
+	if runs() != 2 {
+		t.Errorf("gate ran %d times with the cache bypassed, want 2", runs())
+	}
+
+	e.config.GateCacheTTL = 0
+	_ = e.runGates(context.Background())
+	if runs() != 3 {
+		t.Errorf("gate ran %d times with the cache disabled, want 3", runs())
+	}
+}
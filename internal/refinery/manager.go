@@ -245,7 +245,12 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 		return nil, fmt.Errorf("querying merge queue from beads: %w", err)
 	}
 
-	// Score and sort issues by priority score (highest first)
+	// Score and sort issues by the rig's scoring policy (highest first)
+	eng := NewEngineer(m.rig)
+	eng.SetOutput(io.Discard)
+	if err := eng.LoadConfig(); err != nil {
+		return nil, fmt.Errorf("loading merge queue config: %w", err)
+	}
 	now := time.Now()
 	type scoredIssue struct {
 		issue *beads.Issue
@@ -257,7 +262,7 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 		if issue == nil || issue.Status != "open" {
 			continue
 		}
-		score := m.calculateIssueScore(issue, now)
+		score := eng.ScoreIssue(issue, beads.ParseMRFields(issue), now).Total
 		scored = append(scored, scoredIssue{issue: issue, score: score})
 	}
 
@@ -283,39 +288,6 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 	return items, nil
 }
 
-// calculateIssueScore computes the priority score for an MR issue.
-// Higher scores mean higher priority (process first).
-func (m *Manager) calculateIssueScore(issue *beads.Issue, now time.Time) float64 {
-	fields := beads.ParseMRFields(issue)
-
-	// Parse MR creation time
-	mrCreatedAt := parseTime(issue.CreatedAt)
-	if mrCreatedAt.IsZero() {
-		mrCreatedAt = now // Fallback
-	}
-
-	// Build score input
-	input := ScoreInput{
-		Priority:    issue.Priority,
-		MRCreatedAt: mrCreatedAt,
-		Now:         now,
-	}
-
-	// Add fields from MR metadata if available
-	if fields != nil {
-		input.RetryCount = fields.RetryCount
-
-		// Parse convoy created at if available
-		if fields.ConvoyCreatedAt != "" {
-			if convoyTime := parseTime(fields.ConvoyCreatedAt); !convoyTime.IsZero() {
-				input.ConvoyCreatedAt = &convoyTime
-			}
-		}
-	}
-
-	return ScoreMRWithDefaults(input)
-}
-
 // issueToMR converts a beads issue to a MergeRequest.
 func (m *Manager) issueToMR(issue *beads.Issue) *MergeRequest {
 	if issue == nil {
@@ -0,0 +1,427 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// PostMergeFailureType is the MERGE_FAILED failure type for a merge that
+// broke the post-merge gates after landing.
+const PostMergeFailureType = "post-merge"
+
+// PostMergeState records, per target branch, the last head that passed the
+// post-merge gates, the last head that was verified, the red head
+// verification started from when no head had passed yet, and the revert
+// opened for the target's current breakage.
+type PostMergeState struct {
+	Version     int                         `json:"version"`
+	LastGreen   map[string]string           `json:"last_green"`
+	LastChecked map[string]string           `json:"last_checked"`
+	RedBaseline map[string]string           `json:"red_baseline,omitempty"`
+	OpenReverts map[string]*PostMergeRevert `json:"open_reverts,omitempty"`
+}
+
+// PostMergeRevert is a revert the refinery opened for a post-merge culprit.
+// While its MR is open, later red heads of the target are not bisected again.
+type PostMergeRevert struct {
+	Culprit string `json:"culprit"`
+	Branch  string `json:"branch"`
+	Bug     string `json:"bug,omitempty"`
+	MR      string `json:"mr,omitempty"`
+}
+
+// PostMergeStatePath returns the rig's post-merge verification state file.
+func PostMergeStatePath(rigPath string) string {
+	return filepath.Join(rigPath, constants.DirRuntime, constants.DirRefinery, "post-merge.json")
+}
+
+// LoadPostMergeState loads the rig's post-merge state. A missing file
+// yields an empty state.
+func LoadPostMergeState(rigPath string) (*PostMergeState, error) {
+	s := &PostMergeState{Version: 1}
+	data, err := os.ReadFile(PostMergeStatePath(rigPath)) //nolint:gosec // G304: path is constructed internally
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("parsing post-merge state: %w", err)
+		}
+	}
+	if s.LastGreen == nil {
+		s.LastGreen = make(map[string]string)
+	}
+	if s.LastChecked == nil {
+		s.LastChecked = make(map[string]string)
+	}
+	if s.RedBaseline == nil {
+		s.RedBaseline = make(map[string]string)
+	}
+	if s.OpenReverts == nil {
+		s.OpenReverts = make(map[string]*PostMergeRevert)
+	}
+	return s, nil
+}
+
+// Save writes the post-merge state atomically.
+func (s *PostMergeState) Save(rigPath string) error {
+	return util.EnsureDirAndWriteJSON(PostMergeStatePath(rigPath), s)
+}
+
+// PostMergeResult is the outcome of verifying a target's head.
+type PostMergeResult struct {
+	Target  string
+	Head    string
+	Skipped bool // No post-merge gates, or head was already verified
+	Success bool
+	Error   string
+	Gates   []GateResult
+
+	// NoBaseline is set when the head failed before any head of target
+	// passed: there is nothing to bisect against, so nothing is reverted.
+	NoBaseline bool
+
+	// Set when the head failed: the first commit since the last green head
+	// that fails the gates, the MR that merged it (nil if unknown), and the
+	// revert branch and MR opened for it.
+	Culprit      string
+	CulpritMR    *MRInfo
+	RevertBranch string
+	RevertMR     string
+}
+
+// VerifyPostMerge runs the post-merge gates on target's current head. A
+// head that passes becomes the last green head. A failing head is bisected
+// over the first-parent commits since the last green head to find the merge
+// that broke it; that merge is reverted on a branch, a P0 revert MR is
+// queued, and the culprit's polecat is sent MERGE_FAILED via the witness.
+//
+// While a revert opened for an earlier red head is still open, the range is
+// not bisected again: the failure is reported against that revert.
+//
+// Without a last green head nothing is reverted: the target was already red
+// when verification started. The failure is recorded as the red baseline
+// and the overseer is mailed; bisection starts once a head passes.
+func (e *Engineer) VerifyPostMerge(ctx context.Context, target string) PostMergeResult {
+	result := PostMergeResult{Target: target}
+	if len(e.config.PostMergeGates) == 0 {
+		result.Skipped = true
+		result.Success = true
+		return result
+	}
+
+	if err := e.git.Checkout(target); err != nil {
+		result.Error = fmt.Sprintf("failed to checkout target %s: %v", target, err)
+		return result
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+	head, err := e.git.Rev("HEAD")
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to resolve %s head: %v", target, err)
+		return result
+	}
+	result.Head = head
+
+	state, err := LoadPostMergeState(e.rig.Path)
+	if err != nil {
+		result.Error = fmt.Sprintf("loading post-merge state: %v", err)
+		return result
+	}
+	if state.LastChecked[target] == head {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: %s head %s already verified\n", target, shortSHA(head))
+		result.Skipped = true
+		result.Success = state.LastGreen[target] == head
+		return result
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: verifying %s head %s\n", target, shortSHA(head))
+	checks := e.runGateSet(ctx, e.config.PostMergeGates)
+	result.Gates = checks.Gates
+	state.LastChecked[target] = head
+	if checks.Success {
+		state.LastGreen[target] = head
+		delete(state.OpenReverts, target)
+		if err := state.Save(e.rig.Path); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save post-merge state: %v\n", err)
+		}
+		result.Success = true
+		return result
+	}
+	if state.LastGreen[target] == "" {
+		if state.RedBaseline[target] == "" {
+			state.RedBaseline[target] = head
+		}
+		if err := state.Save(e.rig.Path); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save post-merge state: %v\n", err)
+		}
+		result.NoBaseline = true
+		result.Error = fmt.Sprintf("post-merge gates failed on %s at %s with no green head to bisect from: %s", target, shortSHA(head), checks.Error)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: %s is red since %s; not reverting\n", target, shortSHA(state.RedBaseline[target]))
+		e.notifyPostMergeRed(target, state.RedBaseline[target], result)
+		return result
+	}
+	if open := state.OpenReverts[target]; open != nil && e.revertStillOpen(open) {
+		if err := state.Save(e.rig.Path); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save post-merge state: %v\n", err)
+		}
+		result.Culprit = open.Culprit
+		result.RevertBranch = open.Branch
+		result.RevertMR = open.MR
+		result.Error = fmt.Sprintf("post-merge gates failed on %s at %s: %s (revert %s still open)", target, shortSHA(head), checks.Error, open.Branch)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: %s still red; revert of %s is open, not bisecting again\n", target, shortSHA(open.Culprit))
+		return result
+	}
+	delete(state.OpenReverts, target)
+	if err := state.Save(e.rig.Path); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save post-merge state: %v\n", err)
+	}
+
+	culprit, failure := e.bisectPostMerge(ctx, target, state.LastGreen[target], head, checks)
+	result.Culprit = culprit
+	result.Gates = failure.Gates
+	result.Error = fmt.Sprintf("post-merge gates failed on %s at %s: %s", target, shortSHA(culprit), failure.Error)
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge culprit: %s\n", shortSHA(culprit))
+
+	result.CulpritMR = e.findMergedMR(culprit)
+	revert := e.openRevertMR(target, culprit, result.CulpritMR, result.Error)
+	if revert != nil {
+		result.RevertBranch, result.RevertMR = revert.Branch, revert.MR
+		state.OpenReverts[target] = revert
+		if err := state.Save(e.rig.Path); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to save post-merge state: %v\n", err)
+		}
+	}
+	e.notifyPostMergeFailure(target, result)
+	return result
+}
+
+// revertStillOpen reports whether a recorded revert's MR is still open. A
+// revert without an MR bead stays open until the target passes again. When
+// the MR can't be looked up it is assumed open, so a beads outage doesn't
+// open a second revert.
+func (e *Engineer) revertStillOpen(r *PostMergeRevert) bool {
+	if r.MR == "" || e.beads == nil {
+		return true
+	}
+	issue, err := e.beads.Show(r.MR)
+	if err != nil {
+		if errors.Is(err, beads.ErrNotFound) {
+			return false
+		}
+		return true
+	}
+	return issue.Status != "closed"
+}
+
+// bisectPostMerge finds the first commit after lastGreen on head's
+// first-parent chain that fails the post-merge gates. headFailure is the
+// failed run on head. It returns the culprit and the failed run on it, and
+// leaves target checked out.
+func (e *Engineer) bisectPostMerge(ctx context.Context, target, lastGreen, head string, headFailure ProcessResult) (string, ProcessResult) {
+	if ok, err := e.git.IsAncestor(lastGreen, head); err != nil || !ok {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: last green %s is not an ancestor of %s; blaming head\n", shortSHA(lastGreen), shortSHA(head))
+		return head, headFailure
+	}
+	commits, err := e.git.FirstParentCommits(lastGreen, head)
+	if err != nil || len(commits) == 0 {
+		return head, headFailure
+	}
+	defer func() {
+		if err := e.git.Checkout(target); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to checkout %s after bisect: %v\n", target, err)
+		}
+	}()
+
+	// Flakes were recorded for the run on head; don't count them again for
+	// every probe.
+	e.deferFlakes = true
+	defer func() { e.deferFlakes = false }()
+
+	// lastGreen (index -1) passes; commits[hi] fails.
+	lo, hi := -1, len(commits)-1
+	failure := headFailure
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: bisecting at %s (%d candidate(s))\n", shortSHA(commits[mid]), hi-lo)
+		if err := e.git.Checkout(commits[mid]); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: bisect checkout %s: %v; blaming head\n", shortSHA(commits[mid]), err)
+			return head, headFailure
+		}
+		if r := e.runGateSet(ctx, e.config.PostMergeGates); r.Success {
+			lo = mid
+		} else {
+			hi, failure = mid, r
+		}
+	}
+	return commits[hi], failure
+}
+
+// findMergedMR returns the closed MR whose merge commit is sha, falling back
+// to an MR whose source issue is named in the commit message. It returns nil
+// when no MR matches.
+func (e *Engineer) findMergedMR(sha string) *MRInfo {
+	if e.beads == nil {
+		return nil
+	}
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "closed",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to list merged MRs: %v\n", err)
+		return nil
+	}
+	msg, _ := e.git.GetBranchCommitMessage(sha)
+
+	var bySource *MRInfo
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil {
+			continue
+		}
+		if fields.MergeCommit != "" && (strings.HasPrefix(sha, fields.MergeCommit) || strings.HasPrefix(fields.MergeCommit, sha)) {
+			return issueToMRInfo(issue, fields)
+		}
+		if bySource == nil && fields.SourceIssue != "" && strings.Contains(msg, fields.SourceIssue) {
+			bySource = issueToMRInfo(issue, fields)
+		}
+	}
+	return bySource
+}
+
+// openRevertMR reverts culprit on a new branch off target, pushes it, and
+// queues it as a P0 MR whose source issue is a bug bead describing the
+// breakage. It returns nil when no revert branch was pushed; the bug and MR
+// IDs are "" when their beads couldn't be created.
+func (e *Engineer) openRevertMR(target, culprit string, culpritMR *MRInfo, reason string) *PostMergeRevert {
+	branch := "revert/" + shortSHA(culprit)
+	_ = e.git.DeleteBranch(branch, true)
+	if err := e.git.CreateBranchFrom(branch, target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create %s: %v\n", branch, err)
+		return nil
+	}
+	defer func() { _ = e.git.Checkout(target) }()
+	if err := e.git.Checkout(branch); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to checkout %s: %v\n", branch, err)
+		return nil
+	}
+	if err := e.git.Revert(culprit); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to revert %s: %v\n", shortSHA(culprit), err)
+		return nil
+	}
+	if err := e.git.Push("origin", branch, true); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to push %s: %v\n", branch, err)
+		return nil
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushed revert branch %s\n", branch)
+	revert := &PostMergeRevert{Culprit: culprit, Branch: branch}
+	if e.beads == nil {
+		return revert
+	}
+
+	merged := shortSHA(culprit)
+	if culpritMR != nil {
+		merged = fmt.Sprintf("%s (%s, %s)", merged, culpritMR.ID, culpritMR.Branch)
+	}
+	bug, err := e.beads.Create(beads.CreateOptions{
+		Title:    fmt.Sprintf("Post-merge gates failed on %s: %s", target, merged),
+		Type:     "bug",
+		Priority: 0,
+		Description: fmt.Sprintf(`Commit %s broke the post-merge gates on %s after it landed.
+
+%s
+
+The refinery queued a revert on %s. Reland the change once it passes the post-merge gates.`,
+			merged, target, reason, branch),
+		Actor: e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create post-merge bug bead: %v\n", err)
+		return revert
+	}
+	revert.Bug = bug.ID
+
+	mr, err := e.beads.Create(beads.CreateOptions{
+		Title:    fmt.Sprintf("Revert: %s", merged),
+		Type:     "merge-request",
+		Priority: 0,
+		Description: fmt.Sprintf("branch: %s\ntarget: %s\nsource_issue: %s\nrig: %s",
+			branch, target, bug.ID, e.rig.Name),
+		Actor:     e.rig.Name + "/refinery",
+		Ephemeral: true,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create revert MR: %v\n", err)
+		return revert
+	}
+	revert.MR = mr.ID
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Queued revert MR %s (P0) for %s\n", mr.ID, shortSHA(culprit))
+	return revert
+}
+
+// notifyPostMergeFailure sends MERGE_FAILED for the culprit MR to the
+// witness, which forwards it to the polecat that did the work.
+func (e *Engineer) notifyPostMergeFailure(target string, result PostMergeResult) {
+	mr := result.CulpritMR
+	if mr == nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Post-merge: no MR found for %s; witness not notified\n", shortSHA(result.Culprit))
+		return
+	}
+	artifacts := e.saveGateArtifacts(mr, ProcessResult{Gates: result.Gates})
+	errMsg := result.Error
+	if result.RevertMR != "" {
+		errMsg += fmt.Sprintf(" (reverted in %s)", result.RevertMR)
+	}
+	msg := protocol.NewMergeFailedMessageWithOutput(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, target, PostMergeFailureType, errMsg,
+		artifacts, FailedGateTail(result.Gates))
+	if e.router == nil {
+		return
+	}
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Notified witness of post-merge failure for %s\n", mr.Worker)
+	}
+}
+
+// notifyPostMergeRed mails the overseer that target failed the post-merge
+// gates before any head passed, so no merge could be blamed or reverted.
+func (e *Engineer) notifyPostMergeRed(target, since string, result PostMergeResult) {
+	if e.router == nil {
+		return
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "The post-merge gates fail on %s head %s, and no head of %s has passed them yet.\n", target, shortSHA(result.Head), target)
+	fmt.Fprintf(&body, "Red since: %s\n\n", shortSHA(since))
+	body.WriteString("Nothing was reverted: there is no green head to bisect from. Fix the target branch;\n")
+	body.WriteString("the first head that passes becomes the baseline for later bisects.\n\n")
+	if tail := FailedGateTail(result.Gates); tail != "" {
+		body.WriteString(tail)
+		body.WriteString("\n")
+	}
+
+	msg := mail.NewMessage(
+		e.rig.Name+"/refinery",
+		"overseer",
+		fmt.Sprintf("POST_MERGE_RED %s", target),
+		body.String(),
+	)
+	msg.Priority = mail.PriorityHigh
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to mail overseer about red %s: %v\n", target, err)
+	}
+}
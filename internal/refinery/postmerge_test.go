@@ -0,0 +1,209 @@
+package refinery
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// commitOnMain commits one file to main and pushes it, returning the SHA.
+func commitOnMain(t *testing.T, work, file, content string) string {
+	t.Helper()
+	runGit(t, work, "checkout", "-q", "main")
+	writeTrainFile(t, work, file, content)
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-q", "-m", "add "+file)
+	runGit(t, work, "push", "-q", "origin", "main")
+	return strings.TrimSpace(runGit(t, work, "rev-parse", "HEAD"))
+}
+
+func TestVerifyPostMerge_NoGatesSkips(t *testing.T) {
+	e, _ := newTrainEngineer(t)
+	if r := e.VerifyPostMerge(context.Background(), "main"); !r.Skipped || !r.Success {
+		t.Errorf("expected a skipped success without post-merge gates, got %+v", r)
+	}
+}
+
+func TestVerifyPostMerge_BisectsAndReverts(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	e.config.PostMergeGates = map[string]*GateConfig{"check": {Cmd: "test ! -f bad.txt"}}
+	ctx := context.Background()
+
+	green := e.VerifyPostMerge(ctx, "main")
+	if !green.Success || green.Skipped {
+		t.Fatalf("expected the base head to pass, got %+v", green)
+	}
+	if again := e.VerifyPostMerge(ctx, "main"); !again.Skipped || !again.Success {
+		t.Errorf("expected the verified head to be skipped, got %+v", again)
+	}
+
+	commitOnMain(t, work, "one.txt", "1\n")
+	culprit := commitOnMain(t, work, "bad.txt", "boom\n")
+	commitOnMain(t, work, "two.txt", "2\n")
+	commitOnMain(t, work, "three.txt", "3\n")
+
+	r := e.VerifyPostMerge(ctx, "main")
+	if r.Success {
+		t.Fatal("expected the post-merge gates to fail")
+	}
+	if r.Culprit != culprit {
+		t.Errorf("culprit = %s, want %s", r.Culprit, culprit)
+	}
+	if r.RevertBranch != "revert/"+culprit[:8] {
+		t.Errorf("revert branch = %q", r.RevertBranch)
+	}
+
+	files := runGit(t, work, "ls-tree", "--name-only", "origin/"+r.RevertBranch)
+	if strings.Contains(files, "bad.txt") {
+		t.Error("revert branch should remove bad.txt")
+	}
+	if !strings.Contains(files, "three.txt") {
+		t.Error("revert branch should keep later merges")
+	}
+	if branch := strings.TrimSpace(runGit(t, work, "rev-parse", "--abbrev-ref", "HEAD")); branch != "main" {
+		t.Errorf("work tree left on %q, want main", branch)
+	}
+
+	state, err := LoadPostMergeState(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.LastGreen["main"] != green.Head {
+		t.Errorf("last green = %s, want %s", state.LastGreen["main"], green.Head)
+	}
+}
+
+// installPostMergeBd puts a fake bd on PATH that logs each created bead's
+// labels to the returned file and reports every bead as open.
+func installPostMergeBd(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake bd is a shell script")
+	}
+	binDir := t.TempDir()
+	log := filepath.Join(binDir, "created.log")
+	script := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    --*) ;;
+    *) cmd="$arg"; break ;;
+  esac
+done
+case "$cmd" in
+  create)
+    n=$(( $(wc -l < "` + log + `" 2>/dev/null || echo 0) + 1 ))
+    for arg in "$@"; do
+      case "$arg" in
+        --labels=*) echo "${arg#--labels=}" >> "` + log + `" ;;
+      esac
+    done
+    echo "{\"id\":\"pm-$n\",\"status\":\"open\"}"
+    ;;
+  show)
+    echo "[{\"id\":\"$2\",\"status\":\"open\"}]"
+    ;;
+  *)
+    echo "[]"
+    ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(binDir, "bd"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestVerifyPostMerge_OpenRevertIsNotDuplicated(t *testing.T) {
+	created := installPostMergeBd(t)
+	e, work := newTrainEngineer(t)
+	e.beads = beads.New(work)
+	e.config.PostMergeGates = map[string]*GateConfig{"check": {Cmd: "test ! -f bad.txt"}}
+	ctx := context.Background()
+
+	if r := e.VerifyPostMerge(ctx, "main"); !r.Success {
+		t.Fatalf("expected the base head to pass, got %+v", r)
+	}
+	culprit := commitOnMain(t, work, "bad.txt", "boom\n")
+	first := e.VerifyPostMerge(ctx, "main")
+	if first.Culprit != culprit || first.RevertMR == "" {
+		t.Fatalf("expected a revert MR for %s, got %+v", culprit, first)
+	}
+
+	// Another merge lands while the target is still red.
+	commitOnMain(t, work, "later.txt", "later\n")
+	second := e.VerifyPostMerge(ctx, "main")
+	if second.Success || second.Skipped {
+		t.Fatalf("expected the new head to fail, got %+v", second)
+	}
+	if second.Culprit != culprit || second.RevertMR != first.RevertMR {
+		t.Errorf("second run should report the open revert %s of %s, got %s of %s",
+			first.RevertMR, culprit, second.RevertMR, second.Culprit)
+	}
+
+	data, err := os.ReadFile(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels := strings.Fields(string(data))
+	var bugs, mrs int
+	for _, l := range labels {
+		switch l {
+		case "gt:bug":
+			bugs++
+		case "gt:merge-request":
+			mrs++
+		}
+	}
+	if bugs != 1 || mrs != 1 {
+		t.Errorf("created %d bug(s) and %d revert MR(s), want 1 and 1 (labels: %v)", bugs, mrs, labels)
+	}
+
+	state, err := LoadPostMergeState(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open := state.OpenReverts["main"]; open == nil || open.Culprit != culprit || open.MR != first.RevertMR || open.Bug == "" {
+		t.Errorf("open revert = %+v, want culprit %s and MR %s", open, culprit, first.RevertMR)
+	}
+}
+
+func TestVerifyPostMerge_RedWithoutBaselineDoesNotRevert(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	e.config.PostMergeGates = map[string]*GateConfig{"check": {Cmd: "test ! -f bad.txt"}}
+	ctx := context.Background()
+
+	// main is already red when verification first runs.
+	red := commitOnMain(t, work, "bad.txt", "boom\n")
+	r := e.VerifyPostMerge(ctx, "main")
+	if r.Success || !r.NoBaseline {
+		t.Fatalf("expected a failure with no baseline, got %+v", r)
+	}
+	if r.Culprit != "" || r.RevertBranch != "" {
+		t.Errorf("nothing should be blamed or reverted, got culprit %q, revert %q", r.Culprit, r.RevertBranch)
+	}
+	if out := runGit(t, work, "ls-remote", "--heads", "origin", "revert/*"); strings.TrimSpace(out) != "" {
+		t.Errorf("unexpected revert branch pushed: %s", out)
+	}
+
+	state, err := LoadPostMergeState(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.RedBaseline["main"] != red || state.LastGreen["main"] != "" {
+		t.Errorf("red baseline = %q, last green = %q; want %s and none", state.RedBaseline["main"], state.LastGreen["main"], red)
+	}
+
+	// Once fixed, the green head becomes the baseline for bisects.
+	runGit(t, work, "rm", "-q", "bad.txt")
+	runGit(t, work, "commit", "-q", "-m", "fix")
+	runGit(t, work, "push", "-q", "origin", "main")
+	if r := e.VerifyPostMerge(ctx, "main"); !r.Success {
+		t.Fatalf("expected the fixed head to pass, got %+v", r)
+	}
+}
@@ -0,0 +1,205 @@
+package refinery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/forge"
+)
+
+// Merge modes, as used in merge_queue.merge_mode.
+const (
+	MergeModeDirect      = "direct"
+	MergeModePullRequest = "pull_request"
+)
+
+// SetForge sets the forge used in pull_request merge mode, in place of the
+// one built from merge_queue.forge.
+func (e *Engineer) SetForge(p forge.ForgeProvider) {
+	e.forge = p
+}
+
+// forgeProvider returns the rig's forge, creating it on first use.
+func (e *Engineer) forgeProvider() (forge.ForgeProvider, error) {
+	if e.forge != nil {
+		return e.forge, nil
+	}
+	gitURL := e.rig.GitURL
+	if gitURL == "" {
+		if u, err := e.git.RemoteURL("origin"); err == nil {
+			gitURL = u
+		}
+	}
+	p, err := forge.New(e.config.Forge, gitURL)
+	if err != nil {
+		return nil, err
+	}
+	e.forge = p
+	return p, nil
+}
+
+// mergeViaForge lands an MR through a forge pull request (merge_mode
+// pull_request). The MR passes the same local checks as a direct merge; its
+// branch is then pushed and a pull request opened, or the open one reused.
+//
+// While the forge's checks run, or the forge refuses the merge (e.g. missing
+// reviews), the result is Pending and the MR is retried later. A pull request
+// with no checks is Pending too, since a forge reports none until CI starts,
+// unless the rig's forge config declares no_checks. Failed checks
+// fail the MR back to its worker. Passing checks merge the pull request on
+// the forge at the pushed head, and target is pulled to pick up the result.
+// The MR bead then closes exactly as after a direct merge.
+func (e *Engineer) mergeViaForge(ctx context.Context, mr *MRInfo) ProcessResult {
+	provider, err := e.forgeProvider()
+	if err != nil {
+		return e.forgePending("forge unavailable: %v", err)
+	}
+
+	checks := e.prepareMerge(ctx, mr.Branch, mr.Target)
+	if !checks.Success {
+		return checks
+	}
+
+	head, err := e.git.Rev(mr.Branch)
+	if err != nil {
+		return ProcessResult{Error: fmt.Sprintf("failed to resolve %s: %v", mr.Branch, err)}
+	}
+	// The refinery owns all remote pushes. Force, since the worker may have
+	// rebased a branch an earlier attempt already pushed.
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing %s to origin...\n", mr.Branch)
+	if err := e.git.Push("origin", mr.Branch, true); err != nil {
+		return e.forgePending("failed to push %s: %v", mr.Branch, err)
+	}
+
+	title, body := e.pullRequestText(mr)
+	pr, err := provider.OpenPR(ctx, forge.OpenPROptions{Head: mr.Branch, Base: mr.Target, Title: title, Body: body})
+	if err != nil {
+		return e.forgePending("failed to open pull request for %s: %v", mr.Branch, err)
+	}
+	e.recordPullRequest(mr.ID, pr)
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Pull request #%d: %s\n", pr.Number, pr.URL)
+
+	status, err := provider.Checks(ctx, pr.Number)
+	if err != nil {
+		return e.forgePending("failed to read checks for pull request #%d: %v", pr.Number, err)
+	}
+	switch status.State {
+	case forge.CheckPending:
+		return e.forgePending("waiting for checks on pull request #%d", pr.Number)
+	case forge.CheckNone:
+		if e.config.Forge.NoChecks {
+			break
+		}
+		grace := e.config.Forge.ChecksGraceDuration()
+		if pr.CreatedAt.IsZero() || time.Since(pr.CreatedAt) < grace {
+			return e.forgePending("waiting for checks to start on pull request #%d", pr.Number)
+		}
+		return e.forgePending("no checks on pull request #%d after %s; set merge_queue.forge.no_checks if the repository has no CI", pr.Number, grace)
+	case forge.CheckFailure:
+		var names []string
+		for _, c := range status.Failed() {
+			names = append(names, c.Name)
+		}
+		msg := fmt.Sprintf("forge checks failed on pull request #%d: %s", pr.Number, strings.Join(names, ", "))
+		e.commentPullRequest(ctx, provider, pr.Number, fmt.Sprintf("Returned %s to its worker: %s.", mr.ID, msg))
+		return ProcessResult{TestsFailed: true, Error: msg, Gates: checks.Gates}
+	}
+
+	msg, err := e.git.GetBranchCommitMessage(mr.Branch)
+	if err != nil {
+		msg = title
+	}
+	commitTitle, commitBody, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merging pull request #%d...\n", pr.Number)
+	merged, err := provider.Merge(ctx, pr.Number, forge.MergeOptions{
+		Method:        e.config.Forge.MergeMethod,
+		SHA:           head,
+		CommitTitle:   commitTitle,
+		CommitMessage: strings.TrimSpace(commitBody),
+	})
+	if errors.Is(err, forge.ErrNotMergeable) {
+		return e.forgePending("pull request #%d not mergeable yet: %v", pr.Number, err)
+	}
+	if err != nil {
+		return e.forgePending("failed to merge pull request #%d: %v", pr.Number, err)
+	}
+
+	// Bring the local target up to date with the forge's merge.
+	if err := e.git.Checkout(mr.Target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to checkout %s after merge: %v\n", mr.Target, err)
+	} else if err := e.git.Pull("origin", mr.Target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s after merge: %v\n", mr.Target, err)
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merged pull request #%d: %s\n", pr.Number, shortSHA(merged.MergeCommit))
+	return ProcessResult{
+		Success:     true,
+		MergeCommit: merged.MergeCommit,
+		Gates:       checks.Gates,
+	}
+}
+
+// forgePending returns a Pending result, logging why the MR waits.
+func (e *Engineer) forgePending(format string, args ...interface{}) ProcessResult {
+	msg := fmt.Sprintf(format, args...)
+	_, _ = fmt.Fprintf(e.output, "[Engineer] %s (MR requeued)\n", msg)
+	return ProcessResult{Pending: true, Error: msg}
+}
+
+// pullRequestText returns the title and body of the pull request for mr.
+func (e *Engineer) pullRequestText(mr *MRInfo) (string, string) {
+	title := mr.Title
+	if title == "" {
+		if msg, err := e.git.GetBranchCommitMessage(mr.Branch); err == nil {
+			title, _, _ = strings.Cut(strings.TrimSpace(msg), "\n")
+		}
+	}
+	if title == "" {
+		title = fmt.Sprintf("Merge %s into %s", mr.Branch, mr.Target)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Merge request %s", mr.ID)
+	if mr.SourceIssue != "" {
+		fmt.Fprintf(&body, " for %s", mr.SourceIssue)
+	}
+	if mr.Worker != "" {
+		fmt.Fprintf(&body, " by %s", mr.Worker)
+	}
+	fmt.Fprintf(&body, ".\n\nOpened by the %s refinery, which merges it once the checks pass.\n", e.rig.Name)
+	return title, body.String()
+}
+
+// recordPullRequest stores the pull request on the MR bead.
+func (e *Engineer) recordPullRequest(mrID string, pr *forge.PullRequest) {
+	if mrID == "" || e.beads == nil {
+		return
+	}
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mrID, err)
+		return
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	if fields.PRNumber == pr.Number && fields.PRURL == pr.URL {
+		return
+	}
+	fields.PRNumber, fields.PRURL = pr.Number, pr.URL
+	desc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &desc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record pull request on %s: %v\n", mrID, err)
+	}
+}
+
+// commentPullRequest posts a comment, logging failures.
+func (e *Engineer) commentPullRequest(ctx context.Context, provider forge.ForgeProvider, number int, body string) {
+	if err := provider.Comment(ctx, number, body); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to comment on pull request #%d: %v\n", number, err)
+	}
+}
@@ -0,0 +1,128 @@
+package refinery
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/forge"
+)
+
+// newForgeEngineer returns a train engineer in pull_request merge mode whose
+// fake forge squash-merges pull requests into origin from its own clone.
+func newForgeEngineer(t *testing.T) (*Engineer, *forge.Fake, string) {
+	t.Helper()
+	e, work := newTrainEngineer(t)
+	e.config.MergeMode = MergeModePullRequest
+
+	server := filepath.Join(filepath.Dir(work), "forge")
+	runGit(t, filepath.Dir(work), "clone", "-q", filepath.Join(filepath.Dir(work), "origin.git"), server)
+	f := forge.NewFake()
+	f.MergeFunc = func(pr *forge.PullRequest, opts forge.MergeOptions) (string, error) {
+		runGit(t, server, "fetch", "-q", "origin")
+		runGit(t, server, "checkout", "-q", pr.Base)
+		runGit(t, server, "reset", "-q", "--hard", "origin/"+pr.Base)
+		runGit(t, server, "merge", "-q", "--squash", "origin/"+pr.Head)
+		runGit(t, server, "commit", "-q", "-m", opts.CommitTitle)
+		runGit(t, server, "push", "-q", "origin", pr.Base)
+		return strings.TrimSpace(runGit(t, server, "rev-parse", "HEAD")), nil
+	}
+	e.SetForge(f)
+	return e, f, work
+}
+
+func TestMergeViaForge_LandsWhenChecksPass(t *testing.T) {
+	e, f, work := newForgeEngineer(t)
+	ctx := context.Background()
+	mr := addMRBranch(t, work, "feat-pr", "pr.txt", "hello\n")
+
+	// Open the PR up front so its checks can be set before processing.
+	pr, _ := f.OpenPR(ctx, forge.OpenPROptions{Head: mr.Branch, Base: "main"})
+
+	f.SetChecks(pr.Number, forge.CheckPending)
+	result := e.ProcessMRInfo(ctx, mr)
+	if result.Success || !result.Pending || result.FailureType() != FailureNone {
+		t.Fatalf("pending checks: got %+v, want a pending non-failure", result)
+	}
+	if !strings.Contains(runGit(t, work, "ls-remote", "origin", mr.Branch), mr.Branch) {
+		t.Error("branch should be pushed to origin for the pull request")
+	}
+
+	f.SetChecks(pr.Number, forge.CheckFailure, forge.Check{Name: "ci/test", State: forge.CheckFailure})
+	result = e.ProcessMRInfo(ctx, mr)
+	if result.Success || result.Pending || !result.FailureType().ShouldAssignToWorker() {
+		t.Fatalf("failed checks: got %+v, want a failure for the worker", result)
+	}
+	if !strings.Contains(result.Error, "ci/test") {
+		t.Errorf("error should name the failed check: %s", result.Error)
+	}
+	if c := f.Comments(pr.Number); len(c) != 1 || !strings.Contains(c[0], "ci/test") {
+		t.Errorf("comments = %v, want one naming ci/test", c)
+	}
+
+	f.SetChecks(pr.Number, forge.CheckSuccess)
+	result = e.ProcessMRInfo(ctx, mr)
+	if !result.Success {
+		t.Fatalf("passing checks: got %+v, want success", result)
+	}
+	if got, _ := f.GetPR(ctx, pr.Number); got.State != forge.PRMerged {
+		t.Errorf("pull request state = %s, want merged", got.State)
+	}
+	if !strings.Contains(originFiles(t, work), "pr.txt") {
+		t.Error("pr.txt should be on origin/main after the forge merge")
+	}
+	if head := strings.TrimSpace(runGit(t, work, "rev-parse", "main")); head != result.MergeCommit {
+		t.Errorf("local main = %s, want pulled merge commit %s", head, result.MergeCommit)
+	}
+}
+
+func TestMergeViaForge_NoChecksWaitsForCI(t *testing.T) {
+	e, f, work := newForgeEngineer(t)
+	ctx := context.Background()
+	mr := addMRBranch(t, work, "feat-pr", "pr.txt", "hello\n")
+
+	// Just opened: CI has not reported yet.
+	result := e.ProcessMRInfo(ctx, mr)
+	if result.Success || !result.Pending || !strings.Contains(result.Error, "waiting for checks to start") {
+		t.Fatalf("new pull request with no checks: got %+v, want pending on CI", result)
+	}
+
+	// Past the grace period, still no checks: keep waiting, but say why.
+	f.SetCreatedAt(1, time.Now().Add(-2*forge.DefaultChecksGrace))
+	result = e.ProcessMRInfo(ctx, mr)
+	if result.Success || !result.Pending || !strings.Contains(result.Error, "no_checks") {
+		t.Fatalf("no checks after grace: got %+v, want pending pointing at no_checks", result)
+	}
+	if got, _ := f.GetPR(ctx, 1); got.State != forge.PROpen {
+		t.Fatalf("pull request state = %s, want open", got.State)
+	}
+
+	e.config.Forge.NoChecks = true
+	f.NoCI = true
+	if result = e.ProcessMRInfo(ctx, mr); !result.Success {
+		t.Fatalf("no_checks rig: got %+v, want merged", result)
+	}
+}
+
+func TestProcessTrain_PullRequestModeRequeuesPending(t *testing.T) {
+	e, f, work := newForgeEngineer(t)
+	ctx := context.Background()
+	a := addMRBranch(t, work, "feat-a", "a.txt", "a\n")
+	b := addMRBranch(t, work, "feat-b", "b.txt", "b\n")
+
+	// a's pull request gets no checks: the rig declares it has no CI.
+	e.config.Forge.NoChecks = true
+	f.NoCI = true
+	pr, _ := f.OpenPR(ctx, forge.OpenPROptions{Head: b.Branch, Base: "main"})
+	f.SetChecks(pr.Number, forge.CheckPending)
+
+	got := outcomesByID(e.ProcessTrain(ctx, []*MRInfo{a, b}))
+	if !got[a.ID].Result.Success {
+		t.Errorf("%s: got %+v, want landed", a.ID, got[a.ID].Result)
+	}
+	if o := got[b.ID]; !o.Requeued || !o.Result.Pending {
+		t.Errorf("%s: got %+v, want requeued while checks run", b.ID, o)
+	}
+}
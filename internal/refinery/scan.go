@@ -0,0 +1,382 @@
+package refinery
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScanGateName is the gate name the built-in policy scan reports under.
+const ScanGateName = "scan"
+
+// scanAllowPragma on an added line exempts it from the secret scan.
+const scanAllowPragma = "gt:allow-secret"
+
+// ScanConfig configures the built-in policy scan gate (merge_queue.scan). The
+// scan checks an MR's changes against its target before any other gate runs.
+type ScanConfig struct {
+	// Secrets enables the scan of added lines for credentials in known
+	// formats and for high-entropy strings.
+	Secrets bool `json:"secrets"`
+
+	// SecretEntropy is the Shannon entropy, in bits per character, at which
+	// a 20+ character token counts as a secret. Zero disables the entropy
+	// check; known key formats are still detected.
+	SecretEntropy float64 `json:"secret_entropy"`
+
+	// AllowPaths are path patterns (see MatchPathPattern) exempt from the
+	// secret scan, e.g. lockfiles full of checksums.
+	AllowPaths []string `json:"allow_paths"`
+
+	// ForbiddenPaths are path patterns no MR may add or modify.
+	ForbiddenPaths []string `json:"forbidden_paths"`
+
+	// MaxBinaryBytes is the largest binary file an MR may add or modify.
+	// Zero means no limit.
+	MaxBinaryBytes int64 `json:"max_binary_bytes"`
+
+	// MaxDiffLines caps the lines an MR adds plus deletes. Zero means no limit.
+	MaxDiffLines int `json:"max_diff_lines"`
+
+	// MaxFiles caps the files an MR changes. Zero means no limit.
+	MaxFiles int `json:"max_files"`
+}
+
+// DefaultScanConfig returns the scan policy used for unset merge_queue.scan
+// fields.
+func DefaultScanConfig() ScanConfig {
+	return ScanConfig{
+		Secrets:        true,
+		SecretEntropy:  4.5,
+		AllowPaths:     []string{"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.lock", "*.svg"},
+		ForbiddenPaths: []string{".env", ".env.local", "*.pem", "*.p12", "id_rsa", "id_ed25519"},
+		MaxBinaryBytes: 1 << 20,
+	}
+}
+
+// scanConfigRaw is the JSON representation of merge_queue.scan. Nil fields
+// keep their defaults.
+type scanConfigRaw struct {
+	Secrets        *bool    `json:"secrets"`
+	SecretEntropy  *float64 `json:"secret_entropy"`
+	AllowPaths     []string `json:"allow_paths"`
+	ForbiddenPaths []string `json:"forbidden_paths"`
+	MaxBinaryBytes *int64   `json:"max_binary_bytes"`
+	MaxDiffLines   *int     `json:"max_diff_lines"`
+	MaxFiles       *int     `json:"max_files"`
+}
+
+// apply validates raw and sets its fields on cfg.
+func (raw *scanConfigRaw) apply(cfg *ScanConfig) error {
+	if raw.Secrets != nil {
+		cfg.Secrets = *raw.Secrets
+	}
+	if raw.SecretEntropy != nil {
+		if *raw.SecretEntropy < 0 {
+			return fmt.Errorf("secret_entropy must not be negative, got %v", *raw.SecretEntropy)
+		}
+		cfg.SecretEntropy = *raw.SecretEntropy
+	}
+	for name, patterns := range map[string][]string{"allow_paths": raw.AllowPaths, "forbidden_paths": raw.ForbiddenPaths} {
+		for _, p := range patterns {
+			if err := validatePathPattern(p); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	if raw.AllowPaths != nil {
+		cfg.AllowPaths = raw.AllowPaths
+	}
+	if raw.ForbiddenPaths != nil {
+		cfg.ForbiddenPaths = raw.ForbiddenPaths
+	}
+	if raw.MaxBinaryBytes != nil && *raw.MaxBinaryBytes < 0 {
+		return fmt.Errorf("max_binary_bytes must not be negative, got %d", *raw.MaxBinaryBytes)
+	}
+	for name, v := range map[string]*int{"max_diff_lines": raw.MaxDiffLines, "max_files": raw.MaxFiles} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", name, *v)
+		}
+	}
+	if raw.MaxBinaryBytes != nil {
+		cfg.MaxBinaryBytes = *raw.MaxBinaryBytes
+	}
+	if raw.MaxDiffLines != nil {
+		cfg.MaxDiffLines = *raw.MaxDiffLines
+	}
+	if raw.MaxFiles != nil {
+		cfg.MaxFiles = *raw.MaxFiles
+	}
+	return nil
+}
+
+// Scan violation kinds.
+const (
+	ScanSecret        = "secret"
+	ScanForbiddenPath = "forbidden-path"
+	ScanBinary        = "binary"
+	ScanDiffSize      = "diff-size"
+)
+
+// ScanViolation is one policy violation found by the scan gate. Details never
+// include the offending secret.
+type ScanViolation struct {
+	Kind   string `json:"kind"`
+	Path   string `json:"path,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Detail string `json:"detail"`
+}
+
+func (v ScanViolation) String() string {
+	switch {
+	case v.Line > 0:
+		return fmt.Sprintf("%s:%d: %s: %s", v.Path, v.Line, v.Kind, v.Detail)
+	case v.Path != "":
+		return fmt.Sprintf("%s: %s: %s", v.Path, v.Kind, v.Detail)
+	default:
+		return fmt.Sprintf("%s: %s", v.Kind, v.Detail)
+	}
+}
+
+// secretPatterns are credential formats the secret scan recognizes.
+var secretPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`)},
+	{"Stripe live key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{20,}`)},
+	{"API secret key", regexp.MustCompile(`\bsk-(ant-|proj-)?[A-Za-z0-9_\-]{32,}`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// entropyTokenRe matches the base64- and hex-like runs the entropy check
+// considers.
+var entropyTokenRe = regexp.MustCompile(`[A-Za-z0-9+/_\-]{20,}`)
+
+// ScanPatch checks the added lines of a unified diff (git diff -U0) for
+// secrets. Files matching cfg.AllowPaths and lines carrying the
+// gt:allow-secret pragma are skipped.
+func ScanPatch(patch string, cfg ScanConfig) []ScanViolation {
+	if !cfg.Secrets {
+		return nil
+	}
+	var violations []ScanViolation
+	var file string
+	skip, inHeader := false, false
+	line := 0
+	for _, text := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			file, skip, inHeader = "", true, true
+			continue
+		case inHeader && strings.HasPrefix(text, "+++ "):
+			file = patchPath(strings.TrimPrefix(text, "+++ "))
+			skip = file == "" || matchesAnyPattern(cfg.AllowPaths, file)
+			continue
+		case strings.HasPrefix(text, "@@ "):
+			inHeader = false
+			line = hunkStart(text)
+			continue
+		case inHeader || skip || !strings.HasPrefix(text, "+"):
+			continue
+		}
+		added := text[1:]
+		if !strings.Contains(added, scanAllowPragma) {
+			if kind := detectSecret(added, cfg.SecretEntropy); kind != "" {
+				violations = append(violations, ScanViolation{Kind: ScanSecret, Path: file, Line: line, Detail: kind})
+			}
+		}
+		line++
+	}
+	return violations
+}
+
+// detectSecret returns what kind of secret s contains, or "".
+func detectSecret(s string, entropy float64) string {
+	for _, p := range secretPatterns {
+		if p.re.MatchString(s) {
+			return p.name
+		}
+	}
+	if entropy <= 0 {
+		return ""
+	}
+	for _, tok := range entropyTokenRe.FindAllString(s, -1) {
+		// Require letters and digits: long identifiers and words are
+		// rarely secrets, and random tokens almost always mix both.
+		if !strings.ContainsAny(tok, "0123456789") || !strings.ContainsAny(tok, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			continue
+		}
+		if h := shannonEntropy(tok); h >= entropy {
+			return fmt.Sprintf("high-entropy string (%.1f bits/char)", h)
+		}
+	}
+	return ""
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// patchPath returns the path of a "+++ b/<path>" patch header, or "" for
+// /dev/null.
+func patchPath(s string) string {
+	if uq, err := strconv.Unquote(s); err == nil {
+		s = uq
+	}
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, "b/")
+}
+
+// hunkStart returns the first new-file line of a "@@ -a,b +c,d @@" header.
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+func matchesAnyPattern(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if MatchPathPattern(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanChanges runs the policy scan over the changes head makes against the
+// merge base with base. The secret scan reads every commit in head but not
+// base, so a secret committed and later removed still fails: it stays in the
+// history a fast-forward merge lands.
+func (e *Engineer) ScanChanges(base, head string, cfg ScanConfig) ([]ScanViolation, error) {
+	var violations []ScanViolation
+
+	if cfg.MaxFiles > 0 {
+		files, err := e.git.ChangedFiles(base, head)
+		if err != nil {
+			return nil, fmt.Errorf("listing changed files: %w", err)
+		}
+		if len(files) > cfg.MaxFiles {
+			violations = append(violations, ScanViolation{Kind: ScanDiffSize,
+				Detail: fmt.Sprintf("%d files changed, limit is %d", len(files), cfg.MaxFiles)})
+		}
+	}
+	if cfg.MaxDiffLines > 0 {
+		lines, err := e.git.DiffLines(base, head)
+		if err != nil {
+			return nil, fmt.Errorf("counting changed lines: %w", err)
+		}
+		if lines > cfg.MaxDiffLines {
+			violations = append(violations, ScanViolation{Kind: ScanDiffSize,
+				Detail: fmt.Sprintf("%d lines changed, limit is %d", lines, cfg.MaxDiffLines)})
+		}
+	}
+
+	stats, err := e.git.DiffStats(base, head)
+	if err != nil {
+		return nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	for _, st := range stats {
+		for _, p := range cfg.ForbiddenPaths {
+			if MatchPathPattern(p, st.Path) {
+				violations = append(violations, ScanViolation{Kind: ScanForbiddenPath, Path: st.Path,
+					Detail: fmt.Sprintf("matches forbidden pattern %q", p)})
+				break
+			}
+		}
+		if st.Binary && cfg.MaxBinaryBytes > 0 {
+			size, err := e.git.BlobSize(head, st.Path)
+			if err != nil {
+				return nil, fmt.Errorf("sizing %s: %w", st.Path, err)
+			}
+			if size > cfg.MaxBinaryBytes {
+				violations = append(violations, ScanViolation{Kind: ScanBinary, Path: st.Path,
+					Detail: fmt.Sprintf("binary file is %d bytes, limit is %d", size, cfg.MaxBinaryBytes)})
+			}
+		}
+	}
+
+	if cfg.Secrets {
+		patch, err := e.git.CommitPatches(base, head)
+		if err != nil {
+			return nil, fmt.Errorf("reading commits of %s: %w", head, err)
+		}
+		seen := make(map[ScanViolation]bool)
+		for _, v := range ScanPatch(patch, cfg) {
+			if !seen[v] {
+				seen[v] = true
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// runScan runs the scan gate for branch against target when merge_queue.scan
+// is configured. Violations fail the MR as a policy violation, which goes
+// back to the worker like a test failure.
+func (e *Engineer) runScan(branch, target string) ProcessResult {
+	if e.config.Scan == nil {
+		return ProcessResult{Success: true}
+	}
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: scanning %s\n", ScanGateName, branch)
+	start := time.Now()
+	violations, err := e.ScanChanges(target, branch, *e.config.Scan)
+	gate := GateResult{Name: ScanGateName, Elapsed: time.Since(start)}
+	if err != nil {
+		gate.Error = err.Error()
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: FAILED - %v\n", ScanGateName, err)
+		return ProcessResult{Error: fmt.Sprintf("policy scan failed: %v", err), Gates: []GateResult{gate}}
+	}
+	if len(violations) == 0 {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: passed (%v)\n", ScanGateName, gate.Elapsed.Truncate(time.Millisecond))
+		return ProcessResult{Success: true}
+	}
+
+	lines := make([]string, len(violations))
+	for i, v := range violations {
+		lines[i] = v.String()
+	}
+	gate.Output = strings.Join(lines, "\n") + "\n"
+	gate.Error = fmt.Sprintf("%d policy violation(s)", len(violations))
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Gate %q: FAILED - %s\n", ScanGateName, gate.Error)
+	for _, l := range lines {
+		_, _ = fmt.Fprintf(e.output, "  %s\n", l)
+	}
+
+	summary := lines
+	if len(summary) > 5 {
+		summary = append(summary[:5:5], fmt.Sprintf("and %d more", len(lines)-5))
+	}
+	return ProcessResult{
+		PolicyViolation: true,
+		Violations:      violations,
+		Error:           fmt.Sprintf("policy scan found %s: %s", gate.Error, strings.Join(summary, "; ")),
+		Gates:           []GateResult{gate},
+	}
+}
@@ -4,7 +4,10 @@
 package refinery
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
 )
 
 // ScoreConfig contains tunable weights for MR priority scoring.
@@ -37,6 +40,30 @@ type ScoreConfig struct {
 	// MaxRetryPenalty caps the total retry penalty to prevent permanent deprioritization.
 	// Default: 300.0 (after 6 retries, penalty is capped)
 	MaxRetryPenalty float64
+
+	// LabelBoosts maps MR labels to points added when the MR carries them.
+	// Negative boosts push labeled MRs back.
+	// Default: none
+	LabelBoosts map[string]float64
+
+	// RoleWeights maps the submitting agent's role (polecat, crew, ...) to
+	// points added for MRs from that role.
+	// Default: none
+	RoleWeights map[string]float64
+
+	// DiffSizeWeight is subtracted per 100 changed lines, so small MRs go
+	// ahead of large ones.
+	// Default: 0.0 (diff size ignored)
+	DiffSizeWeight float64
+
+	// MaxDiffSizePenalty caps the diff size penalty. Zero means no cap.
+	// Default: 0.0
+	MaxDiffSizePenalty float64
+
+	// DependentsWeight is points added per open issue blocked on the MR's
+	// source issue, so work that unblocks others lands first.
+	// Default: 0.0 (dependents ignored)
+	DependentsWeight float64
 }
 
 // DefaultScoreConfig returns sensible defaults for MR scoring.
@@ -69,11 +96,43 @@ type ScoreInput struct {
 	// 0 = first attempt.
 	RetryCount int
 
+	// Labels are the MR bead's labels, matched against LabelBoosts.
+	Labels []string
+
+	// AuthorRole is the role of the agent that submitted the MR (see
+	// AuthorRole). Empty if unknown.
+	AuthorRole string
+
+	// DiffLines is the number of lines the MR adds plus deletes.
+	DiffLines int
+
+	// Dependents is the number of open issues blocked on the MR's source issue.
+	Dependents int
+
 	// Now is the current time (for deterministic testing).
 	// If zero, time.Now() is used.
 	Now time.Time
 }
 
+// ScoreFactor is one term of an MR's score.
+type ScoreFactor struct {
+	Name   string  `json:"name"`
+	Detail string  `json:"detail,omitempty"`
+	Points float64 `json:"points"`
+}
+
+// ScoreBreakdown is an MR's score with the factors that make it up, in the
+// order they are applied.
+type ScoreBreakdown struct {
+	Factors []ScoreFactor `json:"factors"`
+	Total   float64       `json:"total"`
+}
+
+func (b *ScoreBreakdown) add(name, detail string, points float64) {
+	b.Factors = append(b.Factors, ScoreFactor{Name: name, Detail: detail, Points: points})
+	b.Total += points
+}
+
 // ScoreMR calculates the priority score for a merge request.
 // Higher scores mean higher priority (process first).
 //
@@ -84,20 +143,32 @@ type ScoreInput struct {
 //	      + PriorityWeight * (4 - priority)          // P0=+400, P4=+0
 //	      - min(RetryPenalty * retryCount, MaxRetryPenalty)  // Prevent thrashing
 //	      + MRAgeWeight * hoursOld(MR)               // FIFO tiebreaker
+//	      + sum(LabelBoosts[label])                  // Per-rig label policy
+//	      + RoleWeights[authorRole]                  // Per-rig author policy
+//	      - min(DiffSizeWeight * lines/100, MaxDiffSizePenalty)  // Small MRs first
+//	      + DependentsWeight * openDependents        // Unblock others first
 func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
+	return ExplainScore(input, config).Total
+}
+
+// ExplainScore calculates the priority score for a merge request like
+// ScoreMR, returning each factor's contribution. Factors that don't apply
+// (no convoy, unconfigured labels or roles, zero weights) are omitted.
+func ExplainScore(input ScoreInput, config ScoreConfig) ScoreBreakdown {
 	now := input.Now
 	if now.IsZero() {
 		now = time.Now()
 	}
 
-	score := config.BaseScore
+	var b ScoreBreakdown
+	b.add("base", "", config.BaseScore)
 
 	// Convoy age factor: prevent starvation of old convoys
 	if input.ConvoyCreatedAt != nil {
 		convoyAge := now.Sub(*input.ConvoyCreatedAt)
 		convoyHours := convoyAge.Hours()
 		if convoyHours > 0 {
-			score += config.ConvoyAgeWeight * convoyHours
+			b.add("convoy age", fmt.Sprintf("%.1fh × %g/h", convoyHours, config.ConvoyAgeWeight), config.ConvoyAgeWeight*convoyHours)
 		}
 	}
 
@@ -109,23 +180,119 @@ func ScoreMR(input ScoreInput, config ScoreConfig) float64 {
 	if priorityBonus > 4 {
 		priorityBonus = 4 // Clamp for invalid priorities < 0
 	}
-	score += config.PriorityWeight * float64(priorityBonus)
+	b.add("priority", fmt.Sprintf("P%d: %d × %g", input.Priority, priorityBonus, config.PriorityWeight), config.PriorityWeight*float64(priorityBonus))
 
 	// Retry penalty: prevent thrashing on repeatedly failing MRs
-	retryPenalty := config.RetryPenalty * float64(input.RetryCount)
-	if retryPenalty > config.MaxRetryPenalty {
-		retryPenalty = config.MaxRetryPenalty
+	if input.RetryCount > 0 {
+		retryPenalty := config.RetryPenalty * float64(input.RetryCount)
+		detail := fmt.Sprintf("%d retries × %g", input.RetryCount, config.RetryPenalty)
+		if retryPenalty > config.MaxRetryPenalty {
+			retryPenalty = config.MaxRetryPenalty
+			detail += fmt.Sprintf(", capped at %g", config.MaxRetryPenalty)
+		}
+		b.add("retries", detail, -retryPenalty)
 	}
-	score -= retryPenalty
 
 	// MR age factor: FIFO ordering as tiebreaker
 	mrAge := now.Sub(input.MRCreatedAt)
 	mrHours := mrAge.Hours()
 	if mrHours > 0 {
-		score += config.MRAgeWeight * mrHours
+		b.add("mr age", fmt.Sprintf("%.1fh × %g/h", mrHours, config.MRAgeWeight), config.MRAgeWeight*mrHours)
+	}
+
+	// Label boosts: per-rig policy (e.g. hotfix, blocked-release)
+	seen := make(map[string]bool, len(input.Labels))
+	for _, label := range input.Labels {
+		boost, ok := config.LabelBoosts[label]
+		if !ok || seen[label] {
+			continue
+		}
+		seen[label] = true
+		b.add("label", label, boost)
+	}
+
+	// Author role: per-rig policy (e.g. favour crew over polecats)
+	if weight, ok := config.RoleWeights[input.AuthorRole]; ok && input.AuthorRole != "" {
+		b.add("author role", input.AuthorRole, weight)
+	}
+
+	// Diff size: small MRs first
+	if config.DiffSizeWeight != 0 {
+		penalty := config.DiffSizeWeight * float64(input.DiffLines) / 100
+		detail := fmt.Sprintf("%d lines × %g/100", input.DiffLines, config.DiffSizeWeight)
+		if config.MaxDiffSizePenalty > 0 && penalty > config.MaxDiffSizePenalty {
+			penalty = config.MaxDiffSizePenalty
+			detail += fmt.Sprintf(", capped at %g", config.MaxDiffSizePenalty)
+		}
+		b.add("diff size", detail, -penalty)
+	}
+
+	// Dependents: work that unblocks other issues first
+	if config.DependentsWeight != 0 {
+		b.add("dependents", fmt.Sprintf("%d open × %g", input.Dependents, config.DependentsWeight), config.DependentsWeight*float64(input.Dependents))
 	}
 
-	return score
+	return b
+}
+
+// AuthorRole returns the role of the agent bead that submitted an MR
+// (polecat, crew, mayor, ...), or "" if agentBead isn't an agent bead ID.
+func AuthorRole(agentBead string) string {
+	if agentBead == "" {
+		return ""
+	}
+	_, role, _, ok := beads.ParseAgentBeadID(agentBead)
+	if !ok {
+		return ""
+	}
+	return role
+}
+
+// CountOpenDependents returns how many of issue's dependents are still open
+// and depend on it with a blocking dependency. issue must come from Show,
+// which populates Dependents.
+func CountOpenDependents(issue *beads.Issue) int {
+	n := 0
+	for _, dep := range issue.Dependents {
+		if dep.Status == "closed" || dep.Status == "tombstone" {
+			continue
+		}
+		if dep.DependencyType != "" && dep.DependencyType != "blocks" {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// ScoreInputFromIssue builds the score input for an MR bead. fields may be
+// nil. DiffLines and Dependents need git and beads lookups and are left
+// for the caller to fill in.
+func ScoreInputFromIssue(issue *beads.Issue, fields *beads.MRFields, now time.Time) ScoreInput {
+	mrCreatedAt := parseTime(issue.CreatedAt)
+	if mrCreatedAt.IsZero() {
+		mrCreatedAt = now // Fallback
+	}
+
+	input := ScoreInput{
+		Priority:    issue.Priority,
+		MRCreatedAt: mrCreatedAt,
+		Labels:      issue.Labels,
+		Now:         now,
+	}
+
+	if fields != nil {
+		input.RetryCount = fields.RetryCount
+		input.AuthorRole = AuthorRole(fields.AgentBead)
+
+		// Parse convoy created at if available
+		if fields.ConvoyCreatedAt != "" {
+			if convoyTime := parseTime(fields.ConvoyCreatedAt); !convoyTime.IsZero() {
+				input.ConvoyCreatedAt = &convoyTime
+			}
+		}
+	}
+	return input
 }
 
 // ScoreMRWithDefaults is a convenience wrapper using default config.
@@ -141,12 +308,60 @@ func (mr *MRInfo) Score() float64 {
 
 // ScoreAt calculates the priority score at a specific time (for deterministic testing).
 func (mr *MRInfo) ScoreAt(now time.Time) float64 {
-	input := ScoreInput{
+	return ScoreMRWithDefaults(mr.ScoreInput(now))
+}
+
+// ScoreWith calculates the priority score at now under a rig's scoring policy.
+func (mr *MRInfo) ScoreWith(config ScoreConfig, now time.Time) float64 {
+	return ScoreMR(mr.ScoreInput(now), config)
+}
+
+// ScoreInput returns the score input for this MR at now.
+func (mr *MRInfo) ScoreInput(now time.Time) ScoreInput {
+	return ScoreInput{
 		Priority:        mr.Priority,
 		MRCreatedAt:     mr.CreatedAt,
 		ConvoyCreatedAt: mr.ConvoyCreatedAt,
 		RetryCount:      mr.RetryCount,
+		Labels:          mr.Labels,
+		AuthorRole:      AuthorRole(mr.AgentBead),
+		DiffLines:       mr.DiffLines,
+		Dependents:      mr.Dependents,
 		Now:             now,
 	}
-	return ScoreMRWithDefaults(input)
+}
+
+// ScoreIssue explains the score of an MR bead under the rig's scoring
+// policy. Diff size and dependents are only looked up when weighted.
+func (e *Engineer) ScoreIssue(issue *beads.Issue, fields *beads.MRFields, now time.Time) ScoreBreakdown {
+	input := ScoreInputFromIssue(issue, fields, now)
+	if fields != nil {
+		input.DiffLines, input.Dependents = e.scoreLookups(fields.Target, fields.Branch, fields.SourceIssue)
+	}
+	return ExplainScore(input, e.config.Scoring)
+}
+
+// fillScoreLookups sets the weighted git and beads derived score inputs of mrs.
+func (e *Engineer) fillScoreLookups(mrs []*MRInfo) {
+	for _, mr := range mrs {
+		mr.DiffLines, mr.Dependents = e.scoreLookups(mr.Target, mr.Branch, mr.SourceIssue)
+	}
+}
+
+// scoreLookups returns an MR's changed line count and open dependents of its
+// source issue, each only when the scoring policy weights it. Lookup
+// failures count as zero.
+func (e *Engineer) scoreLookups(target, branch, sourceIssue string) (diffLines, dependents int) {
+	cfg := e.config.Scoring
+	if cfg.DiffSizeWeight != 0 && target != "" && branch != "" {
+		if n, err := e.git.DiffLines(target, branch); err == nil {
+			diffLines = n
+		}
+	}
+	if cfg.DependentsWeight != 0 && sourceIssue != "" && e.beads != nil {
+		if issue, err := e.beads.Show(sourceIssue); err == nil {
+			dependents = CountOpenDependents(issue)
+		}
+	}
+	return diffLines, dependents
 }
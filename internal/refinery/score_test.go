@@ -0,0 +1,84 @@
+package refinery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScoreMRDefaults(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	convoy := now.Add(-10 * time.Hour)
+	input := ScoreInput{
+		Priority:        1,
+		MRCreatedAt:     now.Add(-2 * time.Hour),
+		ConvoyCreatedAt: &convoy,
+		RetryCount:      8,
+		Labels:          []string{"hotfix"},
+		DiffLines:       5000,
+		Now:             now,
+	}
+	// 1000 + 10*10 (convoy) + 100*3 (P1) - 300 (capped retries) + 2 (MR age).
+	// Labels and diff size carry no weight by default.
+	if got := ScoreMRWithDefaults(input); got != 1102 {
+		t.Errorf("ScoreMRWithDefaults = %v, want 1102", got)
+	}
+}
+
+func TestExplainScore(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultScoreConfig()
+	cfg.LabelBoosts = map[string]float64{"hotfix": 500, "wip": -200}
+	cfg.RoleWeights = map[string]float64{"crew": 50}
+	cfg.DiffSizeWeight = 10
+	cfg.MaxDiffSizePenalty = 100
+	cfg.DependentsWeight = 20
+
+	input := ScoreInput{
+		Priority:    2,
+		MRCreatedAt: now,
+		RetryCount:  1,
+		Labels:      []string{"hotfix", "gt:merge-request", "hotfix"},
+		AuthorRole:  "crew",
+		DiffLines:   3000,
+		Dependents:  3,
+		Now:         now,
+	}
+	b := ExplainScore(input, cfg)
+
+	want := map[string]float64{
+		"base":        1000,
+		"priority":    200,
+		"retries":     -50,
+		"label":       500,
+		"author role": 50,
+		"diff size":   -100, // 300 capped at 100
+		"dependents":  60,
+	}
+	if len(b.Factors) != len(want) {
+		t.Fatalf("got factors %+v, want %d", b.Factors, len(want))
+	}
+	sum := 0.0
+	for _, f := range b.Factors {
+		if f.Points != want[f.Name] {
+			t.Errorf("%s = %v, want %v", f.Name, f.Points, want[f.Name])
+		}
+		sum += f.Points
+	}
+	if b.Total != sum || b.Total != ScoreMR(input, cfg) {
+		t.Errorf("Total = %v, want %v (ScoreMR %v)", b.Total, sum, ScoreMR(input, cfg))
+	}
+}
+
+func TestAuthorRole(t *testing.T) {
+	tests := map[string]string{
+		"gt-gastown-polecat-nux": "polecat",
+		"gt-mayor":               "mayor",
+		"":                       "",
+		"x":                      "",
+	}
+	for id, want := range tests {
+		if got := AuthorRole(id); got != want {
+			t.Errorf("AuthorRole(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package refinery
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Test report formats a gate can declare (GateConfig.Report).
+const (
+	ReportJUnit      = "junit"        // JUnit XML
+	ReportGoTestJSON = "go-test-json" // go test -json event stream
+	ReportTAP        = "tap"          // Test Anything Protocol
+)
+
+// ValidReportFormat reports whether format is a supported test report format.
+func ValidReportFormat(format string) bool {
+	switch format {
+	case ReportJUnit, ReportGoTestJSON, ReportTAP:
+		return true
+	}
+	return false
+}
+
+// ParseFailedTests returns the names of the failed tests in a test report,
+// sorted and de-duplicated. Reports may be partial (output is capped at
+// MaxGateOutputBytes): unparseable lines are skipped where the format allows.
+func ParseFailedTests(format string, data []byte) ([]string, error) {
+	var failed []string
+	var err error
+	switch format {
+	case ReportJUnit:
+		failed, err = parseJUnitFailures(data)
+	case ReportGoTestJSON:
+		failed = parseGoTestJSONFailures(data)
+	case ReportTAP:
+		failed = parseTAPFailures(data)
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dedupeSorted(failed), nil
+}
+
+type junitTestCase struct {
+	Name      string    `xml:"name,attr"`
+	ClassName string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+}
+
+type junitSuite struct {
+	Suites    []junitSuite    `xml:"testsuite"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// parseJUnitFailures handles both a <testsuites> root and a bare <testsuite>.
+func parseJUnitFailures(data []byte) ([]string, error) {
+	var root junitSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing JUnit XML: %w", err)
+	}
+	var failed []string
+	var walk func(s junitSuite)
+	walk = func(s junitSuite) {
+		for _, tc := range s.TestCases {
+			if tc.Failure == nil && tc.Error == nil {
+				continue
+			}
+			name := tc.Name
+			if tc.ClassName != "" {
+				name = tc.ClassName + "." + tc.Name
+			}
+			failed = append(failed, name)
+		}
+		for _, child := range s.Suites {
+			walk(child)
+		}
+	}
+	walk(root)
+	return failed, nil
+}
+
+// parseGoTestJSONFailures reads go test -json events; a test's last
+// pass/fail action decides its result.
+func parseGoTestJSONFailures(data []byte) []string {
+	results := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxGateOutputBytes)
+	for scanner.Scan() {
+		var ev struct {
+			Action  string
+			Package string
+			Test    string
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil || ev.Test == "" {
+			continue
+		}
+		if ev.Action == "pass" || ev.Action == "fail" || ev.Action == "skip" {
+			results[ev.Package+"."+ev.Test] = ev.Action
+		}
+	}
+	var failed []string
+	for name, action := range results {
+		if action == "fail" {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+var tapNotOK = regexp.MustCompile(`^not ok\b\s*(\d*)\s*(?:-\s*)?([^#]*)(#.*)?$`)
+
+// parseTAPFailures reads "not ok" lines, ignoring TODO and SKIP directives.
+func parseTAPFailures(data []byte) []string {
+	var failed []string
+	for _, line := range strings.Split(string(data), "\n") {
+		m := tapNotOK.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		directive := strings.ToUpper(m[3])
+		if strings.Contains(directive, "TODO") || strings.Contains(directive, "SKIP") {
+			continue
+		}
+		name := strings.TrimSpace(m[2])
+		if name == "" {
+			name = "test " + m[1]
+		}
+		failed = append(failed, name)
+	}
+	return failed
+}
+
+func dedupeSorted(names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	out := names[:1]
+	for _, n := range names[1:] {
+		if n != out[len(out)-1] {
+			out = append(out, n)
+		}
+	}
+	return out
+}
@@ -0,0 +1,79 @@
+package refinery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFailedTests(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []string
+	}{
+		{
+			name:   "junit suites",
+			format: ReportJUnit,
+			data: `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="a">
+    <testcase classname="pkg.A" name="TestOK"/>
+    <testcase classname="pkg.A" name="TestBad"><failure message="boom"/></testcase>
+  </testsuite>
+  <testsuite name="b">
+    <testcase name="TestErr"><error/></testcase>
+    <testcase name="TestSkip"><skipped/></testcase>
+  </testsuite>
+</testsuites>`,
+			want: []string{"TestErr", "pkg.A.TestBad"},
+		},
+		{
+			name:   "junit bare suite",
+			format: ReportJUnit,
+			data:   `<testsuite><testcase name="TestX"><failure/></testcase></testsuite>`,
+			want:   []string{"TestX"},
+		},
+		{
+			name:   "go test json",
+			format: ReportGoTestJSON,
+			data: `# building
+{"Action":"run","Package":"p","Test":"TestA"}
+{"Action":"fail","Package":"p","Test":"TestA"}
+{"Action":"run","Package":"p","Test":"TestB"}
+{"Action":"pass","Package":"p","Test":"TestB"}
+{"Action":"fail","Package":"p"}
+{"Action":"fail","Package":"q","Test":"TestC/sub"}
+`,
+			want: []string{"p.TestA", "q.TestC/sub"},
+		},
+		{
+			name:   "tap",
+			format: ReportTAP,
+			data: `TAP version 13
+1..5
+ok 1 - adds
+not ok 2 - subtracts
+not ok 3 - divides # TODO not yet
+not ok 4 # SKIP no network
+not ok 5
+`,
+			want: []string{"subtracts", "test 5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFailedTests(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFailedTests = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseFailedTests("xunit", nil); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
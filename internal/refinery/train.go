@@ -0,0 +1,311 @@
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// trainBranch is the temporary local ref a merge train is stacked on.
+const trainBranch = "refinery/train"
+
+// TrainOutcome is the result of a merge train for one of its MRs.
+type TrainOutcome struct {
+	MR     *MRInfo
+	Result ProcessResult
+
+	// Requeued is set for MRs that were neither landed nor at fault: they
+	// rode behind a culprit, or their pull request is still pending on the
+	// forge, and go back to the queue for the next train.
+	Requeued bool
+}
+
+// TrainSize returns how many MRs a merge train carries: MaxConcurrent in
+// merge-train mode, otherwise 1 (one MR at a time).
+func (c *MergeQueueConfig) TrainSize() int {
+	if !c.MergeTrain || c.MaxConcurrent < 1 {
+		return 1
+	}
+	return c.MaxConcurrent
+}
+
+// SelectTrain picks the MRs for the next train from ready: highest ScoreMR
+// score under the rig's scoring policy first, limited to the top MR's target branch and to TrainSize.
+// MRs predicted to conflict with one already picked are held back.
+func (e *Engineer) SelectTrain(ready []*MRInfo, now time.Time) []*MRInfo {
+	if len(ready) == 0 {
+		return nil
+	}
+	sorted := make([]*MRInfo, len(ready))
+	copy(sorted, ready)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].ScoreWith(e.config.Scoring, now) > sorted[j].ScoreWith(e.config.Scoring, now)
+	})
+
+	target := sorted[0].Target
+	size := e.config.TrainSize()
+	var train []*MRInfo
+	for _, mr := range sorted {
+		if mr.Target != target || e.conflictsWithAny(mr, train) {
+			continue
+		}
+		train = append(train, mr)
+		if len(train) == size {
+			break
+		}
+	}
+	return train
+}
+
+// conflictsWithAny reports whether mr is predicted to conflict with any of
+// mrs (see PredictConflicts); such MRs are held back for a later train.
+func (e *Engineer) conflictsWithAny(mr *MRInfo, mrs []*MRInfo) bool {
+	for _, other := range mrs {
+		if e.conflicts.ConflictsWith(mr.ID, other.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessTrain merges mrs, which must share a target and be in queue order,
+// as one speculative batch. The MRs are squash-merged one after another onto
+// a temporary integration ref and the quality gates run once on the result;
+// if they pass, the whole batch lands with a single push.
+//
+// If the gates fail, the batch is bisected over its prefixes to find the
+// first MR that breaks them. That MR fails, the MRs ahead of it (a prefix
+// that passed the gates) land, and the MRs behind it are requeued. MRs that
+// don't stack cleanly fail as conflicts without stopping the train.
+//
+// A one-MR train takes the regular ProcessMRInfo path, as does every MR in
+// pull_request merge mode, where the forge merges each pull request. MRs
+// whose pull request is still waiting on the forge are requeued.
+func (e *Engineer) ProcessTrain(ctx context.Context, mrs []*MRInfo) []TrainOutcome {
+	if len(mrs) == 0 {
+		return nil
+	}
+	if len(mrs) == 1 || e.config.MergeMode == MergeModePullRequest {
+		outcomes := make([]TrainOutcome, len(mrs))
+		for i, mr := range mrs {
+			result := e.ProcessMRInfo(ctx, mr)
+			outcomes[i] = TrainOutcome{MR: mr, Result: result, Requeued: result.Pending}
+		}
+		return outcomes
+	}
+
+	target := mrs[0].Target
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merge train: %d MR(s) into %s\n", len(mrs), target)
+	for _, mr := range mrs {
+		_, _ = fmt.Fprintf(e.output, "  %s (%s)\n", mr.ID, mr.Branch)
+	}
+	defer e.cleanupTrain(target)
+	e.bypassGateCache = e.consumeGateCacheBypass(mrs...)
+	defer func() { e.bypassGateCache = false }()
+
+	if err := e.git.Checkout(target); err != nil {
+		return trainFailure(mrs, ProcessResult{Error: fmt.Sprintf("failed to checkout target %s: %v", target, err)})
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+
+	// Stack every MR once; those that don't apply are dropped from the train.
+	cars, commits, outcomes := e.buildTrain(target, mrs)
+	if len(cars) == 0 {
+		return outcomes
+	}
+
+	// Bisect probes rerun the gates on overlapping prefixes. Count a flaky
+	// test once, from the run that decides the train.
+	e.deferFlakes = true
+	defer func() { e.deferFlakes = false }()
+
+	result := e.runChecks(ctx)
+	if result.Success {
+		e.recordFlakes(result.Gates)
+		return append(outcomes, e.landTrain(ctx, target, cars, commits)...)
+	}
+
+	// Bisect: prefix lo passes (the empty prefix trivially), prefix hi fails.
+	lo, hi := 0, len(cars)
+	failure := result
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Merge train failed; bisecting with first %d of %d MR(s)\n", mid, hi)
+		prefix, _, _ := e.buildTrain(target, cars[:mid])
+		if len(prefix) != mid {
+			// A prefix of a clean stack always re-stacks; treat anything
+			// else as a failure of this probe.
+			hi = mid
+			continue
+		}
+		if r := e.runChecks(ctx); r.Success {
+			lo = mid
+		} else {
+			hi, failure = mid, r
+		}
+	}
+
+	e.recordFlakes(failure.Gates)
+	culprit := cars[hi-1]
+	failure.Success = false
+	failure.TestsFailed = true
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merge train culprit: %s (%s)\n", culprit.ID, culprit.Branch)
+	outcomes = append(outcomes, TrainOutcome{MR: culprit, Result: failure})
+
+	if lo > 0 {
+		passed, passedCommits, _ := e.buildTrain(target, cars[:lo])
+		outcomes = append(outcomes, e.landTrain(ctx, target, passed, passedCommits)...)
+	}
+	for _, mr := range cars[hi:] {
+		outcomes = append(outcomes, TrainOutcome{
+			MR:       mr,
+			Requeued: true,
+			Result:   ProcessResult{Error: fmt.Sprintf("requeued: merge train failed at %s", culprit.ID)},
+		})
+	}
+	return outcomes
+}
+
+// buildTrain resets trainBranch to target and squash-merges mrs onto it in
+// order. It returns the MRs that stacked cleanly with the commit each one
+// produced, plus failure outcomes for the MRs that did not.
+func (e *Engineer) buildTrain(target string, mrs []*MRInfo) ([]*MRInfo, []string, []TrainOutcome) {
+	if err := e.git.Checkout(target); err != nil {
+		return nil, nil, trainFailure(mrs, ProcessResult{Error: fmt.Sprintf("failed to checkout target %s: %v", target, err)})
+	}
+	_ = e.git.DeleteBranch(trainBranch, true)
+	if err := e.git.CreateBranchFrom(trainBranch, target); err != nil {
+		return nil, nil, trainFailure(mrs, ProcessResult{Error: fmt.Sprintf("failed to create %s: %v", trainBranch, err)})
+	}
+
+	var cars []*MRInfo
+	var commits []string
+	var outcomes []TrainOutcome
+	for _, mr := range mrs {
+		if result := e.stackMR(mr, target); !result.Success {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Dropping %s from merge train: %s\n", mr.ID, result.Error)
+			outcomes = append(outcomes, TrainOutcome{MR: mr, Result: result})
+			continue
+		}
+		sha, err := e.git.Rev("HEAD")
+		if err != nil {
+			outcomes = append(outcomes, TrainOutcome{MR: mr, Result: ProcessResult{Error: fmt.Sprintf("failed to get merge commit SHA: %v", err)}})
+			continue
+		}
+		cars = append(cars, mr)
+		commits = append(commits, sha)
+	}
+	return cars, commits, outcomes
+}
+
+// stackMR squash-merges one MR onto trainBranch, leaving trainBranch checked
+// out and unchanged if it does not apply.
+func (e *Engineer) stackMR(mr *MRInfo, target string) ProcessResult {
+	exists, err := e.git.BranchExists(mr.Branch)
+	if err != nil {
+		return ProcessResult{Error: fmt.Sprintf("failed to check branch %s: %v", mr.Branch, err)}
+	}
+	if !exists {
+		return ProcessResult{Error: fmt.Sprintf("branch %s not found locally", mr.Branch)}
+	}
+
+	conflicts, err := e.git.CheckConflicts(mr.Branch, trainBranch)
+	if err != nil {
+		return ProcessResult{Conflict: true, Error: fmt.Sprintf("conflict check failed: %v", err)}
+	}
+	if len(conflicts) > 0 {
+		return ProcessResult{Conflict: true, Error: fmt.Sprintf("merge conflicts in: %v", conflicts)}
+	}
+
+	if result := e.runScan(mr.Branch, target); !result.Success {
+		return result
+	}
+
+	if result := e.pushSubmoduleChanges(mr.Branch, target); !result.Success {
+		return result
+	}
+
+	msg, err := e.git.GetBranchCommitMessage(mr.Branch)
+	if err != nil {
+		msg = fmt.Sprintf("Squash merge %s into %s", mr.Branch, target)
+		if mr.SourceIssue != "" {
+			msg = fmt.Sprintf("Squash merge %s into %s (%s)", mr.Branch, target, mr.SourceIssue)
+		}
+	}
+	if err := e.git.MergeSquash(mr.Branch, strings.TrimSpace(msg)); err != nil {
+		conflicts, conflictErr := e.git.GetConflictingFiles()
+		_ = e.git.ResetHard("HEAD")
+		if conflictErr == nil && len(conflicts) > 0 {
+			return ProcessResult{Conflict: true, Error: "merge conflict during actual merge"}
+		}
+		return ProcessResult{Error: fmt.Sprintf("merge failed: %v", err)}
+	}
+	return ProcessResult{Success: true}
+}
+
+// landTrain fast-forwards target to the built trainBranch and pushes it.
+func (e *Engineer) landTrain(ctx context.Context, target string, cars []*MRInfo, commits []string) []TrainOutcome {
+	if len(cars) == 0 {
+		return nil
+	}
+	if err := e.git.Checkout(target); err != nil {
+		return trainFailure(cars, ProcessResult{Error: fmt.Sprintf("failed to checkout target %s: %v", target, err)})
+	}
+	if err := e.git.Merge(trainBranch); err != nil {
+		_ = e.git.ResetHard("origin/" + target)
+		return trainFailure(cars, ProcessResult{Error: fmt.Sprintf("failed to fast-forward %s to merge train: %v", target, err)})
+	}
+	if result := e.pushTarget(ctx, target); !result.Success {
+		return trainFailure(cars, result)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Merge train landed %d MR(s) on %s\n", len(cars), target)
+	outcomes := make([]TrainOutcome, len(cars))
+	for i, mr := range cars {
+		outcomes[i] = TrainOutcome{MR: mr, Result: ProcessResult{Success: true, MergeCommit: commits[i]}}
+	}
+	return outcomes
+}
+
+// cleanupTrain leaves target checked out and deletes trainBranch.
+func (e *Engineer) cleanupTrain(target string) {
+	if err := e.git.Checkout(target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to checkout %s after merge train: %v\n", target, err)
+	}
+	_ = e.git.DeleteBranch(trainBranch, true)
+}
+
+// trainFailure gives every MR in mrs the same failed result.
+func trainFailure(mrs []*MRInfo, result ProcessResult) []TrainOutcome {
+	result.Success = false
+	outcomes := make([]TrainOutcome, len(mrs))
+	for i, mr := range mrs {
+		outcomes[i] = TrainOutcome{MR: mr, Result: result}
+	}
+	return outcomes
+}
+
+// HandleTrainOutcomes applies train results to the queue: landed MRs go
+// through HandleMRInfoSuccess, failed ones through HandleMRInfoFailure, and
+// requeued ones are released for the next train.
+func (e *Engineer) HandleTrainOutcomes(outcomes []TrainOutcome) {
+	for _, o := range outcomes {
+		switch {
+		case o.Result.Success:
+			e.HandleMRInfoSuccess(o.MR, o.Result)
+		case o.Requeued:
+			_, _ = fmt.Fprintf(e.output, "[Engineer] ↻ Requeued: %s - %s\n", o.MR.ID, o.Result.Error)
+			if o.MR.ID != "" {
+				if err := e.ReleaseMR(o.MR.ID); err != nil {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to release MR %s: %v\n", o.MR.ID, err)
+				}
+			}
+		default:
+			e.HandleMRInfoFailure(o.MR, o.Result)
+		}
+	}
+}
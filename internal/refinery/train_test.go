@@ -0,0 +1,211 @@
+package refinery
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@test",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@test")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+// newTrainEngineer sets up an origin repo with a main branch, a refinery
+// clone, and an Engineer whose single gate fails when bad.txt exists.
+func newTrainEngineer(t *testing.T) (*Engineer, string) {
+	t.Helper()
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	work := filepath.Join(root, "work")
+	runGit(t, root, "init", "--bare", "-b", "main", origin)
+	runGit(t, root, "clone", origin, work)
+	runGit(t, work, "config", "user.name", "test")
+	runGit(t, work, "config", "user.email", "test@test")
+	runGit(t, work, "checkout", "-b", "main")
+	writeTrainFile(t, work, "README", "base\n")
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-m", "base")
+	runGit(t, work, "push", "-u", "origin", "main")
+
+	cfg := DefaultMergeQueueConfig()
+	cfg.MergeTrain = true
+	cfg.MaxConcurrent = 4
+	cfg.Gates = map[string]*GateConfig{"check": {Cmd: "test ! -f bad.txt"}}
+	e := &Engineer{
+		rig:     &rig.Rig{Name: "testrig", Path: root},
+		git:     git.NewGit(work),
+		config:  cfg,
+		workDir: work,
+		output:  io.Discard,
+		mergeSlotEnsureExists: func() (string, error) {
+			return "merge-slot", nil
+		},
+		mergeSlotAcquire: func(holder string, _ bool) (*beads.MergeSlotStatus, error) {
+			return &beads.MergeSlotStatus{ID: "merge-slot", Available: true, Holder: holder}, nil
+		},
+		mergeSlotRelease: func(string) error { return nil },
+	}
+	return e, work
+}
+
+func writeTrainFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addMRBranch creates a branch off main that writes one file.
+func addMRBranch(t *testing.T, work, branch, file, content string) *MRInfo {
+	t.Helper()
+	runGit(t, work, "checkout", "-q", "-b", branch, "main")
+	writeTrainFile(t, work, file, content)
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "-q", "-m", "feat: "+branch)
+	runGit(t, work, "checkout", "-q", "main")
+	return &MRInfo{ID: "mr-" + branch, Branch: branch, Target: "main"}
+}
+
+func originFiles(t *testing.T, work string) string {
+	t.Helper()
+	runGit(t, work, "fetch", "-q", "origin")
+	return runGit(t, work, "ls-tree", "--name-only", "origin/main")
+}
+
+func outcomesByID(outcomes []TrainOutcome) map[string]TrainOutcome {
+	m := make(map[string]TrainOutcome)
+	for _, o := range outcomes {
+		m[o.MR.ID] = o
+	}
+	return m
+}
+
+func TestProcessTrain_LandsWholeBatch(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	mrs := []*MRInfo{
+		addMRBranch(t, work, "polecat/a", "a.txt", "a\n"),
+		addMRBranch(t, work, "polecat/b", "b.txt", "b\n"),
+		addMRBranch(t, work, "polecat/c", "c.txt", "c\n"),
+	}
+
+	outcomes := outcomesByID(e.ProcessTrain(context.Background(), mrs))
+	for _, mr := range mrs {
+		o := outcomes[mr.ID]
+		if !o.Result.Success || o.Result.MergeCommit == "" {
+			t.Errorf("%s: %+v, want landed", mr.ID, o)
+		}
+	}
+	if got := originFiles(t, work); got != "README\na.txt\nb.txt\nc.txt\n" {
+		t.Errorf("origin/main files = %q", got)
+	}
+	if exists, _ := e.git.BranchExists(trainBranch); exists {
+		t.Error("train branch was not cleaned up")
+	}
+}
+
+func TestProcessTrain_BisectsCulprit(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	mrs := []*MRInfo{
+		addMRBranch(t, work, "polecat/a", "a.txt", "a\n"),
+		addMRBranch(t, work, "polecat/bad", "bad.txt", "bad\n"),
+		addMRBranch(t, work, "polecat/c", "c.txt", "c\n"),
+		addMRBranch(t, work, "polecat/clash", "a.txt", "other\n"),
+	}
+
+	outcomes := outcomesByID(e.ProcessTrain(context.Background(), mrs))
+	if o := outcomes["mr-polecat/a"]; !o.Result.Success {
+		t.Errorf("a: %+v, want landed", o)
+	}
+	if o := outcomes["mr-polecat/bad"]; o.Result.Success || o.Requeued || !o.Result.TestsFailed {
+		t.Errorf("bad: %+v, want culprit", o)
+	}
+	if o := outcomes["mr-polecat/c"]; !o.Requeued {
+		t.Errorf("c: %+v, want requeued", o)
+	}
+	if o := outcomes["mr-polecat/clash"]; !o.Result.Conflict {
+		t.Errorf("clash: %+v, want conflict", o)
+	}
+	if got := originFiles(t, work); got != "README\na.txt\n" {
+		t.Errorf("origin/main files = %q", got)
+	}
+}
+
+func TestSelectTrain(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ready := []*MRInfo{
+		{ID: "low", Target: "main", Priority: 4, CreatedAt: now},
+		{ID: "top", Target: "main", Priority: 0, CreatedAt: now},
+		{ID: "other-target", Target: "integration/x", Priority: 1, CreatedAt: now},
+		{ID: "mid", Target: "main", Priority: 2, CreatedAt: now},
+	}
+
+	e := &Engineer{config: DefaultMergeQueueConfig()}
+	if got := e.SelectTrain(ready, now); len(got) != 1 || got[0].ID != "top" {
+		t.Errorf("without merge train: %v", got)
+	}
+
+	e.config.MergeTrain = true
+	e.config.MaxConcurrent = 2
+	got := e.SelectTrain(ready, now)
+	if len(got) != 2 || got[0].ID != "top" || got[1].ID != "mid" {
+		t.Errorf("merge train of 2 = %v, want [top mid]", got)
+	}
+
+	// A predicted conflict with a car already aboard holds the MR back.
+	p := &ConflictPrediction{Conflicts: map[string][]string{}}
+	p.addConflict("top", "mid")
+	e.SetConflictPrediction(p)
+	got = e.SelectTrain(ready, now)
+	if len(got) != 2 || got[0].ID != "top" || got[1].ID != "low" {
+		t.Errorf("merge train with top/mid conflict = %v, want [top low]", got)
+	}
+}
+
+func TestProcessTrain_BisectRecordsFlakesOnce(t *testing.T) {
+	e, work := newTrainEngineer(t)
+	e.config.RetryFlakyTests = 2
+	// "a-flaky" sorts first, so it runs on every probe: each run fails its
+	// first attempt and passes the retry.
+	marker := filepath.Join(t.TempDir(), "attempted")
+	e.config.Gates["a-flaky"] = &GateConfig{
+		Cmd:    `if [ -f ` + marker + ` ]; then rm ` + marker + `; echo "ok 1 - TestFlaky"; else touch ` + marker + `; echo "not ok 1 - TestFlaky"; exit 1; fi`,
+		Report: ReportTAP,
+	}
+	mrs := []*MRInfo{
+		addMRBranch(t, work, "polecat/a", "a.txt", "a\n"),
+		addMRBranch(t, work, "polecat/b", "b.txt", "b\n"),
+		addMRBranch(t, work, "polecat/c", "c.txt", "c\n"),
+		addMRBranch(t, work, "polecat/bad", "bad.txt", "bad\n"),
+	}
+
+	outcomes := outcomesByID(e.ProcessTrain(context.Background(), mrs))
+	if o := outcomes["mr-polecat/bad"]; o.Result.Success || o.Requeued {
+		t.Fatalf("bad: %+v, want culprit", o)
+	}
+
+	history, err := LoadFlakeHistory(e.rig.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := history.Tests["a-flaky/TestFlaky"]; rec == nil || rec.Flakes != 1 {
+		t.Errorf("flake record = %+v, want 1 flake for the whole train", rec)
+	}
+}
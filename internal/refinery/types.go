@@ -67,6 +67,10 @@ const (
 	// MRPhaseReady means the MR is queued and available for claiming.
 	MRPhaseReady MRPhase = "ready"
 
+	// MRPhaseAwaitingApproval means the MR touches paths that require human
+	// sign-off (merge_queue.approvals) and is held until approved.
+	MRPhaseAwaitingApproval MRPhase = "awaiting-approval"
+
 	// MRPhaseClaimed means a refinery instance has claimed the MR for processing.
 	MRPhaseClaimed MRPhase = "claimed"
 
@@ -91,12 +95,13 @@ const (
 
 // ValidPhaseTransitions defines the allowed state transitions for MR phases.
 var ValidPhaseTransitions = map[MRPhase][]MRPhase{
-	MRPhaseReady:     {MRPhaseClaimed},
-	MRPhaseClaimed:   {MRPhasePreparing, MRPhaseReady},
-	MRPhasePreparing: {MRPhasePrepared, MRPhaseFailed},
-	MRPhasePrepared:  {MRPhaseMerging, MRPhaseRejected, MRPhaseReady},
-	MRPhaseMerging:   {MRPhaseMerged, MRPhaseFailed},
-	MRPhaseFailed:    {MRPhaseReady},
+	MRPhaseReady:            {MRPhaseClaimed, MRPhaseAwaitingApproval},
+	MRPhaseAwaitingApproval: {MRPhaseReady, MRPhaseRejected},
+	MRPhaseClaimed:          {MRPhasePreparing, MRPhaseReady, MRPhaseAwaitingApproval},
+	MRPhasePreparing:        {MRPhasePrepared, MRPhaseFailed},
+	MRPhasePrepared:         {MRPhaseMerging, MRPhaseRejected, MRPhaseReady},
+	MRPhaseMerging:          {MRPhaseMerged, MRPhaseFailed},
+	MRPhaseFailed:           {MRPhaseReady},
 	// Terminal states: MRPhaseMerged, MRPhaseRejected (no transitions out)
 }
 
@@ -277,6 +282,10 @@ const (
 
 	// FailureCheckout indicates checkout of target branch failed.
 	FailureCheckout FailureType = "checkout_fail"
+
+	// FailurePolicyViolation indicates the scan gate found secrets, forbidden
+	// paths, oversized binaries or an oversized diff.
+	FailurePolicyViolation FailureType = "policy_violation"
 )
 
 // FailureLabel returns the beads label for this failure type.
@@ -284,7 +293,7 @@ func (f FailureType) FailureLabel() string {
 	switch f {
 	case FailureConflict:
 		return "needs-rebase"
-	case FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailurePolicyViolation:
 		return "needs-fix"
 	case FailurePushFail:
 		return "needs-retry"
@@ -296,7 +305,7 @@ func (f FailureType) FailureLabel() string {
 // ShouldAssignToWorker returns true if this failure should be assigned back to the worker.
 func (f FailureType) ShouldAssignToWorker() bool {
 	switch f {
-	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailurePolicyViolation:
 		return true
 	default:
 		return false
@@ -269,6 +269,7 @@ func TestFailureType_FailureLabel(t *testing.T) {
 		{FailurePushFail, "needs-retry"},
 		{FailureFetch, ""},
 		{FailureCheckout, ""},
+		{FailurePolicyViolation, "needs-fix"},
 	}
 
 	for _, tt := range tests {
@@ -294,6 +295,7 @@ func TestFailureType_ShouldAssignToWorker(t *testing.T) {
 		{FailurePushFail, false},
 		{FailureFetch, false},
 		{FailureCheckout, false},
+		{FailurePolicyViolation, true},
 	}
 
 	for _, tt := range tests {
@@ -305,3 +307,20 @@ func TestFailureType_ShouldAssignToWorker(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePhaseTransition_AwaitingApproval(t *testing.T) {
+	allowed := [][2]MRPhase{
+		{MRPhaseReady, MRPhaseAwaitingApproval},
+		{MRPhaseClaimed, MRPhaseAwaitingApproval},
+		{MRPhaseAwaitingApproval, MRPhaseReady},
+		{MRPhaseAwaitingApproval, MRPhaseRejected},
+	}
+	for _, tr := range allowed {
+		if err := ValidatePhaseTransition(tr[0], tr[1]); err != nil {
+			t.Errorf("%s → %s: unexpected error %v", tr[0], tr[1], err)
+		}
+	}
+	if err := ValidatePhaseTransition(MRPhaseAwaitingApproval, MRPhaseMerging); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("awaiting-approval → merging: got %v, want ErrInvalidTransition", err)
+	}
+}
@@ -87,6 +87,7 @@ type RigConfig struct {
 	PushURL       string       `json:"push_url,omitempty"`       // optional push URL (fork for read-only upstreams)
 	LocalRepo     string       `json:"local_repo,omitempty"`     // optional local reference repo
 	DefaultBranch string       `json:"default_branch,omitempty"` // main, master, etc.
+	Machine       string       `json:"machine,omitempty"`        // machine registry entry polecats run on (empty = local)
 	CreatedAt     time.Time    `json:"created_at"`               // when rig was created
 	Beads         *BeadsConfig `json:"beads,omitempty"`
 }
@@ -170,6 +171,11 @@ func (m *Manager) loadRig(name string, entry config.RigEntry) (*Rig, error) {
 		Config:    entry.BeadsConfig,
 	}
 
+	// Machine lives in the rig's own config.json, not rigs.json
+	if rigCfg, err := LoadRigConfig(rigPath); err == nil {
+		rig.Machine = rigCfg.Machine
+	}
+
 	// Scan for polecats
 	polecatsDir := filepath.Join(rigPath, "polecats")
 	if entries, err := os.ReadDir(polecatsDir); err == nil {
@@ -224,6 +230,7 @@ type AddRigOptions struct {
 	BeadsPrefix   string // Beads issue prefix (defaults to derived from name)
 	LocalRepo     string // Optional local repo for reference clones
 	DefaultBranch string // Default branch (defaults to auto-detected from remote)
+	Machine       string // Machine registry entry to run polecats on (empty = local)
 }
 
 func resolveLocalRepo(path, gitURL string) (string, string) {
@@ -310,6 +317,22 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		fmt.Printf("  Warning: %s\n", warn)
 	}
 
+	// Provision polecat storage on the remote machine before any local
+	// state exists, so a failure leaves nothing to roll back here.
+	// Provisioning is idempotent, so re-running the command picks up
+	// where a failed attempt stopped on the machine.
+	if opts.Machine != "" && opts.Machine != "local" {
+		remote, err := LoadRemoteMachine(m.townRoot, opts.Machine)
+		if err != nil {
+			return nil, fmt.Errorf("resolving machine: %w", err)
+		}
+		fmt.Printf("  Provisioning remote machine %s...\n", remote.Name)
+		if err := provisionRemote(remote, rigPath, opts.GitURL, opts.PushURL); err != nil {
+			return nil, fmt.Errorf("provisioning machine %s: %w", remote.Name, err)
+		}
+		fmt.Printf("   ✓ Created bare repo at %s:%s\n", remote.Name, remote.Path(filepath.Join(rigPath, ".repo.git")))
+	}
+
 	// Create container directory
 	if err := os.MkdirAll(rigPath, 0755); err != nil {
 		return nil, fmt.Errorf("creating rig directory: %w", err)
@@ -332,6 +355,7 @@ func (m *Manager) AddRig(opts AddRigOptions) (*Rig, error) {
 		GitURL:    opts.GitURL,
 		PushURL:   opts.PushURL,
 		LocalRepo: localRepo,
+		Machine:   opts.Machine,
 		CreatedAt: time.Now(),
 		Beads: &BeadsConfig{
 			Prefix: opts.BeadsPrefix,
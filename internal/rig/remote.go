@@ -0,0 +1,124 @@
+package rig
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// RemoteMachine describes the machine a remote rig's polecats run on.
+// The rig container (config, beads, witness, refinery) stays in the local
+// town; only polecat worktrees and sessions live on the remote machine,
+// under the same relative layout rooted at TownPath.
+type RemoteMachine struct {
+	// Name is the machine registry entry name.
+	Name string
+
+	// Conn executes file, command and tmux operations on the machine.
+	Conn connection.Connection
+
+	// TownPath is the town root on the remote machine.
+	TownPath string
+
+	// localTownRoot is the local town root, used to map local paths.
+	localTownRoot string
+}
+
+// IsRemote reports whether this rig's polecats run on a remote machine.
+func (r *Rig) IsRemote() bool {
+	return r.Machine != "" && r.Machine != "local"
+}
+
+// RemoteMachine resolves the rig's machine from the town's machine registry.
+// Returns nil (and no error) for rigs that run locally.
+func (r *Rig) RemoteMachine() (*RemoteMachine, error) {
+	if !r.IsRemote() {
+		return nil, nil
+	}
+	return LoadRemoteMachine(filepath.Dir(r.Path), r.Machine)
+}
+
+// LoadRemoteMachine looks up a machine in the town's registry
+// (mayor/machines.json) and opens a connection to it.
+func LoadRemoteMachine(townRoot, name string) (*RemoteMachine, error) {
+	reg, err := connection.NewMachineRegistry(constants.MayorMachinesPath(townRoot))
+	if err != nil {
+		return nil, err
+	}
+	m, err := reg.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if m.Type == "local" {
+		return nil, fmt.Errorf("machine %q is local; omit --machine for local rigs", name)
+	}
+	if m.TownPath == "" {
+		return nil, fmt.Errorf("machine %q has no town_path configured in %s", name, constants.MayorMachinesPath(townRoot))
+	}
+	conn, err := reg.Connection(name)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteMachine{
+		Name:          name,
+		Conn:          conn,
+		TownPath:      m.TownPath,
+		localTownRoot: townRoot,
+	}, nil
+}
+
+// Path maps a path inside the local town to the same location on the
+// remote machine. Paths outside the town are returned unchanged.
+func (rm *RemoteMachine) Path(localPath string) string {
+	rel, err := filepath.Rel(rm.localTownRoot, localPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return localPath
+	}
+	return path.Join(rm.TownPath, filepath.ToSlash(rel))
+}
+
+// Git runs a git command on the remote machine in the given (remote) directory.
+func (rm *RemoteMachine) Git(dir string, args ...string) (string, error) {
+	out, err := rm.Conn.ExecDir(dir, "git", args...)
+	if err != nil {
+		return "", fmt.Errorf("git %s on %s: %s", args[0], rm.Name, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// provisionRemote creates the remote half of a rig: the polecats directory
+// and a shared bare repo that polecat worktrees are added from.
+func provisionRemote(rm *RemoteMachine, rigPath, gitURL, pushURL string) error {
+	remoteRig := rm.Path(rigPath)
+	if err := rm.Conn.MkdirAll(path.Join(remoteRig, constants.DirPolecats), 0755); err != nil {
+		return fmt.Errorf("creating remote polecats dir: %w", err)
+	}
+
+	bareRepo := path.Join(remoteRig, ".repo.git")
+	if exists, err := rm.Conn.Exists(bareRepo); err != nil {
+		return err
+	} else if !exists {
+		if _, err := rm.Git(remoteRig, "clone", "--bare", gitURL, bareRepo); err != nil {
+			return err
+		}
+	}
+
+	// Bare clones don't set up remote-tracking refs; polecat worktrees start
+	// from origin/<branch>, so configure the fetch refspec and fetch once.
+	if _, err := rm.Git(bareRepo, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return err
+	}
+	if pushURL != "" {
+		if _, err := rm.Git(bareRepo, "remote", "set-url", "--push", "origin", pushURL); err != nil {
+			return err
+		}
+	}
+	if _, err := rm.Git(bareRepo, "fetch", "origin"); err != nil {
+		return err
+	}
+	return nil
+}
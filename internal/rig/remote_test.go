@@ -0,0 +1,142 @@
+package rig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func writeTestMachines(t *testing.T, townRoot string, machines map[string]map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"version": 1, "machines": machines})
+	if err != nil {
+		t.Fatalf("marshal machines: %v", err)
+	}
+	path := constants.MayorMachinesPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir mayor: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write machines.json: %v", err)
+	}
+}
+
+func TestRigIsRemote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		machine string
+		want    bool
+	}{
+		{"", false},
+		{"local", false},
+		{"buildbox", true},
+	}
+	for _, tt := range tests {
+		r := &Rig{Name: "gastown", Path: "/town/gastown", Machine: tt.machine}
+		if got := r.IsRemote(); got != tt.want {
+			t.Errorf("IsRemote() with machine %q = %v, want %v", tt.machine, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteMachinePath(t *testing.T) {
+	t.Parallel()
+
+	rm := &RemoteMachine{Name: "buildbox", TownPath: "/srv/gt", localTownRoot: "/home/user/gt"}
+
+	tests := []struct {
+		local string
+		want  string
+	}{
+		{"/home/user/gt", "/srv/gt"},
+		{"/home/user/gt/gastown/polecats/Toast/gastown", "/srv/gt/gastown/polecats/Toast/gastown"},
+		{"/home/user/other", "/home/user/other"},
+		{"/home/user/gt-other/x", "/home/user/gt-other/x"},
+	}
+	for _, tt := range tests {
+		if got := rm.Path(tt.local); got != tt.want {
+			t.Errorf("Path(%q) = %q, want %q", tt.local, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRemoteMachine(t *testing.T) {
+	t.Parallel()
+
+	townRoot := t.TempDir()
+	writeTestMachines(t, townRoot, map[string]map[string]any{
+		"buildbox": {"type": "ssh", "host": "gt@buildbox", "town_path": "/srv/gt"},
+		"nopath":   {"type": "ssh", "host": "gt@nopath"},
+	})
+
+	rm, err := LoadRemoteMachine(townRoot, "buildbox")
+	if err != nil {
+		t.Fatalf("LoadRemoteMachine: %v", err)
+	}
+	if rm.TownPath != "/srv/gt" || rm.Conn == nil || rm.Conn.IsLocal() {
+		t.Errorf("unexpected remote machine: %+v", rm)
+	}
+
+	if _, err := LoadRemoteMachine(townRoot, "nopath"); err == nil || !strings.Contains(err.Error(), "town_path") {
+		t.Errorf("expected town_path error, got %v", err)
+	}
+	if _, err := LoadRemoteMachine(townRoot, "local"); err == nil {
+		t.Error("expected error for local machine")
+	}
+	if _, err := LoadRemoteMachine(townRoot, "missing"); err == nil {
+		t.Error("expected error for unknown machine")
+	}
+}
+
+func TestLoadRigReadsMachine(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	createTestRig(t, root, "gastown")
+	rigsConfig.Rigs["gastown"] = config.RigEntry{GitURL: "git@github.com:test/gastown.git"}
+
+	cfg := &RigConfig{Type: "rig", Version: CurrentRigConfigVersion, Name: "gastown", Machine: "buildbox"}
+	data, _ := json.Marshal(cfg)
+	if err := os.WriteFile(filepath.Join(root, "gastown", "config.json"), data, 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+	r, err := manager.GetRig("gastown")
+	if err != nil {
+		t.Fatalf("GetRig: %v", err)
+	}
+	if r.Machine != "buildbox" || !r.IsRemote() {
+		t.Errorf("Machine = %q, IsRemote = %v; want buildbox, true", r.Machine, r.IsRemote())
+	}
+}
+
+func TestAddRig_RemoteProvisionFailureLeavesNoLocalState(t *testing.T) {
+	root, rigsConfig := setupTestTown(t)
+	writeTestMachines(t, root, map[string]map[string]any{
+		// Nothing listens on port 1, so provisioning fails at once.
+		"deadbox": {"type": "ssh", "host": "gt@127.0.0.1", "port": 1, "town_path": "/srv/gt",
+			"options": []string{"ConnectTimeout=2", "BatchMode=yes"}},
+	})
+	manager := NewManager(root, rigsConfig, git.NewGit(root))
+
+	_, err := manager.AddRig(AddRigOptions{
+		Name:    "gastown",
+		GitURL:  "git@github.com:test/test.git",
+		Machine: "deadbox",
+	})
+	if err == nil || !strings.Contains(err.Error(), "provisioning machine deadbox") {
+		t.Fatalf("AddRig error = %v, want a provisioning failure", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(root, "gastown")); !os.IsNotExist(statErr) {
+		t.Errorf("rig directory left behind after failed provisioning: %v", statErr)
+	}
+	if _, ok := rigsConfig.Rigs["gastown"]; ok {
+		t.Error("rig registered after failed provisioning")
+	}
+}
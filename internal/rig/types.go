@@ -41,6 +41,10 @@ type Rig struct {
 
 	// HasMayor indicates if the rig has a mayor clone.
 	HasMayor bool `json:"has_mayor"`
+
+	// Machine is the machine registry entry this rig's polecats run on.
+	// Empty means the local machine.
+	Machine string `json:"machine,omitempty"`
 }
 
 // AgentDirs are the standard agent directories in a rig.
@@ -13,7 +13,7 @@ import (
 	"github.com/steveyegge/gastown/internal/gemini"
 	"github.com/steveyegge/gastown/internal/opencode"
 	"github.com/steveyegge/gastown/internal/templates/commands"
-	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/usage"
 )
 
 func init() {
@@ -35,6 +35,12 @@ func init() {
 		// Copilot custom instructions stay in workDir — no --settings equivalent.
 		return copilot.EnsureSettingsAt(workDir, hooksDir, hooksFile)
 	})
+
+	// Register usage extractors for gt costs, keyed by preset UsageProvider.
+	config.RegisterUsageExtractor("claude", usage.Claude)
+	config.RegisterUsageExtractor("gemini", usage.Gemini)
+	config.RegisterUsageExtractor("codex", usage.Codex)
+	config.RegisterUsageExtractor("opencode", usage.OpenCode)
 }
 
 // EnsureSettingsForRole provisions all agent-specific configuration for a role.
@@ -129,8 +135,13 @@ func StartupFallbackCommands(role string, rc *config.RuntimeConfig) []string {
 	return []string{command}
 }
 
+// Nudger delivers a message to an agent session. *tmux.Tmux implements it.
+type Nudger interface {
+	NudgeSession(session, message string) error
+}
+
 // RunStartupFallback sends the startup fallback commands via tmux.
-func RunStartupFallback(t *tmux.Tmux, sessionID, role string, rc *config.RuntimeConfig) error {
+func RunStartupFallback(t Nudger, sessionID, role string, rc *config.RuntimeConfig) error {
 	commands := StartupFallbackCommands(role, rc)
 	for _, cmd := range commands {
 		if err := t.NudgeSession(sessionID, cmd); err != nil {
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/ptyd"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// Session backend names, as used in settings/config.json session_backend
+// and GT_SESSION_BACKEND.
+const (
+	BackendTmux = "tmux"
+	BackendPTY  = "pty"
+)
+
+// SessionBackend is the set of session operations Gas Town's lifecycle code
+// needs from whatever hosts agent processes. *tmux.Tmux implements it, as
+// does the daemon's headless PTY supervisor (*ptyd.Client).
+//
+// Cosmetic tmux features (themes, status lines, key bindings) are not part
+// of the interface; ApplyTheme skips them for backends without a UI.
+type SessionBackend interface {
+	// Lifecycle
+	NewSessionWithCommand(name, workDir, command string) error
+	HasSession(name string) (bool, error)
+	ListSessions() ([]string, error)
+	KillSessionWithProcesses(name string) error
+	AttachSession(session string) error
+
+	// Environment
+	SetEnvironment(session, key, value string) error
+	GetEnvironment(session, key string) (string, error)
+
+	// Input
+	SendKeysRaw(session, keys string) error
+	SendKeysDebounced(session, keys string, debounceMs int) error
+	NudgeSession(session, message string) error
+	AcceptBypassPermissionsWarning(session string) error
+
+	// Observation
+	CapturePane(session string, lines int) (string, error)
+	GetSessionActivity(session string) (time.Time, error)
+	GetSessionInfo(name string) (*tmux.SessionInfo, error)
+	GetPanePID(target string) (string, error)
+	IsAgentAlive(session string) bool
+	CheckSessionHealth(session string, maxInactivity time.Duration) tmux.ZombieStatus
+	WaitForCommand(session string, excludeCommands []string, timeout time.Duration) error
+
+	// Exit handling
+	SetRemainOnExit(pane string, on bool) error
+	SetAutoRespawnHook(session string) error
+	SetPaneDiedHook(session, agentID string) error
+}
+
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*ptyd.Client)(nil)
+)
+
+// BackendName returns the session backend configured for a town:
+// GT_SESSION_BACKEND, then session_backend in settings/config.json,
+// defaulting to tmux.
+func BackendName(townRoot string) string {
+	if name := os.Getenv("GT_SESSION_BACKEND"); name != "" {
+		return name
+	}
+	if townRoot != "" {
+		if settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot)); err == nil && settings.SessionBackend != "" {
+			return settings.SessionBackend
+		}
+	}
+	return BackendTmux
+}
+
+// NewBackend returns the session backend configured for a town.
+func NewBackend(townRoot string) SessionBackend {
+	return ResolveBackend(townRoot, tmux.NewTmux())
+}
+
+// ResolveBackend returns t, unless the town is configured for headless PTY
+// sessions, in which case it returns a client for the daemon's supervisor.
+// Callers that already hold a *tmux.Tmux use this so the town setting
+// applies without changing how they are constructed.
+func ResolveBackend(townRoot string, t *tmux.Tmux) SessionBackend {
+	if BackendName(townRoot) == BackendPTY {
+		return ptyd.NewClient(townRoot)
+	}
+	return t
+}
+
+// ApplyTheme configures the Gas Town status bar and theme on backends that
+// have a UI. It is a no-op for headless backends.
+func ApplyTheme(b SessionBackend, session string, theme tmux.Theme, rig, worker, role string) error {
+	if t, ok := b.(*tmux.Tmux); ok {
+		return t.ConfigureGasTownSession(session, theme, rig, worker, role)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Reasons a session start falls back to the next agent in a role's chain
+// (see config.ResolveRoleAgentChain).
+const (
+	FallbackBinaryMissing = "binary_missing"
+	FallbackRateLimited   = "rate_limited"
+	FallbackReadyTimeout  = "ready_timeout"
+)
+
+// DefaultReadyTimeout is how long StartSession waits for the ready prompt of
+// an agent that has a fallback after it, when SessionConfig.ReadyTimeout is
+// unset.
+const DefaultReadyTimeout = 30 * time.Second
+
+// ErrRuntimeNotReady is returned when an agent's ready prompt does not
+// appear within SessionConfig.ReadyTimeout.
+var ErrRuntimeNotReady = errors.New("runtime not ready")
+
+// AgentCandidate is one entry of a role's agent fallback chain.
+type AgentCandidate struct {
+	// Agent is the agent name (built-in preset or custom agent).
+	Agent string
+
+	// Override is the agent override to start the candidate with. It is
+	// empty for the role's primary agent, so that normal role resolution
+	// (including GT_COST_TIER) still applies to it.
+	Override string
+
+	// RuntimeConfig is the candidate's resolved runtime config.
+	RuntimeConfig *config.RuntimeConfig
+}
+
+// AgentCandidates resolves a role's agent chain: the role's agent followed by
+// its configured fallbacks. Fallbacks that no longer resolve are dropped.
+func AgentCandidates(role, townRoot, rigPath string) []AgentCandidate {
+	chain := config.ResolveRoleAgentChain(role, townRoot, rigPath)
+	primary := config.ResolveRoleAgentConfig(role, townRoot, rigPath)
+	candidates := []AgentCandidate{{Agent: chain[0], RuntimeConfig: primary}}
+	if primary.ResolvedAgent != "" {
+		candidates[0].Agent = primary.ResolvedAgent
+	}
+	for _, name := range chain[1:] {
+		if name == candidates[0].Agent {
+			continue
+		}
+		rc, _, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: role_agent_fallbacks[%s]=%s - %v, skipping\n", role, name, err)
+			continue
+		}
+		candidates = append(candidates, AgentCandidate{Agent: name, Override: name, RuntimeConfig: rc})
+	}
+	return candidates
+}
+
+// agentUnavailable reports why a candidate cannot start right now, or "".
+// Replaced in tests.
+var agentUnavailable = func(townRoot, runtimeConfigDir string, c AgentCandidate) string {
+	return c.Unavailable(townRoot, runtimeConfigDir)
+}
+
+// Unavailable reports why the candidate cannot start right now, or "" when
+// it can: its binary is not on PATH, or it runs under an account (its runtime
+// takes a config-dir env var) that quota state marks as rate-limited.
+// runtimeConfigDir is the account config dir the session would use.
+func (c AgentCandidate) Unavailable(townRoot, runtimeConfigDir string) string {
+	rc := c.RuntimeConfig
+	if rc == nil {
+		return ""
+	}
+	if rc.Command != "" {
+		if _, err := exec.LookPath(rc.Command); err != nil {
+			return FallbackBinaryMissing
+		}
+	}
+	if runtimeConfigDir != "" && rc.Session != nil && rc.Session.ConfigDirEnv != "" &&
+		accountRateLimited(townRoot, runtimeConfigDir) {
+		return FallbackRateLimited
+	}
+	return ""
+}
+
+// accountRateLimited reports whether the account using configDir is marked
+// limited in mayor/quota.json (as written by gt quota scan).
+func accountRateLimited(townRoot, configDir string) bool {
+	accounts, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot))
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(constants.MayorQuotaPath(townRoot)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return false
+	}
+	var state config.QuotaState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return false
+	}
+	for handle, acct := range accounts.Accounts {
+		if filepath.Clean(util.ExpandHome(acct.ConfigDir)) != filepath.Clean(util.ExpandHome(configDir)) {
+			continue
+		}
+		return state.Accounts[handle].Status == config.QuotaStatusLimited
+	}
+	return false
+}
+
+// LogAgentFallback records that starting sessionID skipped agent from for
+// agent to, and prints a warning.
+func LogAgentFallback(sessionID, role, from, to, reason string) {
+	fmt.Fprintf(os.Stderr, "warning: %s: agent %s unavailable (%s), falling back to %s\n", sessionID, from, reason, to)
+	_ = events.LogFeed(events.TypeAgentFallback, sessionID, events.AgentFallbackPayload(sessionID, role, from, to, reason))
+}
+
+// runtimeReadyWaiter is implemented by backends that can detect an agent's
+// ready prompt (*tmux.Tmux).
+type runtimeReadyWaiter interface {
+	WaitForRuntimeReady(session string, rc *config.RuntimeConfig, timeout time.Duration) error
+}
+
+// WaitForRuntimeReady waits for the agent's ready prompt on backends that
+// support prompt detection; on others it returns nil immediately.
+func WaitForRuntimeReady(t SessionBackend, sessionID string, rc *config.RuntimeConfig, timeout time.Duration) error {
+	w, ok := t.(runtimeReadyWaiter)
+	if !ok {
+		return nil
+	}
+	if err := w.WaitForRuntimeReady(sessionID, rc, timeout); err != nil {
+		return fmt.Errorf("%w: %v", ErrRuntimeNotReady, err)
+	}
+	return nil
+}
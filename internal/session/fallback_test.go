@@ -0,0 +1,187 @@
+package session
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// fallbackBackend records started sessions and fails the ready wait for
+// commands containing notReady.
+type fallbackBackend struct {
+	SessionBackend
+	notReady string
+	commands []string
+	env      map[string]string
+	killed   int
+}
+
+func (b *fallbackBackend) NewSessionWithCommand(_, _, command string) error {
+	b.commands = append(b.commands, command)
+	b.env = make(map[string]string)
+	return nil
+}
+
+func (b *fallbackBackend) SetEnvironment(_, key, value string) error {
+	b.env[key] = value
+	return nil
+}
+
+func (b *fallbackBackend) KillSessionWithProcesses(string) error {
+	b.killed++
+	return nil
+}
+
+func (b *fallbackBackend) WaitForRuntimeReady(_ string, rc *config.RuntimeConfig, _ time.Duration) error {
+	if b.notReady != "" && strings.Contains(rc.Command, b.notReady) {
+		return errors.New("timeout waiting for runtime prompt")
+	}
+	return nil
+}
+
+func writeFallbackTown(t *testing.T) string {
+	t.Helper()
+	townRoot := t.TempDir()
+	settings := config.NewTownSettings()
+	settings.RoleAgents["mayor"] = "claude"
+	settings.RoleAgentFallbacks = map[string][]string{"mayor": {"gemini", "claude", "codex"}}
+	if err := config.SaveTownSettings(config.TownSettingsPath(townRoot), settings); err != nil {
+		t.Fatal(err)
+	}
+	// Fallbacks log events to the town of the working directory.
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(townRoot)
+	return townRoot
+}
+
+func TestAgentCandidates(t *testing.T) {
+	townRoot := writeFallbackTown(t)
+
+	candidates := AgentCandidates("mayor", townRoot, "")
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.Agent+"/"+c.Override)
+	}
+	if got := strings.Join(names, ","); got != "claude/,gemini/gemini,codex/codex" {
+		t.Errorf("candidates = %s", got)
+	}
+}
+
+func TestStartSession_AgentFallback(t *testing.T) {
+	townRoot := writeFallbackTown(t)
+
+	orig := agentUnavailable
+	t.Cleanup(func() { agentUnavailable = orig })
+	agentUnavailable = func(_, _ string, c AgentCandidate) string {
+		if c.Agent == "claude" {
+			return FallbackRateLimited
+		}
+		return ""
+	}
+
+	b := &fallbackBackend{notReady: "gemini"}
+	result, err := StartSession(b, SessionConfig{
+		SessionID:    "hq-mayor",
+		WorkDir:      t.TempDir(),
+		Role:         "mayor",
+		TownRoot:     townRoot,
+		ReadyTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// claude is skipped without starting; gemini starts but never gets ready.
+	if len(b.commands) != 2 || b.killed != 1 {
+		t.Fatalf("started %d sessions, killed %d; want 2 and 1", len(b.commands), b.killed)
+	}
+	if !strings.Contains(b.commands[1], "codex") {
+		t.Errorf("final command = %q, want codex", b.commands[1])
+	}
+	if b.env["GT_AGENT"] != "codex" {
+		t.Errorf("GT_AGENT = %q, want codex", b.env["GT_AGENT"])
+	}
+	if !strings.Contains(result.RuntimeConfig.Command, "codex") {
+		t.Errorf("result runtime command = %q, want codex", result.RuntimeConfig.Command)
+	}
+	data, err := os.ReadFile(filepath.Join(townRoot, ".events.jsonl"))
+	if err != nil {
+		t.Fatalf("reading events: %v", err)
+	}
+	if n := strings.Count(string(data), `"type":"agent_fallback"`); n != 2 {
+		t.Errorf("logged %d agent_fallback events, want 2:\n%s", n, data)
+	}
+}
+
+func TestStartSession_DefaultReadyTimeout(t *testing.T) {
+	townRoot := writeFallbackTown(t)
+
+	orig := agentUnavailable
+	t.Cleanup(func() { agentUnavailable = orig })
+	agentUnavailable = func(_, _ string, _ AgentCandidate) string { return "" }
+
+	// No ReadyTimeout: callers such as the mayor manager leave it unset,
+	// and the fallback chain must still apply.
+	b := &fallbackBackend{notReady: "claude"}
+	if _, err := StartSession(b, SessionConfig{
+		SessionID: "hq-mayor",
+		WorkDir:   t.TempDir(),
+		Role:      "mayor",
+		TownRoot:  townRoot,
+	}); err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	if len(b.commands) != 2 || b.killed != 1 {
+		t.Errorf("started %d sessions, killed %d; want 2 and 1", len(b.commands), b.killed)
+	}
+}
+
+func TestStartSession_LastAgentIgnoresReadyTimeout(t *testing.T) {
+	townRoot := t.TempDir()
+
+	b := &fallbackBackend{notReady: "claude"}
+	if _, err := StartSession(b, SessionConfig{
+		SessionID:    "hq-mayor",
+		WorkDir:      t.TempDir(),
+		Role:         "mayor",
+		TownRoot:     townRoot,
+		ReadyTimeout: time.Second,
+	}); err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	if len(b.commands) != 1 || b.killed != 0 {
+		t.Errorf("started %d sessions, killed %d; want 1 and 0", len(b.commands), b.killed)
+	}
+}
+
+func TestAccountRateLimited(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, constants.DirMayor), 0755); err != nil {
+		t.Fatal(err)
+	}
+	accounts := `{"version":1,"accounts":{"work":{"config_dir":"/accts/work"},"home":{"config_dir":"/accts/home"}},"default":"work"}`
+	quota := `{"version":1,"accounts":{"work":{"status":"limited"},"home":{"status":"available"}}}`
+	if err := os.WriteFile(constants.MayorAccountsPath(townRoot), []byte(accounts), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(constants.MayorQuotaPath(townRoot), []byte(quota), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !accountRateLimited(townRoot, "/accts/work/") {
+		t.Error("work account should be rate-limited")
+	}
+	if accountRateLimited(townRoot, "/accts/home") {
+		t.Error("home account should be available")
+	}
+	if accountRateLimited(townRoot, "/accts/unknown") {
+		t.Error("unknown config dir should not be rate-limited")
+	}
+}
@@ -2,6 +2,7 @@
 package session
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -93,6 +94,13 @@ type SessionConfig struct {
 	// ReadyDelay sleeps for the runtime's configured readiness delay.
 	ReadyDelay bool
 
+	// ReadyTimeout, if set, waits up to this long for the runtime's ready
+	// prompt (tmux backend only). If the prompt never appears and the role
+	// has further agents in its fallback chain, the session is killed and
+	// the next agent is started; otherwise the timeout is ignored. Agents
+	// with a fallback after them default to DefaultReadyTimeout.
+	ReadyTimeout time.Duration
+
 	// AutoRespawn sets the auto-respawn hook so the session survives crashes.
 	AutoRespawn bool
 
@@ -114,7 +122,8 @@ type StartResult struct {
 	RuntimeConfig *config.RuntimeConfig
 }
 
-// StartSession creates a tmux session following the standard Gas Town lifecycle.
+// StartSession creates a session following the standard Gas Town lifecycle.
+// t is usually a *tmux.Tmux; see NewBackend for the headless alternative.
 //
 // The lifecycle handles:
 //  1. Resolve runtime config for the role
@@ -124,12 +133,18 @@ type StartResult struct {
 //  5. Set environment variables (standard + extra)
 //  6. Apply theme (if configured)
 //  7. Optional post-start: wait for agent, accept bypass, ready delay,
-//     auto-respawn, PID tracking, verify survived
+//     ready prompt, auto-respawn, PID tracking, verify survived
 //
 // Role-specific concerns (issue validation, fallback nudges, pane-died hooks,
 // crew cycle bindings, etc.) should be handled by the caller before/after
 // calling StartSession.
-func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
+//
+// Unless Command or AgentOverride pins the agent, the role's agent fallback
+// chain (role_agent_fallbacks) is walked: an agent whose binary is missing,
+// whose account is rate-limited, or whose prompt misses ReadyTimeout is
+// skipped for the next one, and each skip is recorded as an agent_fallback
+// event.
+func StartSession(t SessionBackend, cfg SessionConfig) (*StartResult, error) {
 	if cfg.SessionID == "" {
 		return nil, fmt.Errorf("SessionID is required")
 	}
@@ -140,8 +155,38 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 		return nil, fmt.Errorf("Role is required")
 	}
 
-	// 1. Resolve runtime config.
-	runtimeConfig := config.ResolveRoleAgentConfig(cfg.Role, cfg.TownRoot, cfg.RigPath)
+	if cfg.Command != "" || cfg.AgentOverride != "" {
+		return startSession(t, cfg, config.ResolveRoleAgentConfig(cfg.Role, cfg.TownRoot, cfg.RigPath), false)
+	}
+
+	candidates := AgentCandidates(cfg.Role, cfg.TownRoot, cfg.RigPath)
+	for i, cand := range candidates {
+		last := i == len(candidates)-1
+		if !last {
+			if reason := agentUnavailable(cfg.TownRoot, cfg.RuntimeConfigDir, cand); reason != "" {
+				LogAgentFallback(cfg.SessionID, cfg.Role, cand.Agent, candidates[i+1].Agent, reason)
+				continue
+			}
+		}
+		candCfg := cfg
+		candCfg.AgentOverride = cand.Override
+		if candCfg.ReadyTimeout == 0 && !last {
+			candCfg.ReadyTimeout = DefaultReadyTimeout
+		}
+		result, err := startSession(t, candCfg, cand.RuntimeConfig, !last)
+		if errors.Is(err, ErrRuntimeNotReady) {
+			LogAgentFallback(cfg.SessionID, cfg.Role, cand.Agent, candidates[i+1].Agent, FallbackReadyTimeout)
+			continue
+		}
+		return result, err
+	}
+	return nil, fmt.Errorf("no agent available for %s", cfg.Role)
+}
+
+// startSession runs the lifecycle for one agent. With readyFatal, a ready
+// prompt timeout kills the session and returns ErrRuntimeNotReady.
+func startSession(t SessionBackend, cfg SessionConfig, runtimeConfig *config.RuntimeConfig, readyFatal bool) (*StartResult, error) {
+	// 1. Resolve runtime config (done by the caller).
 
 	// 2. Ensure settings/plugins exist for the agent.
 	settingsDir := config.RoleSettingsDir(cfg.Role, cfg.RigPath)
@@ -203,7 +248,7 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 
 	// 7. Apply theme.
 	if cfg.Theme != nil {
-		_ = t.ConfigureGasTownSession(cfg.SessionID, *cfg.Theme, cfg.RigName, cfg.AgentName, cfg.Role)
+		_ = ApplyTheme(t, cfg.SessionID, *cfg.Theme, cfg.RigName, cfg.AgentName, cfg.Role)
 	}
 
 	// 8. Wait for agent to start.
@@ -233,7 +278,15 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 		runtime.SleepForReadyDelay(runtimeConfig)
 	}
 
-	// 12. Verify session survived startup.
+	// 12. Wait for the runtime's ready prompt.
+	if cfg.ReadyTimeout > 0 {
+		if err := WaitForRuntimeReady(t, cfg.SessionID, runtimeConfig, cfg.ReadyTimeout); err != nil && readyFatal {
+			_ = t.KillSessionWithProcesses(cfg.SessionID)
+			return nil, err
+		}
+	}
+
+	// 13. Verify session survived startup.
 	if cfg.VerifySurvived {
 		running, err := t.HasSession(cfg.SessionID)
 		if err != nil {
@@ -246,7 +299,7 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 		}
 	}
 
-	// 13. Track PID for defense-in-depth orphan cleanup.
+	// 14. Track PID for defense-in-depth orphan cleanup.
 	if cfg.TrackPID && cfg.TownRoot != "" {
 		_ = TrackSessionPID(cfg.TownRoot, cfg.SessionID, t)
 	}
@@ -258,7 +311,7 @@ func StartSession(t *tmux.Tmux, cfg SessionConfig) (*StartResult, error) {
 //
 // If graceful is true, sends Ctrl-C first and waits for the session to exit
 // before force-killing. This allows the agent to clean up.
-func StopSession(t *tmux.Tmux, sessionID string, graceful bool) error {
+func StopSession(t SessionBackend, sessionID string, graceful bool) error {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
@@ -297,7 +350,7 @@ func mapKeysSorted(m map[string]string) []string {
 // If checkAlive is true, only kills zombie sessions (tmux alive but agent dead).
 // If the session exists and the agent is alive, returns ErrAlreadyRunning.
 // If checkAlive is false, kills any existing session unconditionally.
-func KillExistingSession(t *tmux.Tmux, sessionID string, checkAlive bool) (bool, error) {
+func KillExistingSession(t SessionBackend, sessionID string, checkAlive bool) (bool, error) {
 	running, err := t.HasSession(sessionID)
 	if err != nil {
 		return false, fmt.Errorf("checking session: %w", err)
@@ -0,0 +1,89 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// claudeMessage is a line of a Claude Code transcript file.
+type claudeMessage struct {
+	Type    string `json:"type"`
+	Message *struct {
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int `json:"input_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+		} `json:"usage,omitempty"`
+	} `json:"message,omitempty"`
+}
+
+// ClaudeProjectDir returns the Claude Code project directory for a working directory.
+// Claude Code stores transcripts in ~/.claude/projects/<path-with-dashes-instead-of-slashes>/
+func ClaudeProjectDir(workDir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	// Convert path to Claude's directory naming: replace / with -
+	// Keep leading slash - it becomes a leading dash in Claude's encoding
+	projectName := strings.ReplaceAll(workDir, "/", "-")
+	return filepath.Join(home, ".claude", "projects", projectName), nil
+}
+
+// Claude sums token usage from assistant messages in the most recent
+// Claude Code transcript for workDir.
+func Claude(workDir string) (*config.TokenUsage, error) {
+	projectDir, err := ClaudeProjectDir(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting project dir: %w", err)
+	}
+	transcript, err := latestFile(projectDir, func(name string) bool {
+		return strings.HasSuffix(name, ".jsonl")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no transcript files found in %s: %w", projectDir, err)
+	}
+	return ParseClaudeTranscript(transcript)
+}
+
+// ParseClaudeTranscript reads a transcript file and sums token usage from
+// assistant messages.
+func ParseClaudeTranscript(path string) (*config.TokenUsage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	usage := &config.TokenUsage{}
+	err = scanLines(file, func(line []byte) {
+		var msg claudeMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return // Skip malformed lines
+		}
+		if msg.Type != "assistant" || msg.Message == nil || msg.Message.Usage == nil {
+			return
+		}
+		// Capture the model (use first one found, they should all be the same)
+		if usage.Model == "" && msg.Message.Model != "" {
+			usage.Model = msg.Message.Model
+		}
+		u := msg.Message.Usage
+		usage.InputTokens += u.InputTokens
+		usage.CacheCreationInputTokens += u.CacheCreationInputTokens
+		usage.CacheReadInputTokens += u.CacheReadInputTokens
+		usage.OutputTokens += u.OutputTokens
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
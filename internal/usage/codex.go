@@ -0,0 +1,100 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// codexLine is a line of a Codex rollout file
+// ($CODEX_HOME/sessions/YYYY/MM/DD/rollout-*.jsonl).
+type codexLine struct {
+	Type    string `json:"type"`
+	Payload struct {
+		Type  string `json:"type"`
+		CWD   string `json:"cwd"`
+		Model string `json:"model"`
+		Info  *struct {
+			Total struct {
+				InputTokens       int `json:"input_tokens"` // includes cached
+				CachedInputTokens int `json:"cached_input_tokens"`
+				OutputTokens      int `json:"output_tokens"` // includes reasoning
+			} `json:"total_token_usage"`
+		} `json:"info,omitempty"`
+	} `json:"payload"`
+}
+
+// codexScanLimit bounds how many recent rollouts are opened when looking
+// for the one that ran in workDir.
+const codexScanLimit = 50
+
+// Codex reads token usage from the most recent Codex rollout whose session
+// ran in workDir.
+func Codex(workDir string) (*config.TokenUsage, error) {
+	codexHome := os.Getenv("CODEX_HOME")
+	if codexHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		codexHome = filepath.Join(home, ".codex")
+	}
+	sessionsDir := filepath.Join(codexHome, "sessions")
+
+	// Rollout names embed their start time, so a reverse sort is newest first.
+	rollouts, err := filepath.Glob(filepath.Join(sessionsDir, "*", "*", "*", "rollout-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(rollouts)))
+	if len(rollouts) > codexScanLimit {
+		rollouts = rollouts[:codexScanLimit]
+	}
+	for _, path := range rollouts {
+		usage, cwd, err := ParseCodexRollout(path)
+		if err == nil && cwd == workDir {
+			return usage, nil
+		}
+	}
+	return nil, fmt.Errorf("no codex rollout for %s in %s", workDir, sessionsDir)
+}
+
+// ParseCodexRollout reads a Codex rollout file, returning the session's
+// cumulative token usage and working directory.
+func ParseCodexRollout(path string) (*config.TokenUsage, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	usage := &config.TokenUsage{}
+	var cwd string
+	err = scanLines(file, func(line []byte) {
+		var l codexLine
+		if err := json.Unmarshal(line, &l); err != nil {
+			return
+		}
+		switch {
+		case l.Type == "session_meta":
+			cwd = l.Payload.CWD
+		case l.Type == "turn_context" && usage.Model == "":
+			usage.Model = l.Payload.Model
+		case l.Type == "event_msg" && l.Payload.Type == "token_count" && l.Payload.Info != nil:
+			// total_token_usage is cumulative; the last one wins.
+			t := l.Payload.Info.Total
+			usage.InputTokens = t.InputTokens - t.CachedInputTokens
+			usage.CacheReadInputTokens = t.CachedInputTokens
+			usage.OutputTokens = t.OutputTokens
+		}
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return usage, strings.TrimSuffix(cwd, string(filepath.Separator)), nil
+}
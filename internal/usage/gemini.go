@@ -0,0 +1,74 @@
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// geminiChat is a Gemini CLI chat recording
+// (~/.gemini/tmp/<sha256 of project root>/chats/session-*.json).
+type geminiChat struct {
+	Messages []struct {
+		Type   string `json:"type"`
+		Model  string `json:"model"`
+		Tokens *struct {
+			Input    int `json:"input"` // includes cached
+			Output   int `json:"output"`
+			Cached   int `json:"cached"`
+			Thoughts int `json:"thoughts"`
+			Tool     int `json:"tool"`
+		} `json:"tokens,omitempty"`
+	} `json:"messages"`
+}
+
+// Gemini sums token usage from the most recent Gemini CLI chat recording
+// for workDir.
+func Gemini(workDir string) (*config.TokenUsage, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(workDir))
+	chatsDir := filepath.Join(home, ".gemini", "tmp", hex.EncodeToString(sum[:]), "chats")
+
+	chat, err := latestFile(chatsDir, func(name string) bool {
+		return strings.HasPrefix(name, "session-") && strings.HasSuffix(name, ".json")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no chat recordings found in %s: %w", chatsDir, err)
+	}
+	return ParseGeminiChat(chat)
+}
+
+// ParseGeminiChat sums token usage from a Gemini CLI chat recording.
+func ParseGeminiChat(path string) (*config.TokenUsage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var chat geminiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	usage := &config.TokenUsage{}
+	for _, m := range chat.Messages {
+		if m.Type != "gemini" || m.Tokens == nil {
+			continue
+		}
+		if usage.Model == "" {
+			usage.Model = m.Model
+		}
+		usage.InputTokens += m.Tokens.Input - m.Tokens.Cached + m.Tokens.Tool
+		usage.CacheReadInputTokens += m.Tokens.Cached
+		usage.OutputTokens += m.Tokens.Output + m.Tokens.Thoughts
+	}
+	return usage, nil
+}
@@ -0,0 +1,107 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// opencodeSession is an OpenCode session record
+// (<data>/storage/session/<project>/<session>.json).
+type opencodeSession struct {
+	ID        string `json:"id"`
+	Directory string `json:"directory"`
+	Time      struct {
+		Updated int64 `json:"updated"`
+	} `json:"time"`
+}
+
+// opencodeMessage is an OpenCode message record
+// (<data>/storage/message/<session>/<message>.json).
+type opencodeMessage struct {
+	Role    string  `json:"role"`
+	ModelID string  `json:"modelID"`
+	Cost    float64 `json:"cost"`
+	Tokens  *struct {
+		Input     int `json:"input"`
+		Output    int `json:"output"`
+		Reasoning int `json:"reasoning"`
+		Cache     struct {
+			Read  int `json:"read"`
+			Write int `json:"write"`
+		} `json:"cache"`
+	} `json:"tokens,omitempty"`
+}
+
+// opencodeDataDir returns OpenCode's data directory ($XDG_DATA_HOME/opencode).
+func opencodeDataDir() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "opencode"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "opencode"), nil
+}
+
+// OpenCode sums usage over the messages of the most recently updated
+// OpenCode session in workDir. OpenCode records a per-message cost, which
+// is reported as-is.
+func OpenCode(workDir string) (*config.TokenUsage, error) {
+	dataDir, err := opencodeDataDir()
+	if err != nil {
+		return nil, err
+	}
+	storage := filepath.Join(dataDir, "storage")
+
+	sessionFiles, err := filepath.Glob(filepath.Join(storage, "session", "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var latest *opencodeSession
+	for _, path := range sessionFiles {
+		var s opencodeSession
+		if data, err := os.ReadFile(path); err != nil || json.Unmarshal(data, &s) != nil {
+			continue
+		}
+		if s.Directory == workDir && s.ID != "" && (latest == nil || s.Time.Updated > latest.Time.Updated) {
+			latest = &s
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no opencode session for %s in %s", workDir, storage)
+	}
+	return ParseOpenCodeMessages(filepath.Join(storage, "message", latest.ID))
+}
+
+// ParseOpenCodeMessages sums usage over the assistant messages in an
+// OpenCode session's message directory.
+func ParseOpenCodeMessages(dir string) (*config.TokenUsage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	usage := &config.TokenUsage{}
+	for _, path := range files {
+		var m opencodeMessage
+		if data, err := os.ReadFile(path); err != nil || json.Unmarshal(data, &m) != nil {
+			continue
+		}
+		if m.Role != "assistant" || m.Tokens == nil {
+			continue
+		}
+		if usage.Model == "" {
+			usage.Model = m.ModelID
+		}
+		usage.InputTokens += m.Tokens.Input
+		usage.CacheReadInputTokens += m.Tokens.Cache.Read
+		usage.CacheCreationInputTokens += m.Tokens.Cache.Write
+		usage.OutputTokens += m.Tokens.Output + m.Tokens.Reasoning
+		usage.ReportedCostUSD += m.Cost
+	}
+	return usage, nil
+}
@@ -0,0 +1,56 @@
+// Package usage extracts token usage from the on-disk session records of
+// each supported agent runtime, for cost accounting in gt costs.
+//
+// Each extractor has the config.UsageExtractorFunc signature and is
+// registered under its preset's UsageProvider in runtime init().
+package usage
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// latestFile returns the most recently modified file in dir (not
+// recursing) for which match returns true.
+func latestFile(dir string, match func(name string) bool) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var latestPath string
+	var latestTime time.Time
+	for _, e := range entries {
+		if e.IsDir() || !match(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // Skip files we can't stat
+		}
+		if info.ModTime().After(latestTime) {
+			latestTime = info.ModTime()
+			latestPath = filepath.Join(dir, e.Name())
+		}
+	}
+	if latestPath == "" {
+		return "", os.ErrNotExist
+	}
+	return latestPath, nil
+}
+
+// scanLines calls fn for each non-empty line of r, with a buffer large
+// enough for transcript lines.
+func scanLines(r io.Reader, fn func(line []byte)) error {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 256*1024)
+	scanner.Buffer(buf, 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			fn(line)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,154 @@
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkUsage(t *testing.T, got *config.TokenUsage, err error, want config.TokenUsage) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if *got != want {
+		t.Errorf("usage = %+v, want %+v", *got, want)
+	}
+}
+
+func TestClaude(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workDir := "/town/gastown/polecats/toast"
+	projectDir := filepath.Join(home, ".claude", "projects", "-town-gastown-polecats-toast")
+
+	writeFile(t, filepath.Join(projectDir, "old.jsonl"),
+		`{"type":"assistant","message":{"model":"old","usage":{"input_tokens":999}}}`+"\n")
+	old := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(filepath.Join(projectDir, "old.jsonl"), old, old)
+
+	writeFile(t, filepath.Join(projectDir, "new.jsonl"), strings.Join([]string{
+		`{"type":"user","message":{"role":"user"}}`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"cache_creation_input_tokens":20,"cache_read_input_tokens":30,"output_tokens":40}}}`,
+		`not json`,
+		`{"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":1,"output_tokens":2}}}`,
+	}, "\n")+"\n")
+
+	got, err := Claude(workDir)
+	checkUsage(t, got, err, config.TokenUsage{
+		Model:                    "claude-sonnet-4-20250514",
+		InputTokens:              11,
+		CacheCreationInputTokens: 20,
+		CacheReadInputTokens:     30,
+		OutputTokens:             42,
+	})
+
+	if _, err := Claude("/no/such/dir"); err == nil {
+		t.Error("expected error for missing transcripts")
+	}
+}
+
+func TestGemini(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workDir := "/town/gastown/polecats/toast"
+	sum := sha256.Sum256([]byte(workDir))
+	chats := filepath.Join(home, ".gemini", "tmp", hex.EncodeToString(sum[:]), "chats")
+
+	writeFile(t, filepath.Join(chats, "session-2025-01-01T10-00-abc.json"), `{
+  "sessionId": "abc",
+  "messages": [
+    {"type": "user", "content": "hi"},
+    {"type": "gemini", "model": "gemini-2.5-pro", "tokens": {"input": 100, "output": 10, "cached": 60, "thoughts": 5, "tool": 2, "total": 117}},
+    {"type": "gemini", "model": "gemini-2.5-pro", "tokens": {"input": 50, "output": 4, "cached": 0, "thoughts": 1, "tool": 0}}
+  ]
+}`)
+
+	got, err := Gemini(workDir)
+	checkUsage(t, got, err, config.TokenUsage{
+		Model:                "gemini-2.5-pro",
+		InputTokens:          92,
+		CacheReadInputTokens: 60,
+		OutputTokens:         20,
+	})
+}
+
+func TestCodex(t *testing.T) {
+	codexHome := t.TempDir()
+	t.Setenv("CODEX_HOME", codexHome)
+	day := filepath.Join(codexHome, "sessions", "2025", "01", "02")
+
+	rollout := func(cwd string, input int) string {
+		return strings.Join([]string{
+			`{"type":"session_meta","payload":{"id":"x","cwd":"` + cwd + `"}}`,
+			`{"type":"turn_context","payload":{"cwd":"` + cwd + `","model":"gpt-5-codex"}}`,
+			`{"type":"event_msg","payload":{"type":"token_count","info":null}}`,
+			`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":10,"cached_input_tokens":4,"output_tokens":3}}}}`,
+			`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":` +
+				strconv.Itoa(input) + `,"cached_input_tokens":40,"output_tokens":30,"reasoning_output_tokens":10}}}}`,
+		}, "\n") + "\n"
+	}
+	writeFile(t, filepath.Join(day, "rollout-2025-01-02T10-00-00-a.jsonl"), rollout("/work/mine", 100))
+	writeFile(t, filepath.Join(day, "rollout-2025-01-02T11-00-00-b.jsonl"), rollout("/work/other", 500))
+
+	got, err := Codex("/work/mine")
+	checkUsage(t, got, err, config.TokenUsage{
+		Model:                "gpt-5-codex",
+		InputTokens:          60,
+		CacheReadInputTokens: 40,
+		OutputTokens:         30,
+	})
+
+	if _, err := Codex("/work/none"); err == nil {
+		t.Error("expected error when no rollout ran in workDir")
+	}
+}
+
+func TestOpenCode(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	storage := filepath.Join(dataHome, "opencode", "storage")
+
+	writeFile(t, filepath.Join(storage, "session", "proj", "ses_old.json"),
+		`{"id":"ses_old","directory":"/work/mine","time":{"created":1,"updated":100}}`)
+	writeFile(t, filepath.Join(storage, "session", "proj", "ses_new.json"),
+		`{"id":"ses_new","directory":"/work/mine","time":{"created":1,"updated":200}}`)
+	writeFile(t, filepath.Join(storage, "session", "proj", "ses_elsewhere.json"),
+		`{"id":"ses_elsewhere","directory":"/work/other","time":{"created":1,"updated":300}}`)
+
+	writeFile(t, filepath.Join(storage, "message", "ses_old", "msg_1.json"),
+		`{"role":"assistant","modelID":"old","cost":9,"tokens":{"input":999}}`)
+	writeFile(t, filepath.Join(storage, "message", "ses_new", "msg_1.json"),
+		`{"role":"user"}`)
+	writeFile(t, filepath.Join(storage, "message", "ses_new", "msg_2.json"),
+		`{"role":"assistant","modelID":"claude-sonnet-4","cost":0.25,"tokens":{"input":10,"output":20,"reasoning":5,"cache":{"read":30,"write":40}}}`)
+	writeFile(t, filepath.Join(storage, "message", "ses_new", "msg_3.json"),
+		`{"role":"assistant","modelID":"claude-sonnet-4","cost":0.5,"tokens":{"input":1,"output":2,"reasoning":0,"cache":{"read":0,"write":0}}}`)
+
+	got, err := OpenCode("/work/mine")
+	checkUsage(t, got, err, config.TokenUsage{
+		Model:                    "claude-sonnet-4",
+		InputTokens:              11,
+		CacheReadInputTokens:     30,
+		CacheCreationInputTokens: 40,
+		OutputTokens:             27,
+		ReportedCostUSD:          0.75,
+	})
+}
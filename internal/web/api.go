@@ -8,14 +8,18 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 // CommandRequest is the JSON request body for /api/run.
@@ -124,6 +128,8 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleSSE(w, r)
 	case path == "/session/preview" && r.Method == http.MethodGet:
 		h.handleSessionPreview(w, r)
+	case path == "/mq/gate-output" && r.Method == http.MethodGet:
+		h.handleGateOutput(w, r)
 	default:
 		http.Error(w, "Not found", http.StatusNotFound)
 	}
@@ -144,6 +150,13 @@ func (h *APIHandler) handleRun(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A dashboard started from an agent session serves that agent: human
+	// sign-off can't come through it. gt mq approve makes the same check.
+	if role := os.Getenv("GT_ROLE"); meta.HumanOnly && role != "" {
+		h.sendError(w, fmt.Sprintf("Command blocked: %q needs a human, and this dashboard runs in agent session %s (GT_ROLE)", req.Command, role), http.StatusForbidden)
+		return
+	}
+
 	// Determine timeout
 	timeout := h.defaultRunTimeout
 	if req.Timeout > 0 {
@@ -975,6 +988,27 @@ type IssueShowResponse struct {
 	DependsOn   []string `json:"depends_on,omitempty"`
 	Blocks      []string `json:"blocks,omitempty"`
 	RawOutput   string   `json:"raw_output"`
+
+	// GateOutput links to the refinery's saved quality gate output when
+	// the issue is a merge request.
+	GateOutput []GateOutputLink `json:"gate_output,omitempty"`
+
+	// Approval is set when the issue is a merge request held for human
+	// approval (merge_queue.approvals).
+	Approval *MRApproval `json:"approval,omitempty"`
+}
+
+// MRApproval describes a merge request awaiting human approval.
+type MRApproval struct {
+	Rig        string   `json:"rig"`
+	ApprovedBy []string `json:"approved_by,omitempty"`
+}
+
+// GateOutputLink points at one saved quality gate output of a merge request.
+type GateOutputLink struct {
+	Gate   string `json:"gate"`
+	Failed bool   `json:"failed"`
+	URL    string `json:"url"`
 }
 
 // handleIssueShow returns details for a specific issue/bead.
@@ -1003,6 +1037,8 @@ func (h *APIHandler) handleIssueShow(w http.ResponseWriter, r *http.Request) {
 			// Preserve the original request ID in the response (may be external:prefix:id).
 			// Callers may store/compare the full prefixed form.
 			resp.ID = issueID
+			resp.GateOutput = h.gateOutputLinks(showID, resp.Description)
+			resp.Approval = mrApproval(resp.Description)
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(resp)
 			return
@@ -1019,11 +1055,78 @@ func (h *APIHandler) handleIssueShow(w http.ResponseWriter, r *http.Request) {
 	// Pass issueID (not showID) to preserve the original ID in the API response.
 	// Callers may store/compare the full external:prefix:id form.
 	resp := parseIssueShowOutput(output, issueID)
+	resp.GateOutput = h.gateOutputLinks(showID, resp.Description)
+	resp.Approval = mrApproval(resp.Description)
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
 }
 
+// gateOutputLinks returns links to the saved gate output of a merge request,
+// located through the rig named in its MR fields.
+func (h *APIHandler) gateOutputLinks(mrID, description string) []GateOutputLink {
+	fields := beads.ParseMRFields(&beads.Issue{Description: description})
+	if fields == nil || !isValidRigName(fields.Rig) {
+		return nil
+	}
+	townRoot, err := workspace.Find(h.workDir)
+	if err != nil || townRoot == "" {
+		return nil
+	}
+	artifacts, err := refinery.ListGateArtifacts(filepath.Join(townRoot, fields.Rig), mrID)
+	if err != nil {
+		return nil
+	}
+	links := make([]GateOutputLink, 0, len(artifacts))
+	for _, a := range artifacts {
+		links = append(links, GateOutputLink{
+			Gate:   a.Gate,
+			Failed: a.Failed,
+			URL: fmt.Sprintf("/api/mq/gate-output?rig=%s&mr=%s&gate=%s",
+				url.QueryEscape(fields.Rig), url.QueryEscape(mrID), url.QueryEscape(a.Gate)),
+		})
+	}
+	return links
+}
+
+// mrApproval returns the approval state of a merge request held in the
+// awaiting-approval phase, or nil.
+func mrApproval(description string) *MRApproval {
+	fields := beads.ParseMRFields(&beads.Issue{Description: description})
+	if fields == nil || fields.Phase != string(refinery.MRPhaseAwaitingApproval) || !isValidRigName(fields.Rig) {
+		return nil
+	}
+	return &MRApproval{Rig: fields.Rig, ApprovedBy: fields.ApprovedBy}
+}
+
+// handleGateOutput serves one saved quality gate output as plain text.
+func (h *APIHandler) handleGateOutput(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rigName, mrID, gate := q.Get("rig"), q.Get("mr"), q.Get("gate")
+	if !isValidRigName(rigName) || !isValidID(mrID) || !isValidID(gate) {
+		h.sendError(w, "Invalid rig, mr or gate", http.StatusBadRequest)
+		return
+	}
+	townRoot, err := workspace.Find(h.workDir)
+	if err != nil || townRoot == "" {
+		h.sendError(w, "Not in a Gas Town workspace", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := os.ReadFile(refinery.GateArtifactPath(filepath.Join(townRoot, rigName), mrID, gate)) //nolint:gosec // G304: path elements are validated
+	if err != nil {
+		if os.IsNotExist(err) {
+			h.sendError(w, "Gate output not found", http.StatusNotFound)
+			return
+		}
+		h.sendError(w, "Failed to read gate output: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = w.Write(data)
+}
+
 // IssueCreateRequest is the request body for creating an issue.
 type IssueCreateRequest struct {
 	Title       string `json:"title"`
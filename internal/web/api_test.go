@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/session"
 )
 
@@ -292,6 +295,30 @@ func TestAPIHandler_Commands(t *testing.T) {
 	}
 }
 
+func TestAPIHandler_Run_HumanOnlyRefusedInAgentSession(t *testing.T) {
+	t.Setenv("GT_ROLE", "polecat")
+	handler := NewAPIHandler(30*time.Second, 60*time.Second)
+	handler.gtPath = "/nonexistent/gt" // must not be run
+
+	body := `{"command": "mq approve gastown gt-mr-abc"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/run", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST /api/run mq approve from agent session status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	var resp CommandResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !strings.Contains(resp.Error, "needs a human") {
+		t.Errorf("error = %q, want a human-only refusal", resp.Error)
+	}
+}
+
 func TestAPIHandler_Run_BlockedCommand(t *testing.T) {
 	handler := NewAPIHandler(30*time.Second, 60*time.Second)
 
@@ -1069,3 +1096,49 @@ func TestHandleSessionPreviewPrefixValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIHandler_GateOutput(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(townRoot, "mayor", "town.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := refinery.WriteGateArtifacts(filepath.Join(townRoot, "gastown"), "gt-mr1", []refinery.GateResult{
+		{Name: "test", Output: "--- FAIL: TestX\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewAPIHandler(30*time.Second, 60*time.Second)
+	handler.workDir = townRoot
+
+	links := handler.gateOutputLinks("gt-mr1", "branch: polecat/nux\nrig: gastown\n")
+	if len(links) != 1 || !links[0].Failed || links[0].Gate != "test" {
+		t.Fatalf("links = %+v", links)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, links[0].URL, nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "--- FAIL: TestX") {
+		t.Errorf("GET %s = %d %q", links[0].URL, w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/mq/gate-output?rig=gastown&mr=..&gate=test", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("traversal attempt status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestMRApproval(t *testing.T) {
+	held := "branch: polecat/nux/gt-1\ntarget: main\nrig: gastown\nphase: awaiting-approval\napproved_by: alice"
+	got := mrApproval(held)
+	if got == nil || got.Rig != "gastown" || len(got.ApprovedBy) != 1 || got.ApprovedBy[0] != "alice" {
+		t.Errorf("mrApproval(held) = %+v, want rig gastown approved by alice", got)
+	}
+	if got := mrApproval("branch: polecat/nux/gt-1\ntarget: main\nrig: gastown"); got != nil {
+		t.Errorf("mrApproval(ready) = %+v, want nil", got)
+	}
+}
@@ -20,6 +20,8 @@ type CommandMeta struct {
 	Args string
 	// ArgType specifies what kind of options to show (rigs, polecats, convoys, agents, hooks)
 	ArgType string
+	// HumanOnly commands are refused when the dashboard runs in an agent session
+	HumanOnly bool
 }
 
 // AllowedCommands defines which gt commands can be executed from the dashboard.
@@ -89,6 +91,9 @@ var AllowedCommands = map[string]CommandMeta{
 	"hook attach": {Confirm: true, Desc: "Attach hook", Category: "Hooks", Args: "<bead>", ArgType: "hooks"},
 	"hook detach": {Confirm: true, Desc: "Detach hook", Category: "Hooks", Args: "<bead>", ArgType: "hooks"},
 
+	// Merge queue actions
+	"mq approve": {Confirm: true, HumanOnly: true, Desc: "Approve merge request held for sign-off", Category: "Merge Queue", Args: "<rig> <mr-id>", ArgType: "rigs"},
+
 	// Notifications
 	"notify":    {Confirm: true, Desc: "Send notification", Category: "Notifications", Args: "<message>"},
 	"broadcast": {Confirm: true, Desc: "Broadcast message", Category: "Notifications", Args: "<message>"},
@@ -15,6 +15,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/activity"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/budget"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -137,13 +138,20 @@ func (f *LiveConvoyFetcher) FetchConvoys() ([]ConvoyRow, error) {
 		return nil, fmt.Errorf("parsing convoy list: %w", err)
 	}
 
+	// Recorded costs per convoy (best-effort: the ledger is optional)
+	costs, err := budget.LoadTotals(f.townRoot, budget.CostsLogPath())
+	if err != nil {
+		log.Printf("warning: loading convoy costs: %v", err)
+	}
+
 	// Build convoy rows with activity data
 	rows := make([]ConvoyRow, 0, len(convoys))
 	for _, c := range convoys {
 		row := ConvoyRow{
-			ID:     c.ID,
-			Title:  c.Title,
-			Status: c.Status,
+			ID:      c.ID,
+			Title:   c.Title,
+			Status:  c.Status,
+			CostUSD: costs.ByConvoy[c.ID],
 		}
 
 		// Get tracked issues for progress and activity calculation
@@ -236,7 +244,6 @@ type trackedIssueInfo struct {
 	UpdatedAt    time.Time // Fallback for activity when no assignee
 }
 
-
 // getTrackedIssues fetches tracked issues for a convoy.
 func (f *LiveConvoyFetcher) getTrackedIssues(convoyID string) ([]trackedIssueInfo, error) {
 	// Query tracked dependencies using bd dep list
@@ -1641,7 +1641,7 @@
             if (!line) continue;
             // Skip header lines and convoy summary lines
             if (line.startsWith('Convoy') || line.startsWith('===') || line.startsWith('---') ||
-                line.startsWith('Status:') || line.startsWith('Progress:') || line.startsWith('Created:') ||
+                line.startsWith('Status:') || line.startsWith('Progress:') || line.startsWith('Cost:') || line.startsWith('Created:') ||
                 line.startsWith('Title:') || line.startsWith('Issues:') || line.startsWith('Name:')) {
                 // Extract convoy-level status/progress for the detail header
                 if (line.startsWith('Status:')) {
@@ -2178,6 +2178,8 @@
         document.getElementById('issue-detail-blocks').innerHTML = '';
         document.getElementById('issue-detail-deps').style.display = 'none';
         document.getElementById('issue-detail-blocks-section').style.display = 'none';
+        document.getElementById('issue-detail-gate-output').innerHTML = '';
+        document.getElementById('issue-detail-gate-output-section').style.display = 'none';
 
         // Show detail view
         issuesList.style.display = 'none';
@@ -2246,6 +2248,16 @@
                     }).join(' ');
                     document.getElementById('issue-detail-blocks').innerHTML = blocksHtml;
                 }
+
+                // Refinery quality gate output (merge requests)
+                if (data.gate_output && data.gate_output.length > 0) {
+                    document.getElementById('issue-detail-gate-output-section').style.display = 'block';
+                    var gatesHtml = data.gate_output.map(function(g) {
+                        return '<a class="issue-gate-output-link" href="' + escapeHtml(g.url) + '" target="_blank" rel="noopener">' +
+                            (g.failed ? '✗ ' : '✓ ') + escapeHtml(g.gate) + '</a>';
+                    }).join(' ');
+                    document.getElementById('issue-detail-gate-output').innerHTML = gatesHtml;
+                }
             })
             .catch(function(err) {
                 document.getElementById('issue-detail-title-text').textContent = 'Error';
@@ -2289,6 +2301,11 @@
             html += '<button class="issue-action-btn close" onclick="closeIssue(\'' + escapeHtml(issueId) + '\')">✓ Close</button>';
         }
 
+        // Approve button (merge requests held for human sign-off)
+        if (data.approval && !isClosed) {
+            html += '<button class="issue-action-btn approve" onclick="approveMR(\'' + escapeHtml(data.approval.rig) + '\', \'' + escapeHtml(issueId) + '\')">✓ Approve merge</button>';
+        }
+
         // Priority dropdown
         html += '<div class="issue-action-group">';
         html += '<label class="issue-action-label">Priority</label>';
@@ -2388,6 +2405,32 @@
     }
     window.closeIssue = closeIssue;
 
+    // Approve a merge request held for human sign-off
+    function approveMR(rig, issueId) {
+        if (!confirm('Approve merge request ' + issueId + '?')) return;
+
+        showToast('info', 'Approving...', issueId);
+
+        fetch('/api/run', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ command: 'mq approve ' + rig + ' ' + issueId })
+        })
+        .then(function(r) { return r.json(); })
+        .then(function(data) {
+            if (data.success) {
+                showToast('success', 'Approved', issueId + ' approved');
+                openIssueDetail(issueId);
+            } else {
+                showToast('error', 'Failed', data.error || 'Unknown error');
+            }
+        })
+        .catch(function(err) {
+            showToast('error', 'Error', err.message);
+        });
+    }
+    window.approveMR = approveMR;
+
     // Reopen an issue
     function reopenIssue(issueId) {
         showToast('info', 'Reopening...', issueId);
@@ -3097,7 +3140,7 @@
         var detailRow = document.createElement('tr');
         detailRow.className = 'convoy-detail-row';
         var detailCell = document.createElement('td');
-        detailCell.colSpan = 4;
+        detailCell.colSpan = 5;
         detailCell.innerHTML = '<div class="tracked-issues"><div class="tracked-issues-loading">Loading tracked issues...</div></div>';
         detailRow.appendChild(detailCell);
         row.parentNode.insertBefore(detailRow, row.nextSibling);
@@ -204,6 +204,7 @@ type ConvoyRow struct {
 	Completed     int
 	Total         int
 	LastActivity  activity.Info
+	CostUSD       float64 // Recorded agent cost attributed to the convoy
 	TrackedIssues []TrackedIssue
 }
 
@@ -360,7 +360,18 @@ func HandleMergeFailed(workDir, rigName string, msg *mail.Message, router *mail.
 		return result
 	}
 
-	// Notify the polecat about the failure
+	// Notify the polecat about the failure, passing on the gate output so
+	// the failure can be fixed without rerunning the whole suite blind.
+	var output string
+	if payload.Artifacts != "" {
+		output += fmt.Sprintf("Full gate output: %s\n", payload.Artifacts)
+	}
+	if payload.OutputTail != "" {
+		output += fmt.Sprintf("\n%s\n%s\n", gateOutputMarker, payload.OutputTail)
+	}
+	if output != "" {
+		output = "\n" + output
+	}
 	polecatAddr := fmt.Sprintf("%s/polecats/%s", rigName, payload.PolecatName)
 	notification := &mail.Message{
 		From:     fmt.Sprintf("%s/witness", rigName),
@@ -374,12 +385,13 @@ Branch: %s
 Issue: %s
 Failure: %s
 Error: %s
-
+%s
 Please fix the issue and resubmit with 'gt done'.`,
 			payload.Branch,
 			payload.IssueID,
 			payload.FailureType,
 			payload.Error,
+			output,
 		),
 	}
 
@@ -765,9 +777,12 @@ func NukePolecat(workDir, rigName, polecatName string) error {
 	// We do this explicitly here because gt polecat nuke may fail to kill the
 	// session due to rig loading issues or race conditions with IsRunning checks.
 	// See: gt-g9ft5 - sessions were piling up because nuke wasn't killing them.
-	initRegistryFromWorkDir(workDir)
+	townRoot, _ := workspace.Find(workDir)
+	if townRoot != "" {
+		_ = session.InitRegistry(townRoot)
+	}
 	sessionName := session.PolecatSessionName(session.PrefixFor(rigName), polecatName)
-	t := tmux.NewTmux()
+	t := sessionsForRig(townRoot, rigName)
 
 	// Check if session exists and kill it
 	if running, _ := t.HasSession(sessionName); running {
@@ -776,7 +791,7 @@ func NukePolecat(workDir, rigName, polecatName string) error {
 		// Brief delay for graceful handling
 		time.Sleep(100 * time.Millisecond)
 		// Force kill the session
-		if err := t.KillSession(sessionName); err != nil {
+		if err := t.KillSessionWithProcesses(sessionName); err != nil {
 			// Log but continue - session might already be dead
 			// The important thing is we tried
 		}
@@ -980,7 +995,7 @@ func DetectZombiePolecats(workDir, rigName string, router *mail.Router) *DetectZ
 		return result
 	}
 
-	t := tmux.NewTmux()
+	t := sessionsForRig(townRoot, rigName)
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -1086,7 +1101,7 @@ func DetectZombiePolecats(workDir, rigName string, router *mail.Router) *DetectZ
 
 // detectZombieLiveSession checks a polecat with a live tmux session for zombie indicators:
 // stuck done-intent, dead agent process, or closed bead while still running.
-func detectZombieLiveSession(workDir, rigName, polecatName, agentBeadID, sessionName string, t *tmux.Tmux, doneIntent *DoneIntent, router *mail.Router) (ZombieResult, bool) {
+func detectZombieLiveSession(workDir, rigName, polecatName, agentBeadID, sessionName string, t polecatSessions, doneIntent *DoneIntent, router *mail.Router) (ZombieResult, bool) {
 	// Check for done-intent stuck too long (polecat hung in gt done).
 	if doneIntent != nil && time.Since(doneIntent.Timestamp) > 60*time.Second {
 		_, stuckHookBead := getAgentBeadState(workDir, agentBeadID)
@@ -1142,7 +1157,7 @@ func detectZombieLiveSession(workDir, rigName, polecatName, agentBeadID, session
 
 // detectZombieDeadSession checks a polecat with a dead tmux session for zombie indicators:
 // stale done-intent, or active agent state / hooked bead with no session.
-func detectZombieDeadSession(workDir, rigName, polecatName, agentBeadID, sessionName string, t *tmux.Tmux, doneIntent *DoneIntent, detectedAt time.Time, router *mail.Router) (ZombieResult, bool) {
+func detectZombieDeadSession(workDir, rigName, polecatName, agentBeadID, sessionName string, t polecatSessions, doneIntent *DoneIntent, detectedAt time.Time, router *mail.Router) (ZombieResult, bool) {
 	// Done-intent: polecat was trying to exit.
 	if doneIntent != nil {
 		age := time.Since(doneIntent.Timestamp)
@@ -1288,7 +1303,7 @@ func DetectStalledPolecats(workDir, rigName string) *DetectStalledPolecatsResult
 		return result // No polecats directory
 	}
 
-	t := tmux.NewTmux()
+	t := sessionsForRig(townRoot, rigName)
 
 	for _, entry := range entries {
 		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
@@ -1478,7 +1493,7 @@ func DetectOrphanedBeads(workDir, rigName string, router *mail.Router) *DetectOr
 		beadList = append(beadList, batch...)
 	}
 
-	t := tmux.NewTmux()
+	t := sessionsForRig(townRoot, rigName)
 
 	for _, bead := range beadList {
 		if bead.Assignee == "" {
@@ -1620,7 +1635,7 @@ func DetectOrphanedMolecules(workDir, rigName string, router *mail.Router) *Dete
 
 	// Step 2: Check each polecat-assigned bead
 	polecatPrefix := rigName + "/polecats/"
-	t := tmux.NewTmux()
+	t := sessionsForRig(townRoot, rigName)
 	polecatsDir := filepath.Join(townRoot, rigName, "polecats")
 
 	for _, b := range allBeads {
@@ -1854,7 +1869,7 @@ func getAgentBeadLabels(workDir, agentBeadID string) []string {
 // sessionRecreated checks whether a tmux session was (re)created after the
 // given timestamp. Returns true if the session exists and was created after
 // detectedAt, indicating a new session replaced the dead one (TOCTOU guard).
-func sessionRecreated(t *tmux.Tmux, sessionName string, detectedAt time.Time) bool {
+func sessionRecreated(t polecatSessions, sessionName string, detectedAt time.Time) bool {
 	alive, err := t.HasSession(sessionName)
 	if err != nil || !alive {
 		return false // Still dead — not recreated
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/connection"
 	"github.com/steveyegge/gastown/internal/tmux"
 )
 
@@ -1249,3 +1250,26 @@ esac
 	}
 }
 
+
+// flakyConn is a remote connection whose tmux queries fail.
+type flakyConn struct {
+	connection.Connection
+}
+
+func (flakyConn) Name() string { return "flaky" }
+
+func (flakyConn) TmuxHasSession(string) (bool, error) {
+	return false, fmt.Errorf("ssh: connection reset")
+}
+
+func TestDetectZombieLiveSession_RemoteErrorIsNotZombie(t *testing.T) {
+	sessions := remoteSessions{conn: flakyConn{}}
+	if !sessions.IsAgentAlive("gt-nux") {
+		t.Fatal("IsAgentAlive should report true when the machine can't be asked")
+	}
+
+	zombie, found := detectZombieLiveSession(t.TempDir(), "gastown", "nux", "gt-gastown-polecat-nux", "gt-nux", sessions, nil, nil)
+	if found {
+		t.Errorf("SSH error made a live polecat a zombie: %+v", zombie)
+	}
+}
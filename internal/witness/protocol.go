@@ -95,9 +95,15 @@ type MergeFailedPayload struct {
 	IssueID     string
 	FailureType string // "build", "test", "lint", etc.
 	Error       string
+	Artifacts   string // Directory with the full gate output, if captured
+	OutputTail  string // Tail of the failing gates' output
 	FailedAt    time.Time
 }
 
+// gateOutputMarker introduces the gate output tail in a MERGE_FAILED body
+// (protocol.GateOutputMarker; protocol imports this package).
+const gateOutputMarker = "--- Gate output (tail) ---"
+
 // SwarmStartPayload contains parsed data from a SWARM_START message.
 type SwarmStartPayload struct {
 	SwarmID   string
@@ -247,6 +253,10 @@ func ParseMerged(subject, body string) (*MergedPayload, error) {
 //	Issue: <issue-id>
 //	FailureType: <type>
 //	Error: <error-message>
+//	Artifacts: <dir>
+//
+//	--- Gate output (tail) ---
+//	<output lines>
 func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 	matches := PatternMergeFailed.FindStringSubmatch(subject)
 	if len(matches) < 2 {
@@ -258,6 +268,12 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 		FailedAt:    time.Now(),
 	}
 
+	// Gate output is free text; only the lines before it are fields.
+	if idx := strings.Index(body, gateOutputMarker); idx != -1 {
+		payload.OutputTail = strings.Trim(body[idx+len(gateOutputMarker):], "\n")
+		body = body[:idx]
+	}
+
 	// Parse body for structured fields
 	for _, line := range strings.Split(body, "\n") {
 		line = strings.TrimSpace(line)
@@ -270,6 +286,8 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 			payload.FailureType = strings.TrimSpace(strings.TrimPrefix(line, "FailureType:"))
 		case strings.HasPrefix(line, "Error:"):
 			payload.Error = strings.TrimSpace(strings.TrimPrefix(line, "Error:"))
+		case strings.HasPrefix(line, "Artifacts:"):
+			payload.Artifacts = strings.TrimSpace(strings.TrimPrefix(line, "Artifacts:"))
 		}
 	}
 
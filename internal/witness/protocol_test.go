@@ -193,6 +193,31 @@ Error: unit tests failed with 3 errors`
 	}
 }
 
+func TestParseMergeFailed_GateOutput(t *testing.T) {
+	body := `Branch: feature-nux
+Error: quality gates failed
+Artifacts: /town/gastown/.runtime/refinery/gate-output/gt-mr1
+
+--- Gate output (tail) ---
+[test]
+Error: want 1, got 2
+FAIL
+`
+	payload, err := ParseMergeFailed("MERGE_FAILED nux", body)
+	if err != nil {
+		t.Fatalf("ParseMergeFailed() error = %v", err)
+	}
+	if payload.Error != "quality gates failed" {
+		t.Errorf("Error = %q, want header value", payload.Error)
+	}
+	if payload.Artifacts != "/town/gastown/.runtime/refinery/gate-output/gt-mr1" {
+		t.Errorf("Artifacts = %q", payload.Artifacts)
+	}
+	if payload.OutputTail != "[test]\nError: want 1, got 2\nFAIL" {
+		t.Errorf("OutputTail = %q", payload.OutputTail)
+	}
+}
+
 func TestParseMergeFailed_MinimalBody(t *testing.T) {
 	subject := "MERGE_FAILED ace"
 	body := "FailureType: build"
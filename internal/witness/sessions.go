@@ -0,0 +1,100 @@
+package witness
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
+)
+
+// polecatSessions is the subset of session operations the witness uses to
+// inspect and stop polecat sessions. The town's session.SessionBackend satisfies it
+// for local rigs; rigs
+// whose polecats run on a remote machine get an adapter over the machine's
+// connection.Connection.
+type polecatSessions interface {
+	HasSession(name string) (bool, error)
+	IsAgentAlive(session string) bool
+	GetSessionActivity(session string) (time.Time, error)
+	CapturePane(session string, lines int) (string, error)
+	AcceptBypassPermissionsWarning(session string) error
+	SendKeysRaw(session, keys string) error
+	KillSessionWithProcesses(name string) error
+}
+
+// sessionsForRig returns the session inspector for a rig's polecats.
+// If the rig is remote but its machine can't be reached, every check errors
+// so patrols skip the rig's polecats instead of treating them as dead.
+func sessionsForRig(townRoot, rigName string) polecatSessions {
+	cfg, err := rig.LoadRigConfig(filepath.Join(townRoot, rigName))
+	if err != nil || cfg.Machine == "" || cfg.Machine == "local" {
+		return session.NewBackend(townRoot)
+	}
+	rm, err := rig.LoadRemoteMachine(townRoot, cfg.Machine)
+	if err != nil {
+		return unreachableSessions{err: fmt.Errorf("rig %s on machine %s: %w", rigName, cfg.Machine, err)}
+	}
+	return remoteSessions{conn: rm.Conn}
+}
+
+// remoteSessions adapts a Connection to polecatSessions.
+// Remote polecat sessions exec the agent directly, so the session's
+// existence doubles as agent liveness. Pane activity isn't tracked remotely,
+// which disables hung-session detection for remote rigs.
+type remoteSessions struct {
+	conn connection.Connection
+}
+
+func (r remoteSessions) HasSession(name string) (bool, error) {
+	return r.conn.TmuxHasSession(name)
+}
+
+// IsAgentAlive reports true when the machine can't be asked, like
+// unreachableSessions: a brief SSH failure must not make live polecats look
+// like zombies.
+func (r remoteSessions) IsAgentAlive(session string) bool {
+	alive, err := r.conn.TmuxHasSession(session)
+	return err != nil || alive
+}
+
+func (r remoteSessions) GetSessionActivity(string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (r remoteSessions) CapturePane(session string, lines int) (string, error) {
+	return r.conn.TmuxCapturePane(session, lines)
+}
+
+func (r remoteSessions) AcceptBypassPermissionsWarning(string) error {
+	return fmt.Errorf("not supported on remote machine %s", r.conn.Name())
+}
+
+func (r remoteSessions) SendKeysRaw(string, string) error {
+	return fmt.Errorf("not supported on remote machine %s", r.conn.Name())
+}
+
+func (r remoteSessions) KillSessionWithProcesses(name string) error {
+	return r.conn.TmuxKillSession(name)
+}
+
+// unreachableSessions reports a fixed error for every session check.
+type unreachableSessions struct {
+	err error
+}
+
+func (u unreachableSessions) HasSession(string) (bool, error) { return false, u.err }
+
+func (u unreachableSessions) IsAgentAlive(string) bool { return true }
+
+func (u unreachableSessions) GetSessionActivity(string) (time.Time, error) { return time.Time{}, u.err }
+
+func (u unreachableSessions) CapturePane(string, int) (string, error) { return "", u.err }
+
+func (u unreachableSessions) AcceptBypassPermissionsWarning(string) error { return u.err }
+
+func (u unreachableSessions) SendKeysRaw(string, string) error { return u.err }
+
+func (u unreachableSessions) KillSessionWithProcesses(string) error { return u.err }